@@ -0,0 +1,67 @@
+package catalogo
+
+import (
+	"sync"
+	"time"
+)
+
+// maxEntradas bounds the memory a cache of free text searches can take.
+const maxEntradas = 10000
+
+// Cache keeps catalog lookups for TTL. Catalogs only change when a new
+// publication is imported, by a command that can't reach the API's memory,
+// so entries simply expire.
+type Cache[V any] struct {
+	mu       sync.Mutex
+	entradas map[string]entrada[V]
+	TTL      time.Duration
+	Now      func() time.Time
+}
+
+type entrada[V any] struct {
+	valor  V
+	expira time.Time
+}
+
+func NewCache[V any](ttl time.Duration) *Cache[V] {
+	return &Cache[V]{entradas: make(map[string]entrada[V]), TTL: ttl, Now: time.Now}
+}
+
+func (c *Cache[V]) Get(key string) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.entradas[key]
+	if !ok {
+		var cero V
+		return cero, false
+	}
+
+	if !c.Now().Before(e.expira) {
+		delete(c.entradas, key)
+		var cero V
+		return cero, false
+	}
+
+	return e.valor, true
+}
+
+func (c *Cache[V]) Set(key string, valor V) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.Now()
+	if len(c.entradas) >= maxEntradas {
+		for key, e := range c.entradas {
+			if !now.Before(e.expira) {
+				delete(c.entradas, key)
+			}
+		}
+	}
+
+	if len(c.entradas) >= maxEntradas {
+		c.entradas = make(map[string]entrada[V])
+	}
+
+	c.entradas[key] = entrada[V]{valor: valor, expira: now.Add(c.TTL)}
+}
@@ -0,0 +1,76 @@
+package catalogo
+
+import "strings"
+
+// Names of the catalogs other packages look up.
+const (
+	ClaveProdServ = "c_ClaveProdServ"
+	CodigoPostal  = "c_CodigoPostal"
+	FormaPago     = "c_FormaPago"
+	RegimenFiscal = "c_RegimenFiscal"
+	UsoCFDI       = "c_UsoCFDI"
+)
+
+// Catalogo is a sheet of catCFDI.xls the API imports. Slug names it in
+// the URLs. Longitud is the width of numeric claves, which spreadsheets
+// tend to export without their leading zeros.
+type Catalogo struct {
+	Nombre   string `json:"nombre"`
+	Slug     string `json:"slug"`
+	Longitud int    `json:"-"`
+}
+
+// Catalogos are the supported sheets. c_TasaOCuota, whose rows are ranges,
+// and the catalogs keyed by estado, like c_Municipio, are left out.
+var Catalogos = []Catalogo{
+	{Nombre: "c_Aduana", Slug: "aduana", Longitud: 2},
+	{Nombre: ClaveProdServ, Slug: "clave-prod-serv", Longitud: 8},
+	{Nombre: "c_ClaveUnidad", Slug: "clave-unidad"},
+	{Nombre: CodigoPostal, Slug: "codigo-postal", Longitud: 5},
+	{Nombre: "c_Exportacion", Slug: "exportacion", Longitud: 2},
+	{Nombre: FormaPago, Slug: "forma-pago", Longitud: 2},
+	{Nombre: "c_Impuesto", Slug: "impuesto", Longitud: 3},
+	{Nombre: "c_Meses", Slug: "meses", Longitud: 2},
+	{Nombre: "c_MetodoPago", Slug: "metodo-pago"},
+	{Nombre: "c_Moneda", Slug: "moneda"},
+	{Nombre: "c_ObjetoImp", Slug: "objeto-imp", Longitud: 2},
+	{Nombre: "c_Pais", Slug: "pais"},
+	{Nombre: "c_Periodicidad", Slug: "periodicidad", Longitud: 2},
+	{Nombre: RegimenFiscal, Slug: "regimen-fiscal", Longitud: 3},
+	{Nombre: "c_TipoDeComprobante", Slug: "tipo-de-comprobante"},
+	{Nombre: "c_TipoFactor", Slug: "tipo-factor"},
+	{Nombre: "c_TipoRelacion", Slug: "tipo-relacion", Longitud: 2},
+	{Nombre: UsoCFDI, Slug: "uso-cfdi"},
+}
+
+// PorNombre finds a catalog by its sheet name, ignoring case.
+func PorNombre(nombre string) (Catalogo, bool) {
+	for _, catalogo := range Catalogos {
+		if strings.EqualFold(catalogo.Nombre, strings.TrimSpace(nombre)) {
+			return catalogo, true
+		}
+	}
+
+	return Catalogo{}, false
+}
+
+// PorSlug finds a catalog by its slug.
+func PorSlug(slug string) (Catalogo, bool) {
+	for _, catalogo := range Catalogos {
+		if catalogo.Slug == slug {
+			return catalogo, true
+		}
+	}
+
+	return Catalogo{}, false
+}
+
+// NormalizarClave restores the leading zeros of numeric claves.
+func (c Catalogo) NormalizarClave(clave string) string {
+	clave = strings.TrimSpace(clave)
+	if clave == "" || c.Longitud == 0 || len(clave) >= c.Longitud || strings.Trim(clave, "0123456789") != "" {
+		return clave
+	}
+
+	return strings.Repeat("0", c.Longitud-len(clave)) + clave
+}
@@ -0,0 +1,241 @@
+package catalogo
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/unicode/norm"
+)
+
+var (
+	ErrCatalogoInvalido    = errors.New("catalogo: invalid catalog file")
+	ErrCatalogoDesconocido = errors.New("catalogo: unsupported catalog")
+)
+
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// fechaLayouts are the ways spreadsheets export the vigencia dates, day
+// first as in the es-MX locale.
+var fechaLayouts = []string{"2006-01-02", "2006-01-02 15:04:05", "02/01/2006", "2/1/2006", "02/01/2006 15:04"}
+
+// Registro is a row of a catalog. Columns other than the clave, the
+// description and the vigencia dates are kept in Atributos by their
+// normalized header, so "Aplica para tipo persona Física" becomes
+// "aplica_para_tipo_persona_fisica".
+type Registro struct {
+	Clave               string
+	Descripcion         string
+	FechaInicioVigencia *time.Time
+	FechaFinVigencia    *time.Time
+	Atributos           map[string]string
+}
+
+// LeerCSV parses a sheet of catCFDI.xls exported to CSV, in UTF-8 or
+// Windows-1252 and delimited by commas or semicolons. The title rows
+// above the header are skipped, the header is the row that starts with the
+// catalog name, like c_FormaPago. Rows that can't be parsed are skipped and
+// reported in the returned error together with the rows that could.
+func LeerCSV(r io.Reader) (Catalogo, []Registro, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return Catalogo{}, nil, fmt.Errorf("%w: %w", ErrCatalogoInvalido, err)
+	}
+
+	data = bytes.TrimPrefix(data, utf8BOM)
+	if !utf8.Valid(data) {
+		if data, err = charmap.Windows1252.NewDecoder().Bytes(data); err != nil {
+			return Catalogo{}, nil, fmt.Errorf("%w: %w", ErrCatalogoInvalido, err)
+		}
+	}
+
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.Comma = separador(data)
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+
+	var header *csvHeader
+	var registros []Registro
+	var errs []error
+	claves := make(map[string]bool)
+
+	for {
+		campos, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return Catalogo{}, nil, fmt.Errorf("%w: %w", ErrCatalogoInvalido, err)
+		}
+
+		if header == nil {
+			if header, err = parseCSVHeader(campos); err != nil {
+				return Catalogo{}, nil, err
+			}
+			continue
+		}
+
+		linea, _ := reader.FieldPos(0)
+
+		registro, err := header.registro(campos)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("line %d: %w", linea, err))
+			continue
+		}
+
+		// Blank rows and the second header row of some sheets.
+		if registro == nil {
+			continue
+		}
+
+		if claves[registro.Clave] {
+			errs = append(errs, fmt.Errorf("line %d: duplicate clave %s", linea, registro.Clave))
+			continue
+		}
+		claves[registro.Clave] = true
+
+		registros = append(registros, *registro)
+	}
+
+	if header == nil {
+		return Catalogo{}, nil, fmt.Errorf("%w: missing header", ErrCatalogoInvalido)
+	}
+
+	if len(errs) > 0 {
+		return header.catalogo, registros, fmt.Errorf("%w: %w", ErrCatalogoInvalido, errors.Join(errs...))
+	}
+
+	return header.catalogo, registros, nil
+}
+
+// separador tells commas from semicolons, which Excel uses in locales
+// with a decimal comma.
+func separador(data []byte) rune {
+	muestra := data[:min(len(data), 4096)]
+	if bytes.Count(muestra, []byte{';'}) > bytes.Count(muestra, []byte{','}) {
+		return ';'
+	}
+
+	return ','
+}
+
+// csvHeader maps the columns of a catalog to their position.
+type csvHeader struct {
+	catalogo    Catalogo
+	descripcion int
+	inicio      int
+	fin         int
+	atributos   map[int]string
+}
+
+// parseCSVHeader returns nil for the title rows above the header.
+func parseCSVHeader(campos []string) (*csvHeader, error) {
+	if len(campos) == 0 || !strings.HasPrefix(strings.TrimSpace(campos[0]), "c_") {
+		return nil, nil
+	}
+
+	catalogo, ok := PorNombre(campos[0])
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrCatalogoDesconocido, strings.TrimSpace(campos[0]))
+	}
+
+	header := &csvHeader{catalogo: catalogo, descripcion: -1, inicio: -1, fin: -1, atributos: make(map[int]string)}
+	for i, campo := range campos[1:] {
+		nombre := Normalizar(campo)
+		compacto := strings.ReplaceAll(nombre, "_", "")
+
+		switch {
+		case nombre == "":
+			continue
+		case nombre == "descripcion":
+			header.descripcion = i + 1
+		case strings.Contains(compacto, "iniciovigencia") || strings.Contains(compacto, "iniciodevigencia"):
+			header.inicio = i + 1
+		case strings.Contains(compacto, "finvigencia") || strings.Contains(compacto, "findevigencia"):
+			header.fin = i + 1
+		default:
+			header.atributos[i+1] = nombre
+		}
+	}
+
+	return header, nil
+}
+
+func (h *csvHeader) registro(campos []string) (*Registro, error) {
+	campo := func(i int) string {
+		if i < 0 || i >= len(campos) {
+			return ""
+		}
+
+		return strings.TrimSpace(campos[i])
+	}
+
+	clave := h.catalogo.NormalizarClave(campo(0))
+	if clave == "" {
+		return nil, nil
+	}
+
+	registro := &Registro{Clave: clave, Descripcion: campo(h.descripcion), Atributos: make(map[string]string)}
+
+	var err error
+	if registro.FechaInicioVigencia, err = parseFecha(campo(h.inicio)); err != nil {
+		return nil, fmt.Errorf("fecha inicio de vigencia: %w", err)
+	}
+
+	if registro.FechaFinVigencia, err = parseFecha(campo(h.fin)); err != nil {
+		return nil, fmt.Errorf("fecha fin de vigencia: %w", err)
+	}
+
+	for i, nombre := range h.atributos {
+		if valor := campo(i); valor != "" {
+			registro.Atributos[nombre] = valor
+		}
+	}
+
+	return registro, nil
+}
+
+func parseFecha(text string) (*time.Time, error) {
+	if text == "" {
+		return nil, nil
+	}
+
+	for _, layout := range fechaLayouts {
+		if fecha, err := time.Parse(layout, text); err == nil {
+			return &fecha, nil
+		}
+	}
+
+	return nil, fmt.Errorf("invalid date %q", text)
+}
+
+// Normalizar turns a header into an attribute name: lowercase, without
+// accents and with underscores between words.
+func Normalizar(text string) string {
+	var builder strings.Builder
+	guion := false
+
+	for _, r := range norm.NFD.String(strings.TrimSpace(text)) {
+		switch {
+		case unicode.Is(unicode.Mn, r):
+			continue
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if guion && builder.Len() > 0 {
+				builder.WriteByte('_')
+			}
+			builder.WriteRune(unicode.ToLower(r))
+			guion = false
+		default:
+			guion = true
+		}
+	}
+
+	return builder.String()
+}
@@ -0,0 +1,265 @@
+package cfdi
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const namespaceXSI = "http://www.w3.org/2001/XMLSchema-instance"
+
+// nodo is an element of the document with its attributes in document
+// order. The cadena original needs the attributes as written, which the
+// Comprobante struct doesn't keep.
+type nodo struct {
+	espacio   string
+	nombre    string
+	atributos []xml.Attr
+	hijos     []*nodo
+}
+
+func (n *nodo) hijo(nombre string) *nodo {
+	for _, hijo := range n.hijos {
+		if hijo.nombre == nombre {
+			return hijo
+		}
+	}
+
+	return nil
+}
+
+func (n *nodo) cadaHijo(nombre string, f func(*nodo)) {
+	for _, hijo := range n.hijos {
+		if hijo.nombre == nombre {
+			f(hijo)
+		}
+	}
+}
+
+// cadena accumulates the values of a cadena original.
+type cadena struct {
+	valores []string
+}
+
+// atributos adds the attributes in the given order, skipping the missing
+// ones, like the Requerido and Opcional templates of the SAT XSLT.
+func (c *cadena) atributos(n *nodo, nombres ...string) {
+	if n == nil {
+		return
+	}
+
+	for _, nombre := range nombres {
+		for _, atributo := range n.atributos {
+			if atributo.Name.Local == nombre {
+				c.valores = append(c.valores, normalizarEspacios(atributo.Value))
+				break
+			}
+		}
+	}
+}
+
+// generico adds every attribute and child in document order, what the
+// generic templates of the SAT XSLT do for elements they don't know.
+func (c *cadena) generico(n *nodo) {
+	for _, atributo := range n.atributos {
+		if atributo.Name.Space == "xmlns" || atributo.Name.Local == "xmlns" || atributo.Name.Space == namespaceXSI {
+			continue
+		}
+
+		c.valores = append(c.valores, normalizarEspacios(atributo.Value))
+	}
+
+	for _, hijo := range n.hijos {
+		c.generico(hijo)
+	}
+}
+
+// normalizarEspacios does what normalize-space() does in the SAT XSLT: it
+// trims and collapses runs of the XML whitespace, #x20, #x9, #xD and #xA.
+// Other Unicode spaces, like a no-break space, are part of the value.
+func normalizarEspacios(valor string) string {
+	return strings.Join(strings.FieldsFunc(valor, esEspacioXML), " ")
+}
+
+func esEspacioXML(r rune) bool {
+	return r == ' ' || r == '\t' || r == '\r' || r == '\n'
+}
+
+func (c *cadena) String() string {
+	return "||" + strings.Join(c.valores, "|") + "||"
+}
+
+// CadenasOriginales returns the cadena original of a CFDI 3.3 or 4.0, what
+// the emisor signs in Sello, and the one of its TimbreFiscalDigital, what
+// the SAT signs in SelloSAT. They follow the SAT XSLT for the Comprobante,
+// the TimbreFiscalDigital and the complements in plantillasComplementos,
+// other complements are added in document order. timbre is empty if the
+// CFDI is not stamped.
+func CadenasOriginales(data []byte) (comprobante string, timbre string, err error) {
+	raiz, err := leerNodos(data)
+	if err != nil {
+		return "", "", err
+	}
+
+	if raiz.nombre != "Comprobante" {
+		return "", "", ErrNotComprobante
+	}
+
+	c := &cadena{}
+	cadenaComprobante(c, raiz)
+
+	if complemento := raiz.hijo("Complemento"); complemento != nil {
+		if tfd := complemento.hijo("TimbreFiscalDigital"); tfd != nil {
+			t := &cadena{}
+			t.atributos(tfd, "Version", "UUID", "FechaTimbrado", "RfcProvCertif", "Leyenda", "SelloCFD", "NoCertificadoSAT")
+			timbre = t.String()
+		}
+	}
+
+	return c.String(), timbre, nil
+}
+
+// cadenaComprobante follows cadenaoriginal_4_0.xslt. It also serves 3.3
+// documents, whose nodes lack some attributes but keep the same order.
+func cadenaComprobante(c *cadena, comprobante *nodo) {
+	c.atributos(comprobante, "Version", "Serie", "Folio", "Fecha", "FormaPago", "NoCertificado", "CondicionesDePago",
+		"SubTotal", "Descuento", "Moneda", "TipoCambio", "Total", "TipoDeComprobante", "Exportacion", "MetodoPago",
+		"LugarExpedicion", "Confirmacion")
+
+	c.atributos(comprobante.hijo("InformacionGlobal"), "Periodicidad", "Meses", "Año")
+
+	comprobante.cadaHijo("CfdiRelacionados", func(relacionados *nodo) {
+		c.atributos(relacionados, "TipoRelacion")
+		relacionados.cadaHijo("CfdiRelacionado", func(relacionado *nodo) {
+			c.atributos(relacionado, "UUID")
+		})
+	})
+
+	c.atributos(comprobante.hijo("Emisor"), "Rfc", "Nombre", "RegimenFiscal", "FacAtrAdquirente")
+
+	c.atributos(comprobante.hijo("Receptor"), "Rfc", "Nombre", "DomicilioFiscalReceptor", "ResidenciaFiscal",
+		"NumRegIdTrib", "RegimenFiscalReceptor", "UsoCFDI")
+
+	if conceptos := comprobante.hijo("Conceptos"); conceptos != nil {
+		conceptos.cadaHijo("Concepto", func(concepto *nodo) {
+			cadenaConcepto(c, concepto)
+		})
+	}
+
+	if impuestos := comprobante.hijo("Impuestos"); impuestos != nil {
+		if retenciones := impuestos.hijo("Retenciones"); retenciones != nil {
+			retenciones.cadaHijo("Retencion", func(retencion *nodo) {
+				c.atributos(retencion, "Impuesto", "Importe")
+			})
+		}
+		c.atributos(impuestos, "TotalImpuestosRetenidos")
+
+		if traslados := impuestos.hijo("Traslados"); traslados != nil {
+			traslados.cadaHijo("Traslado", func(traslado *nodo) {
+				c.atributos(traslado, "Base", "Impuesto", "TipoFactor", "TasaOCuota", "Importe")
+			})
+		}
+		c.atributos(impuestos, "TotalImpuestosTrasladados")
+	}
+
+	// The TimbreFiscalDigital is added after the emisor signs.
+	if complemento := comprobante.hijo("Complemento"); complemento != nil {
+		for _, hijo := range complemento.hijos {
+			if hijo.nombre != "TimbreFiscalDigital" {
+				cadenaComplemento(c, hijo)
+			}
+		}
+	}
+}
+
+func cadenaConcepto(c *cadena, concepto *nodo) {
+	c.atributos(concepto, "ClaveProdServ", "NoIdentificacion", "Cantidad", "ClaveUnidad", "Unidad", "Descripcion",
+		"ValorUnitario", "Importe", "Descuento", "ObjetoImp")
+
+	if impuestos := concepto.hijo("Impuestos"); impuestos != nil {
+		if traslados := impuestos.hijo("Traslados"); traslados != nil {
+			traslados.cadaHijo("Traslado", func(traslado *nodo) {
+				c.atributos(traslado, "Base", "Impuesto", "TipoFactor", "TasaOCuota", "Importe")
+			})
+		}
+
+		if retenciones := impuestos.hijo("Retenciones"); retenciones != nil {
+			retenciones.cadaHijo("Retencion", func(retencion *nodo) {
+				c.atributos(retencion, "Base", "Impuesto", "TipoFactor", "TasaOCuota", "Importe")
+			})
+		}
+	}
+
+	c.atributos(concepto.hijo("ACuentaTerceros"), "RfcACuentaTerceros", "NombreACuentaTerceros",
+		"RegimenFiscalACuentaTerceros", "DomicilioFiscalACuentaTerceros")
+
+	concepto.cadaHijo("InformacionAduanera", func(aduanera *nodo) {
+		c.atributos(aduanera, "NumeroPedimento")
+	})
+
+	concepto.cadaHijo("CuentaPredial", func(predial *nodo) {
+		c.atributos(predial, "Numero")
+	})
+
+	if complemento := concepto.hijo("ComplementoConcepto"); complemento != nil {
+		for _, hijo := range complemento.hijos {
+			cadenaComplemento(c, hijo)
+		}
+	}
+
+	concepto.cadaHijo("Parte", func(parte *nodo) {
+		c.atributos(parte, "ClaveProdServ", "NoIdentificacion", "Cantidad", "Unidad", "Descripcion", "ValorUnitario",
+			"Importe")
+		parte.cadaHijo("InformacionAduanera", func(aduanera *nodo) {
+			c.atributos(aduanera, "NumeroPedimento")
+		})
+	})
+}
+
+// leerNodos reads the element tree of the document, decoding it the same
+// way as Parse.
+func leerNodos(data []byte) (*nodo, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(bytes.TrimPrefix(data, utf8BOM)))
+	decoder.CharsetReader = charsetReader
+
+	var raiz *nodo
+	var pila []*nodo
+
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrNotComprobante, err)
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			n := &nodo{espacio: t.Name.Space, nombre: t.Name.Local, atributos: t.Copy().Attr}
+
+			if len(pila) == 0 {
+				if raiz != nil {
+					return nil, ErrNotComprobante
+				}
+				raiz = n
+			} else {
+				padre := pila[len(pila)-1]
+				padre.hijos = append(padre.hijos, n)
+			}
+
+			pila = append(pila, n)
+		case xml.EndElement:
+			pila = pila[:len(pila)-1]
+		}
+	}
+
+	if raiz == nil {
+		return nil, ErrNotComprobante
+	}
+
+	return raiz, nil
+}
@@ -0,0 +1,364 @@
+package cfdi
+
+// Namespaces of the complements whose cadena original is built from the
+// templates below.
+const (
+	NamespacePagos10          = "http://www.sat.gob.mx/Pagos"
+	NamespacePagos20          = "http://www.sat.gob.mx/Pagos20"
+	NamespaceNomina12         = "http://www.sat.gob.mx/nomina12"
+	NamespaceCartaPorte30     = "http://www.sat.gob.mx/CartaPorte30"
+	NamespaceCartaPorte31     = "http://www.sat.gob.mx/CartaPorte31"
+	NamespaceImpuestosLocales = "http://www.sat.gob.mx/implocal"
+)
+
+// plantilla is the template of the SAT XSLT for an element of a complement:
+// its attributes in the order the XSLT reads them, then each kind of child
+// in turn. The XSLT doesn't follow the document, a Pago lists its
+// DoctoRelacionado before its ImpuestosP wherever they were written.
+type plantilla struct {
+	nombre    string
+	atributos []string
+	hijos     []*plantilla
+}
+
+// cadenaPlantilla adds n and its children following p.
+func cadenaPlantilla(c *cadena, n *nodo, p *plantilla) {
+	c.atributos(n, p.atributos...)
+
+	for _, hijo := range p.hijos {
+		n.cadaHijo(hijo.nombre, func(h *nodo) {
+			cadenaPlantilla(c, h, hijo)
+		})
+	}
+}
+
+// plantillasComplementos are the templates of the complements by namespace
+// and root element. Complements missing here are added in document order.
+var plantillasComplementos = map[string]*plantilla{
+	NamespacePagos10 + " Pagos":                     pagos10,
+	NamespacePagos20 + " Pagos":                     pagos20,
+	NamespaceNomina12 + " Nomina":                   nomina12,
+	NamespaceCartaPorte30 + " CartaPorte":           cartaPorte30,
+	NamespaceCartaPorte31 + " CartaPorte":           cartaPorte31,
+	NamespaceImpuestosLocales + " ImpuestosLocales": impuestosLocales,
+}
+
+// cadenaComplemento adds a complement of the Comprobante or of a Concepto.
+func cadenaComplemento(c *cadena, complemento *nodo) {
+	if p, ok := plantillasComplementos[complemento.espacio+" "+complemento.nombre]; ok {
+		cadenaPlantilla(c, complemento, p)
+		return
+	}
+
+	c.generico(complemento)
+}
+
+// pagos10 follows Pagos10.xslt.
+var pagos10 = &plantilla{
+	nombre:    "Pagos",
+	atributos: []string{"Version"},
+	hijos: []*plantilla{{
+		nombre: "Pago",
+		atributos: []string{"FechaPago", "FormaDePagoP", "MonedaP", "TipoCambioP", "Monto", "NumOperacion",
+			"RfcEmisorCtaOrd", "NomBancoOrdExt", "CtaOrdenante", "RfcEmisorCtaBen", "CtaBeneficiario", "TipoCadPago",
+			"CertPago", "CadPago", "SelloPago"},
+		hijos: []*plantilla{
+			{
+				nombre: "DoctoRelacionado",
+				atributos: []string{"IdDocumento", "Serie", "Folio", "MonedaDR", "TipoCambioDR", "MetodoDePagoDR",
+					"NumParcialidad", "ImpSaldoAnt", "ImpPagado", "ImpSaldoInsoluto"},
+			},
+			{
+				nombre:    "Impuestos",
+				atributos: []string{"TotalImpuestosRetenidos", "TotalImpuestosTrasladados"},
+				hijos: []*plantilla{
+					{nombre: "Retenciones", hijos: []*plantilla{
+						{nombre: "Retencion", atributos: []string{"Impuesto", "Importe"}},
+					}},
+					{nombre: "Traslados", hijos: []*plantilla{
+						{nombre: "Traslado", atributos: []string{"Impuesto", "TipoFactor", "TasaOCuota", "Importe"}},
+					}},
+				},
+			},
+		},
+	}},
+}
+
+// pagos20 follows Pagos20.xslt.
+var pagos20 = &plantilla{
+	nombre:    "Pagos",
+	atributos: []string{"Version"},
+	hijos: []*plantilla{
+		{
+			nombre: "Totales",
+			atributos: []string{"TotalRetencionesIVA", "TotalRetencionesISR", "TotalRetencionesIEPS",
+				"TotalTrasladosBaseIVA16", "TotalTrasladosImpuestoIVA16", "TotalTrasladosBaseIVA8",
+				"TotalTrasladosImpuestoIVA8", "TotalTrasladosBaseIVA0", "TotalTrasladosImpuestoIVA0",
+				"TotalTrasladosBaseIVAExento", "MontoTotalPagos"},
+		},
+		{
+			nombre: "Pago",
+			atributos: []string{"FechaPago", "FormaDePagoP", "MonedaP", "TipoCambioP", "Monto", "NumOperacion",
+				"RfcEmisorCtaOrd", "NomBancoOrdExt", "CtaOrdenante", "RfcEmisorCtaBen", "CtaBeneficiario",
+				"TipoCadPago", "CertPago", "CadPago", "SelloPago"},
+			hijos: []*plantilla{
+				{
+					nombre: "DoctoRelacionado",
+					atributos: []string{"IdDocumento", "Serie", "Folio", "MonedaDR", "EquivalenciaDR", "NumParcialidad",
+						"ImpSaldoAnt", "ImpPagado", "ImpSaldoInsoluto", "ObjetoImpDR"},
+					hijos: []*plantilla{{
+						nombre: "ImpuestosDR",
+						hijos: []*plantilla{
+							{nombre: "RetencionesDR", hijos: []*plantilla{{
+								nombre:    "RetencionDR",
+								atributos: []string{"BaseDR", "ImpuestoDR", "TipoFactorDR", "TasaOCuotaDR", "ImporteDR"},
+							}}},
+							{nombre: "TrasladosDR", hijos: []*plantilla{{
+								nombre:    "TrasladoDR",
+								atributos: []string{"BaseDR", "ImpuestoDR", "TipoFactorDR", "TasaOCuotaDR", "ImporteDR"},
+							}}},
+						},
+					}},
+				},
+				{
+					nombre: "ImpuestosP",
+					hijos: []*plantilla{
+						{nombre: "RetencionesP", hijos: []*plantilla{{
+							nombre:    "RetencionP",
+							atributos: []string{"ImpuestoP", "ImporteP"},
+						}}},
+						{nombre: "TrasladosP", hijos: []*plantilla{{
+							nombre:    "TrasladoP",
+							atributos: []string{"BaseP", "ImpuestoP", "TipoFactorP", "TasaOCuotaP", "ImporteP"},
+						}}},
+					},
+				},
+			},
+		},
+	},
+}
+
+// nomina12 follows nomina12.xslt.
+var nomina12 = &plantilla{
+	nombre: "Nomina",
+	atributos: []string{"Version", "TipoNomina", "FechaPago", "FechaInicialPago", "FechaFinalPago", "NumDiasPagados",
+		"TotalPercepciones", "TotalDeducciones", "TotalOtrosPagos"},
+	hijos: []*plantilla{
+		{
+			nombre:    "Emisor",
+			atributos: []string{"Curp", "RegistroPatronal", "RfcPatronOrigen"},
+			hijos: []*plantilla{
+				{nombre: "EntidadSNCF", atributos: []string{"OrigenRecurso", "MontoRecursoPropio"}},
+			},
+		},
+		{
+			nombre: "Receptor",
+			atributos: []string{"Curp", "NumSeguridadSocial", "FechaInicioRelLaboral", "Antigüedad", "TipoContrato",
+				"Sindicalizado", "TipoJornada", "TipoRegimen", "NumEmpleado", "Departamento", "Puesto", "RiesgoPuesto",
+				"PeriodicidadPago", "Banco", "CuentaBancaria", "SalarioBaseCotApor", "SalarioDiarioIntegrado",
+				"ClaveEntFed"},
+			hijos: []*plantilla{
+				{nombre: "SubContratacion", atributos: []string{"RfcLabora", "PorcentajeTiempo"}},
+			},
+		},
+		{
+			nombre: "Percepciones",
+			atributos: []string{"TotalSueldos", "TotalSeparacionIndemnizacion", "TotalJubilacionPensionRetiro",
+				"TotalGravado", "TotalExento"},
+			hijos: []*plantilla{
+				{
+					nombre:    "Percepcion",
+					atributos: []string{"TipoPercepcion", "Clave", "Concepto", "ImporteGravado", "ImporteExento"},
+					hijos: []*plantilla{
+						{nombre: "AccionesOTitulos", atributos: []string{"ValorMercado", "PrecioAlOtorgarse"}},
+						{nombre: "HorasExtra", atributos: []string{"Dias", "TipoHoras", "HorasExtra", "ImportePagado"}},
+					},
+				},
+				{
+					nombre: "JubilacionPensionRetiro",
+					atributos: []string{"TotalUnaExhibicion", "TotalParcialidad", "MontoDiario", "IngresoAcumulable",
+						"IngresoNoAcumulable"},
+				},
+				{
+					nombre: "SeparacionIndemnizacion",
+					atributos: []string{"TotalPagado", "NumAñosServicio", "UltimoSueldoMensOrd", "IngresoAcumulable",
+						"IngresoNoAcumulable"},
+				},
+			},
+		},
+		{
+			nombre:    "Deducciones",
+			atributos: []string{"TotalOtrasDeducciones", "TotalImpuestosRetenidos"},
+			hijos: []*plantilla{
+				{nombre: "Deduccion", atributos: []string{"TipoDeduccion", "Clave", "Concepto", "Importe"}},
+			},
+		},
+		{
+			nombre: "OtrosPagos",
+			hijos: []*plantilla{{
+				nombre:    "OtroPago",
+				atributos: []string{"TipoOtroPago", "Clave", "Concepto", "Importe"},
+				hijos: []*plantilla{
+					{nombre: "SubsidioAlEmpleo", atributos: []string{"SubsidioCausado"}},
+					{nombre: "CompensacionSaldosAFavor", atributos: []string{"SaldoAFavor", "Año", "RemanenteSalFav"}},
+				},
+			}},
+		},
+		{
+			nombre: "Incapacidades",
+			hijos: []*plantilla{
+				{nombre: "Incapacidad", atributos: []string{"DiasIncapacidad", "TipoIncapacidad", "ImporteMonetario"}},
+			},
+		},
+	},
+}
+
+// The elements Carta Porte 3.0 and 3.1 share, from CartaPorte30.xslt and
+// CartaPorte31.xslt.
+var (
+	cartaPorteDomicilio = &plantilla{
+		nombre: "Domicilio",
+		atributos: []string{"Calle", "NumeroExterior", "NumeroInterior", "Colonia", "Localidad", "Referencia",
+			"Municipio", "Estado", "Pais", "CodigoPostal"},
+	}
+
+	cartaPorteUbicaciones = &plantilla{
+		nombre: "Ubicaciones",
+		hijos: []*plantilla{{
+			nombre: "Ubicacion",
+			atributos: []string{"TipoUbicacion", "IDUbicacion", "RFCRemitenteDestinatario",
+				"NombreRemitenteDestinatario", "NumRegIdTrib", "ResidenciaFiscal", "NumEstacion", "NombreEstacion",
+				"NavegacionTrafico", "FechaHoraSalidaLlegada", "TipoEstacion", "DistanciaRecorrida"},
+			hijos: []*plantilla{cartaPorteDomicilio},
+		}},
+	}
+
+	cartaPorteMercancia = &plantilla{
+		nombre: "Mercancia",
+		atributos: []string{"BienesTransp", "ClaveSTCC", "Descripcion", "Cantidad", "ClaveUnidad", "Unidad",
+			"Dimensiones", "MaterialPeligroso", "CveMaterialPeligroso", "Embalaje", "DescripEmbalaje",
+			"SectorCOFEPRIS", "NombreIngredienteActivo", "NomQuimico", "DenominacionGenericaProd",
+			"DenominacionDistintivaProd", "Fabricante", "FechaCaducidad", "LoteMedicamento", "FormaFarmaceutica",
+			"CondicionesEspTransp", "RegistroSanitarioFolioAutorizacion", "PermisoImportacion", "FolioImpoVUCEM",
+			"NumCAS", "RazonSocialEmpImp", "NumRegSanPlagCOFEPRIS", "DatosFabricante", "DatosFormulador",
+			"DatosMaquilador", "UsoAutorizado", "PesoEnKg", "ValorMercancia", "Moneda", "FraccionArancelaria",
+			"UUIDComercioExt", "TipoMateria", "DescripcionMateria"},
+		hijos: []*plantilla{
+			{nombre: "DocumentacionAduanera", atributos: []string{"TipoDocumento", "NumPedimento", "IdentDocAduanero",
+				"RFCImpo"}},
+			{nombre: "GuiasIdentificacion", atributos: []string{"NumeroGuiaIdentificacion", "DescripGuiaIdentificacion",
+				"PesoGuiaIdentificacion"}},
+			{nombre: "CantidadTransporta", atributos: []string{"Cantidad", "IDOrigen", "IDDestino", "CvesTransporte"}},
+			{nombre: "DetalleMercancia", atributos: []string{"UnidadPesoMerc", "PesoBruto", "PesoNeto", "PesoTara",
+				"NumPiezas"}},
+		},
+	}
+
+	cartaPorteAutotransporte = &plantilla{
+		nombre:    "Autotransporte",
+		atributos: []string{"PermSCT", "NumPermisoSCT"},
+		hijos: []*plantilla{
+			{nombre: "IdentificacionVehicular", atributos: []string{"ConfigVehicular", "PesoBrutoVehicular", "PlacaVM",
+				"AnioModeloVM"}},
+			{nombre: "Seguros", atributos: []string{"AseguraRespCivil", "PolizaRespCivil", "AseguraMedAmbiente",
+				"PolizaMedAmbiente", "AseguraCarga", "PolizaCarga", "PrimaSeguro"}},
+			{nombre: "Remolques", hijos: []*plantilla{
+				{nombre: "Remolque", atributos: []string{"SubTipoRem", "Placa"}},
+			}},
+		},
+	}
+
+	cartaPorteTransporteMaritimo = &plantilla{
+		nombre: "TransporteMaritimo",
+		atributos: []string{"PermSCT", "NumPermisoSCT", "NombreAseg", "NumPolizaSeguro", "TipoEmbarcacion",
+			"Matricula", "NumeroOMI", "AnioEmbarcacion", "NombreEmbarc", "NacionalidadEmbarc", "UnidadesDeArqBruto",
+			"TipoCarga", "Eslora", "Manga", "Calado", "Puntal", "LineaNaviera", "NombreAgenteNaviero",
+			"NumAutorizacionNaviero", "NumViaje", "NumConocEmbarc", "PermisoTempNavegacion"},
+		hijos: []*plantilla{{
+			nombre: "Contenedor",
+			atributos: []string{"TipoContenedor", "MatriculaContenedor", "NumPrecinto", "IdCCPRelacionado",
+				"PlacaVMCCP", "FechaCertificacionCCP"},
+			hijos: []*plantilla{{nombre: "RemolquesCCP", hijos: []*plantilla{
+				{nombre: "RemolqueCCP", atributos: []string{"SubTipoRemCCP", "PlacaCCP"}},
+			}}},
+		}},
+	}
+
+	cartaPorteTransporteAereo = &plantilla{
+		nombre: "TransporteAereo",
+		atributos: []string{"PermSCT", "NumPermisoSCT", "MatriculaAeronave", "NombreAseg", "NumPolizaSeguro",
+			"NumeroGuia", "LugarContrato", "CodigoTransportista", "RFCEmbarcador", "NumRegIdTribEmbarc",
+			"ResidenciaFiscalEmbarc", "NombreEmbarcador"},
+	}
+
+	cartaPorteTransporteFerroviario = &plantilla{
+		nombre:    "TransporteFerroviario",
+		atributos: []string{"TipoDeServicio", "TipoDeTrafico", "NombreAseg", "NumPolizaSeguro"},
+		hijos: []*plantilla{
+			{nombre: "DerechosDePaso", atributos: []string{"TipoDerechoDePaso", "KilometrajePagado"}},
+			{
+				nombre:    "Carro",
+				atributos: []string{"TipoCarro", "MatriculaCarro", "GuiaCarro", "ToneladasNetasCarro"},
+				hijos: []*plantilla{
+					{nombre: "Contenedor", atributos: []string{"TipoContenedor", "PesoContenedorVacio",
+						"PesoNetoMercancia"}},
+				},
+			},
+		},
+	}
+
+	cartaPorteMercancias = &plantilla{
+		nombre: "Mercancias",
+		atributos: []string{"PesoBrutoTotal", "UnidadPeso", "PesoNetoTotal", "NumTotalMercancias", "CargoPorTasacion",
+			"LogisticaInversaRecoleccionDevolucion"},
+		hijos: []*plantilla{cartaPorteMercancia, cartaPorteAutotransporte, cartaPorteTransporteMaritimo,
+			cartaPorteTransporteAereo, cartaPorteTransporteFerroviario},
+	}
+
+	cartaPorteFiguraTransporte = &plantilla{
+		nombre: "FiguraTransporte",
+		hijos: []*plantilla{{
+			nombre: "TiposFigura",
+			atributos: []string{"TipoFigura", "RFCFigura", "NumLicencia", "NombreFigura", "NumRegIdTribFigura",
+				"ResidenciaFiscalFigura"},
+			hijos: []*plantilla{
+				{nombre: "PartesTransporte", atributos: []string{"ParteTransporte"}},
+				cartaPorteDomicilio,
+			},
+		}},
+	}
+)
+
+// cartaPorte30 follows CartaPorte30.xslt, where RegimenAduanero is an
+// attribute of the CartaPorte.
+var cartaPorte30 = &plantilla{
+	nombre: "CartaPorte",
+	atributos: []string{"Version", "IdCCP", "TranspInternac", "RegimenAduanero", "EntradaSalidaMerc",
+		"PaisOrigenDestino", "ViaEntradaSalida", "TotalDistRec", "RegistroISTMO", "UbicacionPoloOrigen",
+		"UbicacionPoloDestino"},
+	hijos: []*plantilla{cartaPorteUbicaciones, cartaPorteMercancias, cartaPorteFiguraTransporte},
+}
+
+// cartaPorte31 follows CartaPorte31.xslt, which moves the regímenes
+// aduaneros to their own element, read before the Ubicaciones.
+var cartaPorte31 = &plantilla{
+	nombre: "CartaPorte",
+	atributos: []string{"Version", "IdCCP", "TranspInternac", "EntradaSalidaMerc", "PaisOrigenDestino",
+		"ViaEntradaSalida", "TotalDistRec", "RegistroISTMO", "UbicacionPoloOrigen", "UbicacionPoloDestino"},
+	hijos: []*plantilla{
+		{nombre: "RegimenesAduaneros", hijos: []*plantilla{
+			{nombre: "RegimenAduaneroCCP", atributos: []string{"RegimenAduanero"}},
+		}},
+		cartaPorteUbicaciones, cartaPorteMercancias, cartaPorteFiguraTransporte,
+	},
+}
+
+// impuestosLocales follows implocal.xslt.
+var impuestosLocales = &plantilla{
+	nombre:    "ImpuestosLocales",
+	atributos: []string{"version", "TotaldeRetenciones", "TotaldeTraslados"},
+	hijos: []*plantilla{
+		{nombre: "RetencionesLocales", atributos: []string{"ImpLocRetenido", "TasadeRetencion", "Importe"}},
+		{nombre: "TrasladosLocales", atributos: []string{"ImpLocTrasladado", "TasadeTraslado", "Importe"}},
+	},
+}
@@ -0,0 +1,179 @@
+package cfdi
+
+// Versions of the Carta Porte complement stamped since 2024.
+const (
+	VersionCartaPorte30 = "3.0"
+	VersionCartaPorte31 = "3.1"
+)
+
+// Values of TipoUbicacion.
+const (
+	TipoUbicacionOrigen  = "Origen"
+	TipoUbicacionDestino = "Destino"
+)
+
+// Means of transport of a Carta Porte.
+const (
+	MedioAutotransporte        = "autotransporte"
+	MedioTransporteMaritimo    = "maritimo"
+	MedioTransporteAereo       = "aereo"
+	MedioTransporteFerroviario = "ferroviario"
+)
+
+// TipoFiguraOperador is the key of c_FiguraTransporte for the driver.
+const TipoFiguraOperador = "01"
+
+// UnidadPesoKilogramo is the key of c_ClaveUnidadPeso in which every
+// Mercancia declares PesoEnKg.
+const UnidadPesoKilogramo = "KGM"
+
+// CartaPorte is the Carta Porte complement of a CFDI de tipo I or T that
+// covers goods moved by road, sea, air or rail. Only the autotransporte
+// details are parsed, the other means of transport are only told apart.
+type CartaPorte struct {
+	Version           string            `xml:"Version,attr"`
+	IdCCP             string            `xml:"IdCCP,attr"`
+	TranspInternac    string            `xml:"TranspInternac,attr"`
+	EntradaSalidaMerc string            `xml:"EntradaSalidaMerc,attr"`
+	PaisOrigenDestino string            `xml:"PaisOrigenDestino,attr"`
+	ViaEntradaSalida  string            `xml:"ViaEntradaSalida,attr"`
+	TotalDistRec      Decimal           `xml:"TotalDistRec,attr"`
+	RegistroISTMO     string            `xml:"RegistroISTMO,attr"`
+	Ubicaciones       *Ubicaciones      `xml:"Ubicaciones"`
+	Mercancias        *Mercancias       `xml:"Mercancias"`
+	FiguraTransporte  *FiguraTransporte `xml:"FiguraTransporte"`
+}
+
+type Ubicaciones struct {
+	Ubicacion []Ubicacion `xml:"Ubicacion"`
+}
+
+type Ubicacion struct {
+	TipoUbicacion               string     `xml:"TipoUbicacion,attr"`
+	IDUbicacion                 string     `xml:"IDUbicacion,attr"`
+	RFCRemitenteDestinatario    string     `xml:"RFCRemitenteDestinatario,attr"`
+	NombreRemitenteDestinatario string     `xml:"NombreRemitenteDestinatario,attr"`
+	NumRegIdTrib                string     `xml:"NumRegIdTrib,attr"`
+	ResidenciaFiscal            string     `xml:"ResidenciaFiscal,attr"`
+	FechaHoraSalidaLlegada      Fecha      `xml:"FechaHoraSalidaLlegada,attr"`
+	DistanciaRecorrida          Decimal    `xml:"DistanciaRecorrida,attr"`
+	Domicilio                   *Domicilio `xml:"Domicilio"`
+}
+
+type Domicilio struct {
+	Calle          string `xml:"Calle,attr"`
+	NumeroExterior string `xml:"NumeroExterior,attr"`
+	NumeroInterior string `xml:"NumeroInterior,attr"`
+	Colonia        string `xml:"Colonia,attr"`
+	Localidad      string `xml:"Localidad,attr"`
+	Referencia     string `xml:"Referencia,attr"`
+	Municipio      string `xml:"Municipio,attr"`
+	Estado         string `xml:"Estado,attr"`
+	Pais           string `xml:"Pais,attr"`
+	CodigoPostal   string `xml:"CodigoPostal,attr"`
+}
+
+type Mercancias struct {
+	PesoBrutoTotal        Decimal                `xml:"PesoBrutoTotal,attr"`
+	UnidadPeso            string                 `xml:"UnidadPeso,attr"`
+	PesoNetoTotal         Decimal                `xml:"PesoNetoTotal,attr"`
+	NumTotalMercancias    int                    `xml:"NumTotalMercancias,attr"`
+	Mercancia             []Mercancia            `xml:"Mercancia"`
+	Autotransporte        *Autotransporte        `xml:"Autotransporte"`
+	TransporteMaritimo    *TransporteMaritimo    `xml:"TransporteMaritimo"`
+	TransporteAereo       *TransporteAereo       `xml:"TransporteAereo"`
+	TransporteFerroviario *TransporteFerroviario `xml:"TransporteFerroviario"`
+}
+
+type Mercancia struct {
+	BienesTransp         string  `xml:"BienesTransp,attr"`
+	Descripcion          string  `xml:"Descripcion,attr"`
+	Cantidad             Decimal `xml:"Cantidad,attr"`
+	ClaveUnidad          string  `xml:"ClaveUnidad,attr"`
+	Unidad               string  `xml:"Unidad,attr"`
+	MaterialPeligroso    string  `xml:"MaterialPeligroso,attr"`
+	CveMaterialPeligroso string  `xml:"CveMaterialPeligroso,attr"`
+	Embalaje             string  `xml:"Embalaje,attr"`
+	PesoEnKg             Decimal `xml:"PesoEnKg,attr"`
+	ValorMercancia       Decimal `xml:"ValorMercancia,attr"`
+	Moneda               string  `xml:"Moneda,attr"`
+	FraccionArancelaria  string  `xml:"FraccionArancelaria,attr"`
+}
+
+type Autotransporte struct {
+	PermSCT                 string                   `xml:"PermSCT,attr"`
+	NumPermisoSCT           string                   `xml:"NumPermisoSCT,attr"`
+	IdentificacionVehicular *IdentificacionVehicular `xml:"IdentificacionVehicular"`
+	Seguros                 *Seguros                 `xml:"Seguros"`
+	Remolques               []Remolque               `xml:"Remolques>Remolque"`
+}
+
+type IdentificacionVehicular struct {
+	ConfigVehicular    string  `xml:"ConfigVehicular,attr"`
+	PesoBrutoVehicular Decimal `xml:"PesoBrutoVehicular,attr"`
+	PlacaVM            string  `xml:"PlacaVM,attr"`
+	AnioModeloVM       string  `xml:"AnioModeloVM,attr"`
+}
+
+type Seguros struct {
+	AseguraRespCivil   string `xml:"AseguraRespCivil,attr"`
+	PolizaRespCivil    string `xml:"PolizaRespCivil,attr"`
+	AseguraMedAmbiente string `xml:"AseguraMedAmbiente,attr"`
+	PolizaMedAmbiente  string `xml:"PolizaMedAmbiente,attr"`
+	AseguraCarga       string `xml:"AseguraCarga,attr"`
+	PolizaCarga        string `xml:"PolizaCarga,attr"`
+}
+
+type Remolque struct {
+	SubTipoRem string `xml:"SubTipoRem,attr"`
+	Placa      string `xml:"Placa,attr"`
+}
+
+type TransporteMaritimo struct {
+	TipoEmbarcacion string `xml:"TipoEmbarcacion,attr"`
+	Matricula       string `xml:"Matricula,attr"`
+}
+
+type TransporteAereo struct {
+	PermSCT             string `xml:"PermSCT,attr"`
+	NumeroGuia          string `xml:"NumeroGuia,attr"`
+	CodigoTransportista string `xml:"CodigoTransportista,attr"`
+}
+
+type TransporteFerroviario struct {
+	TipoDeServicio string `xml:"TipoDeServicio,attr"`
+	TipoDeTrafico  string `xml:"TipoDeTrafico,attr"`
+}
+
+type FiguraTransporte struct {
+	TiposFigura []TiposFigura `xml:"TiposFigura"`
+}
+
+type TiposFigura struct {
+	TipoFigura             string     `xml:"TipoFigura,attr"`
+	RFCFigura              string     `xml:"RFCFigura,attr"`
+	NumLicencia            string     `xml:"NumLicencia,attr"`
+	NombreFigura           string     `xml:"NombreFigura,attr"`
+	NumRegIdTribFigura     string     `xml:"NumRegIdTribFigura,attr"`
+	ResidenciaFiscalFigura string     `xml:"ResidenciaFiscalFigura,attr"`
+	Domicilio              *Domicilio `xml:"Domicilio"`
+}
+
+// MedioTransporte names the means of transport the complement declares,
+// empty if it declares none.
+func (cp *CartaPorte) MedioTransporte() string {
+	switch {
+	case cp.Mercancias == nil:
+		return ""
+	case cp.Mercancias.Autotransporte != nil:
+		return MedioAutotransporte
+	case cp.Mercancias.TransporteMaritimo != nil:
+		return MedioTransporteMaritimo
+	case cp.Mercancias.TransporteAereo != nil:
+		return MedioTransporteAereo
+	case cp.Mercancias.TransporteFerroviario != nil:
+		return MedioTransporteFerroviario
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,361 @@
+package cfdi
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Tipos of the incidencias found by CartaPorte.Validar.
+const (
+	IncidenciaNodoFaltante       = "nodo_faltante"
+	IncidenciaPesoInconsistente  = "peso_inconsistente"
+	IncidenciaTotalInconsistente = "total_inconsistente"
+	IncidenciaClaveInvalida      = "clave_invalida"
+)
+
+// Incidencia is a structural problem of a complement. Nodo is the path of
+// the node or attribute, like CartaPorte/Ubicaciones/Ubicacion[2]@IDUbicacion.
+type Incidencia struct {
+	Tipo    string `json:"tipo"`
+	Nodo    string `json:"nodo"`
+	Mensaje string `json:"mensaje"`
+}
+
+// Small catalogs of the Carta Porte checked in place, the large ones are
+// only checked for their format.
+var (
+	siNo = map[string]bool{"Sí": true, "No": true}
+
+	// c_FiguraTransporte
+	tiposFigura = map[string]bool{"01": true, "02": true, "03": true, "04": true, "05": true}
+
+	// c_ConfigAutotransporte
+	configuracionesVehiculares = map[string]bool{
+		"VL": true, "C2": true, "C3": true, "C2R2": true, "C3R2": true, "C2R3": true, "C3R3": true,
+		"T2S1": true, "T2S2": true, "T2S3": true, "T3S1": true, "T3S2": true, "T3S3": true,
+		"T2S1R2": true, "T2S2R2": true, "T2S1R3": true, "T3S1R2": true, "T3S1R3": true, "T3S2R2": true,
+		"T3S2R3": true, "T3S2R4": true, "T2S2S2": true, "T3S2S2": true, "T3S3S2": true, "OTROEVGP": true,
+		"GPLUTA": true, "GPLUTB": true, "GPLUTC": true, "GPLUTD": true,
+		"GPLATA": true, "GPLATB": true, "GPLATC": true, "GPLATD": true,
+	}
+
+	patronIdCCP        = regexp.MustCompile(`^CCC[0-9a-fA-F]{5}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+	patronIDUbicacion  = regexp.MustCompile(`^(OR|DE)[0-9]{6}$`)
+	patronBienesTransp = regexp.MustCompile(`^[0-9]{8}$`)
+	patronClaveUnidad  = regexp.MustCompile(`^[A-Z0-9]{1,3}$`)
+	patronPermSCT      = regexp.MustCompile(`^TP(AF[0-9]{2}|TM01|TA0[1-4]|XX00)$`)
+	patronSubTipoRem   = regexp.MustCompile(`^CTR0[0-3][0-9]$`)
+	patronAnioModelo   = regexp.MustCompile(`^(19|20)[0-9]{2}$`)
+	patronPais         = regexp.MustCompile(`^[A-Z]{3}$`)
+	patronCodigoPostal = regexp.MustCompile(`^[0-9]{5}$`)
+	patronRFC          = regexp.MustCompile(`^[A-ZÑ&]{3,4}[0-9]{6}[A-Z0-9]{3}$`)
+	patronMoneda       = regexp.MustCompile(`^[A-Z]{3}$`)
+)
+
+// validacion collects the incidencias of a complement.
+type validacion struct {
+	incidencias []Incidencia
+}
+
+func (v *validacion) agregar(tipo, nodo, mensaje string, args ...interface{}) {
+	v.incidencias = append(v.incidencias, Incidencia{Tipo: tipo, Nodo: nodo, Mensaje: fmt.Sprintf(mensaje, args...)})
+}
+
+func (v *validacion) requerido(nodo, valor string) bool {
+	if strings.TrimSpace(valor) == "" {
+		v.agregar(IncidenciaNodoFaltante, nodo, "%s is required", nodo)
+		return false
+	}
+
+	return true
+}
+
+// clave checks a required catalog key against its format.
+func (v *validacion) clave(nodo, valor string, patron *regexp.Regexp) {
+	if v.requerido(nodo, valor) && !patron.MatchString(valor) {
+		v.agregar(IncidenciaClaveInvalida, nodo, "%q is not a valid key", valor)
+	}
+}
+
+// opcional checks an optional catalog key against its format.
+func (v *validacion) opcional(nodo, valor string, patron *regexp.Regexp) {
+	if valor != "" && !patron.MatchString(valor) {
+		v.agregar(IncidenciaClaveInvalida, nodo, "%q is not a valid key", valor)
+	}
+}
+
+func (v *validacion) catalogo(nodo, valor string, catalogo map[string]bool) {
+	if v.requerido(nodo, valor) && !catalogo[valor] {
+		v.agregar(IncidenciaClaveInvalida, nodo, "%q is not a valid key", valor)
+	}
+}
+
+// Validar checks the structure of the complement the way the PAC and the
+// SAT reject it: the mandatory nodes and attributes, that the weights and
+// totals add up and that the catalog keys are well formed. It doesn't look
+// up the large catalogs.
+func (cp *CartaPorte) Validar() []Incidencia {
+	v := &validacion{}
+
+	if cp.Version != VersionCartaPorte30 && cp.Version != VersionCartaPorte31 {
+		v.agregar(IncidenciaClaveInvalida, "CartaPorte@Version", "version %q is not supported", cp.Version)
+	}
+
+	v.clave("CartaPorte@IdCCP", cp.IdCCP, patronIdCCP)
+	v.catalogo("CartaPorte@TranspInternac", cp.TranspInternac, siNo)
+
+	if cp.TranspInternac == "Sí" {
+		v.requerido("CartaPorte@EntradaSalidaMerc", cp.EntradaSalidaMerc)
+		v.clave("CartaPorte@PaisOrigenDestino", cp.PaisOrigenDestino, patronPais)
+		v.requerido("CartaPorte@ViaEntradaSalida", cp.ViaEntradaSalida)
+	}
+
+	autotransporte := cp.MedioTransporte() == MedioAutotransporte
+
+	cp.validarUbicaciones(v, autotransporte)
+	cp.validarMercancias(v)
+
+	if cp.Mercancias != nil && cp.Mercancias.Autotransporte != nil {
+		cp.validarAutotransporte(v, cp.Mercancias.Autotransporte)
+	}
+
+	cp.validarFiguras(v, autotransporte)
+
+	return v.incidencias
+}
+
+func (cp *CartaPorte) validarUbicaciones(v *validacion, autotransporte bool) {
+	if cp.Ubicaciones == nil || len(cp.Ubicaciones.Ubicacion) == 0 {
+		v.agregar(IncidenciaNodoFaltante, "CartaPorte/Ubicaciones", "CartaPorte/Ubicaciones is required")
+		return
+	}
+
+	origenes, destinos := 0, 0
+	distancia := Decimal{}
+
+	for i, ubicacion := range cp.Ubicaciones.Ubicacion {
+		nodo := fmt.Sprintf("CartaPorte/Ubicaciones/Ubicacion[%d]", i+1)
+
+		switch ubicacion.TipoUbicacion {
+		case TipoUbicacionOrigen:
+			origenes++
+		case TipoUbicacionDestino:
+			destinos++
+			distancia = distancia.Add(ubicacion.DistanciaRecorrida)
+
+			if autotransporte && ubicacion.DistanciaRecorrida.Sign() <= 0 {
+				v.agregar(IncidenciaNodoFaltante, nodo+"@DistanciaRecorrida",
+					"%s@DistanciaRecorrida is required for autotransporte", nodo)
+			}
+		case "":
+			v.requerido(nodo+"@TipoUbicacion", "")
+		default:
+			v.agregar(IncidenciaClaveInvalida, nodo+"@TipoUbicacion", "%q is not a valid key", ubicacion.TipoUbicacion)
+		}
+
+		if ubicacion.IDUbicacion != "" {
+			v.opcional(nodo+"@IDUbicacion", ubicacion.IDUbicacion, patronIDUbicacion)
+
+			prefijo := strings.ToUpper(ubicacion.TipoUbicacion)
+			if len(prefijo) > 2 && !strings.HasPrefix(ubicacion.IDUbicacion, prefijo[:2]) {
+				v.agregar(IncidenciaClaveInvalida, nodo+"@IDUbicacion",
+					"%q doesn't match TipoUbicacion %s", ubicacion.IDUbicacion, ubicacion.TipoUbicacion)
+			}
+		}
+
+		v.clave(nodo+"@RFCRemitenteDestinatario", ubicacion.RFCRemitenteDestinatario, patronRFC)
+
+		if ubicacion.FechaHoraSalidaLlegada.IsZero() {
+			v.requerido(nodo+"@FechaHoraSalidaLlegada", "")
+		}
+
+		if ubicacion.Domicilio == nil {
+			if autotransporte {
+				v.agregar(IncidenciaNodoFaltante, nodo+"/Domicilio", "%s/Domicilio is required for autotransporte", nodo)
+			}
+			continue
+		}
+
+		validarDomicilio(v, nodo+"/Domicilio", ubicacion.Domicilio)
+	}
+
+	if origenes == 0 {
+		v.agregar(IncidenciaNodoFaltante, "CartaPorte/Ubicaciones/Ubicacion",
+			"at least one Ubicacion with TipoUbicacion Origen is required")
+	}
+	if destinos == 0 {
+		v.agregar(IncidenciaNodoFaltante, "CartaPorte/Ubicaciones/Ubicacion",
+			"at least one Ubicacion with TipoUbicacion Destino is required")
+	}
+
+	if !autotransporte {
+		return
+	}
+
+	if cp.TotalDistRec.IsZero() {
+		v.agregar(IncidenciaNodoFaltante, "CartaPorte@TotalDistRec", "CartaPorte@TotalDistRec is required for autotransporte")
+	} else if !cp.TotalDistRec.Equal(distancia) {
+		v.agregar(IncidenciaTotalInconsistente, "CartaPorte@TotalDistRec",
+			"TotalDistRec %s doesn't match the DistanciaRecorrida of the destinos, %s", cp.TotalDistRec, distancia)
+	}
+}
+
+func validarDomicilio(v *validacion, nodo string, domicilio *Domicilio) {
+	v.requerido(nodo+"@Estado", domicilio.Estado)
+	v.clave(nodo+"@Pais", domicilio.Pais, patronPais)
+
+	if domicilio.Pais == "MEX" {
+		v.clave(nodo+"@CodigoPostal", domicilio.CodigoPostal, patronCodigoPostal)
+	} else {
+		v.requerido(nodo+"@CodigoPostal", domicilio.CodigoPostal)
+	}
+}
+
+func (cp *CartaPorte) validarMercancias(v *validacion) {
+	mercancias := cp.Mercancias
+	if mercancias == nil {
+		v.agregar(IncidenciaNodoFaltante, "CartaPorte/Mercancias", "CartaPorte/Mercancias is required")
+		return
+	}
+
+	v.clave("CartaPorte/Mercancias@UnidadPeso", mercancias.UnidadPeso, patronClaveUnidad)
+
+	if len(mercancias.Mercancia) == 0 {
+		v.agregar(IncidenciaNodoFaltante, "CartaPorte/Mercancias/Mercancia", "at least one Mercancia is required")
+	} else if mercancias.NumTotalMercancias != len(mercancias.Mercancia) {
+		v.agregar(IncidenciaTotalInconsistente, "CartaPorte/Mercancias@NumTotalMercancias",
+			"NumTotalMercancias is %d but there are %d Mercancia nodes", mercancias.NumTotalMercancias, len(mercancias.Mercancia))
+	}
+
+	peso := Decimal{}
+	for i, mercancia := range mercancias.Mercancia {
+		nodo := fmt.Sprintf("CartaPorte/Mercancias/Mercancia[%d]", i+1)
+
+		v.clave(nodo+"@BienesTransp", mercancia.BienesTransp, patronBienesTransp)
+		v.requerido(nodo+"@Descripcion", mercancia.Descripcion)
+		v.clave(nodo+"@ClaveUnidad", mercancia.ClaveUnidad, patronClaveUnidad)
+		v.opcional(nodo+"@Moneda", mercancia.Moneda, patronMoneda)
+
+		if mercancia.Cantidad.Sign() <= 0 {
+			v.requerido(nodo+"@Cantidad", "")
+		}
+
+		if mercancia.MaterialPeligroso != "" {
+			v.catalogo(nodo+"@MaterialPeligroso", mercancia.MaterialPeligroso, siNo)
+		}
+		if mercancia.MaterialPeligroso == "Sí" {
+			v.requerido(nodo+"@CveMaterialPeligroso", mercancia.CveMaterialPeligroso)
+			v.requerido(nodo+"@Embalaje", mercancia.Embalaje)
+		}
+
+		if mercancia.PesoEnKg.Sign() <= 0 {
+			v.agregar(IncidenciaPesoInconsistente, nodo+"@PesoEnKg", "PesoEnKg must be greater than 0")
+		}
+
+		peso = peso.Add(mercancia.PesoEnKg)
+	}
+
+	if mercancias.PesoBrutoTotal.Sign() <= 0 {
+		v.agregar(IncidenciaPesoInconsistente, "CartaPorte/Mercancias@PesoBrutoTotal", "PesoBrutoTotal must be greater than 0")
+		return
+	}
+
+	// PesoBrutoTotal is in UnidadPeso, the sum can only be compared in kg.
+	if mercancias.UnidadPeso == UnidadPesoKilogramo && len(mercancias.Mercancia) > 0 && !mercancias.PesoBrutoTotal.Equal(peso) {
+		v.agregar(IncidenciaPesoInconsistente, "CartaPorte/Mercancias@PesoBrutoTotal",
+			"PesoBrutoTotal %s doesn't match the PesoEnKg of the mercancías, %s", mercancias.PesoBrutoTotal, peso)
+	}
+
+	if !mercancias.PesoNetoTotal.IsZero() && mercancias.PesoNetoTotal.Cmp(mercancias.PesoBrutoTotal) > 0 {
+		v.agregar(IncidenciaPesoInconsistente, "CartaPorte/Mercancias@PesoNetoTotal",
+			"PesoNetoTotal %s is greater than PesoBrutoTotal %s", mercancias.PesoNetoTotal, mercancias.PesoBrutoTotal)
+	}
+
+	if cp.MedioTransporte() == "" {
+		v.agregar(IncidenciaNodoFaltante, "CartaPorte/Mercancias/Autotransporte",
+			"one of Autotransporte, TransporteMaritimo, TransporteAereo or TransporteFerroviario is required")
+	}
+}
+
+func (cp *CartaPorte) validarAutotransporte(v *validacion, autotransporte *Autotransporte) {
+	const nodo = "CartaPorte/Mercancias/Autotransporte"
+
+	v.clave(nodo+"@PermSCT", autotransporte.PermSCT, patronPermSCT)
+	v.requerido(nodo+"@NumPermisoSCT", autotransporte.NumPermisoSCT)
+
+	if vehiculo := autotransporte.IdentificacionVehicular; vehiculo == nil {
+		v.agregar(IncidenciaNodoFaltante, nodo+"/IdentificacionVehicular", "%s/IdentificacionVehicular is required", nodo)
+	} else {
+		v.catalogo(nodo+"/IdentificacionVehicular@ConfigVehicular", vehiculo.ConfigVehicular, configuracionesVehiculares)
+		v.requerido(nodo+"/IdentificacionVehicular@PlacaVM", vehiculo.PlacaVM)
+		v.clave(nodo+"/IdentificacionVehicular@AnioModeloVM", vehiculo.AnioModeloVM, patronAnioModelo)
+
+		// Tractors pull a semirremolque and the C..R.. configurations a
+		// remolque, they must say which.
+		configuracion := vehiculo.ConfigVehicular
+		arrastra := strings.HasPrefix(configuracion, "T") ||
+			(strings.HasPrefix(configuracion, "C") && strings.Contains(configuracion, "R"))
+		if configuracionesVehiculares[configuracion] && arrastra && len(autotransporte.Remolques) == 0 {
+			v.agregar(IncidenciaNodoFaltante, nodo+"/Remolques",
+				"%s/Remolques is required for ConfigVehicular %s", nodo, configuracion)
+		}
+
+		// PesoBrutoVehicular is in tons and includes the cargo.
+		pesoBruto := cp.Mercancias.PesoBrutoTotal
+		if vehiculo.PesoBrutoVehicular.Sign() <= 0 {
+			v.agregar(IncidenciaPesoInconsistente, nodo+"/IdentificacionVehicular@PesoBrutoVehicular",
+				"PesoBrutoVehicular must be greater than 0")
+		} else if cp.Mercancias.UnidadPeso == UnidadPesoKilogramo &&
+			pesoBruto.Cmp(vehiculo.PesoBrutoVehicular.Mul(NewDecimal(1000, 0))) > 0 {
+			v.agregar(IncidenciaPesoInconsistente, nodo+"/IdentificacionVehicular@PesoBrutoVehicular",
+				"PesoBrutoTotal %s kg exceeds PesoBrutoVehicular %s t", pesoBruto, vehiculo.PesoBrutoVehicular)
+		}
+	}
+
+	if seguros := autotransporte.Seguros; seguros == nil {
+		v.agregar(IncidenciaNodoFaltante, nodo+"/Seguros", "%s/Seguros is required", nodo)
+	} else {
+		v.requerido(nodo+"/Seguros@AseguraRespCivil", seguros.AseguraRespCivil)
+		v.requerido(nodo+"/Seguros@PolizaRespCivil", seguros.PolizaRespCivil)
+	}
+
+	for i, remolque := range autotransporte.Remolques {
+		remolqueNodo := fmt.Sprintf("%s/Remolques/Remolque[%d]", nodo, i+1)
+
+		v.clave(remolqueNodo+"@SubTipoRem", remolque.SubTipoRem, patronSubTipoRem)
+		v.requerido(remolqueNodo+"@Placa", remolque.Placa)
+	}
+}
+
+func (cp *CartaPorte) validarFiguras(v *validacion, autotransporte bool) {
+	if cp.FiguraTransporte == nil || len(cp.FiguraTransporte.TiposFigura) == 0 {
+		if autotransporte {
+			v.agregar(IncidenciaNodoFaltante, "CartaPorte/FiguraTransporte",
+				"CartaPorte/FiguraTransporte is required for autotransporte")
+		}
+		return
+	}
+
+	operadores := 0
+	for i, figura := range cp.FiguraTransporte.TiposFigura {
+		nodo := fmt.Sprintf("CartaPorte/FiguraTransporte/TiposFigura[%d]", i+1)
+
+		v.catalogo(nodo+"@TipoFigura", figura.TipoFigura, tiposFigura)
+		v.requerido(nodo+"@NombreFigura", figura.NombreFigura)
+
+		if figura.NumRegIdTribFigura == "" {
+			v.clave(nodo+"@RFCFigura", figura.RFCFigura, patronRFC)
+		}
+
+		if figura.TipoFigura == TipoFiguraOperador {
+			operadores++
+			v.requerido(nodo+"@NumLicencia", figura.NumLicencia)
+		}
+	}
+
+	if autotransporte && operadores == 0 {
+		v.agregar(IncidenciaNodoFaltante, "CartaPorte/FiguraTransporte/TiposFigura",
+			"a TiposFigura with TipoFigura 01 (operador) is required for autotransporte")
+	}
+}
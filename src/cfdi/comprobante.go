@@ -0,0 +1,182 @@
+package cfdi
+
+import (
+	"encoding/xml"
+	"strings"
+)
+
+// Namespaces of the Comprobante and the TimbreFiscalDigital. Parsing matches
+// elements and attributes by local name, these are only used to tell apart
+// documents that declare a version in the namespace but not in Version.
+const (
+	NamespaceCFDI33 = "http://www.sat.gob.mx/cfd/3"
+	NamespaceCFDI40 = "http://www.sat.gob.mx/cfd/4"
+	NamespaceTFD    = "http://www.sat.gob.mx/TimbreFiscalDigital"
+)
+
+const (
+	Version33 = "3.3"
+	Version40 = "4.0"
+)
+
+// TipoDeComprobante values from c_TipoDeComprobante.
+const (
+	TipoIngreso  = "I"
+	TipoEgreso   = "E"
+	TipoTraslado = "T"
+	TipoNomina   = "N"
+	TipoPago     = "P"
+)
+
+// MetodoPago values from c_MetodoPago. PPD invoices are paid later through
+// Recepción de Pagos complements.
+const (
+	MetodoPagoPUE = "PUE"
+	MetodoPagoPPD = "PPD"
+)
+
+// Comprobante is a CFDI 3.3 or 4.0. Attributes only present in 4.0, like
+// Exportacion or RegimenFiscalReceptor, are empty for 3.3 documents.
+type Comprobante struct {
+	XMLName           xml.Name
+	Version           string             `xml:"Version,attr"`
+	Serie             string             `xml:"Serie,attr"`
+	Folio             string             `xml:"Folio,attr"`
+	Fecha             Fecha              `xml:"Fecha,attr"`
+	Sello             string             `xml:"Sello,attr"`
+	FormaPago         string             `xml:"FormaPago,attr"`
+	NoCertificado     string             `xml:"NoCertificado,attr"`
+	Certificado       string             `xml:"Certificado,attr"`
+	CondicionesDePago string             `xml:"CondicionesDePago,attr"`
+	SubTotal          Decimal            `xml:"SubTotal,attr"`
+	Descuento         Decimal            `xml:"Descuento,attr"`
+	Moneda            string             `xml:"Moneda,attr"`
+	TipoCambio        Decimal            `xml:"TipoCambio,attr"`
+	Total             Decimal            `xml:"Total,attr"`
+	TipoDeComprobante string             `xml:"TipoDeComprobante,attr"`
+	Exportacion       string             `xml:"Exportacion,attr"`
+	MetodoPago        string             `xml:"MetodoPago,attr"`
+	LugarExpedicion   string             `xml:"LugarExpedicion,attr"`
+	Confirmacion      string             `xml:"Confirmacion,attr"`
+	InformacionGlobal *InformacionGlobal `xml:"InformacionGlobal"`
+	CfdiRelacionados  []CfdiRelacionados `xml:"CfdiRelacionados"`
+	Emisor            Emisor             `xml:"Emisor"`
+	Receptor          Receptor           `xml:"Receptor"`
+	Conceptos         []Concepto         `xml:"Conceptos>Concepto"`
+	Impuestos         *Impuestos         `xml:"Impuestos"`
+	Complemento       Complemento        `xml:"Complemento"`
+}
+
+type InformacionGlobal struct {
+	Periodicidad string `xml:"Periodicidad,attr"`
+	Meses        string `xml:"Meses,attr"`
+	Anio         string `xml:"Año,attr"`
+}
+
+type CfdiRelacionados struct {
+	TipoRelacion    string            `xml:"TipoRelacion,attr"`
+	CfdiRelacionado []CfdiRelacionado `xml:"CfdiRelacionado"`
+}
+
+type CfdiRelacionado struct {
+	UUID string `xml:"UUID,attr"`
+}
+
+type Emisor struct {
+	RFC              string `xml:"Rfc,attr"`
+	Nombre           string `xml:"Nombre,attr"`
+	RegimenFiscal    string `xml:"RegimenFiscal,attr"`
+	FacAtrAdquirente string `xml:"FacAtrAdquirente,attr"`
+}
+
+type Receptor struct {
+	RFC                     string `xml:"Rfc,attr"`
+	Nombre                  string `xml:"Nombre,attr"`
+	DomicilioFiscalReceptor string `xml:"DomicilioFiscalReceptor,attr"`
+	ResidenciaFiscal        string `xml:"ResidenciaFiscal,attr"`
+	NumRegIdTrib            string `xml:"NumRegIdTrib,attr"`
+	RegimenFiscalReceptor   string `xml:"RegimenFiscalReceptor,attr"`
+	UsoCFDI                 string `xml:"UsoCFDI,attr"`
+}
+
+type Concepto struct {
+	ClaveProdServ    string             `xml:"ClaveProdServ,attr"`
+	NoIdentificacion string             `xml:"NoIdentificacion,attr"`
+	Cantidad         Decimal            `xml:"Cantidad,attr"`
+	ClaveUnidad      string             `xml:"ClaveUnidad,attr"`
+	Unidad           string             `xml:"Unidad,attr"`
+	Descripcion      string             `xml:"Descripcion,attr"`
+	ValorUnitario    Decimal            `xml:"ValorUnitario,attr"`
+	Importe          Decimal            `xml:"Importe,attr"`
+	Descuento        Decimal            `xml:"Descuento,attr"`
+	ObjetoImp        string             `xml:"ObjetoImp,attr"`
+	Impuestos        *ImpuestosConcepto `xml:"Impuestos"`
+}
+
+type ImpuestosConcepto struct {
+	Traslados   []Traslado  `xml:"Traslados>Traslado"`
+	Retenciones []Retencion `xml:"Retenciones>Retencion"`
+}
+
+// Impuestos are the tax totals of the Comprobante.
+type Impuestos struct {
+	TotalImpuestosRetenidos   Decimal     `xml:"TotalImpuestosRetenidos,attr"`
+	TotalImpuestosTrasladados Decimal     `xml:"TotalImpuestosTrasladados,attr"`
+	Retenciones               []Retencion `xml:"Retenciones>Retencion"`
+	Traslados                 []Traslado  `xml:"Traslados>Traslado"`
+}
+
+// Traslado is a transferred tax. TasaOCuota and Importe are zero when
+// TipoFactor is Exento.
+type Traslado struct {
+	Base       Decimal `xml:"Base,attr"`
+	Impuesto   string  `xml:"Impuesto,attr"`
+	TipoFactor string  `xml:"TipoFactor,attr"`
+	TasaOCuota Decimal `xml:"TasaOCuota,attr"`
+	Importe    Decimal `xml:"Importe,attr"`
+}
+
+// Retencion is a withheld tax. The Comprobante level totals only carry
+// Impuesto and Importe.
+type Retencion struct {
+	Base       Decimal `xml:"Base,attr"`
+	Impuesto   string  `xml:"Impuesto,attr"`
+	TipoFactor string  `xml:"TipoFactor,attr"`
+	TasaOCuota Decimal `xml:"TasaOCuota,attr"`
+	Importe    Decimal `xml:"Importe,attr"`
+}
+
+// Complemento holds the complements this package understands, the rest are
+// skipped.
+type Complemento struct {
+	TimbreFiscalDigital *TimbreFiscalDigital `xml:"TimbreFiscalDigital"`
+	Pagos               *Pagos               `xml:"Pagos"`
+	Nomina              []Nomina             `xml:"Nomina"`
+	CartaPorte          *CartaPorte          `xml:"CartaPorte"`
+}
+
+type TimbreFiscalDigital struct {
+	Version          string `xml:"Version,attr"`
+	UUID             string `xml:"UUID,attr"`
+	FechaTimbrado    Fecha  `xml:"FechaTimbrado,attr"`
+	RfcProvCertif    string `xml:"RfcProvCertif,attr"`
+	Leyenda          string `xml:"Leyenda,attr"`
+	SelloCFD         string `xml:"SelloCFD,attr"`
+	NoCertificadoSAT string `xml:"NoCertificadoSAT,attr"`
+	SelloSAT         string `xml:"SelloSAT,attr"`
+}
+
+// UUID is the folio fiscal assigned by the TimbreFiscalDigital, uppercased,
+// or empty if the CFDI is not stamped.
+func (c *Comprobante) UUID() string {
+	if c.Complemento.TimbreFiscalDigital == nil {
+		return ""
+	}
+
+	return strings.ToUpper(strings.TrimSpace(c.Complemento.TimbreFiscalDigital.UUID))
+}
+
+// Timbrado reports whether the CFDI carries a TimbreFiscalDigital.
+func (c *Comprobante) Timbrado() bool {
+	return c.UUID() != ""
+}
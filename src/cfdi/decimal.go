@@ -0,0 +1,249 @@
+package cfdi
+
+import (
+	"database/sql/driver"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+var ErrInvalidDecimal = errors.New("invalid decimal amount")
+
+// Decimal is an exact decimal amount, coef * 10^-scale. CFDI amounts carry
+// up to 6 decimals and must add up to the declared totals, which float64
+// can't guarantee. The zero value is 0.
+type Decimal struct {
+	coef  *big.Int
+	scale int
+}
+
+// ParseDecimal parses amounts as written in the CFDI, like "1160.00",
+// "-0.5" or "16". The number of decimals is kept, so String returns the
+// same text.
+func ParseDecimal(s string) (Decimal, error) {
+	text := strings.TrimSpace(s)
+	if text == "" {
+		return Decimal{}, fmt.Errorf("%w: empty value", ErrInvalidDecimal)
+	}
+
+	digits := text
+	if digits[0] == '-' || digits[0] == '+' {
+		digits = digits[1:]
+	}
+
+	integer, fraction, _ := strings.Cut(digits, ".")
+	if integer == "" && fraction == "" {
+		return Decimal{}, fmt.Errorf("%w: %q", ErrInvalidDecimal, s)
+	}
+
+	for _, r := range integer + fraction {
+		if r < '0' || r > '9' {
+			return Decimal{}, fmt.Errorf("%w: %q", ErrInvalidDecimal, s)
+		}
+	}
+
+	coef, ok := new(big.Int).SetString(integer+fraction, 10)
+	if !ok {
+		return Decimal{}, fmt.Errorf("%w: %q", ErrInvalidDecimal, s)
+	}
+
+	if text[0] == '-' {
+		coef.Neg(coef)
+	}
+
+	return Decimal{coef: coef, scale: len(fraction)}, nil
+}
+
+// MustParseDecimal is like ParseDecimal but panics on invalid input. It is
+// meant for constants and tests.
+func MustParseDecimal(s string) Decimal {
+	d, err := ParseDecimal(s)
+	if err != nil {
+		panic(err)
+	}
+
+	return d
+}
+
+// NewDecimal returns unscaled * 10^-scale.
+func NewDecimal(unscaled int64, scale int) Decimal {
+	return Decimal{coef: big.NewInt(unscaled), scale: scale}
+}
+
+func (d Decimal) coefficient() *big.Int {
+	if d.coef == nil {
+		return new(big.Int)
+	}
+
+	return d.coef
+}
+
+// rescale returns the coefficient of d expressed with scale decimals, which
+// must be at least d.scale.
+func (d Decimal) rescale(scale int) *big.Int {
+	coef := new(big.Int).Set(d.coefficient())
+	if scale > d.scale {
+		factor := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(scale-d.scale)), nil)
+		coef.Mul(coef, factor)
+	}
+
+	return coef
+}
+
+func (d Decimal) Add(other Decimal) Decimal {
+	scale := max(d.scale, other.scale)
+	return Decimal{coef: new(big.Int).Add(d.rescale(scale), other.rescale(scale)), scale: scale}
+}
+
+func (d Decimal) Sub(other Decimal) Decimal {
+	scale := max(d.scale, other.scale)
+	return Decimal{coef: new(big.Int).Sub(d.rescale(scale), other.rescale(scale)), scale: scale}
+}
+
+func (d Decimal) Mul(other Decimal) Decimal {
+	return Decimal{coef: new(big.Int).Mul(d.coefficient(), other.coefficient()), scale: d.scale + other.scale}
+}
+
+func (d Decimal) Neg() Decimal {
+	return Decimal{coef: new(big.Int).Neg(d.coefficient()), scale: d.scale}
+}
+
+// Cmp compares d and other and returns -1, 0 or +1.
+func (d Decimal) Cmp(other Decimal) int {
+	scale := max(d.scale, other.scale)
+	return d.rescale(scale).Cmp(other.rescale(scale))
+}
+
+func (d Decimal) Equal(other Decimal) bool {
+	return d.Cmp(other) == 0
+}
+
+func (d Decimal) Sign() int {
+	return d.coefficient().Sign()
+}
+
+func (d Decimal) IsZero() bool {
+	return d.Sign() == 0
+}
+
+// Round rounds half away from zero to the given number of decimals, the
+// rounding the SAT uses for taxes and totals.
+func (d Decimal) Round(decimals int) Decimal {
+	if decimals >= d.scale {
+		return Decimal{coef: d.rescale(decimals), scale: decimals}
+	}
+
+	factor := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(d.scale-decimals)), nil)
+	half := new(big.Int).Quo(factor, big.NewInt(2))
+
+	abs := new(big.Int).Abs(d.coefficient())
+	abs.Add(abs, half)
+	abs.Quo(abs, factor)
+
+	if d.Sign() < 0 {
+		abs.Neg(abs)
+	}
+
+	return Decimal{coef: abs, scale: decimals}
+}
+
+// Scale is the number of decimals of d.
+func (d Decimal) Scale() int {
+	return d.scale
+}
+
+func (d Decimal) String() string {
+	coef := d.coefficient()
+
+	digits := new(big.Int).Abs(coef).String()
+	if d.scale > 0 {
+		if len(digits) <= d.scale {
+			digits = strings.Repeat("0", d.scale-len(digits)+1) + digits
+		}
+		digits = digits[:len(digits)-d.scale] + "." + digits[len(digits)-d.scale:]
+	}
+
+	if coef.Sign() < 0 {
+		return "-" + digits
+	}
+
+	return digits
+}
+
+// Float64 returns the nearest float64, for display and statistics only.
+func (d Decimal) Float64() float64 {
+	f, _ := new(big.Rat).SetFrac(d.coefficient(), new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(d.scale)), nil)).Float64()
+	return f
+}
+
+// MarshalJSON writes the amount as a JSON number with its exact digits.
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+func (d *Decimal) UnmarshalJSON(data []byte) error {
+	text := strings.Trim(string(data), `"`)
+	if text == "null" {
+		return nil
+	}
+
+	parsed, err := ParseDecimal(text)
+	if err != nil {
+		return err
+	}
+
+	*d = parsed
+	return nil
+}
+
+func (d *Decimal) UnmarshalXMLAttr(attr xml.Attr) error {
+	if strings.TrimSpace(attr.Value) == "" {
+		*d = Decimal{}
+		return nil
+	}
+
+	parsed, err := ParseDecimal(attr.Value)
+	if err != nil {
+		return fmt.Errorf("attribute %s: %w", attr.Name.Local, err)
+	}
+
+	*d = parsed
+	return nil
+}
+
+// Value stores the amount in NUMERIC columns as text, keeping every digit.
+func (d Decimal) Value() (driver.Value, error) {
+	return d.String(), nil
+}
+
+func (d *Decimal) Scan(src interface{}) error {
+	var text string
+
+	switch value := src.(type) {
+	case nil:
+		*d = Decimal{}
+		return nil
+	case string:
+		text = value
+	case []byte:
+		text = string(value)
+	case int64:
+		*d = NewDecimal(value, 0)
+		return nil
+	case float64:
+		text = strconv.FormatFloat(value, 'f', -1, 64)
+	default:
+		return fmt.Errorf("%w: cannot scan %T", ErrInvalidDecimal, src)
+	}
+
+	parsed, err := ParseDecimal(text)
+	if err != nil {
+		return err
+	}
+
+	*d = parsed
+	return nil
+}
@@ -0,0 +1,55 @@
+package cfdi
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// FechaLayout is the ISO 8601 layout of CFDI dates. They have no time zone,
+// they are the local time of LugarExpedicion.
+const FechaLayout = "2006-01-02T15:04:05"
+
+// Fecha is a CFDI date. The wall clock is kept as written, in UTC, since the
+// document doesn't say which time zone it is in.
+type Fecha struct {
+	time.Time
+}
+
+// ParseFecha parses CFDI dates, tolerating the fractional seconds and time
+// zone offsets some PACs add to FechaTimbrado.
+func ParseFecha(s string) (Fecha, error) {
+	text := strings.TrimSpace(s)
+
+	for _, layout := range []string{FechaLayout, "2006-01-02T15:04:05.999999999", time.RFC3339Nano, time.DateOnly} {
+		if t, err := time.Parse(layout, text); err == nil {
+			return Fecha{Time: time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), 0, time.UTC)}, nil
+		}
+	}
+
+	return Fecha{}, fmt.Errorf("invalid date %q", s)
+}
+
+func (f Fecha) String() string {
+	if f.IsZero() {
+		return ""
+	}
+
+	return f.Format(FechaLayout)
+}
+
+func (f *Fecha) UnmarshalXMLAttr(attr xml.Attr) error {
+	if strings.TrimSpace(attr.Value) == "" {
+		*f = Fecha{}
+		return nil
+	}
+
+	parsed, err := ParseFecha(attr.Value)
+	if err != nil {
+		return fmt.Errorf("attribute %s: %w", attr.Name.Local, err)
+	}
+
+	*f = parsed
+	return nil
+}
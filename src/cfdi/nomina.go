@@ -0,0 +1,133 @@
+package cfdi
+
+// VersionNomina12 is the only Nómina complement version stamped since 2017.
+const VersionNomina12 = "1.2"
+
+// Keys of c_TipoDeduccion and c_TipoOtroPago the payroll totals care about.
+const (
+	TipoDeduccionISR     = "002"
+	TipoOtroPagoSubsidio = "002"
+)
+
+// Nomina is the Nómina 1.2 complement of a CFDI de tipo N. Dates are plain
+// dates, kept at midnight UTC.
+type Nomina struct {
+	Version           string         `xml:"Version,attr"`
+	TipoNomina        string         `xml:"TipoNomina,attr"`
+	FechaPago         Fecha          `xml:"FechaPago,attr"`
+	FechaInicialPago  Fecha          `xml:"FechaInicialPago,attr"`
+	FechaFinalPago    Fecha          `xml:"FechaFinalPago,attr"`
+	NumDiasPagados    Decimal        `xml:"NumDiasPagados,attr"`
+	TotalPercepciones Decimal        `xml:"TotalPercepciones,attr"`
+	TotalDeducciones  Decimal        `xml:"TotalDeducciones,attr"`
+	TotalOtrosPagos   Decimal        `xml:"TotalOtrosPagos,attr"`
+	Emisor            *NominaEmisor  `xml:"Emisor"`
+	Receptor          NominaReceptor `xml:"Receptor"`
+	Percepciones      *Percepciones  `xml:"Percepciones"`
+	Deducciones       *Deducciones   `xml:"Deducciones"`
+	OtrosPagos        []OtroPago     `xml:"OtrosPagos>OtroPago"`
+}
+
+type NominaEmisor struct {
+	Curp             string `xml:"Curp,attr"`
+	RegistroPatronal string `xml:"RegistroPatronal,attr"`
+	RfcPatronOrigen  string `xml:"RfcPatronOrigen,attr"`
+}
+
+// NominaReceptor is the employee. Their RFC and name are those of the CFDI
+// Receptor.
+type NominaReceptor struct {
+	Curp                   string  `xml:"Curp,attr"`
+	NumSeguridadSocial     string  `xml:"NumSeguridadSocial,attr"`
+	FechaInicioRelLaboral  Fecha   `xml:"FechaInicioRelLaboral,attr"`
+	Antiguedad             string  `xml:"Antigüedad,attr"`
+	TipoContrato           string  `xml:"TipoContrato,attr"`
+	Sindicalizado          string  `xml:"Sindicalizado,attr"`
+	TipoJornada            string  `xml:"TipoJornada,attr"`
+	TipoRegimen            string  `xml:"TipoRegimen,attr"`
+	NumEmpleado            string  `xml:"NumEmpleado,attr"`
+	Departamento           string  `xml:"Departamento,attr"`
+	Puesto                 string  `xml:"Puesto,attr"`
+	RiesgoPuesto           string  `xml:"RiesgoPuesto,attr"`
+	PeriodicidadPago       string  `xml:"PeriodicidadPago,attr"`
+	Banco                  string  `xml:"Banco,attr"`
+	CuentaBancaria         string  `xml:"CuentaBancaria,attr"`
+	SalarioBaseCotApor     Decimal `xml:"SalarioBaseCotApor,attr"`
+	SalarioDiarioIntegrado Decimal `xml:"SalarioDiarioIntegrado,attr"`
+	ClaveEntFed            string  `xml:"ClaveEntFed,attr"`
+}
+
+type Percepciones struct {
+	TotalSueldos                 Decimal      `xml:"TotalSueldos,attr"`
+	TotalSeparacionIndemnizacion Decimal      `xml:"TotalSeparacionIndemnizacion,attr"`
+	TotalJubilacionPensionRetiro Decimal      `xml:"TotalJubilacionPensionRetiro,attr"`
+	TotalGravado                 Decimal      `xml:"TotalGravado,attr"`
+	TotalExento                  Decimal      `xml:"TotalExento,attr"`
+	Percepcion                   []Percepcion `xml:"Percepcion"`
+}
+
+type Percepcion struct {
+	TipoPercepcion string  `xml:"TipoPercepcion,attr"`
+	Clave          string  `xml:"Clave,attr"`
+	Concepto       string  `xml:"Concepto,attr"`
+	ImporteGravado Decimal `xml:"ImporteGravado,attr"`
+	ImporteExento  Decimal `xml:"ImporteExento,attr"`
+}
+
+type Deducciones struct {
+	TotalOtrasDeducciones   Decimal     `xml:"TotalOtrasDeducciones,attr"`
+	TotalImpuestosRetenidos Decimal     `xml:"TotalImpuestosRetenidos,attr"`
+	Deduccion               []Deduccion `xml:"Deduccion"`
+}
+
+type Deduccion struct {
+	TipoDeduccion string  `xml:"TipoDeduccion,attr"`
+	Clave         string  `xml:"Clave,attr"`
+	Concepto      string  `xml:"Concepto,attr"`
+	Importe       Decimal `xml:"Importe,attr"`
+}
+
+// OtroPago is a payment that is not a percepción, like the subsidio para
+// el empleo paid back to the employee.
+type OtroPago struct {
+	TipoOtroPago     string            `xml:"TipoOtroPago,attr"`
+	Clave            string            `xml:"Clave,attr"`
+	Concepto         string            `xml:"Concepto,attr"`
+	Importe          Decimal           `xml:"Importe,attr"`
+	SubsidioAlEmpleo *SubsidioAlEmpleo `xml:"SubsidioAlEmpleo"`
+}
+
+type SubsidioAlEmpleo struct {
+	SubsidioCausado Decimal `xml:"SubsidioCausado,attr"`
+}
+
+// ISRRetenido is the ISR withheld from the employee, the deducciones of type
+// 002.
+func (n *Nomina) ISRRetenido() Decimal {
+	isr := Decimal{}
+	if n.Deducciones == nil {
+		return isr
+	}
+
+	for _, deduccion := range n.Deducciones.Deduccion {
+		if deduccion.TipoDeduccion == TipoDeduccionISR {
+			isr = isr.Add(deduccion.Importe)
+		}
+	}
+
+	return isr
+}
+
+// SubsidioCausado is the subsidio para el empleo the employee was entitled
+// to, which can be more than the amount paid as OtroPago.
+func (n *Nomina) SubsidioCausado() Decimal {
+	subsidio := Decimal{}
+
+	for _, otroPago := range n.OtrosPagos {
+		if otroPago.TipoOtroPago == TipoOtroPagoSubsidio && otroPago.SubsidioAlEmpleo != nil {
+			subsidio = subsidio.Add(otroPago.SubsidioAlEmpleo.SubsidioCausado)
+		}
+	}
+
+	return subsidio
+}
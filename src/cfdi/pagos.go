@@ -0,0 +1,52 @@
+package cfdi
+
+import "strings"
+
+// Versions of the Recepción de Pagos complement. 1.0 goes with CFDI 3.3 and
+// 2.0 with CFDI 4.0.
+const (
+	VersionPagos10 = "1.0"
+	VersionPagos20 = "2.0"
+)
+
+// Pagos is the Recepción de Pagos complement of a CFDI de tipo P. Both
+// versions share element and attribute names, Totales and the taxes of 2.0
+// are not kept.
+type Pagos struct {
+	Version string `xml:"Version,attr"`
+	Pago    []Pago `xml:"Pago"`
+}
+
+// Pago is a payment received, which can settle several invoices.
+type Pago struct {
+	FechaPago    Fecha              `xml:"FechaPago,attr"`
+	FormaDePagoP string             `xml:"FormaDePagoP,attr"`
+	MonedaP      string             `xml:"MonedaP,attr"`
+	TipoCambioP  Decimal            `xml:"TipoCambioP,attr"`
+	Monto        Decimal            `xml:"Monto,attr"`
+	NumOperacion string             `xml:"NumOperacion,attr"`
+	Doctos       []DoctoRelacionado `xml:"DoctoRelacionado"`
+}
+
+// DoctoRelacionado is the part of a Pago applied to one invoice. Amounts are
+// in MonedaDR, the currency of the invoice. Pagos 1.0 uses TipoCambioDR and
+// MetodoDePagoDR, 2.0 replaced them with EquivalenciaDR.
+type DoctoRelacionado struct {
+	IdDocumento      string  `xml:"IdDocumento,attr"`
+	Serie            string  `xml:"Serie,attr"`
+	Folio            string  `xml:"Folio,attr"`
+	MonedaDR         string  `xml:"MonedaDR,attr"`
+	EquivalenciaDR   Decimal `xml:"EquivalenciaDR,attr"`
+	TipoCambioDR     Decimal `xml:"TipoCambioDR,attr"`
+	MetodoDePagoDR   string  `xml:"MetodoDePagoDR,attr"`
+	NumParcialidad   int     `xml:"NumParcialidad,attr"`
+	ImpSaldoAnt      Decimal `xml:"ImpSaldoAnt,attr"`
+	ImpPagado        Decimal `xml:"ImpPagado,attr"`
+	ImpSaldoInsoluto Decimal `xml:"ImpSaldoInsoluto,attr"`
+	ObjetoImpDR      string  `xml:"ObjetoImpDR,attr"`
+}
+
+// UUID is the folio fiscal of the invoice paid, uppercased.
+func (d *DoctoRelacionado) UUID() string {
+	return strings.ToUpper(strings.TrimSpace(d.IdDocumento))
+}
@@ -0,0 +1,89 @@
+package cfdi
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+var (
+	ErrNotComprobante     = errors.New("XML is not a CFDI Comprobante")
+	ErrUnsupportedVersion = errors.New("unsupported CFDI version")
+)
+
+// utf8BOM is written by some PACs and by Windows editors at the start of
+// the file.
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// Parse parses a CFDI 3.3 or 4.0. Elements and attributes are matched by
+// local name, so namespace prefixes and URIs don't matter, and documents
+// declared as ISO-8859-1 or windows-1252 are decoded to UTF-8.
+func Parse(data []byte) (*Comprobante, error) {
+	data = bytes.TrimPrefix(data, utf8BOM)
+
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	decoder.CharsetReader = charsetReader
+
+	comprobante := new(Comprobante)
+	if err := decoder.Decode(comprobante); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, ErrNotComprobante
+		}
+
+		return nil, fmt.Errorf("%w: %w", ErrNotComprobante, err)
+	}
+
+	if comprobante.XMLName.Local != "Comprobante" {
+		return nil, ErrNotComprobante
+	}
+
+	comprobante.Version = strings.TrimSpace(comprobante.Version)
+	if comprobante.Version == "" {
+		comprobante.Version = versionFromNamespace(comprobante.XMLName.Space)
+	}
+
+	if comprobante.Version != Version33 && comprobante.Version != Version40 {
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedVersion, comprobante.Version)
+	}
+
+	return comprobante, nil
+}
+
+// ParseReader is like Parse for a reader, like a file in a ZIP package.
+func ParseReader(r io.Reader) (*Comprobante, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return Parse(data)
+}
+
+func versionFromNamespace(namespace string) string {
+	switch namespace {
+	case NamespaceCFDI33:
+		return Version33
+	case NamespaceCFDI40:
+		return Version40
+	default:
+		return ""
+	}
+}
+
+func charsetReader(label string, input io.Reader) (io.Reader, error) {
+	switch strings.ToLower(strings.TrimSpace(label)) {
+	case "utf-8", "utf8":
+		return input, nil
+	case "iso-8859-1", "iso8859-1", "latin1", "latin-1":
+		return charmap.ISO8859_1.NewDecoder().Reader(input), nil
+	case "windows-1252", "cp1252":
+		return charmap.Windows1252.NewDecoder().Reader(input), nil
+	default:
+		return nil, fmt.Errorf("unsupported charset %q", label)
+	}
+}
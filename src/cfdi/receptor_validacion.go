@@ -0,0 +1,121 @@
+package cfdi
+
+import (
+	"app/src/rfc"
+	"regexp"
+	"strings"
+)
+
+// Tipos of the incidencias found by Comprobante.ValidarReceptor and by the
+// checks of the Receptor against the SAT catalogs.
+const (
+	IncidenciaRFCInvalido    = "rfc_invalido"
+	IncidenciaRFCGenerico    = "rfc_generico"
+	IncidenciaTipoPersona    = "tipo_persona"
+	IncidenciaUsoCFDIRegimen = "uso_cfdi_regimen"
+	IncidenciaUsoCFDITipo    = "uso_cfdi_tipo_comprobante"
+	IncidenciaClaveNoVigente = "clave_no_vigente"
+)
+
+// Claves of c_RegimenFiscal and c_UsoCFDI the receptor rules depend on.
+const (
+	RegimenSinObligaciones    = "616"
+	UsoCFDISinEfectosFiscales = "S01"
+	UsoCFDIPagos              = "CP01"
+	UsoCFDINomina             = "CN01"
+)
+
+// NombrePublicoEnGeneral is the Nombre of the receptor of the global CFDIs,
+// the ones with InformacionGlobal, issued to the generic RFC XAXX010101000.
+// A CFDI to XAXX010101000 without it may carry the name of the customer.
+const NombrePublicoEnGeneral = "PUBLICO EN GENERAL"
+
+var (
+	patronRegimenFiscal = regexp.MustCompile(`^[0-9]{3}$`)
+	patronUsoCFDI       = regexp.MustCompile(`^[A-Z]{1,2}[0-9]{2}$`)
+)
+
+// ValidarReceptor checks the Receptor of a CFDI 4.0 with the rules that
+// don't need the SAT catalogs: the attributes 4.0 made mandatory, the
+// structure of the RFC and the Código Postal, the UsoCFDI of pagos and
+// nóminas and what the generic RFCs require. 3.3 documents aren't checked.
+func (c *Comprobante) ValidarReceptor() []Incidencia {
+	if c.Version != Version40 {
+		return nil
+	}
+
+	v := &validacion{}
+	r := c.Receptor
+
+	rfcReceptor := strings.ToUpper(strings.TrimSpace(r.RFC))
+	if v.requerido("Receptor@Rfc", rfcReceptor) {
+		if err := rfc.Check(rfcReceptor); err != nil {
+			v.agregar(IncidenciaRFCInvalido, "Receptor@Rfc", "%q: %s", rfcReceptor, err.Error())
+		}
+	}
+
+	v.requerido("Receptor@Nombre", r.Nombre)
+	v.clave("Receptor@DomicilioFiscalReceptor", r.DomicilioFiscalReceptor, patronCodigoPostal)
+	v.clave("Receptor@RegimenFiscalReceptor", r.RegimenFiscalReceptor, patronRegimenFiscal)
+	v.clave("Receptor@UsoCFDI", r.UsoCFDI, patronUsoCFDI)
+
+	switch c.TipoDeComprobante {
+	case TipoPago:
+		if r.UsoCFDI != "" && r.UsoCFDI != UsoCFDIPagos {
+			v.agregar(IncidenciaUsoCFDITipo, "Receptor@UsoCFDI", "UsoCFDI must be %s in a pago, not %q", UsoCFDIPagos, r.UsoCFDI)
+		}
+	case TipoNomina:
+		if r.UsoCFDI != "" && r.UsoCFDI != UsoCFDINomina {
+			v.agregar(IncidenciaUsoCFDITipo, "Receptor@UsoCFDI", "UsoCFDI must be %s in a nómina, not %q", UsoCFDINomina, r.UsoCFDI)
+		}
+	}
+
+	if rfc.IsGeneric(rfcReceptor) {
+		c.validarRFCGenerico(v, rfcReceptor)
+	}
+
+	return v.incidencias
+}
+
+// validarRFCGenerico checks the receptor of a CFDI issued to público en
+// general or to a foreign resident, which has no régimen or domicilio at
+// the SAT: it is 616 with the Código Postal of the issuer.
+func (c *Comprobante) validarRFCGenerico(v *validacion, generico string) {
+	r := c.Receptor
+
+	if r.RegimenFiscalReceptor != "" && r.RegimenFiscalReceptor != RegimenSinObligaciones {
+		v.agregar(IncidenciaRFCGenerico, "Receptor@RegimenFiscalReceptor",
+			"RegimenFiscalReceptor must be %s for the generic RFC %s", RegimenSinObligaciones, generico)
+	}
+
+	usos := []string{UsoCFDISinEfectosFiscales}
+	if c.TipoDeComprobante == TipoPago {
+		usos = append(usos, UsoCFDIPagos)
+	}
+
+	if r.UsoCFDI != "" && !contiene(usos, r.UsoCFDI) {
+		v.agregar(IncidenciaRFCGenerico, "Receptor@UsoCFDI",
+			"UsoCFDI must be %s for the generic RFC %s", strings.Join(usos, " or "), generico)
+	}
+
+	if r.DomicilioFiscalReceptor != "" && c.LugarExpedicion != "" && r.DomicilioFiscalReceptor != c.LugarExpedicion {
+		v.agregar(IncidenciaRFCGenerico, "Receptor@DomicilioFiscalReceptor",
+			"DomicilioFiscalReceptor must be the LugarExpedicion %s for the generic RFC %s", c.LugarExpedicion, generico)
+	}
+
+	nombre := strings.ReplaceAll(strings.ToUpper(strings.TrimSpace(r.Nombre)), "Ú", "U")
+	if generico == rfc.GenericoNacional && c.InformacionGlobal != nil && r.Nombre != "" && nombre != NombrePublicoEnGeneral {
+		v.agregar(IncidenciaRFCGenerico, "Receptor@Nombre",
+			"Nombre must be %s for the generic RFC %s", NombrePublicoEnGeneral, generico)
+	}
+}
+
+func contiene(valores []string, valor string) bool {
+	for _, v := range valores {
+		if v == valor {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"app/src/catalogo"
+	"app/src/config"
+	"app/src/database"
+	"app/src/service"
+	"app/src/utils"
+	"context"
+	"errors"
+	"flag"
+	"os"
+	"time"
+)
+
+// Imports the sheets of catCFDI.xls, each exported to its own CSV file, as
+// the publication of the given date. Sheets of unsupported catalogs are
+// skipped, a file with invalid rows is not imported at all.
+//
+//	go run src/cmd/catalogos/main.go -publicacion 2024-06-17 catCFDI/*.csv
+func main() {
+	publicacion := flag.String("publicacion", "", "publication date of the catalogs (YYYY-MM-DD)")
+	flag.Parse()
+
+	fechaPublicacion, err := time.Parse(time.DateOnly, *publicacion)
+	if err != nil {
+		utils.Log.Fatalf("Invalid -publicacion %q, expected YYYY-MM-DD", *publicacion)
+	}
+
+	if flag.NArg() == 0 {
+		utils.Log.Fatalf("No CSV files given")
+	}
+
+	db := database.Connect(config.DBHost, config.DBName)
+	sqlDB, err := db.DB()
+	if err != nil {
+		utils.Log.Fatalf("Error getting database instance: %v", err)
+	}
+	defer sqlDB.Close()
+
+	catalogoService := service.NewCatalogoService(db, nil, config.CatalogosCacheTTL())
+	ctx := context.Background()
+
+	fallidos := 0
+	for _, archivo := range flag.Args() {
+		nombre, importados, err := importar(ctx, catalogoService, archivo, fechaPublicacion)
+		switch {
+		case errors.Is(err, catalogo.ErrCatalogoDesconocido):
+			utils.Log.Warnf("Skipping %s: %v", archivo, err)
+		case err != nil:
+			utils.Log.Errorf("Error importing %s: %v", archivo, err)
+			fallidos++
+		default:
+			utils.Log.Infof("Imported %d entries of %s from %s", importados, nombre, archivo)
+		}
+	}
+
+	if fallidos > 0 {
+		sqlDB.Close()
+		utils.Log.Fatalf("%d files could not be imported", fallidos)
+	}
+}
+
+func importar(
+	ctx context.Context, catalogoService service.CatalogoService, archivo string, fechaPublicacion time.Time,
+) (string, int, error) {
+	file, err := os.Open(archivo)
+	if err != nil {
+		return "", 0, err
+	}
+	defer file.Close()
+
+	definicion, registros, err := catalogo.LeerCSV(file)
+	if err != nil {
+		return "", 0, err
+	}
+
+	importados, err := catalogoService.Importar(ctx, definicion.Nombre, fechaPublicacion, registros)
+	return definicion.Nombre, importados, err
+}
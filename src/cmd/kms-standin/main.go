@@ -0,0 +1,35 @@
+package main
+
+import (
+	"app/src/secrets"
+	"app/src/utils"
+	"flag"
+	"net/http"
+	"time"
+)
+
+// Runs an in-memory server speaking the Vault Transit API so KEY_PROVIDER=vault
+// can be used offline. Keys are lost on restart: development and tests only.
+func main() {
+	address := flag.String("addr", "127.0.0.1:8200", "listen address")
+	token := flag.String("token", "", "token expected in X-Vault-Token")
+	mount := flag.String("mount", "transit", "transit mount path")
+	key := flag.String("key", "datos-fiscales", "key created on startup")
+	flag.Parse()
+
+	standIn := secrets.NewTransitStandIn(*token, *mount)
+	if _, err := standIn.Rotate(*key); err != nil {
+		utils.Log.Fatalf("Error creating key %s: %v", *key, err)
+	}
+
+	server := &http.Server{
+		Addr:              *address,
+		Handler:           standIn,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+
+	utils.Log.Infof("Transit stand-in listening on %s", *address)
+	if err := server.ListenAndServe(); err != nil {
+		utils.Log.Fatalf("Transit stand-in stopped: %v", err)
+	}
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"app/src/config"
+	"app/src/database"
+	"app/src/service"
+	"app/src/utils"
+	"context"
+)
+
+// Re-encrypts every stored e.firma with the newest master key version of the
+// configured KeyProvider. Keep the previous versions available until it finishes.
+func main() {
+	keyProvider, err := config.KeyProvider()
+	if err != nil {
+		utils.Log.Fatalf("Invalid key provider configuration: %v", err)
+	}
+
+	ctx := context.Background()
+	version, err := keyProvider.CurrentVersion(ctx)
+	if err != nil {
+		utils.Log.Fatalf("Error reading the current master key version: %v", err)
+	}
+
+	db := database.Connect(config.DBHost, config.DBName)
+	sqlDB, err := db.DB()
+	if err != nil {
+		utils.Log.Fatalf("Error getting database instance: %v", err)
+	}
+	defer sqlDB.Close()
+
+	migrated, err := service.NewKeyRotationService(db, keyProvider).ReencryptAll(ctx)
+	if err != nil {
+		utils.Log.Fatalf("Re-encryption stopped after %d records: %v", migrated, err)
+	}
+
+	utils.Log.Infof("Re-encrypted %d records with master key version %d", migrated, version)
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"app/src/storage"
+	"app/src/utils"
+	"flag"
+	"net/http"
+	"time"
+)
+
+// Runs an in-memory S3-compatible server so BLOB_STORE=s3 can be used
+// offline. Objects are lost on restart: development and tests only.
+func main() {
+	address := flag.String("addr", "127.0.0.1:9000", "listen address")
+	accessKey := flag.String("access-key", "", "access key id")
+	secretKey := flag.String("secret-key", "", "secret access key")
+	region := flag.String("region", "us-east-1", "region used in the signatures")
+	bucket := flag.String("bucket", "paquetes-sat", "bucket created on startup")
+	flag.Parse()
+
+	standIn := storage.NewS3StandIn(*accessKey, *secretKey, *region, *bucket)
+
+	server := &http.Server{
+		Addr:              *address,
+		Handler:           standIn,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+
+	utils.Log.Infof("S3 stand-in listening on %s", *address)
+	if err := server.ListenAndServe(); err != nil {
+		utils.Log.Fatalf("S3 stand-in stopped: %v", err)
+	}
+}
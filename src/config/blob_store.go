@@ -0,0 +1,27 @@
+package config
+
+import (
+	"app/src/storage"
+	"fmt"
+)
+
+const (
+	BlobStoreLocal = "local"
+	BlobStoreS3    = "s3"
+)
+
+// BlobStore builds the package storage backend selected with BLOB_STORE.
+func BlobStore() (storage.BlobStore, error) {
+	switch BlobStoreType {
+	case "", BlobStoreLocal:
+		path := BlobLocalPath
+		if path == "" {
+			path = "./storage"
+		}
+		return storage.NewLocalStore(path)
+	case BlobStoreS3:
+		return storage.NewS3Store(S3Endpoint, S3Region, S3Bucket, S3AccessKey, S3SecretKey)
+	default:
+		return nil, fmt.Errorf("unknown blob store %q", BlobStoreType)
+	}
+}
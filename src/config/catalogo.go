@@ -0,0 +1,13 @@
+package config
+
+import "time"
+
+// CatalogosCacheTTL is how long catalog lookups are reused, one hour unless
+// configured. Imported publications show up in the API once it expires.
+func CatalogosCacheTTL() time.Duration {
+	if CatalogosCacheSecs <= 0 {
+		return time.Hour
+	}
+
+	return time.Duration(CatalogosCacheSecs) * time.Second
+}
@@ -29,6 +29,35 @@ var (
 	GoogleClientSecret  string
 	RedirectURL         string
 	EncryptionKey       string
+	EncryptionKeys      string
+	EncryptionKeysFile  string
+	KeyProviderType     string
+	VaultAddr           string
+	VaultToken          string
+	VaultTransitMount   string
+	VaultTransitKey     string
+	SATAutenticacionURL string
+	SATSolicitudURL     string
+	SATVerificacionURL  string
+	SATDescargaURL      string
+	SATConsultaURL      string
+	SATVerificacionSecs int
+	SATDescargaSecs     int
+	SATConsultaSecs     int
+	SATConsultaTTLSecs  int
+	SATConsultaDias     int
+	SATCertificadosDir  string
+	SATAutoridadesDir   string
+	CatalogosCacheSecs  int
+	SuscripcionesSecs   int
+	SuscripcionAviso    int
+	BlobStoreType       string
+	BlobLocalPath       string
+	S3Endpoint          string
+	S3Region            string
+	S3Bucket            string
+	S3AccessKey         string
+	S3SecretKey         string
 )
 
 func init() {
@@ -64,7 +93,48 @@ func init() {
 	GoogleClientID = viper.GetString("GOOGLE_CLIENT_ID")
 	GoogleClientSecret = viper.GetString("GOOGLE_CLIENT_SECRET")
 	RedirectURL = viper.GetString("REDIRECT_URL")
+
+	// fiscal data encryption configuration
 	EncryptionKey = viper.GetString("ENCRYPTION_KEY")
+	EncryptionKeys = viper.GetString("ENCRYPTION_KEYS")
+	EncryptionKeysFile = viper.GetString("ENCRYPTION_KEYS_FILE")
+	KeyProviderType = viper.GetString("KEY_PROVIDER")
+	VaultAddr = viper.GetString("VAULT_ADDR")
+	VaultToken = viper.GetString("VAULT_TOKEN")
+	VaultTransitMount = viper.GetString("VAULT_TRANSIT_MOUNT")
+	VaultTransitKey = viper.GetString("VAULT_TRANSIT_KEY")
+
+	// SAT web services configuration, empty values use the production URLs
+	SATAutenticacionURL = viper.GetString("SAT_AUTENTICACION_URL")
+	SATSolicitudURL = viper.GetString("SAT_SOLICITUD_URL")
+	SATVerificacionURL = viper.GetString("SAT_VERIFICACION_URL")
+	SATDescargaURL = viper.GetString("SAT_DESCARGA_URL")
+	SATConsultaURL = viper.GetString("SAT_CONSULTA_URL")
+
+	// certificates of the SAT and the PACs that verify SelloSAT offline
+	SATCertificadosDir = viper.GetString("SAT_CERTIFICADOS_DIR")
+	SATAutoridadesDir = viper.GetString("SAT_AUTORIDADES_DIR")
+
+	// SAT catalogs configuration
+	CatalogosCacheSecs = viper.GetInt("CATALOGOS_CACHE_TTL_SECONDS")
+
+	// background workers configuration
+	SATVerificacionSecs = viper.GetInt("SAT_VERIFICACION_INTERVAL_SECONDS")
+	SATDescargaSecs = viper.GetInt("SAT_DESCARGA_INTERVAL_SECONDS")
+	SATConsultaSecs = viper.GetInt("SAT_CONSULTA_INTERVAL_SECONDS")
+	SATConsultaTTLSecs = viper.GetInt("SAT_CONSULTA_TTL_SECONDS")
+	SATConsultaDias = viper.GetInt("SAT_CONSULTA_DIAS")
+	SuscripcionesSecs = viper.GetInt("SUSCRIPCIONES_INTERVAL_SECONDS")
+	SuscripcionAviso = viper.GetInt("SUSCRIPCION_AVISO_DIAS")
+
+	// package storage configuration
+	BlobStoreType = viper.GetString("BLOB_STORE")
+	BlobLocalPath = viper.GetString("BLOB_LOCAL_PATH")
+	S3Endpoint = viper.GetString("S3_ENDPOINT")
+	S3Region = viper.GetString("S3_REGION")
+	S3Bucket = viper.GetString("S3_BUCKET")
+	S3AccessKey = viper.GetString("S3_ACCESS_KEY")
+	S3SecretKey = viper.GetString("S3_SECRET_KEY")
 }
 
 func loadConfig() {
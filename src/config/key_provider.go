@@ -0,0 +1,26 @@
+package config
+
+import (
+	"app/src/secrets"
+	"fmt"
+)
+
+const (
+	KeyProviderLocal = "local"
+	KeyProviderVault = "vault"
+)
+
+// KeyProvider builds the master key backend selected with KEY_PROVIDER.
+func KeyProvider() (secrets.KeyProvider, error) {
+	switch KeyProviderType {
+	case "", KeyProviderLocal:
+		if EncryptionKeysFile != "" {
+			return secrets.LoadKeyringFile(EncryptionKeysFile)
+		}
+		return secrets.ParseKeyring(EncryptionKeys, EncryptionKey)
+	case KeyProviderVault:
+		return secrets.NewVaultTransit(VaultAddr, VaultToken, VaultTransitMount, VaultTransitKey)
+	default:
+		return nil, fmt.Errorf("unknown key provider %q", KeyProviderType)
+	}
+}
@@ -0,0 +1,53 @@
+package config
+
+import "time"
+
+// SATVerificacionInterval is how often pending download requests are
+// verified, one minute unless configured.
+func SATVerificacionInterval() time.Duration {
+	if SATVerificacionSecs <= 0 {
+		return time.Minute
+	}
+
+	return time.Duration(SATVerificacionSecs) * time.Second
+}
+
+// SATDescargaInterval is how often the packages of finished requests are
+// downloaded, one minute unless configured.
+func SATDescargaInterval() time.Duration {
+	if SATDescargaSecs <= 0 {
+		return time.Minute
+	}
+
+	return time.Duration(SATDescargaSecs) * time.Second
+}
+
+// SATConsultaInterval is how often the status of recent CFDIs is checked
+// again at the SAT, six hours unless configured.
+func SATConsultaInterval() time.Duration {
+	if SATConsultaSecs <= 0 {
+		return 6 * time.Hour
+	}
+
+	return time.Duration(SATConsultaSecs) * time.Second
+}
+
+// SATConsultaTTL is how long a CFDI status from the SAT is reused before
+// asking again, one hour unless configured.
+func SATConsultaTTL() time.Duration {
+	if SATConsultaTTLSecs <= 0 {
+		return time.Hour
+	}
+
+	return time.Duration(SATConsultaTTLSecs) * time.Second
+}
+
+// SATConsultaRecientes is how many days back, by fecha, CFDIs are checked
+// again by the status job, 90 unless configured.
+func SATConsultaRecientes() int {
+	if SATConsultaDias <= 0 {
+		return 90
+	}
+
+	return SATConsultaDias
+}
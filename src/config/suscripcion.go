@@ -0,0 +1,23 @@
+package config
+
+import "time"
+
+// SuscripcionesInterval is how often subscriptions are expired, their
+// monthly counters reset and expiry notices sent, one hour unless configured.
+func SuscripcionesInterval() time.Duration {
+	if SuscripcionesSecs <= 0 {
+		return time.Hour
+	}
+
+	return time.Duration(SuscripcionesSecs) * time.Second
+}
+
+// AvisoVencimiento is how many days before fecha_fin users are told their
+// subscription is about to expire, 7 unless configured.
+func AvisoVencimiento() int {
+	if SuscripcionAviso <= 0 {
+		return 7
+	}
+
+	return SuscripcionAviso
+}
@@ -0,0 +1,52 @@
+package controller
+
+import (
+	"app/src/model"
+	"app/src/response"
+	"app/src/service"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type CartaPorteController struct {
+	CartaPorteService service.CartaPorteService
+}
+
+func NewCartaPorteController(cartaPorteService service.CartaPorteService) *CartaPorteController {
+	return &CartaPorteController{
+		CartaPorteService: cartaPorteService,
+	}
+}
+
+// @Tags         CFDIs
+// @Summary      Get the Carta Porte of a CFDI
+// @Description  Returns the ubicaciones, mercancías, autotransporte and figuras of the Carta Porte complement of a CFDI of the RFCs registered by the logged in user, with the missing nodes, inconsistent weights and invalid catalog keys found when it was indexed.
+// @Security     BearerAuth
+// @Produce      json
+// @Param        uuid  path  string  true  "Folio fiscal of the CFDI"
+// @Router       /cfdis/{uuid}/carta-porte [get]
+// @Success      200  {object}  response.SuccessWithData{data=model.CFDICartaPorte}
+// @Failure      400  {object}  response.Common  "Bad request"
+// @Failure      401  {object}  response.Common  "Unauthorized"
+// @Failure      404  {object}  response.Common  "Not found"
+func (cp *CartaPorteController) GetCartaPorte(ctx *fiber.Ctx) error {
+	user, _ := ctx.Locals("user").(*model.User)
+
+	id, err := uuid.Parse(ctx.Params("uuid"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid CFDI UUID")
+	}
+
+	cartaPorte, err := cp.CartaPorteService.GetCartaPorte(ctx, user.ID, id)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(response.SuccessWithData{
+		Code:    fiber.StatusOK,
+		Status:  "success",
+		Message: "Carta Porte retrieved successfully",
+		Data:    cartaPorte,
+	})
+}
@@ -0,0 +1,111 @@
+package controller
+
+import (
+	"app/src/model"
+	"app/src/response"
+	"app/src/service"
+	"app/src/validation"
+	"math"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type CatalogoController struct {
+	CatalogoService service.CatalogoService
+}
+
+func NewCatalogoController(catalogoService service.CatalogoService) *CatalogoController {
+	return &CatalogoController{
+		CatalogoService: catalogoService,
+	}
+}
+
+// @Tags         Catalogos
+// @Summary      List SAT catalogs
+// @Description  Lists the supported SAT catalogs with the publication date and size of their newest import.
+// @Security     BearerAuth
+// @Produce      json
+// @Router       /catalogos [get]
+// @Success      200  {object}  response.SuccessWithData{data=[]response.Catalogo}
+// @Failure      401  {object}  response.Common  "Unauthorized"
+func (c *CatalogoController) GetCatalogos(ctx *fiber.Ctx) error {
+	catalogos, err := c.CatalogoService.GetCatalogos(ctx)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(response.SuccessWithData{
+		Code:    fiber.StatusOK,
+		Status:  "success",
+		Message: "Catalogs retrieved successfully",
+		Data:    catalogos,
+	})
+}
+
+// @Tags         Catalogos
+// @Summary      Search a SAT catalog
+// @Description  Searches the entries of a catalog by clave prefix or description, ordered by clave. Uses the newest publication, or the one in effect on fecha.
+// @Security     BearerAuth
+// @Produce      json
+// @Param        catalogo  path   string  true   "Catalog slug, like clave-prod-serv or uso-cfdi"
+// @Param        q         query  string  false  "Clave prefix or words of the description"
+// @Param        fecha     query  string  false  "Use the publication in effect on this date (YYYY-MM-DD)"
+// @Param        page      query  int     false  "Page number"  default(1)
+// @Param        limit     query  int     false  "Maximum number of entries"  default(10)
+// @Router       /catalogos/{catalogo} [get]
+// @Success      200  {object}  response.SuccessWithPaginate[model.CatalogoRegistro]
+// @Failure      400  {object}  response.Common  "Bad request"
+// @Failure      401  {object}  response.Common  "Unauthorized"
+// @Failure      404  {object}  response.Common  "Catalog not found or not imported"
+func (c *CatalogoController) BuscarRegistros(ctx *fiber.Ctx) error {
+	query := &validation.QueryCatalogo{
+		Page:  ctx.QueryInt("page", 1),
+		Limit: ctx.QueryInt("limit", 10),
+		Q:     ctx.Query("q", ""),
+		Fecha: ctx.Query("fecha", ""),
+	}
+
+	registros, totalResults, err := c.CatalogoService.BuscarRegistros(ctx, ctx.Params("catalogo"), query)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).
+		JSON(response.SuccessWithPaginate[model.CatalogoRegistro]{
+			Code:         fiber.StatusOK,
+			Status:       "success",
+			Message:      "Catalog entries retrieved successfully",
+			Results:      registros,
+			Page:         query.Page,
+			Limit:        query.Limit,
+			TotalPages:   int64(math.Ceil(float64(totalResults) / float64(query.Limit))),
+			TotalResults: totalResults,
+		})
+}
+
+// @Tags         Catalogos
+// @Summary      Get a SAT catalog entry
+// @Description  Returns the entry of a catalog by clave, from the newest publication or the one in effect on fecha.
+// @Security     BearerAuth
+// @Produce      json
+// @Param        catalogo  path   string  true   "Catalog slug, like clave-prod-serv or uso-cfdi"
+// @Param        clave     path   string  true   "Clave of the entry"
+// @Param        fecha     query  string  false  "Use the publication in effect on this date (YYYY-MM-DD)"
+// @Router       /catalogos/{catalogo}/{clave} [get]
+// @Success      200  {object}  response.SuccessWithData{data=model.CatalogoRegistro}
+// @Failure      400  {object}  response.Common  "Bad request"
+// @Failure      401  {object}  response.Common  "Unauthorized"
+// @Failure      404  {object}  response.Common  "Not found"
+func (c *CatalogoController) GetRegistro(ctx *fiber.Ctx) error {
+	registro, err := c.CatalogoService.GetRegistro(ctx, ctx.Params("catalogo"), ctx.Params("clave"), ctx.Query("fecha", ""))
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(response.SuccessWithData{
+		Code:    fiber.StatusOK,
+		Status:  "success",
+		Message: "Catalog entry retrieved successfully",
+		Data:    registro,
+	})
+}
@@ -0,0 +1,147 @@
+package controller
+
+import (
+	"app/src/model"
+	"app/src/response"
+	"app/src/service"
+	"app/src/validation"
+	"math"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type CFDIController struct {
+	CFDIService       service.CFDIService
+	EstadoCFDIService service.EstadoCFDIService
+}
+
+func NewCFDIController(cfdiService service.CFDIService, estadoCFDIService service.EstadoCFDIService) *CFDIController {
+	return &CFDIController{
+		CFDIService:       cfdiService,
+		EstadoCFDIService: estadoCFDIService,
+	}
+}
+
+// @Tags         CFDIs
+// @Summary      List indexed CFDIs
+// @Description  Lists the CFDIs emitted or received by the RFCs registered by the logged in user, newest first.
+// @Security     BearerAuth
+// @Produce      json
+// @Param        page           query  int     false  "Page number"  default(1)
+// @Param        limit          query  int     false  "Maximum number of CFDIs"  default(10)
+// @Param        fecha_inicial  query  string  false  "Issued on or after this date (YYYY-MM-DD)"
+// @Param        fecha_final    query  string  false  "Issued on or before this date (YYYY-MM-DD)"
+// @Param        rfc            query  string  false  "RFC of the counterpart, emisor or receptor"
+// @Param        tipo           query  string  false  "TipoDeComprobante"  Enums(I, E, T, N, P)
+// @Param        total_min      query  number  false  "Minimum total"
+// @Param        total_max      query  number  false  "Maximum total"
+// @Param        search         query  string  false  "Search in the concepto descriptions"
+// @Router       /cfdis [get]
+// @Success      200  {object}  response.SuccessWithPaginate[model.CFDI]
+// @Failure      400  {object}  response.Common  "Bad request"
+// @Failure      401  {object}  response.Common  "Unauthorized"
+func (c *CFDIController) GetCFDIs(ctx *fiber.Ctx) error {
+	user, _ := ctx.Locals("user").(*model.User)
+
+	query := &validation.QueryCFDI{
+		Page:         ctx.QueryInt("page", 1),
+		Limit:        ctx.QueryInt("limit", 10),
+		FechaInicial: ctx.Query("fecha_inicial", ""),
+		FechaFinal:   ctx.Query("fecha_final", ""),
+		RFC:          ctx.Query("rfc", ""),
+		Tipo:         ctx.Query("tipo", ""),
+		TotalMin:     ctx.Query("total_min", ""),
+		TotalMax:     ctx.Query("total_max", ""),
+		Search:       ctx.Query("search", ""),
+	}
+
+	cfdis, totalResults, err := c.CFDIService.GetCFDIs(ctx, user.ID, query)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).
+		JSON(response.SuccessWithPaginate[model.CFDI]{
+			Code:         fiber.StatusOK,
+			Status:       "success",
+			Message:      "CFDIs retrieved successfully",
+			Results:      cfdis,
+			Page:         query.Page,
+			Limit:        query.Limit,
+			TotalPages:   int64(math.Ceil(float64(totalResults) / float64(query.Limit))),
+			TotalResults: totalResults,
+		})
+}
+
+// @Tags         CFDIs
+// @Summary      Reconcile metadata and XMLs
+// @Description  Lists the CFDIs of the RFCs registered by the logged in user that appear in the downloaded metadata but whose XML was never indexed, and the indexed XMLs that were cancelled afterwards.
+// @Security     BearerAuth
+// @Produce      json
+// @Param        fecha_inicial  query  string  false  "Issued on or after this date (YYYY-MM-DD)"
+// @Param        fecha_final    query  string  false  "Issued on or before this date (YYYY-MM-DD)"
+// @Router       /cfdis/conciliacion [get]
+// @Success      200  {object}  response.SuccessWithData{data=response.Conciliacion}
+// @Failure      400  {object}  response.Common  "Bad request"
+// @Failure      401  {object}  response.Common  "Unauthorized"
+func (c *CFDIController) GetConciliacion(ctx *fiber.Ctx) error {
+	user, _ := ctx.Locals("user").(*model.User)
+
+	query := &validation.QueryConciliacion{
+		FechaInicial: ctx.Query("fecha_inicial", ""),
+		FechaFinal:   ctx.Query("fecha_final", ""),
+	}
+
+	sinXML, cancelados, err := c.CFDIService.GetConciliacion(ctx, user.ID, query)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(response.SuccessWithData{
+		Code:    fiber.StatusOK,
+		Status:  "success",
+		Message: "CFDIs reconciled successfully",
+		Data: response.Conciliacion{
+			SinXML:     sinXML,
+			Cancelados: cancelados,
+		},
+	})
+}
+
+// @Tags         CFDIs
+// @Summary      Check the CFDI status at the SAT
+// @Description  Queries the SAT ConsultaCFDI service for a CFDI of the RFCs registered by the logged in user and updates its stored estado. Answers are cached for a while.
+// @Security     BearerAuth
+// @Produce      json
+// @Param        uuid  path  string  true  "Folio fiscal"
+// @Router       /cfdis/{uuid}/estado [get]
+// @Success      200  {object}  response.SuccessWithData{data=response.EstadoCFDI}
+// @Failure      400  {object}  response.Common  "Bad request"
+// @Failure      401  {object}  response.Common  "Unauthorized"
+// @Failure      404  {object}  response.Common  "Not found"
+// @Failure      502  {object}  response.Common  "SAT unavailable"
+func (c *CFDIController) GetEstado(ctx *fiber.Ctx) error {
+	user, _ := ctx.Locals("user").(*model.User)
+
+	id, err := uuid.Parse(ctx.Params("uuid"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid CFDI UUID")
+	}
+
+	registro, consulta, err := c.EstadoCFDIService.GetEstado(ctx, user.ID, id)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(response.SuccessWithData{
+		Code:    fiber.StatusOK,
+		Status:  "success",
+		Message: "CFDI status retrieved successfully",
+		Data: response.EstadoCFDI{
+			UUID:   registro.UUID,
+			Estado: registro.Estado,
+			SAT:    consulta,
+		},
+	})
+}
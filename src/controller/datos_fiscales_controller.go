@@ -5,8 +5,10 @@ import (
 	"app/src/response"
 	"app/src/service"
 	"app/src/validation"
+	"math"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
 )
 
 type DatosFiscalesController struct {
@@ -21,7 +23,7 @@ func NewDatosFiscalesController(datosFiscalesService service.DatosFiscalesServic
 
 // @Tags         Datos Fiscales
 // @Summary      Register fiscal data
-// @Description  Registra los datos fiscales del usuario autenticado
+// @Description  Registra un RFC con su e.firma para el usuario autenticado
 // @Security     BearerAuth
 // @Accept       multipart/form-data
 // @Produce      json
@@ -30,10 +32,10 @@ func NewDatosFiscalesController(datosFiscalesService service.DatosFiscalesServic
 // @Param        cer_file formData file true "Certificate file (.cer)"
 // @Param        key_file formData file true "Key file (.key)"
 // @Router       /datos-fiscales [post]
-// @Success      201  {object}  response.Common
+// @Success      201  {object}  response.SuccessWithData
 // @Failure      400  {object}  response.Common  "Bad request"
 // @Failure      401  {object}  response.Common  "Unauthorized"
-// @Failure      409  {object}  response.Common  "Fiscal data already exists"
+// @Failure      409  {object}  response.Common  "Fiscal data already exists for this RFC"
 // @Failure      500  {object}  response.Common  "Internal server error"
 func (c *DatosFiscalesController) CreateDatosFiscales(ctx *fiber.Ctx) error {
 	user, _ := ctx.Locals("user").(*model.User)
@@ -59,30 +61,76 @@ func (c *DatosFiscalesController) CreateDatosFiscales(ctx *fiber.Ctx) error {
 	}
 
 	// Llamar al servicio
-	if err := c.DatosFiscalesService.CreateDatosFiscales(ctx, user.ID, req, cerFile, keyFile); err != nil {
+	datosFiscales, err := c.DatosFiscalesService.CreateDatosFiscales(ctx, user.ID, req, cerFile, keyFile)
+	if err != nil {
 		return err
 	}
 
-	return ctx.Status(fiber.StatusCreated).JSON(response.Common{
+	return ctx.Status(fiber.StatusCreated).JSON(response.SuccessWithData{
 		Code:    fiber.StatusCreated,
 		Status:  "success",
 		Message: "Fiscal data registered successfully",
+		Data:    datosFiscales,
 	})
 }
 
 // @Tags         Datos Fiscales
-// @Summary      Get user fiscal data
-// @Description  Get fiscal data for the authenticated user (without sensitive data)
+// @Summary      List user fiscal data
+// @Description  List the RFCs registered by the authenticated user (without sensitive data)
 // @Security     BearerAuth
 // @Produce      json
+// @Param        page     query     int     false   "Page number"  default(1)
+// @Param        limit    query     int     false   "Maximum number of RFCs"    default(10)
+// @Param        search   query     string  false  "Search by RFC"
 // @Router       /datos-fiscales [get]
-// @Success      200  {object}  response.Common
+// @Success      200  {object}  response.SuccessWithPaginate[model.DatosFiscalesSAT]
 // @Failure      401  {object}  response.Common  "Unauthorized"
-// @Failure      404  {object}  response.Common  "Not found"
 func (c *DatosFiscalesController) GetDatosFiscales(ctx *fiber.Ctx) error {
 	user, _ := ctx.Locals("user").(*model.User)
 
-	datosFiscales, err := c.DatosFiscalesService.GetDatosFiscalesByUserID(ctx, user.ID)
+	query := &validation.QueryDatosFiscales{
+		Page:   ctx.QueryInt("page", 1),
+		Limit:  ctx.QueryInt("limit", 10),
+		Search: ctx.Query("search", ""),
+	}
+
+	datosFiscales, totalResults, err := c.DatosFiscalesService.GetDatosFiscales(ctx, user.ID, query)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(response.SuccessWithPaginate[model.DatosFiscalesSAT]{
+		Code:         fiber.StatusOK,
+		Status:       "success",
+		Message:      "Fiscal data retrieved successfully",
+		Results:      datosFiscales,
+		Page:         query.Page,
+		Limit:        query.Limit,
+		TotalPages:   int64(math.Ceil(float64(totalResults) / float64(query.Limit))),
+		TotalResults: totalResults,
+	})
+}
+
+// @Tags         Datos Fiscales
+// @Summary      Get fiscal data
+// @Description  Get one RFC of the authenticated user (without sensitive data)
+// @Security     BearerAuth
+// @Produce      json
+// @Param        rfcId  path  string  true  "Fiscal data id"
+// @Router       /datos-fiscales/{rfcId} [get]
+// @Success      200  {object}  response.SuccessWithData
+// @Failure      400  {object}  response.Common  "Bad request"
+// @Failure      401  {object}  response.Common  "Unauthorized"
+// @Failure      404  {object}  response.Common  "Not found"
+func (c *DatosFiscalesController) GetDatosFiscalesByID(ctx *fiber.Ctx) error {
+	user, _ := ctx.Locals("user").(*model.User)
+
+	rfcID, err := datosFiscalesID(ctx)
+	if err != nil {
+		return err
+	}
+
+	datosFiscales, err := c.DatosFiscalesService.GetDatosFiscalesByID(ctx, user.ID, rfcID)
 	if err != nil {
 		return err
 	}
@@ -97,12 +145,13 @@ func (c *DatosFiscalesController) GetDatosFiscales(ctx *fiber.Ctx) error {
 
 // @Tags         Datos Fiscales
 // @Summary      Update fiscal data
-// @Description  Update RFC or password for fiscal data
+// @Description  Update the password of the e.firma, checked against the stored key. The RFC is optional and must match the registered one.
 // @Security     BearerAuth
 // @Accept       json
 // @Produce      json
-// @Param        request  body  validation.DatosFiscalesRequest  true  "Request body"
-// @Router       /datos-fiscales [patch]
+// @Param        rfcId  path  string  true  "Fiscal data id"
+// @Param        request  body  validation.UpdateDatosFiscalesRequest  true  "Request body"
+// @Router       /datos-fiscales/{rfcId} [patch]
 // @Success      200  {object}  response.Common
 // @Failure      400  {object}  response.Common  "Bad request"
 // @Failure      401  {object}  response.Common  "Unauthorized"
@@ -110,12 +159,17 @@ func (c *DatosFiscalesController) GetDatosFiscales(ctx *fiber.Ctx) error {
 func (c *DatosFiscalesController) UpdateDatosFiscales(ctx *fiber.Ctx) error {
 	user, _ := ctx.Locals("user").(*model.User)
 
-	req := new(validation.DatosFiscalesRequest)
+	rfcID, err := datosFiscalesID(ctx)
+	if err != nil {
+		return err
+	}
+
+	req := new(validation.UpdateDatosFiscalesRequest)
 	if err := ctx.BodyParser(req); err != nil {
 		return fiber.NewError(fiber.StatusBadRequest, "Invalid request body")
 	}
 
-	if err := c.DatosFiscalesService.UpdateDatosFiscales(ctx, user.ID, req); err != nil {
+	if err := c.DatosFiscalesService.UpdateDatosFiscales(ctx, user.ID, rfcID, req); err != nil {
 		return err
 	}
 
@@ -128,17 +182,23 @@ func (c *DatosFiscalesController) UpdateDatosFiscales(ctx *fiber.Ctx) error {
 
 // @Tags         Datos Fiscales
 // @Summary      Delete fiscal data
-// @Description  Delete all fiscal data for the authenticated user
+// @Description  Delete one RFC of the authenticated user
 // @Security     BearerAuth
 // @Produce      json
-// @Router       /datos-fiscales [delete]
+// @Param        rfcId  path  string  true  "Fiscal data id"
+// @Router       /datos-fiscales/{rfcId} [delete]
 // @Success      200  {object}  response.Common
 // @Failure      401  {object}  response.Common  "Unauthorized"
 // @Failure      404  {object}  response.Common  "Not found"
 func (c *DatosFiscalesController) DeleteDatosFiscales(ctx *fiber.Ctx) error {
 	user, _ := ctx.Locals("user").(*model.User)
 
-	if err := c.DatosFiscalesService.DeleteDatosFiscales(ctx, user.ID); err != nil {
+	rfcID, err := datosFiscalesID(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := c.DatosFiscalesService.DeleteDatosFiscales(ctx, user.ID, rfcID); err != nil {
 		return err
 	}
 
@@ -148,3 +208,94 @@ func (c *DatosFiscalesController) DeleteDatosFiscales(ctx *fiber.Ctx) error {
 		Message: "Fiscal data deleted successfully",
 	})
 }
+
+// @Tags         Datos Fiscales
+// @Summary      Renew e.firma
+// @Description  Replace the certificate and key of an RFC keeping the fiscal data record
+// @Security     BearerAuth
+// @Accept       multipart/form-data
+// @Produce      json
+// @Param        rfcId  path  string  true  "Fiscal data id"
+// @Param        password formData string true "E-firma password"
+// @Param        cer_file formData file true "Certificate file (.cer)"
+// @Param        key_file formData file true "Key file (.key)"
+// @Router       /datos-fiscales/{rfcId}/efirma [put]
+// @Success      200  {object}  response.SuccessWithData
+// @Failure      400  {object}  response.Common  "Bad request"
+// @Failure      401  {object}  response.Common  "Unauthorized"
+// @Failure      404  {object}  response.Common  "Not found"
+// @Failure      409  {object}  response.Common  "Certificate already registered"
+func (c *DatosFiscalesController) RenovarEfirma(ctx *fiber.Ctx) error {
+	user, _ := ctx.Locals("user").(*model.User)
+
+	rfcID, err := datosFiscalesID(ctx)
+	if err != nil {
+		return err
+	}
+
+	req := new(validation.RenovarEfirmaRequest)
+	req.Password = ctx.FormValue("password")
+
+	cerFile, err := ctx.FormFile("cer_file")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Certificate file is required")
+	}
+
+	keyFile, err := ctx.FormFile("key_file")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Key file is required")
+	}
+
+	datosFiscales, err := c.DatosFiscalesService.RenovarEfirma(ctx, user.ID, rfcID, req, cerFile, keyFile)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(response.SuccessWithData{
+		Code:    fiber.StatusOK,
+		Status:  "success",
+		Message: "E.firma renewed successfully",
+		Data:    datosFiscales,
+	})
+}
+
+// @Tags         Datos Fiscales
+// @Summary      Get certificate history
+// @Description  List the certificates previously registered for an RFC of the authenticated user
+// @Security     BearerAuth
+// @Produce      json
+// @Param        rfcId  path  string  true  "Fiscal data id"
+// @Router       /datos-fiscales/{rfcId}/historial [get]
+// @Success      200  {object}  response.SuccessWithData
+// @Failure      401  {object}  response.Common  "Unauthorized"
+// @Failure      404  {object}  response.Common  "Not found"
+func (c *DatosFiscalesController) GetHistorialCertificados(ctx *fiber.Ctx) error {
+	user, _ := ctx.Locals("user").(*model.User)
+
+	rfcID, err := datosFiscalesID(ctx)
+	if err != nil {
+		return err
+	}
+
+	historial, err := c.DatosFiscalesService.GetHistorialCertificados(ctx, user.ID, rfcID)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(response.SuccessWithData{
+		Code:    fiber.StatusOK,
+		Status:  "success",
+		Message: "Certificate history retrieved successfully",
+		Data:    historial,
+	})
+}
+
+func datosFiscalesID(ctx *fiber.Ctx) (string, error) {
+	rfcID := ctx.Params("rfcId")
+
+	if _, err := uuid.Parse(rfcID); err != nil {
+		return "", fiber.NewError(fiber.StatusBadRequest, "Invalid fiscal data ID")
+	}
+
+	return rfcID, nil
+}
@@ -0,0 +1,91 @@
+package controller
+
+import (
+	"app/src/model"
+	"app/src/response"
+	"app/src/service"
+	"app/src/validation"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type DescargaController struct {
+	DescargaService service.DescargaService
+}
+
+func NewDescargaController(descargaService service.DescargaService) *DescargaController {
+	return &DescargaController{
+		DescargaService: descargaService,
+	}
+}
+
+// @Tags         Descargas
+// @Summary      Request a bulk download
+// @Description  Sends a SolicitaDescarga request to the SAT for one of the RFCs of the authenticated user.
+// @Description  Each accepted request uses one download of the monthly quota, reported in the X-Quota-Limit, X-Quota-Remaining and X-Quota-Reset headers.
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        request  body  validation.SolicitarDescarga  true  "Request body"
+// @Router       /descargas [post]
+// @Success      201  {object}  response.SuccessWithData
+// @Failure      400  {object}  response.Common  "Bad request"
+// @Failure      401  {object}  response.Common  "Unauthorized"
+// @Failure      404  {object}  response.Common  "Fiscal data not found or SAT found no CFDIs"
+// @Failure      402  {object}  response.Common  "No active subscription"
+// @Failure      409  {object}  response.Common  "Duplicated request"
+// @Failure      429  {object}  response.Common  "Monthly quota exhausted or SAT request limit reached"
+// @Failure      502  {object}  response.Common  "SAT error"
+func (c *DescargaController) SolicitarDescarga(ctx *fiber.Ctx) error {
+	user, _ := ctx.Locals("user").(*model.User)
+
+	req := new(validation.SolicitarDescarga)
+	if err := ctx.BodyParser(req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	solicitud, err := c.DescargaService.SolicitarDescarga(ctx, user.ID, req)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(response.SuccessWithData{
+		Code:    fiber.StatusCreated,
+		Status:  "success",
+		Message: "Download request accepted by the SAT",
+		Data:    solicitud,
+	})
+}
+
+// @Tags         Descargas
+// @Summary      List packages of a download request
+// @Description  Packages reported by the SAT for the request, with their download state, size and checksum
+// @Security     BearerAuth
+// @Produce      json
+// @Param        descargaId  path  string  true  "Download request id"
+// @Router       /descargas/{descargaId}/paquetes [get]
+// @Success      200  {object}  response.SuccessWithData
+// @Failure      400  {object}  response.Common  "Bad request"
+// @Failure      401  {object}  response.Common  "Unauthorized"
+// @Failure      404  {object}  response.Common  "Not found"
+func (c *DescargaController) GetPaquetes(ctx *fiber.Ctx) error {
+	user, _ := ctx.Locals("user").(*model.User)
+
+	descargaID := ctx.Params("descargaId")
+	if _, err := uuid.Parse(descargaID); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid download request ID")
+	}
+
+	paquetes, err := c.DescargaService.GetPaquetes(ctx, user.ID, descargaID)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(response.SuccessWithData{
+		Code:    fiber.StatusOK,
+		Status:  "success",
+		Message: "Packages retrieved successfully",
+		Data:    paquetes,
+	})
+}
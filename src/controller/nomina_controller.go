@@ -0,0 +1,141 @@
+package controller
+
+import (
+	"app/src/model"
+	"app/src/response"
+	"app/src/service"
+	"app/src/validation"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type NominaController struct {
+	NominaService service.NominaService
+}
+
+func NewNominaController(nominaService service.NominaService) *NominaController {
+	return &NominaController{
+		NominaService: nominaService,
+	}
+}
+
+func queryNomina(ctx *fiber.Ctx) *validation.QueryNomina {
+	return &validation.QueryNomina{
+		FechaInicial: ctx.Query("fecha_inicial", ""),
+		FechaFinal:   ctx.Query("fecha_final", ""),
+		RFC:          ctx.Query("rfc", ""),
+		Empleado:     ctx.Query("empleado", ""),
+		Format:       ctx.Query("format", ""),
+	}
+}
+
+// @Tags         Nomina
+// @Summary      Payroll cost per period
+// @Description  Adds up the payroll CFDIs emitted by the RFCs registered by the logged in user by pay period.
+// @Security     BearerAuth
+// @Produce      json,text/csv
+// @Param        fecha_inicial  query  string  false  "Paid on or after this date (YYYY-MM-DD)"
+// @Param        fecha_final    query  string  false  "Paid on or before this date (YYYY-MM-DD)"
+// @Param        rfc            query  string  false  "RFC of the employer"
+// @Param        empleado       query  string  false  "RFC of the employee"
+// @Param        format         query  string  false  "Response format"  Enums(json, csv)
+// @Router       /nomina/periodos [get]
+// @Success      200  {object}  response.SuccessWithData{data=[]model.ResumenNominaPeriodo}
+// @Failure      400  {object}  response.Common  "Bad request"
+// @Failure      401  {object}  response.Common  "Unauthorized"
+func (n *NominaController) GetResumenPeriodos(ctx *fiber.Ctx) error {
+	user, _ := ctx.Locals("user").(*model.User)
+
+	query := queryNomina(ctx)
+
+	resumen, err := n.NominaService.GetResumenPeriodos(ctx, user.ID, query)
+	if err != nil {
+		return err
+	}
+
+	if query.Format == "csv" {
+		header, records := response.NominaPeriodosCSV(resumen)
+		return response.CSV(ctx, "nomina-periodos.csv", header, records)
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(response.SuccessWithData{
+		Code:    fiber.StatusOK,
+		Status:  "success",
+		Message: "Payroll summarized successfully",
+		Data:    resumen,
+	})
+}
+
+// @Tags         Nomina
+// @Summary      Payroll cost per employee
+// @Description  Adds up the payroll CFDIs emitted by the RFCs registered by the logged in user by employee.
+// @Security     BearerAuth
+// @Produce      json,text/csv
+// @Param        fecha_inicial  query  string  false  "Paid on or after this date (YYYY-MM-DD)"
+// @Param        fecha_final    query  string  false  "Paid on or before this date (YYYY-MM-DD)"
+// @Param        rfc            query  string  false  "RFC of the employer"
+// @Param        empleado       query  string  false  "RFC of the employee"
+// @Param        format         query  string  false  "Response format"  Enums(json, csv)
+// @Router       /nomina/empleados [get]
+// @Success      200  {object}  response.SuccessWithData{data=[]model.ResumenNominaEmpleado}
+// @Failure      400  {object}  response.Common  "Bad request"
+// @Failure      401  {object}  response.Common  "Unauthorized"
+func (n *NominaController) GetResumenEmpleados(ctx *fiber.Ctx) error {
+	user, _ := ctx.Locals("user").(*model.User)
+
+	query := queryNomina(ctx)
+
+	resumen, err := n.NominaService.GetResumenEmpleados(ctx, user.ID, query)
+	if err != nil {
+		return err
+	}
+
+	if query.Format == "csv" {
+		header, records := response.NominaEmpleadosCSV(resumen)
+		return response.CSV(ctx, "nomina-empleados.csv", header, records)
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(response.SuccessWithData{
+		Code:    fiber.StatusOK,
+		Status:  "success",
+		Message: "Payroll summarized successfully",
+		Data:    resumen,
+	})
+}
+
+// @Tags         Nomina
+// @Summary      Payroll cost per concept
+// @Description  Adds up the percepciones, deducciones and otros pagos of the payroll CFDIs emitted by the RFCs registered by the logged in user.
+// @Security     BearerAuth
+// @Produce      json,text/csv
+// @Param        fecha_inicial  query  string  false  "Paid on or after this date (YYYY-MM-DD)"
+// @Param        fecha_final    query  string  false  "Paid on or before this date (YYYY-MM-DD)"
+// @Param        rfc            query  string  false  "RFC of the employer"
+// @Param        empleado       query  string  false  "RFC of the employee"
+// @Param        format         query  string  false  "Response format"  Enums(json, csv)
+// @Router       /nomina/conceptos [get]
+// @Success      200  {object}  response.SuccessWithData{data=[]model.ResumenNominaConcepto}
+// @Failure      400  {object}  response.Common  "Bad request"
+// @Failure      401  {object}  response.Common  "Unauthorized"
+func (n *NominaController) GetResumenConceptos(ctx *fiber.Ctx) error {
+	user, _ := ctx.Locals("user").(*model.User)
+
+	query := queryNomina(ctx)
+
+	resumen, err := n.NominaService.GetResumenConceptos(ctx, user.ID, query)
+	if err != nil {
+		return err
+	}
+
+	if query.Format == "csv" {
+		header, records := response.NominaConceptosCSV(resumen)
+		return response.CSV(ctx, "nomina-conceptos.csv", header, records)
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(response.SuccessWithData{
+		Code:    fiber.StatusOK,
+		Status:  "success",
+		Message: "Payroll summarized successfully",
+		Data:    resumen,
+	})
+}
@@ -0,0 +1,109 @@
+package controller
+
+import (
+	"app/src/model"
+	"app/src/response"
+	"app/src/service"
+	"app/src/validation"
+	"math"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type PagoController struct {
+	PagoService service.PagoService
+}
+
+func NewPagoController(pagoService service.PagoService) *PagoController {
+	return &PagoController{
+		PagoService: pagoService,
+	}
+}
+
+// @Tags         CFDIs
+// @Summary      Get the payments of an invoice
+// @Description  Returns the payments applied to a CFDI of the RFCs registered by the logged in user through the vigente Recepción de Pagos complements of its emisor, with the paid and outstanding amounts.
+// @Security     BearerAuth
+// @Produce      json
+// @Param        uuid  path  string  true  "Folio fiscal of the invoice"
+// @Router       /cfdis/{uuid}/pagos [get]
+// @Success      200  {object}  response.SuccessWithData{data=response.SaldoCFDI}
+// @Failure      400  {object}  response.Common  "Bad request"
+// @Failure      401  {object}  response.Common  "Unauthorized"
+// @Failure      404  {object}  response.Common  "Not found"
+func (p *PagoController) GetPagos(ctx *fiber.Ctx) error {
+	user, _ := ctx.Locals("user").(*model.User)
+
+	id, err := uuid.Parse(ctx.Params("uuid"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid CFDI UUID")
+	}
+
+	registro, pagos, err := p.PagoService.GetPagos(ctx, user.ID, id)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(response.SuccessWithData{
+		Code:    fiber.StatusOK,
+		Status:  "success",
+		Message: "Payments retrieved successfully",
+		Data:    response.NewSaldoCFDI(registro, pagos),
+	})
+}
+
+// @Tags         CFDIs
+// @Summary      Aging report of unpaid PPD invoices
+// @Description  Lists the vigente PPD invoices of the RFCs registered by the logged in user with an outstanding balance at the cut-off date, grouped in 30 day buckets by age, a page at a time, with the totals per currency of all of them.
+// @Security     BearerAuth
+// @Produce      json
+// @Param        page         query  int     false  "Page number"  default(1)
+// @Param        limit        query  int     false  "Maximum number of invoices"  default(10)
+// @Param        fecha_corte  query  string  false  "Cut-off date (YYYY-MM-DD), today by default"
+// @Param        rol          query  string  false  "Only invoices where the user RFC is the emisor (receivables) or the receptor (payables)"  Enums(emisor, receptor)
+// @Param        rfc          query  string  false  "RFC of the counterpart, emisor or receptor"
+// @Router       /cfdis/antiguedad-saldos [get]
+// @Success      200  {object}  response.SuccessWithData{data=response.AntiguedadSaldos}
+// @Failure      400  {object}  response.Common  "Bad request"
+// @Failure      401  {object}  response.Common  "Unauthorized"
+func (p *PagoController) GetAntiguedadSaldos(ctx *fiber.Ctx) error {
+	user, _ := ctx.Locals("user").(*model.User)
+
+	query := &validation.QueryAntiguedadSaldos{
+		Page:       ctx.QueryInt("page", 1),
+		Limit:      ctx.QueryInt("limit", 10),
+		FechaCorte: ctx.Query("fecha_corte", time.Now().Format(time.DateOnly)),
+		RFC:        ctx.Query("rfc", ""),
+		Rol:        ctx.Query("rol", ""),
+	}
+
+	saldos, totales, totalResults, err := p.PagoService.GetAntiguedadSaldos(ctx, user.ID, query)
+	if err != nil {
+		return err
+	}
+
+	if saldos == nil {
+		saldos = []model.SaldoPendiente{}
+	}
+
+	if totales == nil {
+		totales = []model.TotalAntiguedad{}
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(response.SuccessWithData{
+		Code:    fiber.StatusOK,
+		Status:  "success",
+		Message: "Outstanding balances retrieved successfully",
+		Data: response.AntiguedadSaldos{
+			FechaCorte:   query.FechaCorte,
+			Totales:      totales,
+			Documentos:   saldos,
+			Page:         query.Page,
+			Limit:        query.Limit,
+			TotalPages:   int64(math.Ceil(float64(totalResults) / float64(query.Limit))),
+			TotalResults: totalResults,
+		},
+	})
+}
@@ -0,0 +1,253 @@
+package controller
+
+import (
+	"app/src/model"
+	"app/src/response"
+	"app/src/service"
+	"app/src/validation"
+	"math"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type PlanController struct {
+	PlanService service.PlanService
+}
+
+func NewPlanController(planService service.PlanService) *PlanController {
+	return &PlanController{
+		PlanService: planService,
+	}
+}
+
+// @Tags         Planes
+// @Summary      List subscription plans
+// @Description  Only admins can manage subscription plans.
+// @Security     BearerAuth
+// @Produce      json
+// @Param        page    query  int     false  "Page number"  default(1)
+// @Param        limit   query  int     false  "Maximum number of plans"  default(10)
+// @Param        search  query  string  false  "Search by name"
+// @Param        activo  query  bool    false  "Filter by active flag"
+// @Router       /planes [get]
+// @Success      200  {object}  response.SuccessWithPaginate[model.PlanSuscripcion]
+// @Failure      401  {object}  response.Common  "Unauthorized"
+// @Failure      403  {object}  response.Common  "Forbidden"
+func (c *PlanController) GetPlanes(ctx *fiber.Ctx) error {
+	query := &validation.QueryPlan{
+		Page:   ctx.QueryInt("page", 1),
+		Limit:  ctx.QueryInt("limit", 10),
+		Search: ctx.Query("search", ""),
+		Activo: ctx.Query("activo", ""),
+	}
+
+	planes, totalResults, err := c.PlanService.GetPlanes(ctx, query)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).
+		JSON(response.SuccessWithPaginate[model.PlanSuscripcion]{
+			Code:         fiber.StatusOK,
+			Status:       "success",
+			Message:      "Subscription plans retrieved successfully",
+			Results:      planes,
+			Page:         query.Page,
+			Limit:        query.Limit,
+			TotalPages:   int64(math.Ceil(float64(totalResults) / float64(query.Limit))),
+			TotalResults: totalResults,
+		})
+}
+
+// @Tags         Planes
+// @Summary      Get a subscription plan
+// @Description  Only admins can manage subscription plans.
+// @Security     BearerAuth
+// @Produce      json
+// @Param        planId  path  string  true  "Plan id"
+// @Router       /planes/{planId} [get]
+// @Success      200  {object}  response.SuccessWithData
+// @Failure      400  {object}  response.Common  "Bad request"
+// @Failure      401  {object}  response.Common  "Unauthorized"
+// @Failure      403  {object}  response.Common  "Forbidden"
+// @Failure      404  {object}  response.Common  "Not found"
+func (c *PlanController) GetPlanByID(ctx *fiber.Ctx) error {
+	id, err := planID(ctx)
+	if err != nil {
+		return err
+	}
+
+	plan, err := c.PlanService.GetPlanByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(response.SuccessWithData{
+		Code:    fiber.StatusOK,
+		Status:  "success",
+		Message: "Subscription plan retrieved successfully",
+		Data:    plan,
+	})
+}
+
+// @Tags         Planes
+// @Summary      Create a subscription plan
+// @Description  Only admins can manage subscription plans.
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        request  body  validation.CreatePlan  true  "Request body"
+// @Router       /planes [post]
+// @Success      201  {object}  response.SuccessWithData
+// @Failure      400  {object}  response.Common  "Bad request"
+// @Failure      401  {object}  response.Common  "Unauthorized"
+// @Failure      403  {object}  response.Common  "Forbidden"
+// @Failure      409  {object}  response.Common  "Plan name already in use"
+func (c *PlanController) CreatePlan(ctx *fiber.Ctx) error {
+	req := new(validation.CreatePlan)
+	if err := ctx.BodyParser(req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	plan, err := c.PlanService.CreatePlan(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(response.SuccessWithData{
+		Code:    fiber.StatusCreated,
+		Status:  "success",
+		Message: "Subscription plan created successfully",
+		Data:    plan,
+	})
+}
+
+// @Tags         Planes
+// @Summary      Update a subscription plan
+// @Description  Only admins can manage subscription plans. Existing subscriptions keep the limit they were sold with.
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        planId   path  string                 true  "Plan id"
+// @Param        request  body  validation.UpdatePlan  true  "Request body"
+// @Router       /planes/{planId} [patch]
+// @Success      200  {object}  response.SuccessWithData
+// @Failure      400  {object}  response.Common  "Bad request"
+// @Failure      401  {object}  response.Common  "Unauthorized"
+// @Failure      403  {object}  response.Common  "Forbidden"
+// @Failure      404  {object}  response.Common  "Not found"
+// @Failure      409  {object}  response.Common  "Plan name already in use"
+func (c *PlanController) UpdatePlan(ctx *fiber.Ctx) error {
+	id, err := planID(ctx)
+	if err != nil {
+		return err
+	}
+
+	req := new(validation.UpdatePlan)
+	if err := ctx.BodyParser(req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	plan, err := c.PlanService.UpdatePlan(ctx, id, req)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(response.SuccessWithData{
+		Code:    fiber.StatusOK,
+		Status:  "success",
+		Message: "Subscription plan updated successfully",
+		Data:    plan,
+	})
+}
+
+// @Tags         Planes
+// @Summary      Activate a subscription plan
+// @Description  Only admins can manage subscription plans. Active plans can be subscribed to.
+// @Security     BearerAuth
+// @Produce      json
+// @Param        planId  path  string  true  "Plan id"
+// @Router       /planes/{planId}/activar [post]
+// @Success      200  {object}  response.SuccessWithData
+// @Failure      400  {object}  response.Common  "Bad request"
+// @Failure      401  {object}  response.Common  "Unauthorized"
+// @Failure      403  {object}  response.Common  "Forbidden"
+// @Failure      404  {object}  response.Common  "Not found"
+func (c *PlanController) ActivarPlan(ctx *fiber.Ctx) error {
+	return c.setPlanActivo(ctx, true, "Subscription plan activated successfully")
+}
+
+// @Tags         Planes
+// @Summary      Deactivate a subscription plan
+// @Description  Only admins can manage subscription plans. Existing subscriptions are not affected.
+// @Security     BearerAuth
+// @Produce      json
+// @Param        planId  path  string  true  "Plan id"
+// @Router       /planes/{planId}/desactivar [post]
+// @Success      200  {object}  response.SuccessWithData
+// @Failure      400  {object}  response.Common  "Bad request"
+// @Failure      401  {object}  response.Common  "Unauthorized"
+// @Failure      403  {object}  response.Common  "Forbidden"
+// @Failure      404  {object}  response.Common  "Not found"
+func (c *PlanController) DesactivarPlan(ctx *fiber.Ctx) error {
+	return c.setPlanActivo(ctx, false, "Subscription plan deactivated successfully")
+}
+
+// @Tags         Planes
+// @Summary      Delete a subscription plan
+// @Description  Only admins can manage subscription plans. Plans with active subscriptions can't be deleted.
+// @Security     BearerAuth
+// @Produce      json
+// @Param        planId  path  string  true  "Plan id"
+// @Router       /planes/{planId} [delete]
+// @Success      200  {object}  response.Common
+// @Failure      400  {object}  response.Common  "Bad request"
+// @Failure      401  {object}  response.Common  "Unauthorized"
+// @Failure      403  {object}  response.Common  "Forbidden"
+// @Failure      404  {object}  response.Common  "Not found"
+// @Failure      409  {object}  response.Common  "Plan has active subscriptions"
+func (c *PlanController) DeletePlan(ctx *fiber.Ctx) error {
+	id, err := planID(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := c.PlanService.DeletePlan(ctx, id); err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(response.Common{
+		Code:    fiber.StatusOK,
+		Status:  "success",
+		Message: "Subscription plan deleted successfully",
+	})
+}
+
+func (c *PlanController) setPlanActivo(ctx *fiber.Ctx, activo bool, message string) error {
+	id, err := planID(ctx)
+	if err != nil {
+		return err
+	}
+
+	plan, err := c.PlanService.SetPlanActivo(ctx, id, activo)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(response.SuccessWithData{
+		Code:    fiber.StatusOK,
+		Status:  "success",
+		Message: message,
+		Data:    plan,
+	})
+}
+
+func planID(ctx *fiber.Ctx) (string, error) {
+	planID := ctx.Params("planId")
+	if _, err := uuid.Parse(planID); err != nil {
+		return "", fiber.NewError(fiber.StatusBadRequest, "Invalid plan ID")
+	}
+
+	return planID, nil
+}
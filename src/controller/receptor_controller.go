@@ -0,0 +1,93 @@
+package controller
+
+import (
+	"app/src/model"
+	"app/src/response"
+	"app/src/service"
+	"app/src/validation"
+	"math"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type ReceptorController struct {
+	ReceptorService service.ReceptorService
+}
+
+func NewReceptorController(receptorService service.ReceptorService) *ReceptorController {
+	return &ReceptorController{
+		ReceptorService: receptorService,
+	}
+}
+
+// @Tags         CFDIs
+// @Summary      Validate the receptor of indexed CFDIs
+// @Description  Checks the Receptor of the CFDI 4.0 of the RFCs registered by the logged in user against the SAT rules and the catalogs in effect when each one was issued: UsoCFDI allowed for the RegimenFiscalReceptor, régimen and UsoCFDI of personas físicas or morales, generic RFCs and Código Postal. Returns the incidencias of each CFDI, newest first.
+// @Security     BearerAuth
+// @Produce      json
+// @Param        page           query  int     false  "Page number"  default(1)
+// @Param        limit          query  int     false  "Maximum number of CFDIs"  default(10)
+// @Param        fecha_inicial  query  string  false  "Issued on or after this date (YYYY-MM-DD)"
+// @Param        fecha_final    query  string  false  "Issued on or before this date (YYYY-MM-DD)"
+// @Param        rfc            query  string  false  "RFC of the counterpart, emisor or receptor"
+// @Router       /cfdis/validacion-receptor [get]
+// @Success      200  {object}  response.SuccessWithPaginate[response.ValidacionReceptor]
+// @Failure      400  {object}  response.Common  "Bad request"
+// @Failure      401  {object}  response.Common  "Unauthorized"
+func (r *ReceptorController) GetValidaciones(ctx *fiber.Ctx) error {
+	user, _ := ctx.Locals("user").(*model.User)
+
+	query := &validation.QueryValidacionReceptor{
+		Page:         ctx.QueryInt("page", 1),
+		Limit:        ctx.QueryInt("limit", 10),
+		FechaInicial: ctx.Query("fecha_inicial", ""),
+		FechaFinal:   ctx.Query("fecha_final", ""),
+		RFC:          ctx.Query("rfc", ""),
+	}
+
+	validaciones, totalResults, err := r.ReceptorService.GetValidaciones(ctx, user.ID, query)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).
+		JSON(response.SuccessWithPaginate[response.ValidacionReceptor]{
+			Code:         fiber.StatusOK,
+			Status:       "success",
+			Message:      "CFDIs validated successfully",
+			Results:      validaciones,
+			Page:         query.Page,
+			Limit:        query.Limit,
+			TotalPages:   int64(math.Ceil(float64(totalResults) / float64(query.Limit))),
+			TotalResults: totalResults,
+		})
+}
+
+// @Tags         CFDIs
+// @Summary      Validate the receptor of a CFDI XML
+// @Description  Checks the Receptor of a CFDI 4.0 sent as the request body, stamped or not, with the same rules as the indexed CFDIs. Nothing is stored.
+// @Security     BearerAuth
+// @Accept       xml
+// @Produce      json
+// @Router       /cfdis/validacion-receptor [post]
+// @Success      200  {object}  response.SuccessWithData{data=response.ValidacionReceptor}
+// @Failure      400  {object}  response.Common  "Bad request"
+// @Failure      401  {object}  response.Common  "Unauthorized"
+// @Failure      422  {object}  response.Common  "Not a CFDI 4.0"
+func (r *ReceptorController) ValidarXML(ctx *fiber.Ctx) error {
+	if len(ctx.Body()) == 0 {
+		return fiber.NewError(fiber.StatusBadRequest, "CFDI XML is required")
+	}
+
+	validacion, err := r.ReceptorService.ValidarXML(ctx, ctx.Body())
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(response.SuccessWithData{
+		Code:    fiber.StatusOK,
+		Status:  "success",
+		Message: "CFDI validated successfully",
+		Data:    validacion,
+	})
+}
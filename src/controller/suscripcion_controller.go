@@ -0,0 +1,146 @@
+package controller
+
+import (
+	"app/src/model"
+	"app/src/response"
+	"app/src/service"
+	"app/src/validation"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type SuscripcionController struct {
+	SubscriptionService service.SubscriptionService
+}
+
+func NewSuscripcionController(subscriptionService service.SubscriptionService) *SuscripcionController {
+	return &SuscripcionController{
+		SubscriptionService: subscriptionService,
+	}
+}
+
+// @Tags         Suscripciones
+// @Summary      Get my subscription
+// @Description  Active subscription of the authenticated user with its plan and the downloads used in the current monthly period
+// @Security     BearerAuth
+// @Produce      json
+// @Router       /suscripciones/me [get]
+// @Success      200  {object}  response.SuccessWithData
+// @Failure      401  {object}  response.Common  "Unauthorized"
+// @Failure      404  {object}  response.Common  "No active subscription"
+func (c *SuscripcionController) GetMiSuscripcion(ctx *fiber.Ctx) error {
+	user, _ := ctx.Locals("user").(*model.User)
+
+	suscripcion, err := c.SubscriptionService.GetSuscripcionActiva(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+
+	response.QuotaHeaders(ctx, suscripcion)
+
+	return ctx.Status(fiber.StatusOK).JSON(response.SuccessWithData{
+		Code:    fiber.StatusOK,
+		Status:  "success",
+		Message: "Subscription retrieved successfully",
+		Data:    response.NewSuscripcion(suscripcion),
+	})
+}
+
+// @Tags         Suscripciones
+// @Summary      Subscribe to a plan
+// @Description  Subscribes the authenticated user to an active plan starting today, for 1 to 12 months
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        request  body  validation.Suscribir  true  "Request body"
+// @Router       /suscripciones [post]
+// @Success      201  {object}  response.SuccessWithData
+// @Failure      400  {object}  response.Common  "Bad request"
+// @Failure      401  {object}  response.Common  "Unauthorized"
+// @Failure      404  {object}  response.Common  "Plan not found"
+// @Failure      409  {object}  response.Common  "User already has an active subscription"
+// @Failure      422  {object}  response.Common  "Plan is not available"
+func (c *SuscripcionController) Suscribir(ctx *fiber.Ctx) error {
+	user, _ := ctx.Locals("user").(*model.User)
+
+	req := new(validation.Suscribir)
+	if err := ctx.BodyParser(req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	suscripcion, err := c.SubscriptionService.Suscribir(ctx, user.ID, req)
+	if err != nil {
+		return err
+	}
+
+	response.QuotaHeaders(ctx, suscripcion)
+
+	return ctx.Status(fiber.StatusCreated).JSON(response.SuccessWithData{
+		Code:    fiber.StatusCreated,
+		Status:  "success",
+		Message: "Subscription created successfully",
+		Data:    response.NewSuscripcion(suscripcion),
+	})
+}
+
+// @Tags         Suscripciones
+// @Summary      Cancel my subscription
+// @Description  Cancels the active subscription of the authenticated user right away
+// @Security     BearerAuth
+// @Produce      json
+// @Router       /suscripciones/me/cancelar [post]
+// @Success      200  {object}  response.SuccessWithData
+// @Failure      401  {object}  response.Common  "Unauthorized"
+// @Failure      404  {object}  response.Common  "No active subscription"
+func (c *SuscripcionController) CancelarSuscripcion(ctx *fiber.Ctx) error {
+	user, _ := ctx.Locals("user").(*model.User)
+
+	suscripcion, err := c.SubscriptionService.CancelarSuscripcion(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(response.SuccessWithData{
+		Code:    fiber.StatusOK,
+		Status:  "success",
+		Message: "Subscription cancelled successfully",
+		Data:    suscripcion,
+	})
+}
+
+// @Tags         Suscripciones
+// @Summary      Renew my subscription
+// @Description  Extends the active subscription of the authenticated user by 1 to 12 months
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        request  body  validation.RenovarSuscripcion  false  "Request body"
+// @Router       /suscripciones/me/renovar [post]
+// @Success      200  {object}  response.SuccessWithData
+// @Failure      400  {object}  response.Common  "Bad request"
+// @Failure      401  {object}  response.Common  "Unauthorized"
+// @Failure      404  {object}  response.Common  "No active subscription"
+func (c *SuscripcionController) RenovarSuscripcion(ctx *fiber.Ctx) error {
+	user, _ := ctx.Locals("user").(*model.User)
+
+	req := new(validation.RenovarSuscripcion)
+	if len(ctx.Body()) > 0 {
+		if err := ctx.BodyParser(req); err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, "Invalid request body")
+		}
+	}
+
+	suscripcion, err := c.SubscriptionService.RenovarSuscripcion(ctx, user.ID, req)
+	if err != nil {
+		return err
+	}
+
+	response.QuotaHeaders(ctx, suscripcion)
+
+	return ctx.Status(fiber.StatusOK).JSON(response.SuccessWithData{
+		Code:    fiber.StatusOK,
+		Status:  "success",
+		Message: "Subscription renewed successfully",
+		Data:    response.NewSuscripcion(suscripcion),
+	})
+}
@@ -20,7 +20,7 @@ func DefineExtensions(db *gorm.DB) {
 			return
 		}
 		// Execute the SQL commands in the extension file
-		if err := db.Exec(string(content)).Error; err != nil {
+		if err := execScript(db, string(content)); err != nil {
 			utils.Log.Errorf("Error executing extension file %s: %v", file, err)
 			return
 		}
@@ -7,8 +7,11 @@ import (
 	"app/src/utils"
 )
 
+// RunMigrations runs the up migrations in version order. They are written
+// to run again on a database that already has them, the down ones are left
+// to golang-migrate.
 func RunMigrations(db *gorm.DB) {
-	files, err := filepath.Glob("src/database/migrations/*.sql")
+	files, err := filepath.Glob("src/database/migrations/*.up.sql")
 	if err != nil {
 		utils.Log.Errorf("Error finding migration files: %v", err)
 		return
@@ -20,7 +23,7 @@ func RunMigrations(db *gorm.DB) {
 			return
 		}
 		// Execute the SQL commands in the migration file
-		if err := db.Exec(string(content)).Error; err != nil {
+		if err := execScript(db, string(content)); err != nil {
 			utils.Log.Errorf("Error executing migration file %s: %v", file, err)
 			return
 		}
@@ -1,4 +1,4 @@
-CREATE TABLE users(
+CREATE TABLE IF NOT EXISTS users(
     id              UUID            PRIMARY KEY DEFAULT uuid_generate_v4(),
     name            VARCHAR(255)    NOT NULL,
     email           VARCHAR(255)    NOT NULL UNIQUE,
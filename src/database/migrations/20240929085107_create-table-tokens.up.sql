@@ -1,4 +1,4 @@
-CREATE TABLE tokens(
+CREATE TABLE IF NOT EXISTS tokens(
     id              UUID            PRIMARY KEY DEFAULT uuid_generate_v4(),
     token           VARCHAR(255)    NOT NULL,
     user_id         UUID            NOT NULL,
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS datos_fiscales_sat;
@@ -1,4 +1,4 @@
-CREATE TABLE datos_fiscales_sat (
+CREATE TABLE IF NOT EXISTS datos_fiscales_sat (
     uuid UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
     user_id UUID NOT NULL,
     rfc VARCHAR(225) NOT NULL,
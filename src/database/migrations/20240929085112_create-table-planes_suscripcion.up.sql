@@ -1,8 +1,8 @@
-CREATE TABLE planes_suscripcion(
+CREATE TABLE IF NOT EXISTS planes_suscripcion(
     id  UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
     nombre VARCHAR NOT NULL,
     descripcion VARCHAR NOT NULL,
     limite_descargas_mensuales INT NOT NULL,
     precio DECIMAL(10, 2) NOT NULL,
     activo BOOLEAN NOT NULL DEFAULT FALSE
-);
\ No newline at end of file
+);
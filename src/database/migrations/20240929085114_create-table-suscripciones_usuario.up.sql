@@ -1,4 +1,4 @@
-CREATE TABLE suscripciones_usuario(
+CREATE TABLE IF NOT EXISTS suscripciones_usuario(
     id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
     usuario_id UUID,
     plan_id UUID,
@@ -8,4 +8,4 @@ CREATE TABLE suscripciones_usuario(
     estatus VARCHAR(20) NOT NULL CHECK (estatus IN ('activo', 'cancelado', 'pendiente')),
     CONSTRAINT fk_usuario_id FOREIGN KEY (usuario_id) REFERENCES users(id) ON DELETE CASCADE,
     CONSTRAINT fk_plan_id FOREIGN KEY (plan_id) REFERENCES planes_suscripcion(id) ON DELETE CASCADE
-);
\ No newline at end of file
+);
@@ -0,0 +1,5 @@
+ALTER TABLE datos_fiscales_sat
+    DROP COLUMN IF EXISTS no_certificado,
+    DROP COLUMN IF EXISTS emisor_certificado,
+    DROP COLUMN IF EXISTS valido_desde,
+    DROP COLUMN IF EXISTS valido_hasta;
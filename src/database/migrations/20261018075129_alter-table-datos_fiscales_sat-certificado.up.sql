@@ -0,0 +1,13 @@
+-- Rows stored before the certificate was parsed get an already expired
+-- window, so their e.firma has to be renewed before it is used again.
+ALTER TABLE datos_fiscales_sat
+    ADD COLUMN IF NOT EXISTS no_certificado VARCHAR(64) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS emisor_certificado VARCHAR(255) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS valido_desde TIMESTAMP NOT NULL DEFAULT '1970-01-01',
+    ADD COLUMN IF NOT EXISTS valido_hasta TIMESTAMP NOT NULL DEFAULT '1970-01-01';
+
+ALTER TABLE datos_fiscales_sat
+    ALTER COLUMN no_certificado DROP DEFAULT,
+    ALTER COLUMN emisor_certificado DROP DEFAULT,
+    ALTER COLUMN valido_desde DROP DEFAULT,
+    ALTER COLUMN valido_hasta DROP DEFAULT;
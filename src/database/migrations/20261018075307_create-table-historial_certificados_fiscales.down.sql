@@ -0,0 +1 @@
+DROP TABLE IF EXISTS historial_certificados_fiscales;
@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS historial_certificados_fiscales (
+    uuid UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    datos_fiscales_uuid UUID NOT NULL,
+    no_certificado VARCHAR(64) NOT NULL,
+    emisor_certificado VARCHAR(255) NOT NULL,
+    valido_desde TIMESTAMP NOT NULL,
+    valido_hasta TIMESTAMP NOT NULL,
+    reemplazado_at TIMESTAMP NOT NULL,
+    reemplazado_by UUID,
+    CONSTRAINT fk_datos_fiscales_uuid FOREIGN KEY (datos_fiscales_uuid) REFERENCES datos_fiscales_sat(uuid) ON DELETE CASCADE
+);
@@ -0,0 +1,3 @@
+ALTER TABLE datos_fiscales_sat
+    DROP COLUMN IF EXISTS data_key_encriptada,
+    DROP COLUMN IF EXISTS key_version;
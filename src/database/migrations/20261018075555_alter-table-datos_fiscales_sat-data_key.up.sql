@@ -0,0 +1,3 @@
+ALTER TABLE datos_fiscales_sat
+    ADD COLUMN IF NOT EXISTS data_key_encriptada TEXT,
+    ADD COLUMN IF NOT EXISTS key_version INT NOT NULL DEFAULT 1;
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS uq_datos_fiscales_sat_user_rfc;
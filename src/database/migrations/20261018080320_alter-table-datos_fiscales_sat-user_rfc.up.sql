@@ -0,0 +1 @@
+CREATE UNIQUE INDEX IF NOT EXISTS uq_datos_fiscales_sat_user_rfc ON datos_fiscales_sat (user_id, rfc) WHERE deleted_at IS NULL;
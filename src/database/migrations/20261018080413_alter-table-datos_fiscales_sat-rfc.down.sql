@@ -0,0 +1 @@
+ALTER TABLE datos_fiscales_sat ALTER COLUMN rfc TYPE VARCHAR(225);
@@ -0,0 +1,24 @@
+CREATE TABLE IF NOT EXISTS solicitudes_descarga (
+    uuid UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL,
+    datos_fiscales_uuid UUID NOT NULL,
+    rfc_solicitante VARCHAR(13) NOT NULL,
+    id_solicitud VARCHAR(64),
+    tipo_descarga VARCHAR(10) NOT NULL CHECK (tipo_descarga IN ('emitidos', 'recibidos')),
+    tipo_solicitud VARCHAR(10) NOT NULL CHECK (tipo_solicitud IN ('CFDI', 'Metadata')),
+    fecha_inicial TIMESTAMP NOT NULL,
+    fecha_final TIMESTAMP NOT NULL,
+    tipo_comprobante VARCHAR(1),
+    estado_comprobante VARCHAR(10),
+    rfc_contraparte VARCHAR(13),
+    cod_estatus VARCHAR(10) NOT NULL,
+    mensaje TEXT,
+    estado VARCHAR(20) NOT NULL CHECK (estado IN ('aceptada', 'en_proceso', 'terminada', 'error', 'rechazada', 'vencida')),
+    created_at TIMESTAMP,
+    updated_at TIMESTAMP,
+    created_by UUID,
+    CONSTRAINT fk_solicitud_user_id FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    CONSTRAINT fk_solicitud_datos_fiscales_uuid FOREIGN KEY (datos_fiscales_uuid) REFERENCES datos_fiscales_sat(uuid) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_solicitudes_descarga_estado ON solicitudes_descarga (estado);
@@ -0,0 +1,4 @@
+ALTER TABLE solicitudes_descarga
+    DROP COLUMN IF EXISTS codigo_estado_solicitud,
+    DROP COLUMN IF EXISTS numero_cfdis,
+    DROP COLUMN IF EXISTS verificada_at;
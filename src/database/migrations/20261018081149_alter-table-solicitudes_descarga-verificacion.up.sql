@@ -0,0 +1,4 @@
+ALTER TABLE solicitudes_descarga
+    ADD COLUMN IF NOT EXISTS codigo_estado_solicitud VARCHAR(10),
+    ADD COLUMN IF NOT EXISTS numero_cfdis INT NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS verificada_at TIMESTAMP;
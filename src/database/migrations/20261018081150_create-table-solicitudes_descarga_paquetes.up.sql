@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS solicitudes_descarga_paquetes (
+    uuid UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    solicitud_uuid UUID NOT NULL,
+    id_paquete VARCHAR(128) NOT NULL,
+    estado VARCHAR(20) NOT NULL CHECK (estado IN ('pendiente', 'descargado', 'error')),
+    created_at TIMESTAMP,
+    updated_at TIMESTAMP,
+    CONSTRAINT fk_paquete_solicitud_uuid FOREIGN KEY (solicitud_uuid) REFERENCES solicitudes_descarga(uuid) ON DELETE CASCADE,
+    CONSTRAINT uq_paquete_id_paquete UNIQUE (id_paquete)
+);
@@ -0,0 +1,10 @@
+DROP INDEX IF EXISTS idx_solicitudes_descarga_paquetes_estado;
+
+ALTER TABLE solicitudes_descarga_paquetes
+    DROP COLUMN IF EXISTS tamano_bytes,
+    DROP COLUMN IF EXISTS sha256,
+    DROP COLUMN IF EXISTS storage_key,
+    DROP COLUMN IF EXISTS numero_archivos,
+    DROP COLUMN IF EXISTS intentos,
+    DROP COLUMN IF EXISTS mensaje,
+    DROP COLUMN IF EXISTS descargado_at;
@@ -0,0 +1,10 @@
+ALTER TABLE solicitudes_descarga_paquetes
+    ADD COLUMN IF NOT EXISTS tamano_bytes BIGINT NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS sha256 VARCHAR(64),
+    ADD COLUMN IF NOT EXISTS storage_key TEXT,
+    ADD COLUMN IF NOT EXISTS numero_archivos INT NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS intentos INT NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS mensaje TEXT,
+    ADD COLUMN IF NOT EXISTS descargado_at TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS idx_solicitudes_descarga_paquetes_estado ON solicitudes_descarga_paquetes (estado);
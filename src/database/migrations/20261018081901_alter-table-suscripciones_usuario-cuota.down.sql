@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_suscripciones_usuario_usuario_estatus;
+
+ALTER TABLE suscripciones_usuario
+    DROP COLUMN IF EXISTS descargas_usadas,
+    DROP COLUMN IF EXISTS periodo_inicio;
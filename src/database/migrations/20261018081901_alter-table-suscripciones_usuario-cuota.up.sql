@@ -0,0 +1,5 @@
+ALTER TABLE suscripciones_usuario
+    ADD COLUMN IF NOT EXISTS descargas_usadas INT NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS periodo_inicio DATE NOT NULL DEFAULT CURRENT_DATE;
+
+CREATE INDEX IF NOT EXISTS idx_suscripciones_usuario_usuario_estatus ON suscripciones_usuario (usuario_id, estatus);
@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS uq_planes_suscripcion_nombre;
+
+ALTER TABLE planes_suscripcion
+    DROP CONSTRAINT IF EXISTS planes_suscripcion_limite_descargas_mensuales_check,
+    DROP CONSTRAINT IF EXISTS planes_suscripcion_precio_check,
+    DROP COLUMN IF EXISTS created_at,
+    DROP COLUMN IF EXISTS updated_at,
+    DROP COLUMN IF EXISTS deleted_at;
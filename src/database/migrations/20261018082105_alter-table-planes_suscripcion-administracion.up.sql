@@ -0,0 +1,12 @@
+ALTER TABLE planes_suscripcion
+    ADD COLUMN IF NOT EXISTS created_at TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS updated_at TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP,
+    DROP CONSTRAINT IF EXISTS planes_suscripcion_limite_descargas_mensuales_check,
+    DROP CONSTRAINT IF EXISTS planes_suscripcion_precio_check;
+
+ALTER TABLE planes_suscripcion
+    ADD CONSTRAINT planes_suscripcion_limite_descargas_mensuales_check CHECK (limite_descargas_mensuales > 0),
+    ADD CONSTRAINT planes_suscripcion_precio_check CHECK (precio >= 0);
+
+CREATE UNIQUE INDEX IF NOT EXISTS uq_planes_suscripcion_nombre ON planes_suscripcion (nombre) WHERE deleted_at IS NULL;
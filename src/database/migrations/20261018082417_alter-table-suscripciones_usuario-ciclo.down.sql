@@ -0,0 +1,11 @@
+DROP INDEX IF EXISTS uq_suscripciones_usuario_activa;
+
+ALTER TABLE suscripciones_usuario
+    DROP CONSTRAINT IF EXISTS suscripciones_usuario_estatus_check,
+    DROP COLUMN IF EXISTS cancelada_at,
+    DROP COLUMN IF EXISTS aviso_vencimiento_at,
+    DROP COLUMN IF EXISTS created_at,
+    DROP COLUMN IF EXISTS updated_at;
+
+ALTER TABLE suscripciones_usuario
+    ADD CONSTRAINT suscripciones_usuario_estatus_check CHECK (estatus IN ('activo', 'cancelado', 'pendiente'));
@@ -0,0 +1,12 @@
+ALTER TABLE suscripciones_usuario
+    ADD COLUMN IF NOT EXISTS cancelada_at TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS aviso_vencimiento_at TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS created_at TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS updated_at TIMESTAMP,
+    DROP CONSTRAINT IF EXISTS suscripciones_usuario_estatus_check;
+
+ALTER TABLE suscripciones_usuario
+    ADD CONSTRAINT suscripciones_usuario_estatus_check
+        CHECK (estatus IN ('activo', 'cancelado', 'pendiente', 'expirado'));
+
+CREATE UNIQUE INDEX IF NOT EXISTS uq_suscripciones_usuario_activa ON suscripciones_usuario (usuario_id) WHERE estatus = 'activo';
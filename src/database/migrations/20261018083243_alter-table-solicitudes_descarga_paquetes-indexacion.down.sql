@@ -0,0 +1,3 @@
+ALTER TABLE solicitudes_descarga_paquetes
+    DROP COLUMN IF EXISTS cfdis_indexados,
+    DROP COLUMN IF EXISTS indexado_at;
@@ -0,0 +1,3 @@
+ALTER TABLE solicitudes_descarga_paquetes
+    ADD COLUMN IF NOT EXISTS cfdis_indexados INT NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS indexado_at TIMESTAMP;
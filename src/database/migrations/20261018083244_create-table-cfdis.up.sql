@@ -0,0 +1,36 @@
+CREATE TABLE IF NOT EXISTS cfdis (
+    uuid UUID PRIMARY KEY,
+    paquete_uuid UUID,
+    version VARCHAR(3) NOT NULL,
+    serie VARCHAR(25),
+    folio VARCHAR(40),
+    fecha TIMESTAMP NOT NULL,
+    fecha_timbrado TIMESTAMP NOT NULL,
+    tipo_comprobante VARCHAR(1) NOT NULL CHECK (tipo_comprobante IN ('I', 'E', 'T', 'N', 'P')),
+    rfc_emisor VARCHAR(13) NOT NULL,
+    nombre_emisor VARCHAR(300),
+    regimen_fiscal_emisor VARCHAR(3),
+    rfc_receptor VARCHAR(13) NOT NULL,
+    nombre_receptor VARCHAR(300),
+    regimen_fiscal_receptor VARCHAR(3),
+    domicilio_fiscal_receptor VARCHAR(5),
+    uso_cfdi VARCHAR(4),
+    subtotal NUMERIC NOT NULL,
+    descuento NUMERIC NOT NULL DEFAULT 0,
+    total_impuestos_trasladados NUMERIC NOT NULL DEFAULT 0,
+    total_impuestos_retenidos NUMERIC NOT NULL DEFAULT 0,
+    total NUMERIC NOT NULL,
+    moneda VARCHAR(3) NOT NULL,
+    tipo_cambio NUMERIC,
+    metodo_pago VARCHAR(3),
+    forma_pago VARCHAR(2),
+    estado VARCHAR(20) NOT NULL DEFAULT 'vigente' CHECK (estado IN ('vigente', 'cancelado')),
+    storage_key TEXT NOT NULL,
+    created_at TIMESTAMP,
+    updated_at TIMESTAMP,
+    CONSTRAINT fk_cfdi_paquete_uuid FOREIGN KEY (paquete_uuid) REFERENCES solicitudes_descarga_paquetes(uuid) ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_cfdis_rfc_emisor_fecha ON cfdis (rfc_emisor, fecha);
+
+CREATE INDEX IF NOT EXISTS idx_cfdis_rfc_receptor_fecha ON cfdis (rfc_receptor, fecha);
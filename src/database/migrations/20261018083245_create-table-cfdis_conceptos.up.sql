@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS cfdis_conceptos (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    cfdi_uuid UUID NOT NULL,
+    numero INT NOT NULL,
+    clave_prod_serv VARCHAR(8),
+    no_identificacion VARCHAR(100),
+    cantidad NUMERIC NOT NULL,
+    clave_unidad VARCHAR(3),
+    unidad VARCHAR(20),
+    descripcion TEXT NOT NULL,
+    valor_unitario NUMERIC NOT NULL,
+    importe NUMERIC NOT NULL,
+    descuento NUMERIC NOT NULL DEFAULT 0,
+    objeto_imp VARCHAR(2),
+    CONSTRAINT fk_concepto_cfdi_uuid FOREIGN KEY (cfdi_uuid) REFERENCES cfdis(uuid) ON DELETE CASCADE,
+    CONSTRAINT uq_concepto_cfdi_numero UNIQUE (cfdi_uuid, numero)
+);
+
+CREATE INDEX IF NOT EXISTS idx_cfdis_conceptos_descripcion ON cfdis_conceptos USING gin (descripcion gin_trgm_ops);
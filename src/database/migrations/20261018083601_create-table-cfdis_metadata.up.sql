@@ -0,0 +1,24 @@
+CREATE TABLE IF NOT EXISTS cfdis_metadata (
+    uuid UUID PRIMARY KEY,
+    paquete_uuid UUID,
+    rfc_emisor VARCHAR(13) NOT NULL,
+    nombre_emisor VARCHAR(300),
+    rfc_receptor VARCHAR(13) NOT NULL,
+    nombre_receptor VARCHAR(300),
+    rfc_pac VARCHAR(13),
+    fecha_emision TIMESTAMP NOT NULL,
+    fecha_certificacion TIMESTAMP,
+    monto NUMERIC NOT NULL,
+    efecto_comprobante VARCHAR(1) NOT NULL,
+    estatus VARCHAR(20) NOT NULL CHECK (estatus IN ('vigente', 'cancelado')),
+    fecha_cancelacion TIMESTAMP,
+    created_at TIMESTAMP,
+    updated_at TIMESTAMP,
+    CONSTRAINT fk_cfdi_metadata_paquete_uuid FOREIGN KEY (paquete_uuid) REFERENCES solicitudes_descarga_paquetes(uuid) ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_cfdis_metadata_rfc_emisor_fecha ON cfdis_metadata (rfc_emisor, fecha_emision);
+
+CREATE INDEX IF NOT EXISTS idx_cfdis_metadata_rfc_receptor_fecha ON cfdis_metadata (rfc_receptor, fecha_emision);
+
+CREATE INDEX IF NOT EXISTS idx_cfdis_metadata_estatus ON cfdis_metadata (estatus);
@@ -0,0 +1,8 @@
+ALTER TABLE cfdis
+    DROP CONSTRAINT IF EXISTS cfdis_estado_check,
+    DROP COLUMN IF EXISTS estado_consultado_at;
+
+UPDATE cfdis SET estado = 'vigente' WHERE estado = 'en_cancelacion';
+
+ALTER TABLE cfdis
+    ADD CONSTRAINT cfdis_estado_check CHECK (estado IN ('vigente', 'cancelado'));
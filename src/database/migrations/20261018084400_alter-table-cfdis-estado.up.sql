@@ -0,0 +1,6 @@
+ALTER TABLE cfdis
+    ADD COLUMN IF NOT EXISTS estado_consultado_at TIMESTAMP,
+    DROP CONSTRAINT IF EXISTS cfdis_estado_check;
+
+ALTER TABLE cfdis
+    ADD CONSTRAINT cfdis_estado_check CHECK (estado IN ('vigente', 'en_cancelacion', 'cancelado'));
@@ -0,0 +1,25 @@
+CREATE TABLE IF NOT EXISTS cfdis_pagos (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    cfdi_uuid UUID NOT NULL,
+    numero_pago INT NOT NULL,
+    numero INT NOT NULL,
+    fecha_pago TIMESTAMP NOT NULL,
+    forma_pago VARCHAR(2),
+    moneda_pago VARCHAR(3) NOT NULL,
+    tipo_cambio_pago NUMERIC,
+    monto NUMERIC NOT NULL,
+    num_operacion VARCHAR(100),
+    id_documento UUID NOT NULL,
+    serie VARCHAR(25),
+    folio VARCHAR(40),
+    moneda_dr VARCHAR(3) NOT NULL,
+    equivalencia_dr NUMERIC,
+    num_parcialidad INT NOT NULL DEFAULT 0,
+    imp_saldo_ant NUMERIC NOT NULL DEFAULT 0,
+    imp_pagado NUMERIC NOT NULL DEFAULT 0,
+    imp_saldo_insoluto NUMERIC NOT NULL DEFAULT 0,
+    CONSTRAINT fk_pago_cfdi_uuid FOREIGN KEY (cfdi_uuid) REFERENCES cfdis(uuid) ON DELETE CASCADE,
+    CONSTRAINT uq_pago_cfdi_numero UNIQUE (cfdi_uuid, numero_pago, numero)
+);
+
+CREATE INDEX IF NOT EXISTS idx_cfdis_pagos_id_documento ON cfdis_pagos (id_documento);
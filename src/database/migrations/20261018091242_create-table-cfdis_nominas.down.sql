@@ -0,0 +1 @@
+DROP TABLE IF EXISTS cfdis_nominas;
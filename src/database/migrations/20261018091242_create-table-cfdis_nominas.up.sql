@@ -0,0 +1,38 @@
+CREATE TABLE IF NOT EXISTS cfdis_nominas (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    cfdi_uuid UUID NOT NULL,
+    numero INT NOT NULL,
+    tipo_nomina VARCHAR(1) NOT NULL,
+    fecha_pago DATE NOT NULL,
+    fecha_inicial_pago DATE NOT NULL,
+    fecha_final_pago DATE NOT NULL,
+    num_dias_pagados NUMERIC NOT NULL DEFAULT 0,
+    registro_patronal VARCHAR(20),
+    rfc_empleado VARCHAR(13) NOT NULL,
+    nombre_empleado VARCHAR(300),
+    curp VARCHAR(18),
+    num_seguridad_social VARCHAR(15),
+    num_empleado VARCHAR(15),
+    departamento VARCHAR(100),
+    puesto VARCHAR(100),
+    tipo_contrato VARCHAR(2),
+    tipo_regimen VARCHAR(2),
+    periodicidad_pago VARCHAR(2),
+    fecha_inicio_rel_laboral DATE,
+    salario_base_cot_apor NUMERIC NOT NULL DEFAULT 0,
+    salario_diario_integrado NUMERIC NOT NULL DEFAULT 0,
+    clave_ent_fed VARCHAR(3),
+    total_percepciones NUMERIC NOT NULL DEFAULT 0,
+    total_gravado NUMERIC NOT NULL DEFAULT 0,
+    total_exento NUMERIC NOT NULL DEFAULT 0,
+    total_deducciones NUMERIC NOT NULL DEFAULT 0,
+    total_otros_pagos NUMERIC NOT NULL DEFAULT 0,
+    isr_retenido NUMERIC NOT NULL DEFAULT 0,
+    subsidio_causado NUMERIC NOT NULL DEFAULT 0,
+    CONSTRAINT fk_nomina_cfdi_uuid FOREIGN KEY (cfdi_uuid) REFERENCES cfdis(uuid) ON DELETE CASCADE,
+    CONSTRAINT uq_nomina_cfdi_numero UNIQUE (cfdi_uuid, numero)
+);
+
+CREATE INDEX IF NOT EXISTS idx_cfdis_nominas_fecha_pago ON cfdis_nominas (fecha_pago);
+
+CREATE INDEX IF NOT EXISTS idx_cfdis_nominas_rfc_empleado ON cfdis_nominas (rfc_empleado);
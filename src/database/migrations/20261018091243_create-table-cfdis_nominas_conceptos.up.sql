@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS cfdis_nominas_conceptos (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    nomina_id UUID NOT NULL,
+    numero INT NOT NULL,
+    tipo VARCHAR(10) NOT NULL CHECK (tipo IN ('percepcion', 'deduccion', 'otro_pago')),
+    tipo_sat VARCHAR(3) NOT NULL,
+    clave VARCHAR(15),
+    concepto VARCHAR(100),
+    importe_gravado NUMERIC NOT NULL DEFAULT 0,
+    importe_exento NUMERIC NOT NULL DEFAULT 0,
+    importe NUMERIC NOT NULL DEFAULT 0,
+    CONSTRAINT fk_nomina_concepto_nomina_id FOREIGN KEY (nomina_id) REFERENCES cfdis_nominas(id) ON DELETE CASCADE,
+    CONSTRAINT uq_nomina_concepto_numero UNIQUE (nomina_id, tipo, numero)
+);
@@ -0,0 +1,29 @@
+CREATE TABLE IF NOT EXISTS cfdis_cartas_porte (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    cfdi_uuid UUID NOT NULL,
+    version VARCHAR(3) NOT NULL,
+    id_ccp VARCHAR(36),
+    transp_internac VARCHAR(2),
+    entrada_salida_merc VARCHAR(7),
+    pais_origen_destino VARCHAR(3),
+    via_entrada_salida VARCHAR(2),
+    total_dist_rec NUMERIC NOT NULL DEFAULT 0,
+    medio_transporte VARCHAR(20),
+    peso_bruto_total NUMERIC NOT NULL DEFAULT 0,
+    unidad_peso VARCHAR(3),
+    peso_neto_total NUMERIC NOT NULL DEFAULT 0,
+    num_total_mercancias INT NOT NULL DEFAULT 0,
+    perm_sct VARCHAR(6),
+    num_permiso_sct VARCHAR(50),
+    config_vehicular VARCHAR(8),
+    peso_bruto_vehicular NUMERIC NOT NULL DEFAULT 0,
+    placa_vm VARCHAR(10),
+    anio_modelo_vm VARCHAR(4),
+    asegura_resp_civil VARCHAR(50),
+    poliza_resp_civil VARCHAR(30),
+    asegura_carga VARCHAR(50),
+    poliza_carga VARCHAR(30),
+    valida BOOLEAN NOT NULL DEFAULT FALSE,
+    CONSTRAINT fk_carta_porte_cfdi_uuid FOREIGN KEY (cfdi_uuid) REFERENCES cfdis(uuid) ON DELETE CASCADE,
+    CONSTRAINT uq_carta_porte_cfdi_uuid UNIQUE (cfdi_uuid)
+);
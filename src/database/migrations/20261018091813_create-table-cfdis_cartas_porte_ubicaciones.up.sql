@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS cfdis_cartas_porte_ubicaciones (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    carta_porte_id UUID NOT NULL,
+    numero INT NOT NULL,
+    tipo_ubicacion VARCHAR(7),
+    id_ubicacion VARCHAR(8),
+    rfc_remitente_destinatario VARCHAR(13),
+    nombre_remitente_destinatario VARCHAR(254),
+    fecha_hora_salida_llegada TIMESTAMP,
+    distancia_recorrida NUMERIC NOT NULL DEFAULT 0,
+    calle VARCHAR(100),
+    numero_exterior VARCHAR(55),
+    colonia VARCHAR(120),
+    municipio VARCHAR(120),
+    estado VARCHAR(30),
+    pais VARCHAR(3),
+    codigo_postal VARCHAR(12),
+    CONSTRAINT fk_carta_porte_ubicacion_carta_porte_id FOREIGN KEY (carta_porte_id) REFERENCES cfdis_cartas_porte(id) ON DELETE CASCADE,
+    CONSTRAINT uq_carta_porte_ubicacion_numero UNIQUE (carta_porte_id, numero)
+);
@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS cfdis_cartas_porte_mercancias (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    carta_porte_id UUID NOT NULL,
+    numero INT NOT NULL,
+    bienes_transp VARCHAR(8),
+    descripcion TEXT,
+    cantidad NUMERIC NOT NULL DEFAULT 0,
+    clave_unidad VARCHAR(3),
+    material_peligroso VARCHAR(2),
+    cve_material_peligroso VARCHAR(4),
+    embalaje VARCHAR(4),
+    peso_en_kg NUMERIC NOT NULL DEFAULT 0,
+    valor_mercancia NUMERIC NOT NULL DEFAULT 0,
+    moneda VARCHAR(3),
+    CONSTRAINT fk_carta_porte_mercancia_carta_porte_id FOREIGN KEY (carta_porte_id) REFERENCES cfdis_cartas_porte(id) ON DELETE CASCADE,
+    CONSTRAINT uq_carta_porte_mercancia_numero UNIQUE (carta_porte_id, numero)
+);
@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS cfdis_cartas_porte_remolques (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    carta_porte_id UUID NOT NULL,
+    numero INT NOT NULL,
+    sub_tipo_rem VARCHAR(6),
+    placa VARCHAR(10),
+    CONSTRAINT fk_carta_porte_remolque_carta_porte_id FOREIGN KEY (carta_porte_id) REFERENCES cfdis_cartas_porte(id) ON DELETE CASCADE,
+    CONSTRAINT uq_carta_porte_remolque_numero UNIQUE (carta_porte_id, numero)
+);
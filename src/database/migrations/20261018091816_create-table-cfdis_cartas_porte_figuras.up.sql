@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS cfdis_cartas_porte_figuras (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    carta_porte_id UUID NOT NULL,
+    numero INT NOT NULL,
+    tipo_figura VARCHAR(2),
+    rfc_figura VARCHAR(13),
+    num_licencia VARCHAR(16),
+    nombre_figura VARCHAR(254),
+    CONSTRAINT fk_carta_porte_figura_carta_porte_id FOREIGN KEY (carta_porte_id) REFERENCES cfdis_cartas_porte(id) ON DELETE CASCADE,
+    CONSTRAINT uq_carta_porte_figura_numero UNIQUE (carta_porte_id, numero)
+);
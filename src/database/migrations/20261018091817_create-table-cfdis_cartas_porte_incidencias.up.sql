@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS cfdis_cartas_porte_incidencias (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    carta_porte_id UUID NOT NULL,
+    numero INT NOT NULL,
+    tipo VARCHAR(20) NOT NULL CHECK (tipo IN ('nodo_faltante', 'peso_inconsistente', 'total_inconsistente', 'clave_invalida')),
+    nodo VARCHAR(200) NOT NULL,
+    mensaje TEXT NOT NULL,
+    CONSTRAINT fk_carta_porte_incidencia_carta_porte_id FOREIGN KEY (carta_porte_id) REFERENCES cfdis_cartas_porte(id) ON DELETE CASCADE,
+    CONSTRAINT uq_carta_porte_incidencia_numero UNIQUE (carta_porte_id, numero)
+);
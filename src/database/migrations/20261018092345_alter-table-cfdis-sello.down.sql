@@ -0,0 +1,3 @@
+ALTER TABLE cfdis
+    DROP COLUMN IF EXISTS sello_valido,
+    DROP COLUMN IF EXISTS sello_error;
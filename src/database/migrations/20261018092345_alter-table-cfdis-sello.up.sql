@@ -0,0 +1,3 @@
+ALTER TABLE cfdis
+    ADD COLUMN IF NOT EXISTS sello_valido BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN IF NOT EXISTS sello_error TEXT;
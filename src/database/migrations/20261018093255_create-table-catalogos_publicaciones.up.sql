@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS catalogos_publicaciones (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    catalogo VARCHAR(30) NOT NULL,
+    fecha_publicacion DATE NOT NULL,
+    registros INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP,
+    updated_at TIMESTAMP,
+    CONSTRAINT uq_catalogo_publicacion UNIQUE (catalogo, fecha_publicacion)
+);
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS catalogos_registros (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    publicacion_id UUID NOT NULL,
+    clave VARCHAR(20) NOT NULL,
+    descripcion TEXT,
+    fecha_inicio_vigencia DATE,
+    fecha_fin_vigencia DATE,
+    atributos JSONB NOT NULL DEFAULT '{}',
+    CONSTRAINT fk_catalogo_registro_publicacion_id FOREIGN KEY (publicacion_id) REFERENCES catalogos_publicaciones(id) ON DELETE CASCADE,
+    CONSTRAINT uq_catalogo_registro_clave UNIQUE (publicacion_id, clave)
+);
+
+CREATE INDEX IF NOT EXISTS idx_catalogos_registros_descripcion ON catalogos_registros USING gin (descripcion gin_trgm_ops);
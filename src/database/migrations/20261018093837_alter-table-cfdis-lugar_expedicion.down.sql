@@ -0,0 +1 @@
+ALTER TABLE cfdis DROP COLUMN IF EXISTS lugar_expedicion;
@@ -0,0 +1 @@
+ALTER TABLE cfdis ADD COLUMN IF NOT EXISTS lugar_expedicion VARCHAR(5);
@@ -0,0 +1,3 @@
+ALTER TABLE solicitudes_descarga_paquetes
+    DROP COLUMN IF EXISTS intentos_indexacion,
+    DROP COLUMN IF EXISTS error_indexacion;
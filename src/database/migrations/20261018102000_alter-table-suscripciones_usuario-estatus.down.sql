@@ -0,0 +1,6 @@
+ALTER TABLE suscripciones_usuario
+    DROP CONSTRAINT IF EXISTS suscripciones_usuario_estatus_check;
+
+ALTER TABLE suscripciones_usuario
+    ADD CONSTRAINT suscripciones_usuario_estatus_check
+        CHECK (estatus IN ('activo', 'cancelado', 'pendiente', 'expirado'));
@@ -0,0 +1,8 @@
+UPDATE suscripciones_usuario SET estatus = 'cancelado' WHERE estatus = 'pendiente';
+
+ALTER TABLE suscripciones_usuario
+    DROP CONSTRAINT IF EXISTS suscripciones_usuario_estatus_check;
+
+ALTER TABLE suscripciones_usuario
+    ADD CONSTRAINT suscripciones_usuario_estatus_check
+        CHECK (estatus IN ('activo', 'cancelado', 'expirado'));
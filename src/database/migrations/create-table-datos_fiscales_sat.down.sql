@@ -1 +0,0 @@
-DROP TABLE IF EXISTS datos_fiscales_sat;
\ No newline at end of file
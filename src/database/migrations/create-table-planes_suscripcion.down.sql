@@ -1 +0,0 @@
-DROP TABLE IF EXISTS planes_suscripcion;
\ No newline at end of file
@@ -1 +0,0 @@
-DROP TABLE IF EXISTS suscripciones_usuario;
\ No newline at end of file
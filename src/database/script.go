@@ -0,0 +1,19 @@
+package database
+
+import (
+	"gorm.io/gorm"
+)
+
+// execScript runs the statements of a SQL file. The connection prepares
+// its statements (PrepareStmt) and Postgres rejects a prepared statement
+// with more than one command, so the script goes through the underlying
+// connection, which sends queries without arguments as simple queries.
+func execScript(db *gorm.DB, script string) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+
+	_, err = sqlDB.Exec(script)
+	return err
+}
@@ -0,0 +1,81 @@
+package fiel
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// LoadAuthorities loads the certificates of the SAT certification
+// authorities, the root and the intermediate ones, from every .cer, .crt
+// (DER) and .pem file in dir. The SAT publishes them so e.firma and CSD
+// certificates can be verified offline.
+func LoadAuthorities(dir string) (*x509.CertPool, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("read authorities dir: %w", err)
+	}
+
+	pool := x509.NewCertPool()
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		extension := strings.ToLower(filepath.Ext(entry.Name()))
+		if extension != ".cer" && extension != ".crt" && extension != ".pem" {
+			continue
+		}
+
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("read authority %s: %w", entry.Name(), err)
+		}
+
+		if err := addAuthorities(pool, data, extension == ".pem"); err != nil {
+			return nil, fmt.Errorf("load authority %s: %w", entry.Name(), err)
+		}
+	}
+
+	return pool, nil
+}
+
+func addAuthorities(pool *x509.CertPool, data []byte, isPEM bool) error {
+	if !isPEM {
+		return addAuthority(pool, data)
+	}
+
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			return nil
+		}
+
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+
+		if err := addAuthority(pool, block.Bytes); err != nil {
+			return err
+		}
+	}
+}
+
+func addAuthority(pool *x509.CertPool, der []byte) error {
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidCertificate, err)
+	}
+
+	if !cert.IsCA {
+		return fmt.Errorf("%w: %s is not a certification authority", ErrInvalidCertificate, issuerName(cert.Subject))
+	}
+
+	pool.AddCert(cert)
+
+	return nil
+}
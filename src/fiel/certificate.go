@@ -0,0 +1,126 @@
+package fiel
+
+import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidCertificate = errors.New("invalid certificate")
+	ErrMissingRFC         = errors.New("certificate does not contain an RFC")
+	ErrExpiredCertificate = errors.New("certificate is expired or not yet valid")
+	ErrUntrustedIssuer    = errors.New("certificate was not issued by the SAT")
+)
+
+// oidUniqueIdentifier is the x500UniqueIdentifier attribute where the SAT
+// stores the RFC of the certificate holder.
+var oidUniqueIdentifier = asn1.ObjectIdentifier{2, 5, 4, 45}
+
+type Certificate struct {
+	RFC           string
+	NoCertificado string
+	Issuer        string
+	NotBefore     time.Time
+	NotAfter      time.Time
+	X509          *x509.Certificate
+}
+
+// ParseCertificate parses a DER encoded certificate as issued by the SAT.
+func ParseCertificate(der []byte) (*Certificate, error) {
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidCertificate, err)
+	}
+
+	rfc := rfcFromSubject(cert.Subject)
+	if rfc == "" {
+		return nil, ErrMissingRFC
+	}
+
+	return &Certificate{
+		RFC:           rfc,
+		NoCertificado: NoCertificado(cert.SerialNumber),
+		Issuer:        issuerName(cert.Issuer),
+		NotBefore:     cert.NotBefore,
+		NotAfter:      cert.NotAfter,
+		X509:          cert,
+	}, nil
+}
+
+// Validate checks that the certificate is within its validity window and
+// chains up to one of the SAT certification authorities. The issuer name
+// proves nothing, anyone can put the SAT name in a certificate, so without
+// authorities every certificate is rejected.
+func (c *Certificate) Validate(now time.Time, authorities *x509.CertPool) error {
+	if now.Before(c.NotBefore) || now.After(c.NotAfter) {
+		return ErrExpiredCertificate
+	}
+
+	if authorities == nil {
+		return fmt.Errorf("%w: no SAT certification authorities configured", ErrUntrustedIssuer)
+	}
+
+	_, err := c.X509.Verify(x509.VerifyOptions{
+		Roots:       authorities,
+		CurrentTime: now,
+		KeyUsages:   []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	})
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrUntrustedIssuer, err)
+	}
+
+	return nil
+}
+
+// MatchesRFC reports whether the certificate belongs to the given RFC.
+func (c *Certificate) MatchesRFC(rfc string) bool {
+	return strings.EqualFold(c.RFC, strings.TrimSpace(rfc))
+}
+
+// NoCertificado converts the certificate serial number to the 20 digit
+// "número de certificado" used by the SAT, whose bytes are ASCII digits.
+func NoCertificado(serial *big.Int) string {
+	raw := serial.Bytes()
+	for _, b := range raw {
+		if b < '0' || b > '9' {
+			return serial.String()
+		}
+	}
+
+	return string(raw)
+}
+
+// rfcFromSubject reads the x500UniqueIdentifier attribute. For personas
+// morales it holds "RFC / RFC del representante legal", the first one is
+// the RFC of the certificate holder.
+func rfcFromSubject(subject pkix.Name) string {
+	for _, attr := range subject.Names {
+		if !attr.Type.Equal(oidUniqueIdentifier) {
+			continue
+		}
+
+		value, ok := attr.Value.(string)
+		if !ok {
+			continue
+		}
+
+		rfc, _, _ := strings.Cut(value, "/")
+		return strings.ToUpper(strings.TrimSpace(rfc))
+	}
+
+	return ""
+}
+
+func issuerName(issuer pkix.Name) string {
+	if issuer.CommonName != "" {
+		return issuer.CommonName
+	}
+
+	return issuer.String()
+}
@@ -0,0 +1,210 @@
+package fiel
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/des"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"hash"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+var (
+	ErrInvalidPrivateKey  = errors.New("invalid private key")
+	ErrUnsupportedKey     = errors.New("unsupported private key encryption")
+	ErrInvalidPassword    = errors.New("invalid private key password")
+	ErrKeyDoesNotMatchCer = errors.New("private key does not belong to the certificate")
+)
+
+var (
+	oidPBES2      = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 5, 13}
+	oidPBKDF2     = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 5, 12}
+	oidHMACSHA1   = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 7}
+	oidHMACSHA256 = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 9}
+	oidDESEDE3CBC = asn1.ObjectIdentifier{1, 2, 840, 113549, 3, 7}
+	oidAES128CBC  = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 2}
+	oidAES192CBC  = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 22}
+	oidAES256CBC  = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 42}
+)
+
+// maxIterations bounds the PBKDF2 iteration count read from the .key file.
+// The SAT uses 2048, an uploaded file could ask for enough to keep a request
+// busy for minutes.
+const maxIterations = 1000000
+
+type encryptedPrivateKeyInfo struct {
+	Algorithm     pkix.AlgorithmIdentifier
+	EncryptedData []byte
+}
+
+type pbes2Params struct {
+	KeyDerivationFunc pkix.AlgorithmIdentifier
+	EncryptionScheme  pkix.AlgorithmIdentifier
+}
+
+type pbkdf2Params struct {
+	Salt           []byte
+	IterationCount int
+	KeyLength      int                      `asn1:"optional"`
+	PRF            pkix.AlgorithmIdentifier `asn1:"optional"`
+}
+
+// DecryptPrivateKey decrypts a DER encoded PKCS#8 EncryptedPrivateKeyInfo,
+// the format of the .key file the SAT hands out with the e.firma. The
+// password is taken as bytes so callers can wipe it.
+func DecryptPrivateKey(der, password []byte) (*rsa.PrivateKey, error) {
+	var info encryptedPrivateKeyInfo
+	if rest, err := asn1.Unmarshal(der, &info); err != nil || len(rest) > 0 {
+		return nil, ErrInvalidPrivateKey
+	}
+
+	if !info.Algorithm.Algorithm.Equal(oidPBES2) {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedKey, info.Algorithm.Algorithm)
+	}
+
+	var params pbes2Params
+	if _, err := asn1.Unmarshal(info.Algorithm.Parameters.FullBytes, &params); err != nil {
+		return nil, ErrInvalidPrivateKey
+	}
+
+	block, iv, err := pbes2Cipher(params, password)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(info.EncryptedData) == 0 || len(info.EncryptedData)%block.BlockSize() != 0 {
+		return nil, ErrInvalidPrivateKey
+	}
+
+	plaintext := make([]byte, len(info.EncryptedData))
+	cipher.NewCBCDecrypter(block, iv).CryptBlocks(plaintext, info.EncryptedData)
+	defer Zero(plaintext)
+
+	// A wrong password yields garbage, which shows up either as invalid
+	// padding or as a structure that is not a PKCS#8 private key.
+	unpadded, ok := unpad(plaintext, block.BlockSize())
+	if !ok {
+		return nil, ErrInvalidPassword
+	}
+
+	parsed, err := x509.ParsePKCS8PrivateKey(unpadded)
+	if err != nil {
+		return nil, ErrInvalidPassword
+	}
+
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("%w: not an RSA key", ErrUnsupportedKey)
+	}
+
+	return key, nil
+}
+
+// VerifyKeyPair checks that the private key is the counterpart of the
+// certificate public key.
+func VerifyKeyPair(key *rsa.PrivateKey, certificate *Certificate) error {
+	if !key.PublicKey.Equal(certificate.X509.PublicKey) {
+		return ErrKeyDoesNotMatchCer
+	}
+
+	return nil
+}
+
+func pbes2Cipher(params pbes2Params, password []byte) (cipher.Block, []byte, error) {
+	if !params.KeyDerivationFunc.Algorithm.Equal(oidPBKDF2) {
+		return nil, nil, fmt.Errorf("%w: %s", ErrUnsupportedKey, params.KeyDerivationFunc.Algorithm)
+	}
+
+	var kdf pbkdf2Params
+	if _, err := asn1.Unmarshal(params.KeyDerivationFunc.Parameters.FullBytes, &kdf); err != nil {
+		return nil, nil, ErrInvalidPrivateKey
+	}
+
+	if kdf.IterationCount <= 0 || kdf.IterationCount > maxIterations {
+		return nil, nil, fmt.Errorf("%w: %d PBKDF2 iterations", ErrInvalidPrivateKey, kdf.IterationCount)
+	}
+
+	prf, err := pbkdf2PRF(kdf.PRF.Algorithm)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var iv []byte
+	if _, err := asn1.Unmarshal(params.EncryptionScheme.Parameters.FullBytes, &iv); err != nil {
+		return nil, nil, ErrInvalidPrivateKey
+	}
+
+	scheme := params.EncryptionScheme.Algorithm
+	keyLength := 0
+	switch {
+	case scheme.Equal(oidDESEDE3CBC):
+		keyLength = 24
+	case scheme.Equal(oidAES128CBC):
+		keyLength = 16
+	case scheme.Equal(oidAES192CBC):
+		keyLength = 24
+	case scheme.Equal(oidAES256CBC):
+		keyLength = 32
+	default:
+		return nil, nil, fmt.Errorf("%w: %s", ErrUnsupportedKey, scheme)
+	}
+
+	key := pbkdf2.Key(password, kdf.Salt, kdf.IterationCount, keyLength, prf)
+	defer Zero(key)
+
+	var block cipher.Block
+	if scheme.Equal(oidDESEDE3CBC) {
+		block, err = des.NewTripleDESCipher(key)
+	} else {
+		block, err = aes.NewCipher(key)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(iv) != block.BlockSize() {
+		return nil, nil, ErrInvalidPrivateKey
+	}
+
+	return block, iv, nil
+}
+
+func pbkdf2PRF(oid asn1.ObjectIdentifier) (func() hash.Hash, error) {
+	switch {
+	case len(oid) == 0, oid.Equal(oidHMACSHA1):
+		return sha1.New, nil
+	case oid.Equal(oidHMACSHA256):
+		return sha256.New, nil
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedKey, oid)
+	}
+}
+
+func unpad(data []byte, blockSize int) ([]byte, bool) {
+	padding := int(data[len(data)-1])
+	if padding == 0 || padding > blockSize || padding > len(data) {
+		return nil, false
+	}
+
+	if !bytes.Equal(data[len(data)-padding:], bytes.Repeat([]byte{byte(padding)}, padding)) {
+		return nil, false
+	}
+
+	return data[:len(data)-padding], true
+}
+
+// Zero overwrites a buffer holding secret material.
+func Zero(data []byte) {
+	for i := range data {
+		data[i] = 0
+	}
+}
@@ -0,0 +1,108 @@
+package fiel
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+)
+
+var ErrSignerClosed = errors.New("signer is closed")
+
+// Signer holds a decrypted e.firma in memory. Callers must Close it as soon
+// as they are done so the private key material is wiped.
+type Signer struct {
+	mu          sync.Mutex
+	certificate *Certificate
+	key         *rsa.PrivateKey
+}
+
+// NewSigner parses the certificate, decrypts the key and checks that both
+// belong together. The password is wiped once the key is decrypted, it is
+// not needed afterwards.
+func NewSigner(cerDER, keyDER, password []byte) (*Signer, error) {
+	key, err := DecryptPrivateKey(keyDER, password)
+	Zero(password)
+	if err != nil {
+		return nil, err
+	}
+
+	certificate, err := ParseCertificate(cerDER)
+	if err != nil {
+		zeroKey(key)
+		return nil, err
+	}
+
+	if err := VerifyKeyPair(key, certificate); err != nil {
+		zeroKey(key)
+		return nil, err
+	}
+
+	return &Signer{certificate: certificate, key: key}, nil
+}
+
+// Sign returns the PKCS#1 v1.5 signature of data. The SAT services use
+// crypto.SHA1 for WS-Security and crypto.SHA256 for CFDI seals.
+func (s *Signer) Sign(data []byte, hash crypto.Hash) ([]byte, error) {
+	if hash != crypto.SHA1 && hash != crypto.SHA256 {
+		return nil, fmt.Errorf("unsupported hash %s", hash)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.key == nil {
+		return nil, ErrSignerClosed
+	}
+
+	digest := hash.New()
+	digest.Write(data)
+
+	return rsa.SignPKCS1v15(rand.Reader, s.key, hash, digest.Sum(nil))
+}
+
+// Certificate returns the certificate that verifies the signatures.
+func (s *Signer) Certificate() *Certificate {
+	return s.certificate
+}
+
+// Close wipes the private key. The signer cannot be used afterwards.
+func (s *Signer) Close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.key != nil {
+		zeroKey(s.key)
+		s.key = nil
+	}
+}
+
+func zeroKey(key *rsa.PrivateKey) {
+	zeroInt(key.D)
+	for _, prime := range key.Primes {
+		zeroInt(prime)
+	}
+	zeroInt(key.Precomputed.Dp)
+	zeroInt(key.Precomputed.Dq)
+	zeroInt(key.Precomputed.Qinv)
+	for _, crt := range key.Precomputed.CRTValues {
+		zeroInt(crt.Exp)
+		zeroInt(crt.Coeff)
+		zeroInt(crt.R)
+	}
+}
+
+func zeroInt(value *big.Int) {
+	if value == nil {
+		return
+	}
+
+	words := value.Bits()
+	for i := range words {
+		words[i] = 0
+	}
+	value.SetInt64(0)
+}
@@ -5,7 +5,9 @@ import (
 	"app/src/database"
 	"app/src/middleware"
 	"app/src/router"
+	"app/src/service"
 	"app/src/utils"
+	"app/src/worker"
 	"context"
 	"fmt"
 	"os"
@@ -36,14 +38,18 @@ func main() {
 	app := setupFiberApp()
 	db := setupDatabase()
 	defer closeDatabase(db)
-	setupRoutes(app, db)
+	services := router.NewServices(db)
+	setupRoutes(app, db, services)
+
+	workers := setupWorkers(db, services)
+	startWorkers(ctx, workers)
 
 	address := fmt.Sprintf("%s:%d", config.AppHost, config.AppPort)
 
 	// Start server and handle graceful shutdown
 	serverErrors := make(chan error, 1)
 	go startServer(app, address, serverErrors)
-	handleGracefulShutdown(ctx, app, serverErrors)
+	handleGracefulShutdown(ctx, app, serverErrors, workers)
 }
 
 func setupFiberApp() *fiber.App {
@@ -68,11 +74,68 @@ func setupDatabase() *gorm.DB {
 	return db
 }
 
-func setupRoutes(app *fiber.App, db *gorm.DB) {
-	router.Routes(app, db)
+func setupRoutes(app *fiber.App, db *gorm.DB, services *router.Services) {
+	router.Routes(app, db, services)
 	app.Use(utils.NotFoundHandler)
 }
 
+// setupWorkers builds the background jobs on the services the routes use,
+// so the SAT tokens and CFDI statuses they cache are shared.
+func setupWorkers(db *gorm.DB, services *router.Services) []*worker.Worker {
+	verificacionService := service.NewVerificacionService(db, services.Fiel, services.SATAuth, services.SATClient)
+
+	blobStore, err := config.BlobStore()
+	if err != nil {
+		utils.Log.Fatalf("Invalid blob store configuration: %v", err)
+	}
+	paqueteService := service.NewPaqueteService(db, services.Fiel, services.SATAuth, services.SATClient, blobStore)
+	indexacionService := service.NewIndexacionService(db, blobStore, services.CertificadosSAT)
+	vencimientoService := service.NewVencimientoService(db, service.NewEmailService(), config.AvisoVencimiento())
+
+	return []*worker.Worker{
+		worker.New("verificacion-descargas", config.SATVerificacionInterval(), func(ctx context.Context) error {
+			_, err := verificacionService.VerificarPendientes(ctx)
+			return err
+		}),
+		worker.New("descarga-paquetes", config.SATDescargaInterval(), func(ctx context.Context) error {
+			_, err := paqueteService.DescargarPendientes(ctx)
+			return err
+		}),
+		worker.New("indexacion-cfdis", config.SATDescargaInterval(), func(ctx context.Context) error {
+			_, err := indexacionService.IndexarPendientes(ctx)
+			return err
+		}),
+		worker.New("estado-cfdis", config.SATConsultaInterval(), func(ctx context.Context) error {
+			_, err := services.EstadoCFDI.RevisarRecientes(ctx)
+			return err
+		}),
+		worker.New("suscripciones", config.SuscripcionesInterval(), func(ctx context.Context) error {
+			if _, err := vencimientoService.ExpirarSuscripciones(ctx); err != nil {
+				return err
+			}
+
+			if _, err := vencimientoService.ReiniciarPeriodos(ctx); err != nil {
+				return err
+			}
+
+			_, err := vencimientoService.AvisarVencimientos(ctx)
+			return err
+		}),
+	}
+}
+
+func startWorkers(ctx context.Context, workers []*worker.Worker) {
+	for _, w := range workers {
+		w.Start(ctx)
+	}
+}
+
+func stopWorkers(workers []*worker.Worker) {
+	for _, w := range workers {
+		w.Stop()
+	}
+}
+
 func startServer(app *fiber.App, address string, errs chan<- error) {
 	if err := app.Listen(address); err != nil {
 		errs <- fmt.Errorf("error starting server: %w", err)
@@ -93,12 +156,13 @@ func closeDatabase(db *gorm.DB) {
 	}
 }
 
-func handleGracefulShutdown(ctx context.Context, app *fiber.App, serverErrors <-chan error) {
+func handleGracefulShutdown(ctx context.Context, app *fiber.App, serverErrors <-chan error, workers []*worker.Worker) {
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, os.Interrupt, syscall.SIGTERM)
 
 	select {
 	case err := <-serverErrors:
+		stopWorkers(workers)
 		utils.Log.Fatalf("Server error: %v", err)
 	case <-quit:
 		utils.Log.Info("Shutting down server...")
@@ -109,5 +173,9 @@ func handleGracefulShutdown(ctx context.Context, app *fiber.App, serverErrors <-
 		utils.Log.Info("Server exiting due to context cancellation")
 	}
 
+	// Stop the workers before the deferred database close so a running job
+	// can finish its writes.
+	stopWorkers(workers)
+
 	utils.Log.Info("Server exited")
 }
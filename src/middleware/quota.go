@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"app/src/model"
+	"app/src/response"
+	"app/src/service"
+	"app/src/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Quota reserves one download from the monthly quota of the user's active
+// subscription before running the handler, and gives it back when the
+// handler fails. Must be used after Auth.
+func Quota(subscriptionService service.SubscriptionService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		user, _ := c.Locals("user").(*model.User)
+
+		suscripcion, err := subscriptionService.ReservarDescarga(c, user.ID)
+		if suscripcion != nil {
+			response.QuotaHeaders(c, suscripcion)
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if err := c.Next(); err != nil {
+			liberada, errRelease := subscriptionService.LiberarDescarga(c, suscripcion.ID)
+			if errRelease != nil {
+				utils.Log.Errorf("Failed to release download for subscription %s: %+v", suscripcion.ID, errRelease)
+				return err
+			}
+
+			response.QuotaHeaders(c, liberada)
+			return err
+		}
+
+		return nil
+	}
+}
@@ -0,0 +1,88 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CatalogoPublicacion is an import of a SAT catalog. The SAT republishes
+// catCFDI.xls every few weeks, each publication is kept so CFDIs can be
+// checked against the catalog in effect when they were issued.
+type CatalogoPublicacion struct {
+	ID               uuid.UUID `json:"-" gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	Catalogo         string    `json:"catalogo" gorm:"type:varchar(30);not null"`
+	FechaPublicacion time.Time `json:"fecha_publicacion" gorm:"type:date;not null"`
+	Registros        int       `json:"registros" gorm:"not null"`
+	CreatedAt        time.Time `json:"created_at" gorm:"default:CURRENT_TIMESTAMP"`
+	UpdatedAt        time.Time `json:"updated_at" gorm:"default:CURRENT_TIMESTAMP"`
+}
+
+func (CatalogoPublicacion) TableName() string {
+	return "catalogos_publicaciones"
+}
+
+// CatalogoRegistro is a row of a catalog publication. The columns that are
+// specific to a catalog, like the regímenes a UsoCFDI applies to, are kept
+// in Atributos.
+type CatalogoRegistro struct {
+	ID                  uuid.UUID  `json:"-" gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	PublicacionID       uuid.UUID  `json:"-" gorm:"type:uuid;not null"`
+	Clave               string     `json:"clave" gorm:"type:varchar(20);not null"`
+	Descripcion         string     `json:"descripcion,omitempty" gorm:"type:text"`
+	FechaInicioVigencia *time.Time `json:"fecha_inicio_vigencia,omitempty" gorm:"type:date"`
+	FechaFinVigencia    *time.Time `json:"fecha_fin_vigencia,omitempty" gorm:"type:date"`
+	Atributos           Atributos  `json:"atributos,omitempty" gorm:"type:jsonb;not null"`
+}
+
+func (CatalogoRegistro) TableName() string {
+	return "catalogos_registros"
+}
+
+// Vigente reports whether the row was in effect on fecha.
+func (r *CatalogoRegistro) Vigente(fecha time.Time) bool {
+	dia := time.Date(fecha.Year(), fecha.Month(), fecha.Day(), 0, 0, 0, 0, time.UTC)
+
+	if r.FechaInicioVigencia != nil && dia.Before(*r.FechaInicioVigencia) {
+		return false
+	}
+
+	return r.FechaFinVigencia == nil || !dia.After(*r.FechaFinVigencia)
+}
+
+// Atributos are the extra columns of a catalog row, stored as JSONB.
+type Atributos map[string]string
+
+func (a Atributos) Value() (driver.Value, error) {
+	if a == nil {
+		return "{}", nil
+	}
+
+	data, err := json.Marshal(a)
+	if err != nil {
+		return nil, err
+	}
+
+	return string(data), nil
+}
+
+func (a *Atributos) Scan(src interface{}) error {
+	var data []byte
+
+	switch value := src.(type) {
+	case nil:
+		*a = nil
+		return nil
+	case string:
+		data = []byte(value)
+	case []byte:
+		data = value
+	default:
+		return fmt.Errorf("cannot scan %T into Atributos", src)
+	}
+
+	return json.Unmarshal(data, a)
+}
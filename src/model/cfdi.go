@@ -0,0 +1,87 @@
+package model
+
+import (
+	"app/src/cfdi"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Estados of an indexed CFDI. XML packages only include CFDIs that were
+// vigente when downloaded, later checks can mark them as cancelled.
+// en_cancelacion is a vigente CFDI whose cancellation awaits the receptor.
+const (
+	EstadoCFDIVigente       = "vigente"
+	EstadoCFDIEnCancelacion = "en_cancelacion"
+	EstadoCFDICancelado     = "cancelado"
+)
+
+// CFDI is a stamped comprobante indexed from a downloaded package. The
+// primary key is the folio fiscal, so a CFDI downloaded by both the emisor
+// and the receptor is stored once.
+type CFDI struct {
+	UUID                      uuid.UUID       `json:"uuid" gorm:"type:uuid;primaryKey"`
+	PaqueteUUID               *uuid.UUID      `json:"-" gorm:"type:uuid"`
+	Version                   string          `json:"version" gorm:"type:varchar(3);not null"`
+	Serie                     string          `json:"serie,omitempty" gorm:"type:varchar(25)"`
+	Folio                     string          `json:"folio,omitempty" gorm:"type:varchar(40)"`
+	Fecha                     time.Time       `json:"fecha" gorm:"not null"`
+	FechaTimbrado             time.Time       `json:"fecha_timbrado" gorm:"not null"`
+	TipoComprobante           string          `json:"tipo_comprobante" gorm:"type:varchar(1);not null"`
+	RFCEmisor                 string          `json:"rfc_emisor" gorm:"type:varchar(13);not null"`
+	NombreEmisor              string          `json:"nombre_emisor,omitempty" gorm:"type:varchar(300)"`
+	RegimenFiscalEmisor       string          `json:"regimen_fiscal_emisor,omitempty" gorm:"type:varchar(3)"`
+	RFCReceptor               string          `json:"rfc_receptor" gorm:"type:varchar(13);not null"`
+	NombreReceptor            string          `json:"nombre_receptor,omitempty" gorm:"type:varchar(300)"`
+	RegimenFiscalReceptor     string          `json:"regimen_fiscal_receptor,omitempty" gorm:"type:varchar(3)"`
+	DomicilioFiscalReceptor   string          `json:"domicilio_fiscal_receptor,omitempty" gorm:"type:varchar(5)"`
+	UsoCFDI                   string          `json:"uso_cfdi,omitempty" gorm:"column:uso_cfdi;type:varchar(4)"`
+	SubTotal                  cfdi.Decimal    `json:"subtotal" gorm:"column:subtotal;type:numeric;not null"`
+	Descuento                 cfdi.Decimal    `json:"descuento" gorm:"type:numeric;not null"`
+	TotalImpuestosTrasladados cfdi.Decimal    `json:"total_impuestos_trasladados" gorm:"type:numeric;not null"`
+	TotalImpuestosRetenidos   cfdi.Decimal    `json:"total_impuestos_retenidos" gorm:"type:numeric;not null"`
+	Total                     cfdi.Decimal    `json:"total" gorm:"type:numeric;not null"`
+	Moneda                    string          `json:"moneda" gorm:"type:varchar(3);not null"`
+	TipoCambio                *cfdi.Decimal   `json:"tipo_cambio,omitempty" gorm:"type:numeric"`
+	MetodoPago                string          `json:"metodo_pago,omitempty" gorm:"type:varchar(3)"`
+	FormaPago                 string          `json:"forma_pago,omitempty" gorm:"type:varchar(2)"`
+	LugarExpedicion           string          `json:"lugar_expedicion,omitempty" gorm:"type:varchar(5)"`
+	Estado                    string          `json:"estado" gorm:"type:varchar(20);not null"`
+	EstadoConsultadoAt        *time.Time      `json:"estado_consultado_at,omitempty"`
+	SelloValido               bool            `json:"sello_valido" gorm:"not null"`
+	SelloError                string          `json:"sello_error,omitempty" gorm:"type:text"`
+	StorageKey                string          `json:"-" gorm:"type:text;not null"`
+	Conceptos                 []CFDIConcepto  `json:"conceptos,omitempty" gorm:"foreignKey:CFDIUUID"`
+	Pagos                     []CFDIPago      `json:"pagos,omitempty" gorm:"foreignKey:CFDIUUID"`
+	Nominas                   []CFDINomina    `json:"nominas,omitempty" gorm:"foreignKey:CFDIUUID"`
+	CartaPorte                *CFDICartaPorte `json:"carta_porte,omitempty" gorm:"foreignKey:CFDIUUID"`
+	Metadata                  *CFDIMetadata   `json:"metadata,omitempty" gorm:"foreignKey:UUID;references:UUID"`
+	CreatedAt                 time.Time       `json:"created_at" gorm:"default:CURRENT_TIMESTAMP"`
+	UpdatedAt                 time.Time       `json:"updated_at" gorm:"default:CURRENT_TIMESTAMP"`
+}
+
+func (CFDI) TableName() string {
+	return "cfdis"
+}
+
+// CFDIConcepto is a concepto of an indexed CFDI, Numero keeps the order of
+// the document.
+type CFDIConcepto struct {
+	ID               uuid.UUID    `json:"id" gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	CFDIUUID         uuid.UUID    `json:"-" gorm:"column:cfdi_uuid;type:uuid;not null"`
+	Numero           int          `json:"numero" gorm:"not null"`
+	ClaveProdServ    string       `json:"clave_prod_serv" gorm:"type:varchar(8)"`
+	NoIdentificacion string       `json:"no_identificacion,omitempty" gorm:"type:varchar(100)"`
+	Cantidad         cfdi.Decimal `json:"cantidad" gorm:"type:numeric;not null"`
+	ClaveUnidad      string       `json:"clave_unidad" gorm:"type:varchar(3)"`
+	Unidad           string       `json:"unidad,omitempty" gorm:"type:varchar(20)"`
+	Descripcion      string       `json:"descripcion" gorm:"type:text;not null"`
+	ValorUnitario    cfdi.Decimal `json:"valor_unitario" gorm:"type:numeric;not null"`
+	Importe          cfdi.Decimal `json:"importe" gorm:"type:numeric;not null"`
+	Descuento        cfdi.Decimal `json:"descuento" gorm:"type:numeric;not null"`
+	ObjetoImp        string       `json:"objeto_imp,omitempty" gorm:"type:varchar(2)"`
+}
+
+func (CFDIConcepto) TableName() string {
+	return "cfdis_conceptos"
+}
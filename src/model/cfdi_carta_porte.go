@@ -0,0 +1,136 @@
+package model
+
+import (
+	"app/src/cfdi"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CFDICartaPorte is the Carta Porte complement of an indexed CFDI with the
+// autotransporte details flattened in. Valida tells whether the structural
+// validation found no Incidencias.
+type CFDICartaPorte struct {
+	ID                 uuid.UUID                  `json:"id" gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	CFDIUUID           uuid.UUID                  `json:"cfdi_uuid" gorm:"column:cfdi_uuid;type:uuid;not null"`
+	Version            string                     `json:"version" gorm:"type:varchar(3);not null"`
+	IdCCP              string                     `json:"id_ccp,omitempty" gorm:"column:id_ccp;type:varchar(36)"`
+	TranspInternac     string                     `json:"transp_internac" gorm:"type:varchar(2)"`
+	EntradaSalidaMerc  string                     `json:"entrada_salida_merc,omitempty" gorm:"type:varchar(7)"`
+	PaisOrigenDestino  string                     `json:"pais_origen_destino,omitempty" gorm:"type:varchar(3)"`
+	ViaEntradaSalida   string                     `json:"via_entrada_salida,omitempty" gorm:"type:varchar(2)"`
+	TotalDistRec       cfdi.Decimal               `json:"total_dist_rec" gorm:"type:numeric;not null"`
+	MedioTransporte    string                     `json:"medio_transporte,omitempty" gorm:"type:varchar(20)"`
+	PesoBrutoTotal     cfdi.Decimal               `json:"peso_bruto_total" gorm:"type:numeric;not null"`
+	UnidadPeso         string                     `json:"unidad_peso" gorm:"type:varchar(3)"`
+	PesoNetoTotal      cfdi.Decimal               `json:"peso_neto_total" gorm:"type:numeric;not null"`
+	NumTotalMercancias int                        `json:"num_total_mercancias" gorm:"not null"`
+	PermSCT            string                     `json:"perm_sct,omitempty" gorm:"column:perm_sct;type:varchar(6)"`
+	NumPermisoSCT      string                     `json:"num_permiso_sct,omitempty" gorm:"column:num_permiso_sct;type:varchar(50)"`
+	ConfigVehicular    string                     `json:"config_vehicular,omitempty" gorm:"type:varchar(8)"`
+	PesoBrutoVehicular cfdi.Decimal               `json:"peso_bruto_vehicular" gorm:"type:numeric;not null"`
+	PlacaVM            string                     `json:"placa_vm,omitempty" gorm:"column:placa_vm;type:varchar(10)"`
+	AnioModeloVM       string                     `json:"anio_modelo_vm,omitempty" gorm:"column:anio_modelo_vm;type:varchar(4)"`
+	AseguraRespCivil   string                     `json:"asegura_resp_civil,omitempty" gorm:"type:varchar(50)"`
+	PolizaRespCivil    string                     `json:"poliza_resp_civil,omitempty" gorm:"type:varchar(30)"`
+	AseguraCarga       string                     `json:"asegura_carga,omitempty" gorm:"type:varchar(50)"`
+	PolizaCarga        string                     `json:"poliza_carga,omitempty" gorm:"type:varchar(30)"`
+	Valida             bool                       `json:"valida" gorm:"not null"`
+	Ubicaciones        []CFDICartaPorteUbicacion  `json:"ubicaciones" gorm:"foreignKey:CartaPorteID"`
+	Mercancias         []CFDICartaPorteMercancia  `json:"mercancias" gorm:"foreignKey:CartaPorteID"`
+	Remolques          []CFDICartaPorteRemolque   `json:"remolques,omitempty" gorm:"foreignKey:CartaPorteID"`
+	Figuras            []CFDICartaPorteFigura     `json:"figuras" gorm:"foreignKey:CartaPorteID"`
+	Incidencias        []CFDICartaPorteIncidencia `json:"incidencias" gorm:"foreignKey:CartaPorteID"`
+}
+
+func (CFDICartaPorte) TableName() string {
+	return "cfdis_cartas_porte"
+}
+
+// CFDICartaPorteUbicacion is an origin or destination of the goods with
+// its Domicilio flattened in.
+type CFDICartaPorteUbicacion struct {
+	ID                          uuid.UUID    `json:"id" gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	CartaPorteID                uuid.UUID    `json:"-" gorm:"type:uuid;not null"`
+	Numero                      int          `json:"numero" gorm:"not null"`
+	TipoUbicacion               string       `json:"tipo_ubicacion" gorm:"type:varchar(7)"`
+	IDUbicacion                 string       `json:"id_ubicacion,omitempty" gorm:"column:id_ubicacion;type:varchar(8)"`
+	RFCRemitenteDestinatario    string       `json:"rfc_remitente_destinatario" gorm:"column:rfc_remitente_destinatario;type:varchar(13)"`
+	NombreRemitenteDestinatario string       `json:"nombre_remitente_destinatario,omitempty" gorm:"type:varchar(254)"`
+	FechaHoraSalidaLlegada      *time.Time   `json:"fecha_hora_salida_llegada,omitempty"`
+	DistanciaRecorrida          cfdi.Decimal `json:"distancia_recorrida" gorm:"type:numeric;not null"`
+	Calle                       string       `json:"calle,omitempty" gorm:"type:varchar(100)"`
+	NumeroExterior              string       `json:"numero_exterior,omitempty" gorm:"type:varchar(55)"`
+	Colonia                     string       `json:"colonia,omitempty" gorm:"type:varchar(120)"`
+	Municipio                   string       `json:"municipio,omitempty" gorm:"type:varchar(120)"`
+	Estado                      string       `json:"estado,omitempty" gorm:"type:varchar(30)"`
+	Pais                        string       `json:"pais,omitempty" gorm:"type:varchar(3)"`
+	CodigoPostal                string       `json:"codigo_postal,omitempty" gorm:"type:varchar(12)"`
+}
+
+func (CFDICartaPorteUbicacion) TableName() string {
+	return "cfdis_cartas_porte_ubicaciones"
+}
+
+type CFDICartaPorteMercancia struct {
+	ID                   uuid.UUID    `json:"id" gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	CartaPorteID         uuid.UUID    `json:"-" gorm:"type:uuid;not null"`
+	Numero               int          `json:"numero" gorm:"not null"`
+	BienesTransp         string       `json:"bienes_transp" gorm:"type:varchar(8)"`
+	Descripcion          string       `json:"descripcion" gorm:"type:text"`
+	Cantidad             cfdi.Decimal `json:"cantidad" gorm:"type:numeric;not null"`
+	ClaveUnidad          string       `json:"clave_unidad" gorm:"type:varchar(3)"`
+	MaterialPeligroso    string       `json:"material_peligroso,omitempty" gorm:"type:varchar(2)"`
+	CveMaterialPeligroso string       `json:"cve_material_peligroso,omitempty" gorm:"type:varchar(4)"`
+	Embalaje             string       `json:"embalaje,omitempty" gorm:"type:varchar(4)"`
+	PesoEnKg             cfdi.Decimal `json:"peso_en_kg" gorm:"type:numeric;not null"`
+	ValorMercancia       cfdi.Decimal `json:"valor_mercancia" gorm:"type:numeric;not null"`
+	Moneda               string       `json:"moneda,omitempty" gorm:"type:varchar(3)"`
+}
+
+func (CFDICartaPorteMercancia) TableName() string {
+	return "cfdis_cartas_porte_mercancias"
+}
+
+type CFDICartaPorteRemolque struct {
+	ID           uuid.UUID `json:"id" gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	CartaPorteID uuid.UUID `json:"-" gorm:"type:uuid;not null"`
+	Numero       int       `json:"numero" gorm:"not null"`
+	SubTipoRem   string    `json:"sub_tipo_rem" gorm:"type:varchar(6)"`
+	Placa        string    `json:"placa" gorm:"type:varchar(10)"`
+}
+
+func (CFDICartaPorteRemolque) TableName() string {
+	return "cfdis_cartas_porte_remolques"
+}
+
+// CFDICartaPorteFigura is a person involved in the transport, like the
+// operador of the vehicle or its owner.
+type CFDICartaPorteFigura struct {
+	ID           uuid.UUID `json:"id" gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	CartaPorteID uuid.UUID `json:"-" gorm:"type:uuid;not null"`
+	Numero       int       `json:"numero" gorm:"not null"`
+	TipoFigura   string    `json:"tipo_figura" gorm:"type:varchar(2)"`
+	RFCFigura    string    `json:"rfc_figura,omitempty" gorm:"column:rfc_figura;type:varchar(13)"`
+	NumLicencia  string    `json:"num_licencia,omitempty" gorm:"type:varchar(16)"`
+	NombreFigura string    `json:"nombre_figura" gorm:"type:varchar(254)"`
+}
+
+func (CFDICartaPorteFigura) TableName() string {
+	return "cfdis_cartas_porte_figuras"
+}
+
+// CFDICartaPorteIncidencia is a cfdi.Incidencia found when the complement
+// was indexed.
+type CFDICartaPorteIncidencia struct {
+	ID           uuid.UUID `json:"-" gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	CartaPorteID uuid.UUID `json:"-" gorm:"type:uuid;not null"`
+	Numero       int       `json:"-" gorm:"not null"`
+	Tipo         string    `json:"tipo" gorm:"type:varchar(20);not null"`
+	Nodo         string    `json:"nodo" gorm:"type:varchar(200);not null"`
+	Mensaje      string    `json:"mensaje" gorm:"type:text;not null"`
+}
+
+func (CFDICartaPorteIncidencia) TableName() string {
+	return "cfdis_cartas_porte_incidencias"
+}
@@ -0,0 +1,32 @@
+package model
+
+import (
+	"app/src/cfdi"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CFDIMetadata is what a metadata package says about a CFDI. It is updated
+// by every newer package, so Estatus reflects the last known status.
+type CFDIMetadata struct {
+	UUID               uuid.UUID    `json:"uuid" gorm:"type:uuid;primaryKey"`
+	PaqueteUUID        *uuid.UUID   `json:"-" gorm:"type:uuid"`
+	RFCEmisor          string       `json:"rfc_emisor" gorm:"type:varchar(13);not null"`
+	NombreEmisor       string       `json:"nombre_emisor,omitempty" gorm:"type:varchar(300)"`
+	RFCReceptor        string       `json:"rfc_receptor" gorm:"type:varchar(13);not null"`
+	NombreReceptor     string       `json:"nombre_receptor,omitempty" gorm:"type:varchar(300)"`
+	RFCPac             string       `json:"rfc_pac,omitempty" gorm:"type:varchar(13)"`
+	FechaEmision       time.Time    `json:"fecha_emision" gorm:"not null"`
+	FechaCertificacion *time.Time   `json:"fecha_certificacion,omitempty"`
+	Monto              cfdi.Decimal `json:"monto" gorm:"type:numeric;not null"`
+	EfectoComprobante  string       `json:"efecto_comprobante" gorm:"type:varchar(1);not null"`
+	Estatus            string       `json:"estatus" gorm:"type:varchar(20);not null"`
+	FechaCancelacion   *time.Time   `json:"fecha_cancelacion,omitempty"`
+	CreatedAt          time.Time    `json:"created_at" gorm:"default:CURRENT_TIMESTAMP"`
+	UpdatedAt          time.Time    `json:"updated_at" gorm:"default:CURRENT_TIMESTAMP"`
+}
+
+func (CFDIMetadata) TableName() string {
+	return "cfdis_metadata"
+}
@@ -0,0 +1,119 @@
+package model
+
+import (
+	"app/src/cfdi"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Tipos of the conceptos of a payroll CFDI.
+const (
+	TipoConceptoPercepcion = "percepcion"
+	TipoConceptoDeduccion  = "deduccion"
+	TipoConceptoOtroPago   = "otro_pago"
+)
+
+// CFDINomina is a Nómina complement of an indexed payroll CFDI, with the
+// employee data and the totals the summaries add up. RFCEmpleado and
+// NombreEmpleado come from the CFDI Receptor.
+type CFDINomina struct {
+	ID                     uuid.UUID            `json:"id" gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	CFDIUUID               uuid.UUID            `json:"cfdi_uuid" gorm:"column:cfdi_uuid;type:uuid;not null"`
+	Numero                 int                  `json:"numero" gorm:"not null"`
+	TipoNomina             string               `json:"tipo_nomina" gorm:"type:varchar(1);not null"`
+	FechaPago              time.Time            `json:"fecha_pago" gorm:"type:date;not null"`
+	FechaInicialPago       time.Time            `json:"fecha_inicial_pago" gorm:"type:date;not null"`
+	FechaFinalPago         time.Time            `json:"fecha_final_pago" gorm:"type:date;not null"`
+	NumDiasPagados         cfdi.Decimal         `json:"num_dias_pagados" gorm:"type:numeric;not null"`
+	RegistroPatronal       string               `json:"registro_patronal,omitempty" gorm:"type:varchar(20)"`
+	RFCEmpleado            string               `json:"rfc_empleado" gorm:"column:rfc_empleado;type:varchar(13);not null"`
+	NombreEmpleado         string               `json:"nombre_empleado,omitempty" gorm:"type:varchar(300)"`
+	Curp                   string               `json:"curp" gorm:"type:varchar(18)"`
+	NumSeguridadSocial     string               `json:"num_seguridad_social,omitempty" gorm:"type:varchar(15)"`
+	NumEmpleado            string               `json:"num_empleado" gorm:"type:varchar(15)"`
+	Departamento           string               `json:"departamento,omitempty" gorm:"type:varchar(100)"`
+	Puesto                 string               `json:"puesto,omitempty" gorm:"type:varchar(100)"`
+	TipoContrato           string               `json:"tipo_contrato" gorm:"type:varchar(2)"`
+	TipoRegimen            string               `json:"tipo_regimen" gorm:"type:varchar(2)"`
+	PeriodicidadPago       string               `json:"periodicidad_pago" gorm:"type:varchar(2)"`
+	FechaInicioRelLaboral  *time.Time           `json:"fecha_inicio_rel_laboral,omitempty" gorm:"type:date"`
+	SalarioBaseCotApor     cfdi.Decimal         `json:"salario_base_cot_apor" gorm:"type:numeric;not null"`
+	SalarioDiarioIntegrado cfdi.Decimal         `json:"salario_diario_integrado" gorm:"type:numeric;not null"`
+	ClaveEntFed            string               `json:"clave_ent_fed,omitempty" gorm:"type:varchar(3)"`
+	TotalPercepciones      cfdi.Decimal         `json:"total_percepciones" gorm:"type:numeric;not null"`
+	TotalGravado           cfdi.Decimal         `json:"total_gravado" gorm:"type:numeric;not null"`
+	TotalExento            cfdi.Decimal         `json:"total_exento" gorm:"type:numeric;not null"`
+	TotalDeducciones       cfdi.Decimal         `json:"total_deducciones" gorm:"type:numeric;not null"`
+	TotalOtrosPagos        cfdi.Decimal         `json:"total_otros_pagos" gorm:"type:numeric;not null"`
+	ISRRetenido            cfdi.Decimal         `json:"isr_retenido" gorm:"column:isr_retenido;type:numeric;not null"`
+	SubsidioCausado        cfdi.Decimal         `json:"subsidio_causado" gorm:"type:numeric;not null"`
+	Conceptos              []CFDINominaConcepto `json:"conceptos,omitempty" gorm:"foreignKey:NominaID"`
+}
+
+func (CFDINomina) TableName() string {
+	return "cfdis_nominas"
+}
+
+// CFDINominaConcepto is a percepción, deducción or otro pago of a payroll
+// CFDI. Tipo tells them apart and TipoSAT is the key of c_TipoPercepcion,
+// c_TipoDeduccion or c_TipoOtroPago. Only percepciones split Importe into
+// gravado and exento.
+type CFDINominaConcepto struct {
+	ID             uuid.UUID    `json:"id" gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	NominaID       uuid.UUID    `json:"-" gorm:"type:uuid;not null"`
+	Numero         int          `json:"numero" gorm:"not null"`
+	Tipo           string       `json:"tipo" gorm:"type:varchar(10);not null"`
+	TipoSAT        string       `json:"tipo_sat" gorm:"column:tipo_sat;type:varchar(3);not null"`
+	Clave          string       `json:"clave" gorm:"type:varchar(15)"`
+	Concepto       string       `json:"concepto" gorm:"type:varchar(100)"`
+	ImporteGravado cfdi.Decimal `json:"importe_gravado" gorm:"type:numeric;not null"`
+	ImporteExento  cfdi.Decimal `json:"importe_exento" gorm:"type:numeric;not null"`
+	Importe        cfdi.Decimal `json:"importe" gorm:"type:numeric;not null"`
+}
+
+func (CFDINominaConcepto) TableName() string {
+	return "cfdis_nominas_conceptos"
+}
+
+// ResumenNomina adds up the payroll CFDIs of a period, an employee or a
+// concepto. Neto is what the employees received, percepciones plus otros
+// pagos minus deducciones.
+type ResumenNomina struct {
+	Recibos           int          `json:"recibos"`
+	Empleados         int          `json:"empleados"`
+	TotalPercepciones cfdi.Decimal `json:"total_percepciones"`
+	TotalGravado      cfdi.Decimal `json:"total_gravado"`
+	TotalExento       cfdi.Decimal `json:"total_exento"`
+	TotalDeducciones  cfdi.Decimal `json:"total_deducciones"`
+	TotalOtrosPagos   cfdi.Decimal `json:"total_otros_pagos"`
+	ISRRetenido       cfdi.Decimal `json:"isr_retenido" gorm:"column:isr_retenido"`
+	SubsidioCausado   cfdi.Decimal `json:"subsidio_causado"`
+	Neto              cfdi.Decimal `json:"neto"`
+}
+
+type ResumenNominaPeriodo struct {
+	FechaInicialPago time.Time `json:"fecha_inicial_pago"`
+	FechaFinalPago   time.Time `json:"fecha_final_pago"`
+	ResumenNomina
+}
+
+type ResumenNominaEmpleado struct {
+	RFCEmpleado    string `json:"rfc_empleado" gorm:"column:rfc_empleado"`
+	NombreEmpleado string `json:"nombre_empleado"`
+	Curp           string `json:"curp"`
+	NumEmpleado    string `json:"num_empleado"`
+	ResumenNomina
+}
+
+type ResumenNominaConcepto struct {
+	Tipo           string       `json:"tipo"`
+	TipoSAT        string       `json:"tipo_sat" gorm:"column:tipo_sat"`
+	Clave          string       `json:"clave"`
+	Concepto       string       `json:"concepto"`
+	Recibos        int          `json:"recibos"`
+	Empleados      int          `json:"empleados"`
+	ImporteGravado cfdi.Decimal `json:"importe_gravado"`
+	ImporteExento  cfdi.Decimal `json:"importe_exento"`
+	Importe        cfdi.Decimal `json:"importe"`
+}
@@ -0,0 +1,80 @@
+package model
+
+import (
+	"app/src/cfdi"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CFDIPago is a DoctoRelacionado of a Recepción de Pagos complement, the
+// part of a payment applied to one invoice, along with the Pago it belongs
+// to. IDDocumento is not a foreign key since the invoice may not be
+// indexed.
+type CFDIPago struct {
+	ID               uuid.UUID     `json:"id" gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	CFDIUUID         uuid.UUID     `json:"cfdi_uuid" gorm:"column:cfdi_uuid;type:uuid;not null"`
+	NumeroPago       int           `json:"numero_pago" gorm:"not null"`
+	Numero           int           `json:"numero" gorm:"not null"`
+	FechaPago        time.Time     `json:"fecha_pago" gorm:"not null"`
+	FormaPago        string        `json:"forma_pago,omitempty" gorm:"type:varchar(2)"`
+	MonedaPago       string        `json:"moneda_pago" gorm:"type:varchar(3);not null"`
+	TipoCambioPago   *cfdi.Decimal `json:"tipo_cambio_pago,omitempty" gorm:"type:numeric"`
+	Monto            cfdi.Decimal  `json:"monto" gorm:"type:numeric;not null"`
+	NumOperacion     string        `json:"num_operacion,omitempty" gorm:"type:varchar(100)"`
+	IDDocumento      uuid.UUID     `json:"id_documento" gorm:"column:id_documento;type:uuid;not null"`
+	Serie            string        `json:"serie,omitempty" gorm:"type:varchar(25)"`
+	Folio            string        `json:"folio,omitempty" gorm:"type:varchar(40)"`
+	MonedaDR         string        `json:"moneda_dr" gorm:"column:moneda_dr;type:varchar(3);not null"`
+	EquivalenciaDR   *cfdi.Decimal `json:"equivalencia_dr,omitempty" gorm:"column:equivalencia_dr;type:numeric"`
+	NumParcialidad   int           `json:"num_parcialidad"`
+	ImpSaldoAnt      cfdi.Decimal  `json:"imp_saldo_ant" gorm:"type:numeric;not null"`
+	ImpPagado        cfdi.Decimal  `json:"imp_pagado" gorm:"type:numeric;not null"`
+	ImpSaldoInsoluto cfdi.Decimal  `json:"imp_saldo_insoluto" gorm:"type:numeric;not null"`
+}
+
+func (CFDIPago) TableName() string {
+	return "cfdis_pagos"
+}
+
+// Aging buckets of the outstanding balances, by days since the invoice was
+// issued.
+const (
+	RangoDe0a30  = "de_0_a_30"
+	RangoDe31a60 = "de_31_a_60"
+	RangoDe61a90 = "de_61_a_90"
+	RangoMasDe90 = "mas_de_90"
+)
+
+// SaldoPendiente is an unpaid PPD invoice with the payments applied to it by
+// the vigente payment complements of its emisor, aged at the cut-off date.
+type SaldoPendiente struct {
+	UUID           uuid.UUID    `json:"uuid"`
+	Serie          string       `json:"serie,omitempty"`
+	Folio          string       `json:"folio,omitempty"`
+	Fecha          time.Time    `json:"fecha"`
+	RFCEmisor      string       `json:"rfc_emisor"`
+	NombreEmisor   string       `json:"nombre_emisor,omitempty"`
+	RFCReceptor    string       `json:"rfc_receptor"`
+	NombreReceptor string       `json:"nombre_receptor,omitempty"`
+	Moneda         string       `json:"moneda"`
+	Total          cfdi.Decimal `json:"total"`
+	Pagado         cfdi.Decimal `json:"pagado"`
+	Saldo          cfdi.Decimal `json:"saldo"`
+	Parcialidades  int          `json:"parcialidades"`
+	UltimoPago     *time.Time   `json:"ultimo_pago,omitempty"`
+	Dias           int          `json:"dias"`
+	Rango          string       `json:"rango"`
+}
+
+// TotalAntiguedad adds up the outstanding balances of one currency by aging
+// bucket. Amounts of different currencies are never added.
+type TotalAntiguedad struct {
+	Moneda     string       `json:"moneda"`
+	De0a30     cfdi.Decimal `json:"de_0_a_30" gorm:"column:de_0_a_30"`
+	De31a60    cfdi.Decimal `json:"de_31_a_60" gorm:"column:de_31_a_60"`
+	De61a90    cfdi.Decimal `json:"de_61_a_90" gorm:"column:de_61_a_90"`
+	MasDe90    cfdi.Decimal `json:"mas_de_90" gorm:"column:mas_de_90"`
+	Total      cfdi.Decimal `json:"total"`
+	Documentos int          `json:"documentos"`
+}
@@ -1,26 +1,32 @@
 package model
 
 import (
-	"time"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"time"
 )
 
 type DatosFiscalesSAT struct {
-	UUID                  uuid.UUID      `json:"uuid" gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
-	UserID                uuid.UUID      `json:"user_id" gorm:"type:uuid;not null"`
-	User                  User           `json:"user,omitempty" gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
-	RFC                   string         `json:"rfc" gorm:"type:varchar(13);not null"`
-	CerB64Encriptado      string         `json:"-" gorm:"type:text;not null"` // No exponer en JSON
-	KeyB64Encriptado      string         `json:"-" gorm:"type:text;not null"` // No exponer en JSON  
-	PasswordEfirmaEncrip  string         `json:"-" gorm:"type:varchar(255);not null"` // No exponer en JSON
-	CreatedAt             time.Time      `json:"created_at" gorm:"default:CURRENT_TIMESTAMP"`
-	UpdatedAt             time.Time      `json:"updated_at" gorm:"default:CURRENT_TIMESTAMP"`
-	CreatedBy             *uuid.UUID     `json:"created_by,omitempty" gorm:"type:uuid"`
-	UpdatedBy             *uuid.UUID     `json:"updated_by,omitempty" gorm:"type:uuid"`
-	DeletedAt             gorm.DeletedAt `json:"-" gorm:"index"`
+	UUID                 uuid.UUID      `json:"uuid" gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	UserID               uuid.UUID      `json:"user_id" gorm:"type:uuid;not null"`
+	User                 User           `json:"user,omitempty" gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+	RFC                  string         `json:"rfc" gorm:"type:varchar(13);not null"`
+	CerB64Encriptado     string         `json:"-" gorm:"type:text;not null"`         // No exponer en JSON
+	KeyB64Encriptado     string         `json:"-" gorm:"type:text;not null"`         // No exponer en JSON
+	PasswordEfirmaEncrip string         `json:"-" gorm:"type:varchar(255);not null"` // No exponer en JSON
+	DataKeyEncriptada    string         `json:"-" gorm:"type:text"`                  // No exponer en JSON
+	KeyVersion           int            `json:"-" gorm:"not null;default:1"`
+	NoCertificado        string         `json:"no_certificado" gorm:"type:varchar(64);not null"`
+	EmisorCertificado    string         `json:"emisor_certificado" gorm:"type:varchar(255);not null"`
+	ValidoDesde          time.Time      `json:"valido_desde" gorm:"not null"`
+	ValidoHasta          time.Time      `json:"valido_hasta" gorm:"not null"`
+	CreatedAt            time.Time      `json:"created_at" gorm:"default:CURRENT_TIMESTAMP"`
+	UpdatedAt            time.Time      `json:"updated_at" gorm:"default:CURRENT_TIMESTAMP"`
+	CreatedBy            *uuid.UUID     `json:"created_by,omitempty" gorm:"type:uuid"`
+	UpdatedBy            *uuid.UUID     `json:"updated_by,omitempty" gorm:"type:uuid"`
+	DeletedAt            gorm.DeletedAt `json:"-" gorm:"index"`
 }
 
 func (DatosFiscalesSAT) TableName() string {
 	return "datos_fiscales_sat"
-}
\ No newline at end of file
+}
@@ -0,0 +1,22 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type HistorialCertificadoFiscal struct {
+	UUID              uuid.UUID  `json:"uuid" gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	DatosFiscalesUUID uuid.UUID  `json:"datos_fiscales_uuid" gorm:"type:uuid;not null"`
+	NoCertificado     string     `json:"no_certificado" gorm:"type:varchar(64);not null"`
+	EmisorCertificado string     `json:"emisor_certificado" gorm:"type:varchar(255);not null"`
+	ValidoDesde       time.Time  `json:"valido_desde" gorm:"not null"`
+	ValidoHasta       time.Time  `json:"valido_hasta" gorm:"not null"`
+	ReemplazadoAt     time.Time  `json:"reemplazado_at" gorm:"not null"`
+	ReemplazadoBy     *uuid.UUID `json:"reemplazado_by,omitempty" gorm:"type:uuid"`
+}
+
+func (HistorialCertificadoFiscal) TableName() string {
+	return "historial_certificados_fiscales"
+}
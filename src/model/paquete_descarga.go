@@ -0,0 +1,38 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	EstadoPaquetePendiente  = "pendiente"
+	EstadoPaqueteDescargado = "descargado"
+	EstadoPaqueteError      = "error"
+)
+
+type PaqueteDescarga struct {
+	UUID               uuid.UUID          `json:"uuid" gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	SolicitudUUID      uuid.UUID          `json:"solicitud_uuid" gorm:"type:uuid;not null"`
+	Solicitud          *SolicitudDescarga `json:"-" gorm:"foreignKey:SolicitudUUID"`
+	IDPaquete          string             `json:"id_paquete" gorm:"type:varchar(128);not null"`
+	Estado             string             `json:"estado" gorm:"type:varchar(20);not null"`
+	TamanoBytes        int64              `json:"tamano_bytes" gorm:"not null;default:0"`
+	SHA256             string             `json:"sha256,omitempty" gorm:"column:sha256;type:varchar(64)"`
+	StorageKey         string             `json:"-" gorm:"type:text"`
+	NumeroArchivos     int                `json:"numero_archivos" gorm:"not null;default:0"`
+	Intentos           int                `json:"intentos" gorm:"not null;default:0"`
+	Mensaje            string             `json:"mensaje,omitempty" gorm:"type:text"`
+	DescargadoAt       *time.Time         `json:"descargado_at,omitempty"`
+	CFDIsIndexados     int                `json:"cfdis_indexados" gorm:"column:cfdis_indexados;not null;default:0"`
+	IndexadoAt         *time.Time         `json:"indexado_at,omitempty"`
+	IntentosIndexacion int                `json:"intentos_indexacion" gorm:"not null;default:0"`
+	ErrorIndexacion    string             `json:"error_indexacion,omitempty" gorm:"type:text"`
+	CreatedAt          time.Time          `json:"created_at" gorm:"default:CURRENT_TIMESTAMP"`
+	UpdatedAt          time.Time          `json:"updated_at" gorm:"default:CURRENT_TIMESTAMP"`
+}
+
+func (PaqueteDescarga) TableName() string {
+	return "solicitudes_descarga_paquetes"
+}
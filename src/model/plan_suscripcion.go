@@ -0,0 +1,25 @@
+package model
+
+import (
+	"app/src/cfdi"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type PlanSuscripcion struct {
+	ID                       uuid.UUID      `json:"id" gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	Nombre                   string         `json:"nombre" gorm:"type:varchar;not null"`
+	Descripcion              string         `json:"descripcion" gorm:"type:varchar;not null"`
+	LimiteDescargasMensuales int            `json:"limite_descargas_mensuales" gorm:"not null"`
+	Precio                   cfdi.Decimal   `json:"precio" gorm:"type:decimal(10,2);not null"`
+	Activo                   bool           `json:"activo" gorm:"not null;default:false"`
+	CreatedAt                time.Time      `json:"created_at"`
+	UpdatedAt                time.Time      `json:"updated_at"`
+	DeletedAt                gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+func (PlanSuscripcion) TableName() string {
+	return "planes_suscripcion"
+}
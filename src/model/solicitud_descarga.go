@@ -0,0 +1,52 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Estados of a solicitud de descarga, following the EstadoSolicitud values
+// reported by the SAT verification service.
+const (
+	EstadoSolicitudAceptada  = "aceptada"
+	EstadoSolicitudEnProceso = "en_proceso"
+	EstadoSolicitudTerminada = "terminada"
+	EstadoSolicitudError     = "error"
+	EstadoSolicitudRechazada = "rechazada"
+	EstadoSolicitudVencida   = "vencida"
+)
+
+type SolicitudDescarga struct {
+	UUID              uuid.UUID         `json:"uuid" gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	UserID            uuid.UUID         `json:"user_id" gorm:"type:uuid;not null"`
+	DatosFiscalesUUID uuid.UUID         `json:"datos_fiscales_uuid" gorm:"type:uuid;not null"`
+	RFCSolicitante    string            `json:"rfc_solicitante" gorm:"type:varchar(13);not null"`
+	IDSolicitud       *string           `json:"id_solicitud" gorm:"type:varchar(64)"`
+	TipoDescarga      string            `json:"tipo_descarga" gorm:"type:varchar(10);not null"`
+	TipoSolicitud     string            `json:"tipo_solicitud" gorm:"type:varchar(10);not null"`
+	FechaInicial      time.Time         `json:"fecha_inicial" gorm:"not null"`
+	FechaFinal        time.Time         `json:"fecha_final" gorm:"not null"`
+	TipoComprobante   string            `json:"tipo_comprobante,omitempty" gorm:"type:varchar(1)"`
+	EstadoComprobante string            `json:"estado_comprobante,omitempty" gorm:"type:varchar(10)"`
+	RFCContraparte    string            `json:"rfc_contraparte,omitempty" gorm:"type:varchar(13)"`
+	CodEstatus        string            `json:"cod_estatus" gorm:"type:varchar(10);not null"`
+	Mensaje           string            `json:"mensaje" gorm:"type:text"`
+	Estado            string            `json:"estado" gorm:"type:varchar(20);not null"`
+	CodigoEstado      string            `json:"codigo_estado_solicitud,omitempty" gorm:"column:codigo_estado_solicitud;type:varchar(10)"`
+	NumeroCFDIs       int               `json:"numero_cfdis" gorm:"column:numero_cfdis;not null;default:0"`
+	VerificadaAt      *time.Time        `json:"verificada_at,omitempty"`
+	Paquetes          []PaqueteDescarga `json:"paquetes,omitempty" gorm:"foreignKey:SolicitudUUID"`
+	CreatedAt         time.Time         `json:"created_at" gorm:"default:CURRENT_TIMESTAMP"`
+	UpdatedAt         time.Time         `json:"updated_at" gorm:"default:CURRENT_TIMESTAMP"`
+	CreatedBy         *uuid.UUID        `json:"created_by,omitempty" gorm:"type:uuid"`
+}
+
+func (SolicitudDescarga) TableName() string {
+	return "solicitudes_descarga"
+}
+
+// Pendiente reports whether the SAT is still preparing the packages.
+func (s *SolicitudDescarga) Pendiente() bool {
+	return s.Estado == EstadoSolicitudAceptada || s.Estado == EstadoSolicitudEnProceso
+}
@@ -0,0 +1,107 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Estatus of a suscripción de usuario.
+const (
+	EstatusSuscripcionActivo    = "activo"
+	EstatusSuscripcionCancelado = "cancelado"
+	EstatusSuscripcionExpirado  = "expirado"
+)
+
+// SuscripcionUsuario keeps its own copy of the plan's monthly limit so plan
+// changes don't affect subscriptions already sold. DescargasUsadas counts the
+// download requests accepted since PeriodoInicio.
+type SuscripcionUsuario struct {
+	ID                       uuid.UUID        `json:"id" gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	UsuarioID                uuid.UUID        `json:"usuario_id" gorm:"type:uuid"`
+	Usuario                  *User            `json:"-" gorm:"foreignKey:UsuarioID"`
+	PlanID                   uuid.UUID        `json:"plan_id" gorm:"type:uuid"`
+	Plan                     *PlanSuscripcion `json:"plan,omitempty" gorm:"foreignKey:PlanID"`
+	LimiteDescargasMensuales int              `json:"limite_descargas_mensuales" gorm:"not null"`
+	DescargasUsadas          int              `json:"descargas_usadas" gorm:"not null;default:0"`
+	PeriodoInicio            time.Time        `json:"periodo_inicio" gorm:"type:date;not null"`
+	FechaInicio              time.Time        `json:"fecha_inicio" gorm:"type:date;not null"`
+	FechaFin                 time.Time        `json:"fecha_fin" gorm:"type:date;not null"`
+	Estatus                  string           `json:"estatus" gorm:"type:varchar(20);not null"`
+	CanceladaAt              *time.Time       `json:"cancelada_at,omitempty"`
+	AvisoVencimientoAt       *time.Time       `json:"-"`
+	CreatedAt                time.Time        `json:"created_at"`
+	UpdatedAt                time.Time        `json:"updated_at"`
+}
+
+func (SuscripcionUsuario) TableName() string {
+	return "suscripciones_usuario"
+}
+
+// DescargasRestantes is the number of download requests left in the current
+// monthly period.
+func (s *SuscripcionUsuario) DescargasRestantes() int {
+	return max(s.LimiteDescargasMensuales-s.DescargasUsadas, 0)
+}
+
+// PeriodoActual returns the start of the monthly period containing now.
+// Periods are anchored to FechaInicio, so a subscription started on the 31st
+// renews on the last day of shorter months.
+func (s *SuscripcionUsuario) PeriodoActual(now time.Time) time.Time {
+	today := truncateDate(now)
+
+	inicio := truncateDate(s.FechaInicio)
+	for months := 1; ; months++ {
+		next := addMonths(truncateDate(s.FechaInicio), months)
+		if next.After(today) {
+			return inicio
+		}
+		inicio = next
+	}
+}
+
+// ProximoReinicio returns the date the monthly counter resets.
+func (s *SuscripcionUsuario) ProximoReinicio(now time.Time) time.Time {
+	return addMonths(truncateDate(s.FechaInicio), s.mesesHasta(now)+1)
+}
+
+// Extender adds months to the subscription, keeping the periods anchored to
+// FechaInicio.
+func (s *SuscripcionUsuario) Extender(meses int) {
+	s.FechaFin = FinVigencia(s.FechaInicio, s.mesesHasta(s.FechaFin.AddDate(0, 0, 1))+meses)
+}
+
+// mesesHasta counts the monthly periods between FechaInicio and the period
+// containing t.
+func (s *SuscripcionUsuario) mesesHasta(t time.Time) int {
+	inicio := truncateDate(s.FechaInicio)
+	periodo := s.PeriodoActual(t)
+
+	return (periodo.Year()-inicio.Year())*12 + int(periodo.Month()-inicio.Month())
+}
+
+// Vigente reports whether the subscription is active on now's date.
+func (s *SuscripcionUsuario) Vigente(now time.Time) bool {
+	today := truncateDate(now)
+	return s.Estatus == EstatusSuscripcionActivo &&
+		!today.Before(truncateDate(s.FechaInicio)) && !today.After(truncateDate(s.FechaFin))
+}
+
+// FinVigencia returns the last day of a subscription of meses months starting
+// on inicio.
+func FinVigencia(inicio time.Time, meses int) time.Time {
+	return addMonths(truncateDate(inicio), meses).AddDate(0, 0, -1)
+}
+
+func truncateDate(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// addMonths adds months to a date clamping the day to the end of the month,
+// unlike time.AddDate which turns Jan 31 + 1 month into Mar 3.
+func addMonths(t time.Time, months int) time.Time {
+	firstOfMonth := time.Date(t.Year(), t.Month()+time.Month(months), 1, 0, 0, 0, 0, time.UTC)
+	lastDay := firstOfMonth.AddDate(0, 1, -1).Day()
+
+	return firstOfMonth.AddDate(0, 0, min(t.Day(), lastDay)-1)
+}
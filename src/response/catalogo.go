@@ -0,0 +1,12 @@
+package response
+
+import "time"
+
+// Catalogo is a supported SAT catalog with its newest imported publication,
+// FechaPublicacion is empty until one is imported.
+type Catalogo struct {
+	Nombre           string     `json:"nombre"`
+	Slug             string     `json:"slug"`
+	FechaPublicacion *time.Time `json:"fecha_publicacion"`
+	Registros        int        `json:"registros"`
+}
@@ -0,0 +1,10 @@
+package response
+
+import "app/src/model"
+
+// Conciliacion lists the differences between the metadata and the XMLs
+// downloaded for the same RFCs.
+type Conciliacion struct {
+	SinXML     []model.CFDIMetadata `json:"sin_xml"`
+	Cancelados []model.CFDI         `json:"cancelados"`
+}
@@ -0,0 +1,54 @@
+package response
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// CSV sends the records as a CSV attachment named nombre, with a header
+// row. It starts with a UTF-8 BOM so spreadsheets read the accents right,
+// and cells that a spreadsheet would run as a formula are escaped.
+func CSV(c *fiber.Ctx, nombre string, header []string, records [][]string) error {
+	var buf bytes.Buffer
+	buf.WriteString("\uFEFF")
+
+	w := csv.NewWriter(&buf)
+	if err := w.Write(header); err != nil {
+		return err
+	}
+	for _, record := range records {
+		celdas := make([]string, len(record))
+		for i, celda := range record {
+			celdas[i] = escaparFormula(celda)
+		}
+
+		if err := w.Write(celdas); err != nil {
+			return err
+		}
+	}
+
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return err
+	}
+
+	c.Set(fiber.HeaderContentType, "text/csv; charset=utf-8")
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="%s"`, nombre))
+
+	return c.Status(fiber.StatusOK).Send(buf.Bytes())
+}
+
+// escaparFormula prefixes with an apostrophe the cells starting with a
+// character spreadsheets take as the start of a formula. Names and
+// descriptions come from the CFDIs, which anyone can issue to the user.
+func escaparFormula(celda string) string {
+	if celda != "" && strings.ContainsRune("=+-@\t\r", rune(celda[0])) {
+		return "'" + celda
+	}
+
+	return celda
+}
@@ -0,0 +1,15 @@
+package response
+
+import (
+	"app/src/sat"
+
+	"github.com/google/uuid"
+)
+
+// EstadoCFDI is the stored estado of a CFDI along with the answer of the SAT
+// it was derived from.
+type EstadoCFDI struct {
+	UUID   uuid.UUID           `json:"uuid"`
+	Estado string              `json:"estado"`
+	SAT    *sat.ConsultaResult `json:"sat"`
+}
@@ -0,0 +1,88 @@
+package response
+
+import (
+	"app/src/model"
+	"strconv"
+	"time"
+)
+
+var columnasResumenNomina = []string{
+	"recibos", "empleados", "total_percepciones", "total_gravado", "total_exento", "total_deducciones",
+	"total_otros_pagos", "isr_retenido", "subsidio_causado", "neto",
+}
+
+func resumenNominaRecord(resumen *model.ResumenNomina) []string {
+	return []string{
+		strconv.Itoa(resumen.Recibos),
+		strconv.Itoa(resumen.Empleados),
+		resumen.TotalPercepciones.String(),
+		resumen.TotalGravado.String(),
+		resumen.TotalExento.String(),
+		resumen.TotalDeducciones.String(),
+		resumen.TotalOtrosPagos.String(),
+		resumen.ISRRetenido.String(),
+		resumen.SubsidioCausado.String(),
+		resumen.Neto.String(),
+	}
+}
+
+// NominaPeriodosCSV is the header and the rows of the payroll summary by
+// period.
+func NominaPeriodosCSV(resumen []model.ResumenNominaPeriodo) ([]string, [][]string) {
+	header := append([]string{"fecha_inicial_pago", "fecha_final_pago"}, columnasResumenNomina...)
+
+	records := make([][]string, 0, len(resumen))
+	for i := range resumen {
+		periodo := &resumen[i]
+		records = append(records, append([]string{
+			periodo.FechaInicialPago.Format(time.DateOnly),
+			periodo.FechaFinalPago.Format(time.DateOnly),
+		}, resumenNominaRecord(&periodo.ResumenNomina)...))
+	}
+
+	return header, records
+}
+
+// NominaEmpleadosCSV is the header and the rows of the payroll summary by
+// employee.
+func NominaEmpleadosCSV(resumen []model.ResumenNominaEmpleado) ([]string, [][]string) {
+	header := append([]string{"rfc_empleado", "nombre_empleado", "curp", "num_empleado"}, columnasResumenNomina...)
+
+	records := make([][]string, 0, len(resumen))
+	for i := range resumen {
+		empleado := &resumen[i]
+		records = append(records, append([]string{
+			empleado.RFCEmpleado,
+			empleado.NombreEmpleado,
+			empleado.Curp,
+			empleado.NumEmpleado,
+		}, resumenNominaRecord(&empleado.ResumenNomina)...))
+	}
+
+	return header, records
+}
+
+// NominaConceptosCSV is the header and the rows of the payroll summary by
+// concepto.
+func NominaConceptosCSV(resumen []model.ResumenNominaConcepto) ([]string, [][]string) {
+	header := []string{
+		"tipo", "tipo_sat", "clave", "concepto", "recibos", "empleados", "importe_gravado", "importe_exento", "importe",
+	}
+
+	records := make([][]string, 0, len(resumen))
+	for _, concepto := range resumen {
+		records = append(records, []string{
+			concepto.Tipo,
+			concepto.TipoSAT,
+			concepto.Clave,
+			concepto.Concepto,
+			strconv.Itoa(concepto.Recibos),
+			strconv.Itoa(concepto.Empleados),
+			concepto.ImporteGravado.String(),
+			concepto.ImporteExento.String(),
+			concepto.Importe.String(),
+		})
+	}
+
+	return header, records
+}
@@ -0,0 +1,62 @@
+package response
+
+import (
+	"app/src/cfdi"
+	"app/src/model"
+
+	"github.com/google/uuid"
+)
+
+// SaldoCFDI is the balance of an invoice after the payments applied to it.
+// PUE invoices are paid when issued, so they never have an outstanding
+// balance.
+type SaldoCFDI struct {
+	UUID       uuid.UUID        `json:"uuid"`
+	Serie      string           `json:"serie,omitempty"`
+	Folio      string           `json:"folio,omitempty"`
+	MetodoPago string           `json:"metodo_pago,omitempty"`
+	Moneda     string           `json:"moneda"`
+	Total      cfdi.Decimal     `json:"total"`
+	Pagado     cfdi.Decimal     `json:"pagado"`
+	Saldo      cfdi.Decimal     `json:"saldo"`
+	Pagos      []model.CFDIPago `json:"pagos"`
+}
+
+func NewSaldoCFDI(registro *model.CFDI, pagos []model.CFDIPago) SaldoCFDI {
+	pagado := cfdi.Decimal{}
+	for _, pago := range pagos {
+		pagado = pagado.Add(pago.ImpPagado)
+	}
+
+	if registro.MetodoPago != cfdi.MetodoPagoPPD {
+		pagado = registro.Total
+	}
+
+	if pagos == nil {
+		pagos = []model.CFDIPago{}
+	}
+
+	return SaldoCFDI{
+		UUID:       registro.UUID,
+		Serie:      registro.Serie,
+		Folio:      registro.Folio,
+		MetodoPago: registro.MetodoPago,
+		Moneda:     registro.Moneda,
+		Total:      registro.Total,
+		Pagado:     pagado,
+		Saldo:      registro.Total.Sub(pagado),
+		Pagos:      pagos,
+	}
+}
+
+// AntiguedadSaldos is the aging report of the unpaid PPD invoices. Totales
+// has a row per currency and covers every page of Documentos.
+type AntiguedadSaldos struct {
+	FechaCorte   string                  `json:"fecha_corte"`
+	Totales      []model.TotalAntiguedad `json:"totales"`
+	Documentos   []model.SaldoPendiente  `json:"documentos"`
+	Page         int                     `json:"page"`
+	Limit        int                     `json:"limit"`
+	TotalPages   int64                   `json:"total_pages"`
+	TotalResults int64                   `json:"total_results"`
+}
@@ -0,0 +1,26 @@
+package response
+
+import (
+	"app/src/cfdi"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ValidacionReceptor is the result of checking the Receptor of a CFDI 4.0.
+// CatalogosFaltantes lists the SAT catalogs that have not been imported,
+// the rules that depend on them were skipped.
+type ValidacionReceptor struct {
+	UUID                    *uuid.UUID        `json:"uuid,omitempty"`
+	Fecha                   time.Time         `json:"fecha"`
+	TipoComprobante         string            `json:"tipo_comprobante"`
+	RFCEmisor               string            `json:"rfc_emisor"`
+	RFCReceptor             string            `json:"rfc_receptor"`
+	NombreReceptor          string            `json:"nombre_receptor,omitempty"`
+	RegimenFiscalReceptor   string            `json:"regimen_fiscal_receptor,omitempty"`
+	DomicilioFiscalReceptor string            `json:"domicilio_fiscal_receptor,omitempty"`
+	UsoCFDI                 string            `json:"uso_cfdi,omitempty"`
+	Valido                  bool              `json:"valido"`
+	Incidencias             []cfdi.Incidencia `json:"incidencias"`
+	CatalogosFaltantes      []string          `json:"catalogos_faltantes,omitempty"`
+}
@@ -0,0 +1,30 @@
+package response
+
+import (
+	"app/src/model"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type Suscripcion struct {
+	model.SuscripcionUsuario
+	DescargasRestantes int    `json:"descargas_restantes"`
+	ProximoReinicio    string `json:"proximo_reinicio"`
+}
+
+func NewSuscripcion(suscripcion *model.SuscripcionUsuario) Suscripcion {
+	return Suscripcion{
+		SuscripcionUsuario: *suscripcion,
+		DescargasRestantes: suscripcion.DescargasRestantes(),
+		ProximoReinicio:    suscripcion.ProximoReinicio(time.Now()).Format(time.DateOnly),
+	}
+}
+
+// QuotaHeaders reports the monthly download quota of a subscription.
+func QuotaHeaders(c *fiber.Ctx, suscripcion *model.SuscripcionUsuario) {
+	c.Set("X-Quota-Limit", strconv.Itoa(suscripcion.LimiteDescargasMensuales))
+	c.Set("X-Quota-Remaining", strconv.Itoa(suscripcion.DescargasRestantes()))
+	c.Set("X-Quota-Reset", suscripcion.ProximoReinicio(time.Now()).Format(time.DateOnly))
+}
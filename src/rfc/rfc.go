@@ -0,0 +1,127 @@
+package rfc
+
+import (
+	"errors"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	PersonaFisicaLength = 13
+	PersonaMoralLength  = 12
+
+	// GenericoNacional and GenericoExtranjero are the RFCs the SAT assigns
+	// to público en general and to foreign residents.
+	GenericoNacional   = "XAXX010101000"
+	GenericoExtranjero = "XEXX010101000"
+)
+
+var (
+	ErrLength     = errors.New("RFC must have 12 characters for personas morales or 13 for personas físicas")
+	ErrName       = errors.New("RFC name segment contains invalid characters")
+	ErrDate       = errors.New("RFC date segment is not a valid date")
+	ErrHomoclave  = errors.New("RFC homoclave contains invalid characters")
+	ErrCheckDigit = errors.New("RFC check digit is invalid")
+)
+
+const (
+	nameChars      = "ABCDEFGHIJKLMNÑOPQRSTUVWXYZ&"
+	homoclaveChars = "123456789ABCDEFGHIJKLMNPQRSTUVWXYZ"
+)
+
+// checkDigitChars maps every character to its value in the SAT check digit
+// algorithm, which is its position in this slice.
+var checkDigitChars = []rune("0123456789ABCDEFGHIJKLMN&OPQRSTUVWXYZ Ñ")
+
+// IsGeneric reports whether the RFC is one of the generic RFCs.
+func IsGeneric(valor string) bool {
+	valor = strings.ToUpper(strings.TrimSpace(valor))
+	return valor == GenericoNacional || valor == GenericoExtranjero
+}
+
+// IsPersonaMoral reports whether a valid RFC belongs to a persona moral.
+func IsPersonaMoral(valor string) bool {
+	return utf8.RuneCountInString(strings.TrimSpace(valor)) == PersonaMoralLength
+}
+
+// Check validates the structure of an RFC: name segment (3 letters for
+// personas morales, 4 for personas físicas), date of birth or incorporation
+// as YYMMDD, a two character homoclave and the check digit.
+func Check(valor string) error {
+	valor = strings.ToUpper(strings.TrimSpace(valor))
+	if IsGeneric(valor) {
+		return nil
+	}
+
+	runes := []rune(valor)
+	if len(runes) != PersonaFisicaLength && len(runes) != PersonaMoralLength {
+		return ErrLength
+	}
+
+	nameLength := len(runes) - 9
+	for _, r := range runes[:nameLength] {
+		if !strings.ContainsRune(nameChars, r) {
+			return ErrName
+		}
+	}
+
+	if !validDate(string(runes[nameLength : nameLength+6])) {
+		return ErrDate
+	}
+
+	for _, r := range runes[nameLength+6 : nameLength+8] {
+		if !strings.ContainsRune(homoclaveChars, r) {
+			return ErrHomoclave
+		}
+	}
+
+	if runes[len(runes)-1] != checkDigit(runes[:len(runes)-1]) {
+		return ErrCheckDigit
+	}
+
+	return nil
+}
+
+// validDate accepts the YYMMDD segment when it is a calendar date in either
+// the 1900s or the 2000s, so 000229 is valid because 2000 is leap.
+func validDate(segment string) bool {
+	for _, century := range []string{"19", "20"} {
+		if _, err := time.Parse("20060102", century+segment); err == nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+// checkDigit computes the check digit of the first 11 (moral) or 12
+// (física) characters. Personas morales are padded with a leading space so
+// both have the same weights.
+func checkDigit(base []rune) rune {
+	if len(base) == PersonaMoralLength-1 {
+		base = append([]rune{' '}, base...)
+	}
+
+	sum := 0
+	for i, r := range base {
+		value := 0
+		for j, c := range checkDigitChars {
+			if c == r {
+				value = j
+				break
+			}
+		}
+		sum += value * (len(base) + 1 - i)
+	}
+
+	remainder := sum % 11
+	switch remainder {
+	case 0:
+		return '0'
+	case 1:
+		return 'A'
+	default:
+		return rune('0' + 11 - remainder)
+	}
+}
@@ -0,0 +1,21 @@
+package router
+
+import (
+	"app/src/controller"
+	m "app/src/middleware"
+	"app/src/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func CatalogoRoutes(v1 fiber.Router, c service.CatalogoService, u service.UserService) {
+	catalogoController := controller.NewCatalogoController(c)
+
+	catalogos := v1.Group("/catalogos")
+
+	catalogos.Use(m.Auth(u))
+
+	catalogos.Get("/", catalogoController.GetCatalogos)
+	catalogos.Get("/:catalogo", catalogoController.BuscarRegistros)
+	catalogos.Get("/:catalogo/:clave", catalogoController.GetRegistro)
+}
@@ -0,0 +1,32 @@
+package router
+
+import (
+	"app/src/controller"
+	m "app/src/middleware"
+	"app/src/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func CFDIRoutes(
+	v1 fiber.Router, c service.CFDIService, e service.EstadoCFDIService, p service.PagoService,
+	cp service.CartaPorteService, r service.ReceptorService, u service.UserService,
+) {
+	cfdiController := controller.NewCFDIController(c, e)
+	pagoController := controller.NewPagoController(p)
+	cartaPorteController := controller.NewCartaPorteController(cp)
+	receptorController := controller.NewReceptorController(r)
+
+	cfdis := v1.Group("/cfdis")
+
+	cfdis.Use(m.Auth(u))
+
+	cfdis.Get("/", cfdiController.GetCFDIs)
+	cfdis.Get("/conciliacion", cfdiController.GetConciliacion)
+	cfdis.Get("/antiguedad-saldos", pagoController.GetAntiguedadSaldos)
+	cfdis.Get("/validacion-receptor", receptorController.GetValidaciones)
+	cfdis.Post("/validacion-receptor", receptorController.ValidarXML)
+	cfdis.Get("/:uuid/estado", cfdiController.GetEstado)
+	cfdis.Get("/:uuid/pagos", pagoController.GetPagos)
+	cfdis.Get("/:uuid/carta-porte", cartaPorteController.GetCartaPorte)
+}
@@ -17,6 +17,9 @@ func DatosFiscalesRoutes(v1 fiber.Router, d service.DatosFiscalesService, u serv
 
 	datosFiscales.Post("/", datosFiscalesController.CreateDatosFiscales)
 	datosFiscales.Get("/", datosFiscalesController.GetDatosFiscales)
-	datosFiscales.Patch("/", datosFiscalesController.UpdateDatosFiscales)
-	datosFiscales.Delete("/", datosFiscalesController.DeleteDatosFiscales)
-}
\ No newline at end of file
+	datosFiscales.Get("/:rfcId", datosFiscalesController.GetDatosFiscalesByID)
+	datosFiscales.Patch("/:rfcId", datosFiscalesController.UpdateDatosFiscales)
+	datosFiscales.Delete("/:rfcId", datosFiscalesController.DeleteDatosFiscales)
+	datosFiscales.Put("/:rfcId/efirma", datosFiscalesController.RenovarEfirma)
+	datosFiscales.Get("/:rfcId/historial", datosFiscalesController.GetHistorialCertificados)
+}
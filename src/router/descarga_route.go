@@ -0,0 +1,20 @@
+package router
+
+import (
+	"app/src/controller"
+	m "app/src/middleware"
+	"app/src/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func DescargaRoutes(v1 fiber.Router, d service.DescargaService, s service.SubscriptionService, u service.UserService) {
+	descargaController := controller.NewDescargaController(d)
+
+	descargas := v1.Group("/descargas")
+
+	descargas.Use(m.Auth(u))
+
+	descargas.Post("/", m.Quota(s), descargaController.SolicitarDescarga)
+	descargas.Get("/:descargaId/paquetes", descargaController.GetPaquetes)
+}
@@ -0,0 +1,21 @@
+package router
+
+import (
+	"app/src/controller"
+	m "app/src/middleware"
+	"app/src/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func NominaRoutes(v1 fiber.Router, n service.NominaService, u service.UserService) {
+	nominaController := controller.NewNominaController(n)
+
+	nomina := v1.Group("/nomina")
+
+	nomina.Use(m.Auth(u))
+
+	nomina.Get("/periodos", nominaController.GetResumenPeriodos)
+	nomina.Get("/empleados", nominaController.GetResumenEmpleados)
+	nomina.Get("/conceptos", nominaController.GetResumenConceptos)
+}
@@ -0,0 +1,25 @@
+package router
+
+import (
+	"app/src/controller"
+	m "app/src/middleware"
+	"app/src/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func PlanRoutes(v1 fiber.Router, p service.PlanService, u service.UserService) {
+	planController := controller.NewPlanController(p)
+
+	planes := v1.Group("/planes")
+
+	planes.Use(m.Auth(u, "managePlans"))
+
+	planes.Get("/", planController.GetPlanes)
+	planes.Post("/", planController.CreatePlan)
+	planes.Get("/:planId", planController.GetPlanByID)
+	planes.Patch("/:planId", planController.UpdatePlan)
+	planes.Delete("/:planId", planController.DeletePlan)
+	planes.Post("/:planId/activar", planController.ActivarPlan)
+	planes.Post("/:planId/desactivar", planController.DesactivarPlan)
+}
@@ -9,7 +9,7 @@ import (
 	"gorm.io/gorm"
 )
 
-func Routes(app *fiber.App, db *gorm.DB) {
+func Routes(app *fiber.App, db *gorm.DB, services *Services) {
 	validate := validation.Validator()
 
 	// Servicios existentes
@@ -19,8 +19,19 @@ func Routes(app *fiber.App, db *gorm.DB) {
 	tokenService := service.NewTokenService(db, validate, userService)
 	authService := service.NewAuthService(db, validate, userService, tokenService)
 	
-	// NUEVO: Servicio de datos fiscales usando config.EncryptionKey
-	datosFiscalesService := service.NewDatosFiscalesService(db, validate, config.EncryptionKey)
+	// NUEVO: Servicio de datos fiscales con llaves maestras del KeyProvider configurado
+	datosFiscalesService := service.NewDatosFiscalesService(db, validate, services.KeyProvider, services.AutoridadesSAT)
+
+	// Descarga masiva, sobre los servicios del SAT compartidos con los workers
+	subscriptionService := service.NewSubscriptionService(db, validate)
+	planService := service.NewPlanService(db, validate)
+	descargaService := service.NewDescargaService(db, validate, services.Fiel, services.SATAuth, services.SATClient)
+	cfdiService := service.NewCFDIService(db, validate)
+	pagoService := service.NewPagoService(db, validate)
+	nominaService := service.NewNominaService(db, validate)
+	cartaPorteService := service.NewCartaPorteService(db)
+	catalogoService := service.NewCatalogoService(db, validate, config.CatalogosCacheTTL())
+	receptorService := service.NewReceptorService(db, validate, catalogoService)
 
 	v1 := app.Group("/v1")
 
@@ -31,6 +42,12 @@ func Routes(app *fiber.App, db *gorm.DB) {
 	
 	// NUEVA: Ruta de datos fiscales
 	DatosFiscalesRoutes(v1, datosFiscalesService, userService)
+	DescargaRoutes(v1, descargaService, subscriptionService, userService)
+	SuscripcionRoutes(v1, subscriptionService, userService)
+	PlanRoutes(v1, planService, userService)
+	CFDIRoutes(v1, cfdiService, services.EstadoCFDI, pagoService, cartaPorteService, receptorService, userService)
+	NominaRoutes(v1, nominaService, userService)
+	CatalogoRoutes(v1, catalogoService, userService)
 
 	if !config.IsProd {
 		DocsRoutes(v1)
@@ -0,0 +1,101 @@
+package router
+
+import (
+	"app/src/config"
+	"app/src/fiel"
+	"app/src/sat"
+	"app/src/secrets"
+	"app/src/sello"
+	"app/src/service"
+	"app/src/utils"
+	"crypto/x509"
+
+	"gorm.io/gorm"
+)
+
+// Services are the services the routes share with the background workers.
+// They keep caches of their own, SAT tokens and CFDI statuses, so they are
+// built once and handed to both, along with the SAT certificates loaded
+// from the configured directories.
+type Services struct {
+	KeyProvider     secrets.KeyProvider
+	SATClient       *sat.Client
+	AutoridadesSAT  *x509.CertPool
+	CertificadosSAT *sello.Almacen
+	Fiel            service.FielService
+	SATAuth         service.SATAuthService
+	EstadoCFDI      service.EstadoCFDIService
+}
+
+func NewServices(db *gorm.DB) *Services {
+	keyProvider, err := config.KeyProvider()
+	if err != nil {
+		utils.Log.Fatalf("Invalid key provider configuration: %v", err)
+	}
+
+	satClient := sat.NewClient(sat.Endpoints{
+		Autenticacion: config.SATAutenticacionURL,
+		Solicitud:     config.SATSolicitudURL,
+		Verificacion:  config.SATVerificacionURL,
+		Descarga:      config.SATDescargaURL,
+		Consulta:      config.SATConsultaURL,
+	})
+	fielService := service.NewFielService(db, keyProvider)
+
+	autoridadesSAT, err := autoridadesSAT()
+	if err != nil {
+		utils.Log.Fatalf("Invalid SAT authorities configuration: %v", err)
+	}
+	if autoridadesSAT == nil {
+		utils.Log.Warn("No SAT authorities loaded, every e.firma certificate will be rejected")
+	}
+
+	certificadosSAT, err := certificadosSAT(autoridadesSAT)
+	if err != nil {
+		utils.Log.Fatalf("Invalid SAT certificates configuration: %v", err)
+	}
+	if certificadosSAT.Len() == 0 {
+		utils.Log.Warn("No SAT certificates loaded, SelloSAT verification will fail for every CFDI")
+	}
+
+	return &Services{
+		KeyProvider:     keyProvider,
+		SATClient:       satClient,
+		AutoridadesSAT:  autoridadesSAT,
+		CertificadosSAT: certificadosSAT,
+		Fiel:            fielService,
+		SATAuth:         service.NewSATAuthService(satClient),
+		EstadoCFDI: service.NewEstadoCFDIService(
+			db, satClient, sat.NewConsultaCache(config.SATConsultaTTL()), config.SATConsultaRecientes(),
+		),
+	}
+}
+
+// autoridadesSAT loads the SAT certification authorities that e.firma and
+// CSD certificates are verified against. Without SAT_AUTORIDADES_DIR there
+// is no pool and every certificate is rejected.
+func autoridadesSAT() (*x509.CertPool, error) {
+	if config.SATAutoridadesDir == "" {
+		return nil, nil
+	}
+
+	return fiel.LoadAuthorities(config.SATAutoridadesDir)
+}
+
+// certificadosSAT loads the certificates that verify the SelloSAT of the
+// indexed CFDIs and trusts the autoridades as issuers of the CSD of the
+// emisores. Without SAT_CERTIFICADOS_DIR the store is empty and every
+// SelloSAT is reported as signed by an unknown certificate.
+func certificadosSAT(autoridades *x509.CertPool) (*sello.Almacen, error) {
+	almacen := sello.NewAlmacen()
+	if config.SATCertificadosDir != "" {
+		var err error
+		if almacen, err = sello.CargarAlmacen(config.SATCertificadosDir); err != nil {
+			return nil, err
+		}
+	}
+
+	almacen.ConfiarEn(autoridades)
+
+	return almacen, nil
+}
@@ -0,0 +1,22 @@
+package router
+
+import (
+	"app/src/controller"
+	m "app/src/middleware"
+	"app/src/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func SuscripcionRoutes(v1 fiber.Router, s service.SubscriptionService, u service.UserService) {
+	suscripcionController := controller.NewSuscripcionController(s)
+
+	suscripciones := v1.Group("/suscripciones")
+
+	suscripciones.Use(m.Auth(u))
+
+	suscripciones.Post("/", suscripcionController.Suscribir)
+	suscripciones.Get("/me", suscripcionController.GetMiSuscripcion)
+	suscripciones.Post("/me/cancelar", suscripcionController.CancelarSuscripcion)
+	suscripciones.Post("/me/renovar", suscripcionController.RenovarSuscripcion)
+}
@@ -0,0 +1,67 @@
+package sat
+
+import (
+	"app/src/fiel"
+	"crypto"
+	"net/http"
+	"time"
+)
+
+// Endpoints are the URLs of the SAT web services. Tests and staging point
+// them to a local fake server.
+type Endpoints struct {
+	Autenticacion string
+	Solicitud     string
+	Verificacion  string
+	Descarga      string
+	Consulta      string
+}
+
+var DefaultEndpoints = Endpoints{
+	Autenticacion: "https://cfdidescargamasivasolicitud.clouda.sat.gob.mx/Autenticacion/Autenticacion.svc",
+	Solicitud:     "https://cfdidescargamasivasolicitud.clouda.sat.gob.mx/SolicitaDescargaService.svc",
+	Verificacion:  "https://cfdidescargamasivasolicitud.clouda.sat.gob.mx/VerificaSolicitudDescargaService.svc",
+	Descarga:      "https://cfdidescargamasiva.clouda.sat.gob.mx/DescargaMasivaService.svc",
+	Consulta:      "https://consultaqr.facturaelectronica.sat.gob.mx/ConsultaCFDIService.svc",
+}
+
+// Signer signs requests with the e.firma of the taxpayer. *fiel.Signer
+// implements it.
+type Signer interface {
+	Sign(data []byte, hash crypto.Hash) ([]byte, error)
+	Certificate() *fiel.Certificate
+}
+
+// Client talks to the SAT Descarga Masiva web services and to the CFDI
+// status service.
+type Client struct {
+	Endpoints       Endpoints
+	HTTPClient      *http.Client
+	MaxResponseSize int64
+	Now             func() time.Time
+}
+
+func NewClient(endpoints Endpoints) *Client {
+	if endpoints.Autenticacion == "" {
+		endpoints.Autenticacion = DefaultEndpoints.Autenticacion
+	}
+	if endpoints.Solicitud == "" {
+		endpoints.Solicitud = DefaultEndpoints.Solicitud
+	}
+	if endpoints.Verificacion == "" {
+		endpoints.Verificacion = DefaultEndpoints.Verificacion
+	}
+	if endpoints.Descarga == "" {
+		endpoints.Descarga = DefaultEndpoints.Descarga
+	}
+	if endpoints.Consulta == "" {
+		endpoints.Consulta = DefaultEndpoints.Consulta
+	}
+
+	return &Client{
+		Endpoints:       endpoints,
+		HTTPClient:      &http.Client{Timeout: 5 * time.Minute},
+		MaxResponseSize: 512 << 20,
+		Now:             time.Now,
+	}
+}
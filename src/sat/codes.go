@@ -0,0 +1,22 @@
+package sat
+
+// CodEstatus values returned by the Descarga Masiva services.
+const (
+	CodUsuarioNoValido       = "300"
+	CodXMLMalFormado         = "301"
+	CodSelloMalFormado       = "302"
+	CodSelloNoCorresponde    = "303"
+	CodCertificadoRevocado   = "304"
+	CodCertificadoInvalido   = "305"
+	CodErrorNoControlado     = "404"
+	CodSolicitudAceptada     = "5000"
+	CodTerceroNoAutorizado   = "5001"
+	CodSolicitudesAgotadas   = "5002"
+	CodTopeMaximo            = "5003"
+	CodSinInformacion        = "5004"
+	CodSolicitudDuplicada    = "5005"
+	CodErrorInterno          = "5006"
+	CodSolicitudNoEncontrada = "5007"
+	CodMaximoDescargas       = "5008"
+	CodLimiteDescargasFolio  = "5011"
+)
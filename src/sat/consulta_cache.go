@@ -0,0 +1,26 @@
+package sat
+
+import (
+	"app/src/catalogo"
+	"strings"
+	"time"
+)
+
+// ConsultaCache keeps the last status of every CFDI for TTL, so repeated
+// lookups of the same folio fiscal don't hit the SAT. It is bounded like the
+// catalog cache, folios looked up once don't pile up in memory.
+type ConsultaCache struct {
+	*catalogo.Cache[*ConsultaResult]
+}
+
+func NewConsultaCache(ttl time.Duration) *ConsultaCache {
+	return &ConsultaCache{Cache: catalogo.NewCache[*ConsultaResult](ttl)}
+}
+
+func (c *ConsultaCache) Get(uuid string) (*ConsultaResult, bool) {
+	return c.Cache.Get(strings.ToUpper(uuid))
+}
+
+func (c *ConsultaCache) Set(uuid string, result *ConsultaResult) {
+	c.Cache.Set(strings.ToUpper(uuid), result)
+}
@@ -0,0 +1,113 @@
+package sat
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const actionDescargar = "http://DescargaMasivaTerceros.sat.gob.mx/IDescargaMasivaTercerosService/Descargar"
+
+var (
+	ErrPaqueteVacio    = errors.New("sat: package is empty")
+	ErrPaqueteInvalido = errors.New("sat: package is not a valid ZIP archive")
+)
+
+// DescargaResult is a downloaded package. Paquete holds the decoded ZIP
+// when CodEstatus is CodSolicitudAceptada.
+type DescargaResult struct {
+	CodEstatus string
+	Mensaje    string
+	Paquete    []byte
+}
+
+type descargarResponse struct {
+	Header struct {
+		Respuesta struct {
+			CodEstatus string `xml:"CodEstatus,attr"`
+			Mensaje    string `xml:"Mensaje,attr"`
+		} `xml:"respuesta"`
+	} `xml:"Header"`
+	Body struct {
+		Salida struct {
+			Paquete string `xml:"Paquete"`
+		} `xml:"RespuestaDescargaMasivaTercerosSalida"`
+	} `xml:"Body"`
+}
+
+// Descargar downloads one package of a finished request. The SAT only
+// allows a couple of downloads per package, so callers should store it
+// before doing anything else with it.
+func (c *Client) Descargar(ctx context.Context, token string, signer Signer, rfcSolicitante, idPaquete string) (*DescargaResult, error) {
+	attrs := []xmlAttr{
+		{"IdPaquete", idPaquete},
+		{"RfcSolicitante", strings.ToUpper(rfcSolicitante)},
+	}
+
+	body, err := signedOperation(signer, "PeticionDescargaMasivaTercerosEntrada", "peticionDescarga", attrs, "")
+	if err != nil {
+		return nil, fmt.Errorf("sat: signing package download: %w", err)
+	}
+
+	var res descargarResponse
+	if err := c.call(ctx, c.Endpoints.Descarga, actionDescargar, token, signedEnvelope(body), &res); err != nil {
+		return nil, err
+	}
+
+	result := &DescargaResult{
+		CodEstatus: res.Header.Respuesta.CodEstatus,
+		Mensaje:    res.Header.Respuesta.Mensaje,
+	}
+
+	if result.CodEstatus != CodSolicitudAceptada {
+		return result, nil
+	}
+
+	result.Paquete, err = base64.StdEncoding.DecodeString(strings.TrimSpace(res.Body.Salida.Paquete))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrPaqueteInvalido, err)
+	}
+
+	return result, nil
+}
+
+// ValidatePaquete checks that the package is a readable ZIP archive and
+// returns the number of files it contains.
+func ValidatePaquete(data []byte) (int, error) {
+	if len(data) == 0 {
+		return 0, ErrPaqueteVacio
+	}
+
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return 0, fmt.Errorf("%w: %w", ErrPaqueteInvalido, err)
+	}
+
+	files := 0
+	for _, file := range archive.File {
+		if file.FileInfo().IsDir() {
+			continue
+		}
+
+		// Reading every entry checks its CRC, a truncated download fails here.
+		reader, err := file.Open()
+		if err != nil {
+			return 0, fmt.Errorf("%w: %s: %w", ErrPaqueteInvalido, file.Name, err)
+		}
+
+		_, err = io.Copy(io.Discard, reader)
+		reader.Close()
+		if err != nil {
+			return 0, fmt.Errorf("%w: %s: %w", ErrPaqueteInvalido, file.Name, err)
+		}
+
+		files++
+	}
+
+	return files, nil
+}
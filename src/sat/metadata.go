@@ -0,0 +1,190 @@
+package sat
+
+import (
+	"app/src/cfdi"
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+var ErrMetadataInvalida = errors.New("sat: invalid metadata file")
+
+// metadataFechaLayout is the layout of the dates in metadata files, which
+// unlike the CFDI use a space between date and time.
+const metadataFechaLayout = "2006-01-02 15:04:05"
+
+// metadataColumnas are the header names the parser needs. The SAT added the
+// RfcACuentaTerceros and NombreACuentaTerceros columns in 2023, so columns
+// are located by name instead of position.
+var metadataColumnas = []string{
+	"Uuid", "RfcEmisor", "NombreEmisor", "RfcReceptor", "NombreReceptor", "RfcPac",
+	"FechaEmision", "FechaCertificacionSat", "Monto", "EfectoComprobante", "Estatus", "FechaCancelacion",
+}
+
+// Metadata is a row of a metadata package: what the SAT knows about a CFDI
+// without its XML, including whether it was cancelled.
+type Metadata struct {
+	UUID               string
+	RFCEmisor          string
+	NombreEmisor       string
+	RFCReceptor        string
+	NombreReceptor     string
+	RFCPac             string
+	FechaEmision       time.Time
+	FechaCertificacion time.Time
+	Monto              cfdi.Decimal
+	EfectoComprobante  string
+	Cancelado          bool
+	FechaCancelacion   *time.Time
+}
+
+// ParseMetadata parses a tilde delimited metadata file. Rows that can't be
+// parsed are skipped and reported in the returned error, together with the
+// rows that could, since one bad row shouldn't hide the rest of the file.
+func ParseMetadata(r io.Reader) ([]Metadata, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	var header *metadataHeader
+	var filas []Metadata
+	var errs []error
+
+	linea := 0
+	for scanner.Scan() {
+		linea++
+
+		text := strings.TrimRight(string(bytes.TrimPrefix(scanner.Bytes(), utf8BOM)), "\r")
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
+
+		campos := strings.Split(text, "~")
+
+		if header == nil {
+			parsed, err := parseMetadataHeader(campos)
+			if err != nil {
+				return nil, err
+			}
+
+			header = parsed
+			continue
+		}
+
+		fila, err := header.fila(campos)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("line %d: %w", linea, err))
+			continue
+		}
+
+		filas = append(filas, *fila)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return filas, fmt.Errorf("%w: %w", ErrMetadataInvalida, err)
+	}
+
+	if header == nil {
+		return nil, fmt.Errorf("%w: missing header", ErrMetadataInvalida)
+	}
+
+	if len(errs) > 0 {
+		return filas, fmt.Errorf("%w: %w", ErrMetadataInvalida, errors.Join(errs...))
+	}
+
+	return filas, nil
+}
+
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// metadataHeader maps the lowercased column names to their position.
+type metadataHeader struct {
+	columnas map[string]int
+	campos   int
+}
+
+func parseMetadataHeader(campos []string) (*metadataHeader, error) {
+	header := &metadataHeader{columnas: make(map[string]int, len(campos)), campos: len(campos)}
+	for i, campo := range campos {
+		header.columnas[strings.ToLower(strings.TrimSpace(campo))] = i
+	}
+
+	for _, nombre := range metadataColumnas {
+		if _, ok := header.columnas[strings.ToLower(nombre)]; !ok {
+			return nil, fmt.Errorf("%w: missing column %s", ErrMetadataInvalida, nombre)
+		}
+	}
+
+	return header, nil
+}
+
+func (h *metadataHeader) fila(campos []string) (*Metadata, error) {
+	// A tilde in a name shifts every column after it.
+	if len(campos) != h.campos {
+		return nil, fmt.Errorf("expected %d fields, got %d", h.campos, len(campos))
+	}
+
+	campo := func(nombre string) string {
+		return strings.TrimSpace(campos[h.columnas[strings.ToLower(nombre)]])
+	}
+
+	fila := &Metadata{
+		UUID:              strings.ToUpper(campo("Uuid")),
+		RFCEmisor:         strings.ToUpper(campo("RfcEmisor")),
+		NombreEmisor:      campo("NombreEmisor"),
+		RFCReceptor:       strings.ToUpper(campo("RfcReceptor")),
+		NombreReceptor:    campo("NombreReceptor"),
+		RFCPac:            strings.ToUpper(campo("RfcPac")),
+		EfectoComprobante: strings.ToUpper(campo("EfectoComprobante")),
+	}
+
+	if fila.UUID == "" || fila.RFCEmisor == "" || fila.RFCReceptor == "" {
+		return nil, errors.New("missing UUID or RFCs")
+	}
+
+	var err error
+	if fila.FechaEmision, err = parseMetadataFecha(campo("FechaEmision")); err != nil {
+		return nil, fmt.Errorf("FechaEmision: %w", err)
+	}
+
+	if text := campo("FechaCertificacionSat"); text != "" {
+		if fila.FechaCertificacion, err = parseMetadataFecha(text); err != nil {
+			return nil, fmt.Errorf("FechaCertificacionSat: %w", err)
+		}
+	}
+
+	if fila.Monto, err = cfdi.ParseDecimal(campo("Monto")); err != nil {
+		return nil, fmt.Errorf("Monto: %w", err)
+	}
+
+	switch strings.ToLower(campo("Estatus")) {
+	case "1", "vigente":
+		fila.Cancelado = false
+	case "0", "cancelado":
+		fila.Cancelado = true
+	default:
+		return nil, fmt.Errorf("unknown Estatus %q", campo("Estatus"))
+	}
+
+	if text := campo("FechaCancelacion"); text != "" {
+		fecha, err := parseMetadataFecha(text)
+		if err != nil {
+			return nil, fmt.Errorf("FechaCancelacion: %w", err)
+		}
+		fila.FechaCancelacion = &fecha
+	}
+
+	return fila, nil
+}
+
+func parseMetadataFecha(text string) (time.Time, error) {
+	if t, err := time.Parse(metadataFechaLayout, text); err == nil {
+		return t, nil
+	}
+
+	fecha, err := cfdi.ParseFecha(text)
+	return fecha.Time, err
+}
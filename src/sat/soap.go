@@ -0,0 +1,88 @@
+package sat
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+const (
+	nsSOAP           = "http://schemas.xmlsoap.org/soap/envelope/"
+	nsWSU            = "http://docs.oasis-open.org/wss/2004/01/oasis-200401-wss-wssecurity-utility-1.0.xsd"
+	nsWSSE           = "http://docs.oasis-open.org/wss/2004/01/oasis-200401-wss-wssecurity-secext-1.0.xsd"
+	nsDSig           = "http://www.w3.org/2000/09/xmldsig#"
+	nsDescargaMasiva = "http://DescargaMasivaTerceros.gob.mx"
+	nsDescargaSAT    = "http://DescargaMasivaTerceros.sat.gob.mx"
+
+	x509TokenType  = "http://docs.oasis-open.org/wss/2004/01/oasis-200401-wss-x509-token-profile-1.0#X509v3"
+	base64Encoding = "http://docs.oasis-open.org/wss/2004/01/oasis-200401-wss-soap-message-security-1.0#Base64Binary"
+	algExcC14N     = "http://www.w3.org/2001/10/xml-exc-c14n#"
+	algEnveloped   = "http://www.w3.org/2000/09/xmldsig#enveloped-signature"
+	algRSASHA1     = "http://www.w3.org/2000/09/xmldsig#rsa-sha1"
+	algSHA1        = "http://www.w3.org/2000/09/xmldsig#sha1"
+)
+
+// FaultError is a SOAP fault returned by a SAT service.
+type FaultError struct {
+	Code    string
+	Message string
+}
+
+func (e *FaultError) Error() string {
+	return fmt.Sprintf("sat: soap fault %s: %s", e.Code, e.Message)
+}
+
+type soapFault struct {
+	Code    string `xml:"faultcode"`
+	Message string `xml:"faultstring"`
+}
+
+type faultEnvelope struct {
+	Body struct {
+		Fault *soapFault `xml:"Fault"`
+	} `xml:"Body"`
+}
+
+// call posts a SOAP envelope and decodes the response into out. Faults are
+// returned as *FaultError whatever the HTTP status code is.
+func (c *Client) call(ctx context.Context, endpoint, action, token string, payload []byte, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "text/xml; charset=utf-8")
+	req.Header.Set("SOAPAction", action)
+	if token != "" {
+		req.Header.Set("Authorization", fmt.Sprintf(`WRAP access_token="%s"`, token))
+	}
+
+	res, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("sat: %s: %w", action, err)
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(res.Body, c.MaxResponseSize))
+	if err != nil {
+		return fmt.Errorf("sat: reading %s response: %w", action, err)
+	}
+
+	var fault faultEnvelope
+	if err := xml.Unmarshal(body, &fault); err == nil && fault.Body.Fault != nil {
+		return &FaultError{Code: fault.Body.Fault.Code, Message: fault.Body.Fault.Message}
+	}
+
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("sat: %s returned HTTP %d", action, res.StatusCode)
+	}
+
+	if err := xml.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("sat: decoding %s response: %w", action, err)
+	}
+
+	return nil
+}
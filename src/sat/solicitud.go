@@ -0,0 +1,103 @@
+package sat
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	TipoDescargaEmitidos  = "emitidos"
+	TipoDescargaRecibidos = "recibidos"
+
+	TipoSolicitudCFDI     = "CFDI"
+	TipoSolicitudMetadata = "Metadata"
+
+	actionSolicitaEmitidos  = "http://DescargaMasivaTerceros.sat.gob.mx/ISolicitaDescargaService/SolicitaDescargaEmitidos"
+	actionSolicitaRecibidos = "http://DescargaMasivaTerceros.sat.gob.mx/ISolicitaDescargaService/SolicitaDescargaRecibidos"
+
+	fechaSolicitudFormat = "2006-01-02T15:04:05"
+)
+
+// SolicitudParams are the filters of a bulk download request. The RFC
+// contraparte is the receptor for emitidos and the emisor for recibidos.
+type SolicitudParams struct {
+	RFCSolicitante    string
+	TipoDescarga      string
+	TipoSolicitud     string
+	FechaInicial      time.Time
+	FechaFinal        time.Time
+	TipoComprobante   string
+	EstadoComprobante string
+	RFCContraparte    string
+}
+
+// SolicitudResult is the SAT answer to a download request. A CodEstatus
+// other than CodSolicitudAceptada means the request was rejected.
+type SolicitudResult struct {
+	IDSolicitud    string `xml:"IdSolicitud,attr"`
+	RFCSolicitante string `xml:"RfcSolicitante,attr"`
+	CodEstatus     string `xml:"CodEstatus,attr"`
+	Mensaje        string `xml:"Mensaje,attr"`
+}
+
+func (r *SolicitudResult) Aceptada() bool {
+	return r.CodEstatus == CodSolicitudAceptada
+}
+
+type solicitaResponse struct {
+	Body struct {
+		Response struct {
+			Result SolicitudResult `xml:",any"`
+		} `xml:",any"`
+	} `xml:"Body"`
+}
+
+// SolicitaDescarga asks the SAT to prepare the CFDIs or metadata matching
+// the filters. The packages are produced asynchronously, the returned
+// IdSolicitud is polled with VerificaSolicitudDescarga.
+func (c *Client) SolicitaDescarga(ctx context.Context, token string, signer Signer, params SolicitudParams) (*SolicitudResult, error) {
+	rfc := strings.ToUpper(params.RFCSolicitante)
+	attrs := []xmlAttr{
+		{"RfcSolicitante", rfc},
+		{"FechaInicial", params.FechaInicial.Format(fechaSolicitudFormat)},
+		{"FechaFinal", params.FechaFinal.Format(fechaSolicitudFormat)},
+		{"TipoSolicitud", params.TipoSolicitud},
+		{"TipoComprobante", params.TipoComprobante},
+		{"EstadoComprobante", params.EstadoComprobante},
+	}
+
+	var operation, action, children string
+	switch params.TipoDescarga {
+	case TipoDescargaEmitidos:
+		operation, action = "SolicitaDescargaEmitidos", actionSolicitaEmitidos
+		attrs = append(attrs, xmlAttr{"RfcEmisor", rfc})
+		if params.RFCContraparte != "" {
+			children = fmt.Sprintf(
+				`<des:RfcReceptores><des:RfcReceptor>%s</des:RfcReceptor></des:RfcReceptores>`,
+				textEscaper.Replace(strings.ToUpper(params.RFCContraparte)),
+			)
+		}
+	case TipoDescargaRecibidos:
+		operation, action = "SolicitaDescargaRecibidos", actionSolicitaRecibidos
+		attrs = append(attrs,
+			xmlAttr{"RfcReceptor", rfc},
+			xmlAttr{"RfcEmisor", strings.ToUpper(params.RFCContraparte)},
+		)
+	default:
+		return nil, fmt.Errorf("sat: unknown tipo de descarga %q", params.TipoDescarga)
+	}
+
+	body, err := signedOperation(signer, operation, "solicitud", attrs, children)
+	if err != nil {
+		return nil, fmt.Errorf("sat: signing download request: %w", err)
+	}
+
+	var res solicitaResponse
+	if err := c.call(ctx, c.Endpoints.Solicitud, action, token, signedEnvelope(body), &res); err != nil {
+		return nil, err
+	}
+
+	return &res.Body.Response.Result, nil
+}
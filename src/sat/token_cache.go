@@ -0,0 +1,52 @@
+package sat
+
+import (
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TokenCache keeps the last token of every registered e.firma until it
+// expires, keyed by the fiscal data it was requested with. The same RFC can
+// be registered by several users, each one with their own token.
+type TokenCache struct {
+	mu     sync.Mutex
+	tokens map[uuid.UUID]*Token
+	Now    func() time.Time
+}
+
+func NewTokenCache() *TokenCache {
+	return &TokenCache{tokens: make(map[uuid.UUID]*Token), Now: time.Now}
+}
+
+func (c *TokenCache) Get(datosFiscalesID uuid.UUID) (*Token, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	token, ok := c.tokens[datosFiscalesID]
+	if !ok {
+		return nil, false
+	}
+
+	if !token.Valid(c.Now()) {
+		delete(c.tokens, datosFiscalesID)
+		return nil, false
+	}
+
+	return token, true
+}
+
+func (c *TokenCache) Set(datosFiscalesID uuid.UUID, token *Token) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.tokens[datosFiscalesID] = token
+}
+
+func (c *TokenCache) Delete(datosFiscalesID uuid.UUID) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.tokens, datosFiscalesID)
+}
@@ -0,0 +1,60 @@
+package sat
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+const actionVerifica = "http://DescargaMasivaTerceros.sat.gob.mx/IVerificaSolicitudDescargaService/VerificaSolicitudDescarga"
+
+// EstadoSolicitud values reported by VerificaSolicitudDescarga.
+const (
+	EstadoSolicitudAceptada  = 1
+	EstadoSolicitudEnProceso = 2
+	EstadoSolicitudTerminada = 3
+	EstadoSolicitudError     = 4
+	EstadoSolicitudRechazada = 5
+	EstadoSolicitudVencida   = 6
+)
+
+// VerificacionResult is the state of a download request. IDsPaquetes is
+// only filled once the request is terminada.
+type VerificacionResult struct {
+	CodEstatus            string   `xml:"CodEstatus,attr"`
+	EstadoSolicitud       int      `xml:"EstadoSolicitud,attr"`
+	CodigoEstadoSolicitud string   `xml:"CodigoEstadoSolicitud,attr"`
+	NumeroCFDIs           int      `xml:"NumeroCFDIs,attr"`
+	Mensaje               string   `xml:"Mensaje,attr"`
+	IDsPaquetes           []string `xml:"IdsPaquetes"`
+}
+
+type verificaResponse struct {
+	Body struct {
+		Response struct {
+			Result VerificacionResult `xml:",any"`
+		} `xml:",any"`
+	} `xml:"Body"`
+}
+
+// VerificaSolicitudDescarga asks the SAT for the state of a download request.
+func (c *Client) VerificaSolicitudDescarga(
+	ctx context.Context, token string, signer Signer, rfcSolicitante, idSolicitud string,
+) (*VerificacionResult, error) {
+	attrs := []xmlAttr{
+		{"IdSolicitud", idSolicitud},
+		{"RfcSolicitante", strings.ToUpper(rfcSolicitante)},
+	}
+
+	body, err := signedOperation(signer, "VerificaSolicitudDescarga", "solicitud", attrs, "")
+	if err != nil {
+		return nil, fmt.Errorf("sat: signing verification request: %w", err)
+	}
+
+	var res verificaResponse
+	if err := c.call(ctx, c.Endpoints.Verificacion, actionVerifica, token, signedEnvelope(body), &res); err != nil {
+		return nil, err
+	}
+
+	return &res.Body.Response.Result, nil
+}
@@ -0,0 +1,75 @@
+package sat
+
+import (
+	"crypto"
+	"crypto/sha1"
+	"encoding/base64"
+	"fmt"
+	"time"
+)
+
+const timestampFormat = "2006-01-02T15:04:05.000Z"
+
+// canonicalTimestamp is the exclusive canonicalization of the
+// wsu:Timestamp element, the one the WS-Security signature references.
+func canonicalTimestamp(created, expires string) string {
+	return fmt.Sprintf(
+		`<u:Timestamp xmlns:u="%s" u:Id="_0"><u:Created>%s</u:Created><u:Expires>%s</u:Expires></u:Timestamp>`,
+		nsWSU, created, expires,
+	)
+}
+
+// canonicalSignedInfo is the exclusive canonicalization of the SignedInfo
+// element for a single reference.
+func canonicalSignedInfo(uri, transform, digest string) string {
+	return fmt.Sprintf(
+		`<SignedInfo xmlns="%s">`+
+			`<CanonicalizationMethod Algorithm="%s"></CanonicalizationMethod>`+
+			`<SignatureMethod Algorithm="%s"></SignatureMethod>`+
+			`<Reference URI="%s"><Transforms><Transform Algorithm="%s"></Transform></Transforms>`+
+			`<DigestMethod Algorithm="%s"></DigestMethod><DigestValue>%s</DigestValue></Reference>`+
+			`</SignedInfo>`,
+		nsDSig, algExcC14N, algRSASHA1, uri, transform, algSHA1, digest,
+	)
+}
+
+func sha1Base64(data string) string {
+	digest := sha1.Sum([]byte(data))
+	return base64.StdEncoding.EncodeToString(digest[:])
+}
+
+// authEnvelope builds the Autentica request: a WS-Security header with a
+// timestamp valid for five minutes, the certificate as BinarySecurityToken
+// and an RSA-SHA1 signature over the timestamp.
+func authEnvelope(signer Signer, now time.Time, tokenID string) ([]byte, error) {
+	created := now.UTC().Format(timestampFormat)
+	expires := now.UTC().Add(5 * time.Minute).Format(timestampFormat)
+
+	signedInfo := canonicalSignedInfo("#_0", algExcC14N, sha1Base64(canonicalTimestamp(created, expires)))
+
+	signature, err := signer.Sign([]byte(signedInfo), crypto.SHA1)
+	if err != nil {
+		return nil, err
+	}
+
+	certificate := base64.StdEncoding.EncodeToString(signer.Certificate().X509.Raw)
+
+	envelope := fmt.Sprintf(
+		`<s:Envelope xmlns:s="%s" xmlns:u="%s">`+
+			`<s:Header><o:Security s:mustUnderstand="1" xmlns:o="%s">`+
+			`<u:Timestamp u:Id="_0"><u:Created>%s</u:Created><u:Expires>%s</u:Expires></u:Timestamp>`+
+			`<o:BinarySecurityToken u:Id="%s" ValueType="%s" EncodingType="%s">%s</o:BinarySecurityToken>`+
+			`<Signature xmlns="%s">%s<SignatureValue>%s</SignatureValue>`+
+			`<KeyInfo><o:SecurityTokenReference><o:Reference ValueType="%s" URI="#%s"/></o:SecurityTokenReference></KeyInfo>`+
+			`</Signature></o:Security></s:Header>`+
+			`<s:Body><Autentica xmlns="%s"/></s:Body></s:Envelope>`,
+		nsSOAP, nsWSU, nsWSSE,
+		created, expires,
+		tokenID, x509TokenType, base64Encoding, certificate,
+		nsDSig, signedInfo, base64.StdEncoding.EncodeToString(signature),
+		x509TokenType, tokenID,
+		nsDescargaMasiva,
+	)
+
+	return []byte(envelope), nil
+}
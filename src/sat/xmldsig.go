@@ -0,0 +1,85 @@
+package sat
+
+import (
+	"crypto"
+	"encoding/base64"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+type xmlAttr struct {
+	Name  string
+	Value string
+}
+
+var (
+	attrEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", `"`, "&quot;", "\t", "&#x9;", "\n", "&#xA;", "\r", "&#xD;")
+	textEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", "\r", "&#xD;")
+)
+
+// canonicalAttrs renders the attributes the way c14n does: sorted by name
+// and escaped. Empty attributes are left out, the SAT treats them as absent.
+func canonicalAttrs(attrs []xmlAttr) string {
+	sorted := make([]xmlAttr, 0, len(attrs))
+	for _, attr := range attrs {
+		if attr.Value != "" {
+			sorted = append(sorted, attr)
+		}
+	}
+
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Name < sorted[j].Name })
+
+	var builder strings.Builder
+	for _, attr := range sorted {
+		fmt.Fprintf(&builder, ` %s="%s"`, attr.Name, attrEscaper.Replace(attr.Value))
+	}
+
+	return builder.String()
+}
+
+// signedOperation builds the body of the Descarga Masiva requests, an
+// operation element wrapping a single element that carries the request
+// attributes and an enveloped XML signature:
+//
+//	<des:operation><des:element ...>children<Signature/></des:element></des:operation>
+//
+// The digest covers the canonical operation element without the signature.
+func signedOperation(signer Signer, operation, element string, attrs []xmlAttr, children string) (string, error) {
+	rendered := canonicalAttrs(attrs)
+
+	canonical := fmt.Sprintf(
+		`<des:%s xmlns:des="%s"><des:%s%s>%s</des:%s></des:%s>`,
+		operation, nsDescargaSAT, element, rendered, children, element, operation,
+	)
+
+	signedInfo := canonicalSignedInfo("", algEnveloped, sha1Base64(canonical))
+
+	signature, err := signer.Sign([]byte(signedInfo), crypto.SHA1)
+	if err != nil {
+		return "", err
+	}
+
+	x509Cert := signer.Certificate().X509
+
+	return fmt.Sprintf(
+		`<des:%s><des:%s%s>%s<Signature xmlns="%s">%s<SignatureValue>%s</SignatureValue>`+
+			`<KeyInfo><X509Data><X509IssuerSerial><X509IssuerName>%s</X509IssuerName>`+
+			`<X509SerialNumber>%s</X509SerialNumber></X509IssuerSerial>`+
+			`<X509Certificate>%s</X509Certificate></X509Data></KeyInfo></Signature></des:%s></des:%s>`,
+		operation, element, rendered, children,
+		nsDSig, signedInfo, base64.StdEncoding.EncodeToString(signature),
+		textEscaper.Replace(x509Cert.Issuer.String()), x509Cert.SerialNumber.String(),
+		base64.StdEncoding.EncodeToString(x509Cert.Raw),
+		element, operation,
+	), nil
+}
+
+// signedEnvelope wraps a signed operation in the SOAP envelope used by the
+// solicitud, verificación and descarga services.
+func signedEnvelope(operation string) []byte {
+	return []byte(fmt.Sprintf(
+		`<s:Envelope xmlns:s="%s" xmlns:des="%s"><s:Header/><s:Body>%s</s:Body></s:Envelope>`,
+		nsSOAP, nsDescargaSAT, operation,
+	))
+}
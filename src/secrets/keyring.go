@@ -0,0 +1,141 @@
+package secrets
+
+import (
+	"app/src/utils"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrUnknownKeyVersion = errors.New("unknown master key version")
+	ErrNoMasterKey       = errors.New("no master key configured")
+)
+
+// Keyring is the local KeyProvider: it holds every master key that may still
+// protect stored data keys, read from the environment or a file. New data
+// keys are always wrapped with the highest version.
+type Keyring struct {
+	keys    map[int]string
+	current int
+}
+
+func NewKeyring(keys map[int]string) (*Keyring, error) {
+	if len(keys) == 0 {
+		return nil, ErrNoMasterKey
+	}
+
+	keyring := &Keyring{keys: make(map[int]string, len(keys))}
+	for version, secret := range keys {
+		if version <= 0 {
+			return nil, fmt.Errorf("invalid master key version %d", version)
+		}
+
+		if strings.TrimSpace(secret) == "" {
+			return nil, fmt.Errorf("%w: version %d is empty", ErrNoMasterKey, version)
+		}
+
+		keyring.keys[version] = secret
+		if version > keyring.current {
+			keyring.current = version
+		}
+	}
+
+	return keyring, nil
+}
+
+// ParseKeyring reads master keys in the "version:secret,version:secret"
+// format. When spec is empty the legacy single key is used as version 1,
+// which is the key every record created before versioning was sealed with.
+// Without either there is no key to seal with and an error is returned.
+func ParseKeyring(spec, legacyKey string) (*Keyring, error) {
+	if strings.TrimSpace(spec) == "" {
+		if strings.TrimSpace(legacyKey) == "" {
+			return nil, fmt.Errorf("%w: set ENCRYPTION_KEYS or ENCRYPTION_KEY", ErrNoMasterKey)
+		}
+
+		return NewKeyring(map[int]string{1: legacyKey})
+	}
+
+	keys := make(map[int]string)
+	for _, entry := range strings.Split(spec, ",") {
+		rawVersion, secret, found := strings.Cut(strings.TrimSpace(entry), ":")
+		if !found || secret == "" {
+			return nil, fmt.Errorf("invalid master key entry %q", entry)
+		}
+
+		version, err := strconv.Atoi(rawVersion)
+		if err != nil {
+			return nil, fmt.Errorf("invalid master key version %q", rawVersion)
+		}
+
+		if _, exists := keys[version]; exists {
+			return nil, fmt.Errorf("duplicated master key version %d", version)
+		}
+
+		keys[version] = secret
+	}
+
+	return NewKeyring(keys)
+}
+
+// LoadKeyringFile reads the master keys from a file holding one
+// "version:secret" entry per line, which keeps them out of the environment.
+func LoadKeyringFile(path string) (*Keyring, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []string
+	for _, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, "#") {
+			entries = append(entries, line)
+		}
+	}
+
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("no master keys found in %s", path)
+	}
+
+	return ParseKeyring(strings.Join(entries, ","), "")
+}
+
+func (k *Keyring) CurrentVersion(_ context.Context) (int, error) {
+	return k.current, nil
+}
+
+// Wrap encrypts a data key with the current master key.
+func (k *Keyring) Wrap(_ context.Context, dataKey []byte) (string, int, error) {
+	wrapped, err := utils.EncryptAESGCM(k.keys[k.current], dataKey)
+	if err != nil {
+		return "", 0, err
+	}
+
+	return wrapped, k.current, nil
+}
+
+// Unwrap decrypts a data key wrapped with the given master key version.
+func (k *Keyring) Unwrap(_ context.Context, wrapped string, version int) ([]byte, error) {
+	secret, ok := k.keys[version]
+	if !ok {
+		return nil, fmt.Errorf("%w: %d", ErrUnknownKeyVersion, version)
+	}
+
+	return utils.DecryptAESGCM(secret, wrapped)
+}
+
+// DecryptLegacy decrypts a value sealed directly with a master key, as
+// records were stored before data keys were introduced.
+func (k *Keyring) DecryptLegacy(ciphertext string, version int) ([]byte, error) {
+	secret, ok := k.keys[version]
+	if !ok {
+		return nil, fmt.Errorf("%w: %d", ErrUnknownKeyVersion, version)
+	}
+
+	return utils.DecryptAESGCM(secret, ciphertext)
+}
@@ -0,0 +1,20 @@
+package secrets
+
+import "context"
+
+// KeyProvider protects the per-record data keys with a versioned master
+// key. The master key itself never leaves the provider.
+type KeyProvider interface {
+	// Wrap encrypts a data key with the current master key version.
+	Wrap(ctx context.Context, dataKey []byte) (string, int, error)
+	// Unwrap decrypts a data key wrapped with the given master key version.
+	Unwrap(ctx context.Context, wrapped string, version int) ([]byte, error)
+	// CurrentVersion returns the master key version used by Wrap.
+	CurrentVersion(ctx context.Context) (int, error)
+}
+
+// LegacyDecrypter is implemented by providers that can still read records
+// sealed directly with the master key, before data keys were introduced.
+type LegacyDecrypter interface {
+	DecryptLegacy(ciphertext string, version int) ([]byte, error)
+}
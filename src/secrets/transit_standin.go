@@ -0,0 +1,196 @@
+package secrets
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// TransitStandIn is a small in-memory server implementing the subset of the
+// Vault Transit API used by VaultTransit: encrypt, decrypt, read and rotate
+// keys. It lets the Vault path run offline in tests and development; it is
+// not meant to protect production keys.
+type TransitStandIn struct {
+	mu    sync.RWMutex
+	token string
+	mount string
+	keys  map[string][][]byte
+}
+
+func NewTransitStandIn(token, mount string) *TransitStandIn {
+	if mount == "" {
+		mount = "transit"
+	}
+
+	return &TransitStandIn{
+		token: token,
+		mount: strings.Trim(mount, "/"),
+		keys:  make(map[string][][]byte),
+	}
+}
+
+// Rotate adds a new version to the named key, creating it when needed.
+func (t *TransitStandIn) Rotate(name string) (int, error) {
+	secret := make([]byte, dataKeySize)
+	if _, err := io.ReadFull(rand.Reader, secret); err != nil {
+		return 0, err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.keys[name] = append(t.keys[name], secret)
+
+	return len(t.keys[name]), nil
+}
+
+func (t *TransitStandIn) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if t.token != "" && r.Header.Get("X-Vault-Token") != t.token {
+		writeTransitError(w, http.StatusForbidden, "permission denied")
+		return
+	}
+
+	path := strings.TrimPrefix(r.URL.Path, "/v1/"+t.mount+"/")
+	action, name, found := strings.Cut(path, "/")
+	if !found || name == "" || path == r.URL.Path {
+		writeTransitError(w, http.StatusNotFound, "unsupported path")
+		return
+	}
+
+	switch {
+	case action == "encrypt" && r.Method == http.MethodPost:
+		t.encrypt(w, r, name)
+	case action == "decrypt" && r.Method == http.MethodPost:
+		t.decrypt(w, r, name)
+	case action == "keys" && r.Method == http.MethodGet:
+		t.readKey(w, name)
+	case action == "keys" && r.Method == http.MethodPost && strings.HasSuffix(name, "/rotate"):
+		t.rotate(w, strings.TrimSuffix(name, "/rotate"))
+	case action == "keys" && r.Method == http.MethodPost:
+		t.create(w, name)
+	default:
+		writeTransitError(w, http.StatusNotFound, "unsupported path")
+	}
+}
+
+func (t *TransitStandIn) encrypt(w http.ResponseWriter, r *http.Request, name string) {
+	var body struct {
+		Plaintext string `json:"plaintext"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeTransitError(w, http.StatusBadRequest, "invalid body")
+		return
+	}
+
+	plaintext, err := base64.StdEncoding.DecodeString(body.Plaintext)
+	if err != nil {
+		writeTransitError(w, http.StatusBadRequest, "plaintext must be base64")
+		return
+	}
+
+	t.mu.RLock()
+	versions := t.keys[name]
+	t.mu.RUnlock()
+
+	if len(versions) == 0 {
+		writeTransitError(w, http.StatusBadRequest, "encryption key not found")
+		return
+	}
+
+	sealed, err := Seal(versions[len(versions)-1], plaintext)
+	if err != nil {
+		writeTransitError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	writeTransitData(w, map[string]any{
+		"ciphertext":  fmt.Sprintf("vault:v%d:%s", len(versions), sealed),
+		"key_version": len(versions),
+	})
+}
+
+func (t *TransitStandIn) decrypt(w http.ResponseWriter, r *http.Request, name string) {
+	var body struct {
+		Ciphertext string `json:"ciphertext"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeTransitError(w, http.StatusBadRequest, "invalid body")
+		return
+	}
+
+	version, err := ciphertextVersion(body.Ciphertext)
+	if err != nil {
+		writeTransitError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	t.mu.RLock()
+	versions := t.keys[name]
+	t.mu.RUnlock()
+
+	if version < 1 || version > len(versions) {
+		writeTransitError(w, http.StatusBadRequest, "invalid key version")
+		return
+	}
+
+	sealed := strings.SplitN(body.Ciphertext, ":", 3)[2]
+	plaintext, err := Open(versions[version-1], sealed)
+	if err != nil {
+		writeTransitError(w, http.StatusBadRequest, "cipher: message authentication failed")
+		return
+	}
+
+	writeTransitData(w, map[string]any{"plaintext": base64.StdEncoding.EncodeToString(plaintext)})
+}
+
+func (t *TransitStandIn) readKey(w http.ResponseWriter, name string) {
+	t.mu.RLock()
+	versions := len(t.keys[name])
+	t.mu.RUnlock()
+
+	if versions == 0 {
+		writeTransitError(w, http.StatusNotFound, "key not found")
+		return
+	}
+
+	writeTransitData(w, map[string]any{"name": name, "latest_version": versions})
+}
+
+func (t *TransitStandIn) create(w http.ResponseWriter, name string) {
+	t.mu.RLock()
+	versions := len(t.keys[name])
+	t.mu.RUnlock()
+
+	if versions > 0 {
+		writeTransitData(w, map[string]any{"name": name, "latest_version": versions})
+		return
+	}
+
+	t.rotate(w, name)
+}
+
+func (t *TransitStandIn) rotate(w http.ResponseWriter, name string) {
+	version, err := t.Rotate(name)
+	if err != nil {
+		writeTransitError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	writeTransitData(w, map[string]any{"name": name, "latest_version": version})
+}
+
+func writeTransitData(w http.ResponseWriter, data map[string]any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]any{"data": data})
+}
+
+func writeTransitError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]any{"errors": []string{message}})
+}
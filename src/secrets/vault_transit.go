@@ -0,0 +1,140 @@
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// VaultTransit is a KeyProvider backed by the HashiCorp Vault Transit
+// secrets engine, or any server speaking the same HTTP API.
+type VaultTransit struct {
+	Address string
+	Token   string
+	Mount   string
+	KeyName string
+	Client  *http.Client
+}
+
+func NewVaultTransit(address, token, mount, keyName string) (*VaultTransit, error) {
+	if address == "" || keyName == "" {
+		return nil, errors.New("vault address and transit key name are required")
+	}
+
+	if mount == "" {
+		mount = "transit"
+	}
+
+	return &VaultTransit{
+		Address: strings.TrimRight(address, "/"),
+		Token:   token,
+		Mount:   strings.Trim(mount, "/"),
+		KeyName: keyName,
+		Client:  &http.Client{Timeout: 10 * time.Second},
+	}, nil
+}
+
+type transitResponse struct {
+	Data struct {
+		Ciphertext    string `json:"ciphertext"`
+		Plaintext     string `json:"plaintext"`
+		KeyVersion    int    `json:"key_version"`
+		LatestVersion int    `json:"latest_version"`
+	} `json:"data"`
+	Errors []string `json:"errors"`
+}
+
+func (v *VaultTransit) Wrap(ctx context.Context, dataKey []byte) (string, int, error) {
+	body := map[string]string{"plaintext": base64.StdEncoding.EncodeToString(dataKey)}
+
+	res, err := v.do(ctx, http.MethodPost, "encrypt/"+v.KeyName, body)
+	if err != nil {
+		return "", 0, err
+	}
+
+	version, err := ciphertextVersion(res.Data.Ciphertext)
+	if err != nil {
+		return "", 0, err
+	}
+
+	return res.Data.Ciphertext, version, nil
+}
+
+func (v *VaultTransit) Unwrap(ctx context.Context, wrapped string, version int) ([]byte, error) {
+	wrappedVersion, err := ciphertextVersion(wrapped)
+	if err != nil {
+		return nil, err
+	}
+
+	if wrappedVersion != version {
+		return nil, fmt.Errorf("%w: stored %d, ciphertext %d", ErrUnknownKeyVersion, version, wrappedVersion)
+	}
+
+	res, err := v.do(ctx, http.MethodPost, "decrypt/"+v.KeyName, map[string]string{"ciphertext": wrapped})
+	if err != nil {
+		return nil, err
+	}
+
+	return base64.StdEncoding.DecodeString(res.Data.Plaintext)
+}
+
+func (v *VaultTransit) CurrentVersion(ctx context.Context) (int, error) {
+	res, err := v.do(ctx, http.MethodGet, "keys/"+v.KeyName, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.Data.LatestVersion, nil
+}
+
+func (v *VaultTransit) do(ctx context.Context, method, path string, body any) (*transitResponse, error) {
+	var payload bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&payload).Encode(body); err != nil {
+			return nil, err
+		}
+	}
+
+	url := fmt.Sprintf("%s/v1/%s/%s", v.Address, v.Mount, path)
+	req, err := http.NewRequestWithContext(ctx, method, url, &payload)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("X-Vault-Token", v.Token)
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := v.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	decoded := new(transitResponse)
+	if err := json.NewDecoder(res.Body).Decode(decoded); err != nil {
+		return nil, fmt.Errorf("vault transit %s: unexpected response (%d)", path, res.StatusCode)
+	}
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("vault transit %s: %d %s", path, res.StatusCode, strings.Join(decoded.Errors, "; "))
+	}
+
+	return decoded, nil
+}
+
+// ciphertextVersion reads the key version from the "vault:v<N>:" prefix.
+func ciphertextVersion(ciphertext string) (int, error) {
+	parts := strings.SplitN(ciphertext, ":", 3)
+	if len(parts) != 3 || parts[0] != "vault" || !strings.HasPrefix(parts[1], "v") {
+		return 0, errors.New("malformed transit ciphertext")
+	}
+
+	return strconv.Atoi(strings.TrimPrefix(parts[1], "v"))
+}
@@ -0,0 +1,142 @@
+package sello
+
+import (
+	"app/src/fiel"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Almacen holds the certificates the SAT and the PACs stamp with, keyed by
+// their NoCertificado. They are published by the SAT and loaded from disk,
+// so SelloSAT can be verified without calling any web service.
+type Almacen struct {
+	mu           sync.RWMutex
+	certificados map[string]*x509.Certificate
+	autoridades  *x509.CertPool
+}
+
+func NewAlmacen() *Almacen {
+	return &Almacen{certificados: make(map[string]*x509.Certificate)}
+}
+
+// CargarAlmacen loads every .cer (DER) and .pem certificate in dir.
+func CargarAlmacen(dir string) (*Almacen, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("read certificates dir: %w", err)
+	}
+
+	almacen := NewAlmacen()
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		extension := strings.ToLower(filepath.Ext(entry.Name()))
+		if extension != ".cer" && extension != ".pem" {
+			continue
+		}
+
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("read certificate %s: %w", entry.Name(), err)
+		}
+
+		if err := almacen.cargar(data, extension == ".pem"); err != nil {
+			return nil, fmt.Errorf("load certificate %s: %w", entry.Name(), err)
+		}
+	}
+
+	return almacen, nil
+}
+
+func (a *Almacen) cargar(data []byte, esPEM bool) error {
+	if !esPEM {
+		return a.AgregarDER(data)
+	}
+
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			return nil
+		}
+
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+
+		if err := a.AgregarDER(block.Bytes); err != nil {
+			return err
+		}
+	}
+}
+
+// AgregarDER adds a DER encoded certificate. The certificates of the SAT
+// don't carry an RFC, so they are not parsed with fiel.ParseCertificate.
+func (a *Almacen) AgregarDER(der []byte) error {
+	certificado, err := x509.ParseCertificate(der)
+	if err != nil {
+		return fmt.Errorf("%w: %w", fiel.ErrInvalidCertificate, err)
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.certificados[fiel.NoCertificado(certificado.SerialNumber)] = certificado
+
+	return nil
+}
+
+// Certificado returns the certificate with the given NoCertificado. A nil
+// store has no certificates.
+func (a *Almacen) Certificado(noCertificado string) (*x509.Certificate, bool) {
+	if a == nil {
+		return nil, false
+	}
+
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	certificado, ok := a.certificados[strings.TrimSpace(noCertificado)]
+	return certificado, ok
+}
+
+// ConfiarEn sets the SAT certification authorities the certificates of the
+// emisores must chain up to.
+func (a *Almacen) ConfiarEn(autoridades *x509.CertPool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.autoridades = autoridades
+}
+
+// Autoridades returns the SAT certification authorities, nil when none
+// were configured or the store is nil.
+func (a *Almacen) Autoridades() *x509.CertPool {
+	if a == nil {
+		return nil
+	}
+
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	return a.autoridades
+}
+
+// Len is the number of certificates in the store.
+func (a *Almacen) Len() int {
+	if a == nil {
+		return 0
+	}
+
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	return len(a.certificados)
+}
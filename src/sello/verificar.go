@@ -0,0 +1,108 @@
+package sello
+
+import (
+	"app/src/cfdi"
+	"app/src/fiel"
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	ErrSinTimbre                 = errors.New("CFDI is not stamped")
+	ErrCertificadoInvalido       = errors.New("embedded certificate is invalid")
+	ErrCertificadoNoCorresponde  = errors.New("embedded certificate does not match NoCertificado or the emisor RFC")
+	ErrCertificadoNoVigente      = errors.New("embedded certificate was not valid at the CFDI fecha")
+	ErrCertificadoNoConfiable    = errors.New("embedded certificate was not issued by a SAT certification authority")
+	ErrSelloInvalido             = errors.New("sello does not match the cadena original")
+	ErrSelloCFDNoCorresponde     = errors.New("the TimbreFiscalDigital SelloCFD does not match Sello")
+	ErrCertificadoSATDesconocido = errors.New("the NoCertificadoSAT is not in the certificate store")
+	ErrSelloSATInvalido          = errors.New("the SelloSAT does not match the TimbreFiscalDigital cadena original")
+)
+
+// Verificar checks offline that the emisor sealed the CFDI with the
+// certificate it embeds, issued by one of the authorities of almacen, and
+// that the TimbreFiscalDigital was signed by a certificate of almacen.
+// data is the raw document comprobante was parsed from, the cadenas
+// originales are recomputed from it. The error tells the first check that
+// failed.
+func Verificar(data []byte, comprobante *cfdi.Comprobante, almacen *Almacen) error {
+	tfd := comprobante.Complemento.TimbreFiscalDigital
+	if tfd == nil {
+		return ErrSinTimbre
+	}
+
+	cadenaComprobante, cadenaTimbre, err := cfdi.CadenasOriginales(data)
+	if err != nil {
+		return err
+	}
+
+	der, err := decodificar(comprobante.Certificado)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrCertificadoInvalido, err)
+	}
+
+	certificado, err := fiel.ParseCertificate(der)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrCertificadoInvalido, err)
+	}
+
+	if certificado.NoCertificado != strings.TrimSpace(comprobante.NoCertificado) ||
+		!certificado.MatchesRFC(comprobante.Emisor.RFC) {
+		return ErrCertificadoNoCorresponde
+	}
+
+	switch err := certificado.Validate(comprobante.Fecha.Time, almacen.Autoridades()); {
+	case errors.Is(err, fiel.ErrExpiredCertificate):
+		return fmt.Errorf("%w: %w", ErrCertificadoNoVigente, err)
+	case err != nil:
+		return fmt.Errorf("%w: %w", ErrCertificadoNoConfiable, err)
+	}
+
+	if err := verificarFirma(certificado.X509, cadenaComprobante, comprobante.Sello); err != nil {
+		return fmt.Errorf("%w: %w", ErrSelloInvalido, err)
+	}
+
+	if strings.TrimSpace(tfd.SelloCFD) != strings.TrimSpace(comprobante.Sello) {
+		return ErrSelloCFDNoCorresponde
+	}
+
+	certificadoSAT, ok := almacen.Certificado(tfd.NoCertificadoSAT)
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrCertificadoSATDesconocido, tfd.NoCertificadoSAT)
+	}
+
+	if err := verificarFirma(certificadoSAT, cadenaTimbre, tfd.SelloSAT); err != nil {
+		return fmt.Errorf("%w: %w", ErrSelloSATInvalido, err)
+	}
+
+	return nil
+}
+
+// verificarFirma checks a base64 PKCS#1 v1.5 SHA-256 signature, the one
+// CFDI 3.3 and 4.0 seals use.
+func verificarFirma(certificado *x509.Certificate, cadena, firma string) error {
+	key, ok := certificado.PublicKey.(*rsa.PublicKey)
+	if !ok {
+		return errors.New("certificate key is not RSA")
+	}
+
+	signature, err := decodificar(firma)
+	if err != nil {
+		return err
+	}
+
+	digest := sha256.Sum256([]byte(cadena))
+
+	return rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature)
+}
+
+// decodificar decodes a base64 attribute, which some emisores wrap.
+func decodificar(valor string) ([]byte, error) {
+	return base64.StdEncoding.DecodeString(strings.Join(strings.Fields(valor), ""))
+}
@@ -0,0 +1,61 @@
+package service
+
+import (
+	"app/src/model"
+	"app/src/utils"
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type CartaPorteService interface {
+	GetCartaPorte(c *fiber.Ctx, userID uuid.UUID, id uuid.UUID) (*model.CFDICartaPorte, error)
+}
+
+type cartaPorteService struct {
+	Log *logrus.Logger
+	DB  *gorm.DB
+}
+
+func NewCartaPorteService(db *gorm.DB) CartaPorteService {
+	return &cartaPorteService{
+		Log: utils.Log,
+		DB:  db,
+	}
+}
+
+// GetCartaPorte returns the Carta Porte complement of a CFDI of the user
+// RFCs with its nodes in document order and the incidencias found by the
+// structural validation.
+func (s *cartaPorteService) GetCartaPorte(c *fiber.Ctx, userID uuid.UUID, id uuid.UUID) (*model.CFDICartaPorte, error) {
+	cartaPorte := new(model.CFDICartaPorte)
+
+	db := s.DB.WithContext(c.Context())
+
+	porNumero := func(db *gorm.DB) *gorm.DB {
+		return db.Order("numero asc")
+	}
+
+	result := db.
+		Preload("Ubicaciones", porNumero).
+		Preload("Mercancias", porNumero).
+		Preload("Remolques", porNumero).
+		Preload("Figuras", porNumero).
+		Preload("Incidencias", porNumero).
+		Where("cfdi_uuid IN (?)", propios(db.Model(&model.CFDI{}), "cfdis", userID).
+			Select("cfdis.uuid").
+			Where("cfdis.uuid = ?", id)).
+		First(cartaPorte)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, fiber.NewError(fiber.StatusNotFound, "Carta Porte not found")
+	}
+	if result.Error != nil {
+		s.Log.Errorf("Failed to get the Carta Porte of CFDI %s: %+v", id, result.Error)
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to retrieve Carta Porte")
+	}
+
+	return cartaPorte, nil
+}
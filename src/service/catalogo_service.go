@@ -0,0 +1,341 @@
+package service
+
+import (
+	"app/src/catalogo"
+	"app/src/model"
+	"app/src/response"
+	"app/src/utils"
+	"app/src/validation"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type CatalogoService interface {
+	GetCatalogos(c *fiber.Ctx) ([]response.Catalogo, error)
+	BuscarRegistros(c *fiber.Ctx, slug string, params *validation.QueryCatalogo) ([]model.CatalogoRegistro, int64, error)
+	GetRegistro(c *fiber.Ctx, slug, clave, fecha string) (*model.CatalogoRegistro, error)
+	Publicacion(ctx context.Context, nombre string, fecha time.Time) (*model.CatalogoPublicacion, error)
+	Registro(ctx context.Context, nombre, clave string, fecha time.Time) (*model.CatalogoRegistro, error)
+	Importar(
+		ctx context.Context, nombre string, fechaPublicacion time.Time, registros []catalogo.Registro,
+	) (int, error)
+}
+
+// busquedaCatalogo is a page of search results kept in the cache.
+type busquedaCatalogo struct {
+	registros []model.CatalogoRegistro
+	total     int64
+}
+
+type catalogoService struct {
+	Log           *logrus.Logger
+	DB            *gorm.DB
+	Validate      *validator.Validate
+	Publicaciones *catalogo.Cache[*model.CatalogoPublicacion]
+	Registros     *catalogo.Cache[*model.CatalogoRegistro]
+	Busquedas     *catalogo.Cache[busquedaCatalogo]
+	BatchSize     int
+}
+
+func NewCatalogoService(db *gorm.DB, validate *validator.Validate, ttl time.Duration) CatalogoService {
+	return &catalogoService{
+		Log:           utils.Log,
+		DB:            db,
+		Validate:      validate,
+		Publicaciones: catalogo.NewCache[*model.CatalogoPublicacion](ttl),
+		Registros:     catalogo.NewCache[*model.CatalogoRegistro](ttl),
+		Busquedas:     catalogo.NewCache[busquedaCatalogo](ttl),
+		BatchSize:     1000,
+	}
+}
+
+// GetCatalogos lists the supported catalogs with their newest publication.
+func (s *catalogoService) GetCatalogos(c *fiber.Ctx) ([]response.Catalogo, error) {
+	catalogos := make([]response.Catalogo, 0, len(catalogo.Catalogos))
+
+	for _, definicion := range catalogo.Catalogos {
+		publicacion, err := s.Publicacion(c.Context(), definicion.Nombre, time.Time{})
+		if err != nil {
+			s.Log.Errorf("Failed to get the publication of %s: %+v", definicion.Nombre, err)
+			return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to retrieve catalogs")
+		}
+
+		item := response.Catalogo{Nombre: definicion.Nombre, Slug: definicion.Slug}
+		if publicacion != nil {
+			item.FechaPublicacion = &publicacion.FechaPublicacion
+			item.Registros = publicacion.Registros
+		}
+
+		catalogos = append(catalogos, item)
+	}
+
+	return catalogos, nil
+}
+
+// BuscarRegistros searches a catalog by clave prefix or description, and
+// by the similar words of c_ClaveProdServ, ordered by clave.
+func (s *catalogoService) BuscarRegistros(
+	c *fiber.Ctx, slug string, params *validation.QueryCatalogo,
+) ([]model.CatalogoRegistro, int64, error) {
+	if err := s.Validate.Struct(params); err != nil {
+		return nil, 0, err
+	}
+
+	publicacion, err := s.publicacionPorSlug(c, slug, params.Fecha)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	q := strings.TrimSpace(params.Q)
+	key := fmt.Sprintf("%s|%s|%d|%d", publicacion.ID, strings.ToLower(q), params.Page, params.Limit)
+	if busqueda, ok := s.Busquedas.Get(key); ok {
+		return busqueda.registros, busqueda.total, nil
+	}
+
+	var registros []model.CatalogoRegistro
+	var totalResults int64
+
+	query := s.DB.WithContext(c.Context()).Model(&model.CatalogoRegistro{}).
+		Where("publicacion_id = ?", publicacion.ID)
+
+	if q != "" {
+		query = query.Where(
+			"clave ILIKE ? OR descripcion ILIKE ? OR atributos->>'palabras_similares' ILIKE ?",
+			q+"%", "%"+q+"%", "%"+q+"%",
+		)
+	}
+
+	if result := query.Count(&totalResults); result.Error != nil {
+		s.Log.Errorf("Failed to count catalog entries: %+v", result.Error)
+		return nil, 0, fiber.NewError(fiber.StatusInternalServerError, "Failed to search the catalog")
+	}
+
+	offset := (params.Page - 1) * params.Limit
+	result := query.Order("clave asc").Limit(params.Limit).Offset(offset).Find(&registros)
+	if result.Error != nil {
+		s.Log.Errorf("Failed to search catalog entries: %+v", result.Error)
+		return nil, 0, fiber.NewError(fiber.StatusInternalServerError, "Failed to search the catalog")
+	}
+
+	s.Busquedas.Set(key, busquedaCatalogo{registros: registros, total: totalResults})
+
+	return registros, totalResults, nil
+}
+
+// GetRegistro returns an entry of a catalog by clave.
+func (s *catalogoService) GetRegistro(c *fiber.Ctx, slug, clave, fecha string) (*model.CatalogoRegistro, error) {
+	if err := s.Validate.Var(fecha, "omitempty,datetime=2006-01-02"); err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "fecha must be a YYYY-MM-DD date")
+	}
+
+	publicacion, err := s.publicacionPorSlug(c, slug, fecha)
+	if err != nil {
+		return nil, err
+	}
+
+	definicion, _ := catalogo.PorSlug(slug)
+
+	registro, err := s.registro(c.Context(), publicacion, definicion.NormalizarClave(clave))
+	if err != nil {
+		s.Log.Errorf("Failed to get catalog entry: %+v", err)
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to retrieve the catalog entry")
+	}
+
+	if registro == nil {
+		return nil, fiber.NewError(fiber.StatusNotFound, "Catalog entry not found")
+	}
+
+	return registro, nil
+}
+
+// Publicacion returns the newest publication of the catalog on or before
+// fecha, or the oldest one when all are newer, since that is the closest
+// to what was in effect. A zero fecha returns the newest publication. It
+// is nil while the catalog has not been imported.
+func (s *catalogoService) Publicacion(
+	ctx context.Context, nombre string, fecha time.Time,
+) (*model.CatalogoPublicacion, error) {
+	dia := ""
+	if !fecha.IsZero() {
+		dia = fecha.Format(time.DateOnly)
+	}
+
+	key := nombre + "|" + dia
+	if publicacion, ok := s.Publicaciones.Get(key); ok {
+		return publicacion, nil
+	}
+
+	var publicaciones []model.CatalogoPublicacion
+
+	query := s.DB.WithContext(ctx).Where("catalogo = ?", nombre)
+	if dia != "" {
+		query = query.Where("fecha_publicacion <= ?", dia)
+	}
+
+	if err := query.Order("fecha_publicacion desc").Limit(1).Find(&publicaciones).Error; err != nil {
+		return nil, err
+	}
+
+	if len(publicaciones) == 0 && dia != "" {
+		err := s.DB.WithContext(ctx).Where("catalogo = ?", nombre).
+			Order("fecha_publicacion asc").Limit(1).Find(&publicaciones).Error
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var publicacion *model.CatalogoPublicacion
+	if len(publicaciones) > 0 {
+		publicacion = &publicaciones[0]
+	}
+
+	s.Publicaciones.Set(key, publicacion)
+
+	return publicacion, nil
+}
+
+// Registro returns the entry of the catalog publication in effect on
+// fecha, nil when the clave is not in it or the catalog has not been
+// imported.
+func (s *catalogoService) Registro(
+	ctx context.Context, nombre, clave string, fecha time.Time,
+) (*model.CatalogoRegistro, error) {
+	publicacion, err := s.Publicacion(ctx, nombre, fecha)
+	if err != nil || publicacion == nil {
+		return nil, err
+	}
+
+	definicion, _ := catalogo.PorNombre(nombre)
+
+	return s.registro(ctx, publicacion, definicion.NormalizarClave(clave))
+}
+
+func (s *catalogoService) registro(
+	ctx context.Context, publicacion *model.CatalogoPublicacion, clave string,
+) (*model.CatalogoRegistro, error) {
+	key := publicacion.ID.String() + "|" + clave
+	if registro, ok := s.Registros.Get(key); ok {
+		return registro, nil
+	}
+
+	registro := new(model.CatalogoRegistro)
+
+	result := s.DB.WithContext(ctx).First(registro, "publicacion_id = ? AND clave = ?", publicacion.ID, clave)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		registro = nil
+	} else if result.Error != nil {
+		return nil, result.Error
+	}
+
+	s.Registros.Set(key, registro)
+
+	return registro, nil
+}
+
+func (s *catalogoService) publicacionPorSlug(
+	c *fiber.Ctx, slug, fecha string,
+) (*model.CatalogoPublicacion, error) {
+	definicion, ok := catalogo.PorSlug(slug)
+	if !ok {
+		return nil, fiber.NewError(fiber.StatusNotFound, "Catalog not found")
+	}
+
+	var dia time.Time
+	if fecha != "" {
+		dia, _ = time.Parse(time.DateOnly, fecha)
+	}
+
+	publicacion, err := s.Publicacion(c.Context(), definicion.Nombre, dia)
+	if err != nil {
+		s.Log.Errorf("Failed to get the publication of %s: %+v", definicion.Nombre, err)
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to retrieve the catalog")
+	}
+
+	if publicacion == nil {
+		return nil, fiber.NewError(fiber.StatusNotFound, "Catalog has not been imported")
+	}
+
+	return publicacion, nil
+}
+
+// Importar stores a publication of the catalog and returns how many rows
+// it has. Importing the same publication again replaces its rows but keeps
+// its ID, so the lookups cached by the API stay valid.
+func (s *catalogoService) Importar(
+	ctx context.Context, nombre string, fechaPublicacion time.Time, registros []catalogo.Registro,
+) (int, error) {
+	definicion, ok := catalogo.PorNombre(nombre)
+	if !ok {
+		return 0, fmt.Errorf("%w: %s", catalogo.ErrCatalogoDesconocido, nombre)
+	}
+
+	dia := fechaPublicacion.Format(time.DateOnly)
+
+	err := s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var publicaciones []model.CatalogoPublicacion
+
+		err := tx.Where("catalogo = ? AND fecha_publicacion = ?", definicion.Nombre, dia).
+			Limit(1).Find(&publicaciones).Error
+		if err != nil {
+			return err
+		}
+
+		var publicacion *model.CatalogoPublicacion
+		if len(publicaciones) > 0 {
+			publicacion = &publicaciones[0]
+
+			if err := tx.Where("publicacion_id = ?", publicacion.ID).Delete(&model.CatalogoRegistro{}).Error; err != nil {
+				return err
+			}
+
+			publicacion.Registros = len(registros)
+			if err := tx.Save(publicacion).Error; err != nil {
+				return err
+			}
+		} else {
+			fecha, _ := time.Parse(time.DateOnly, dia)
+			publicacion = &model.CatalogoPublicacion{
+				ID:               uuid.New(),
+				Catalogo:         definicion.Nombre,
+				FechaPublicacion: fecha,
+				Registros:        len(registros),
+			}
+
+			if err := tx.Create(publicacion).Error; err != nil {
+				return err
+			}
+		}
+
+		if len(registros) == 0 {
+			return nil
+		}
+
+		filas := make([]model.CatalogoRegistro, 0, len(registros))
+		for _, registro := range registros {
+			filas = append(filas, model.CatalogoRegistro{
+				PublicacionID:       publicacion.ID,
+				Clave:               registro.Clave,
+				Descripcion:         registro.Descripcion,
+				FechaInicioVigencia: registro.FechaInicioVigencia,
+				FechaFinVigencia:    registro.FechaFinVigencia,
+				Atributos:           registro.Atributos,
+			})
+		}
+
+		return tx.CreateInBatches(filas, s.BatchSize).Error
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return len(registros), nil
+}
@@ -0,0 +1,212 @@
+package service
+
+import (
+	"app/src/cfdi"
+	"app/src/model"
+	"app/src/utils"
+	"app/src/validation"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type CFDIService interface {
+	GetCFDIs(c *fiber.Ctx, userID uuid.UUID, params *validation.QueryCFDI) ([]model.CFDI, int64, error)
+	GetConciliacion(c *fiber.Ctx, userID uuid.UUID, params *validation.QueryConciliacion) ([]model.CFDIMetadata, []model.CFDI, error)
+}
+
+type cfdiService struct {
+	Log      *logrus.Logger
+	DB       *gorm.DB
+	Validate *validator.Validate
+}
+
+func NewCFDIService(db *gorm.DB, validate *validator.Validate) CFDIService {
+	return &cfdiService{
+		Log:      utils.Log,
+		DB:       db,
+		Validate: validate,
+	}
+}
+
+// GetCFDIs lists the indexed CFDIs emitted or received by the RFCs the user
+// registered, newest first.
+func (s *cfdiService) GetCFDIs(
+	c *fiber.Ctx, userID uuid.UUID, params *validation.QueryCFDI,
+) ([]model.CFDI, int64, error) {
+	var cfdis []model.CFDI
+	var totalResults int64
+
+	if err := s.Validate.Struct(params); err != nil {
+		return nil, 0, err
+	}
+
+	db := s.DB.WithContext(c.Context())
+
+	query, err := s.filtrar(propios(db.Model(&model.CFDI{}), "cfdis", userID), params)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	result := query.Count(&totalResults)
+	if result.Error != nil {
+		s.Log.Errorf("Failed to count CFDIs: %+v", result.Error)
+		return nil, 0, fiber.NewError(fiber.StatusInternalServerError, "Failed to retrieve CFDIs")
+	}
+
+	offset := (params.Page - 1) * params.Limit
+
+	result = query.Preload("Conceptos", func(db *gorm.DB) *gorm.DB {
+		return db.Order("numero asc")
+	}).Order("fecha desc, uuid asc").Limit(params.Limit).Offset(offset).Find(&cfdis)
+	if result.Error != nil {
+		s.Log.Errorf("Failed to get CFDIs: %+v", result.Error)
+		return nil, 0, fiber.NewError(fiber.StatusInternalServerError, "Failed to retrieve CFDIs")
+	}
+
+	return cfdis, totalResults, nil
+}
+
+// GetConciliacion compares the metadata with the indexed XMLs of the user
+// RFCs. It returns the metadata rows whose XML was never indexed and the
+// indexed XMLs that were cancelled afterwards, newest first.
+func (s *cfdiService) GetConciliacion(
+	c *fiber.Ctx, userID uuid.UUID, params *validation.QueryConciliacion,
+) ([]model.CFDIMetadata, []model.CFDI, error) {
+	var sinXML []model.CFDIMetadata
+	var cancelados []model.CFDI
+
+	if err := s.Validate.Struct(params); err != nil {
+		return nil, nil, err
+	}
+
+	if err := validarRangoFechas(params.FechaInicial, params.FechaFinal); err != nil {
+		return nil, nil, err
+	}
+
+	db := s.DB.WithContext(c.Context())
+
+	query := propios(db.Model(&model.CFDIMetadata{}), "cfdis_metadata", userID).
+		Where("NOT EXISTS (SELECT 1 FROM cfdis WHERE cfdis.uuid = cfdis_metadata.uuid)")
+	query = filtrarFechas(query, "cfdis_metadata.fecha_emision", params.FechaInicial, params.FechaFinal)
+
+	result := query.Order("fecha_emision desc, uuid asc").Find(&sinXML)
+	if result.Error != nil {
+		s.Log.Errorf("Failed to get metadata without XML: %+v", result.Error)
+		return nil, nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to reconcile CFDIs")
+	}
+
+	query = propios(db.Model(&model.CFDI{}), "cfdis", userID).
+		Where("cfdis.estado = ?", model.EstadoCFDICancelado)
+	query = filtrarFechas(query, "cfdis.fecha", params.FechaInicial, params.FechaFinal)
+
+	result = query.Preload("Metadata").Order("fecha desc, uuid asc").Find(&cancelados)
+	if result.Error != nil {
+		s.Log.Errorf("Failed to get cancelled CFDIs: %+v", result.Error)
+		return nil, nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to reconcile CFDIs")
+	}
+
+	return sinXML, cancelados, nil
+}
+
+// propios scopes the query to the rows of tabla where one of the RFCs
+// registered by the user is the emisor or the receptor.
+func propios(query *gorm.DB, tabla string, userID uuid.UUID) *gorm.DB {
+	rfcs := rfcsDelUsuario(query, userID)
+
+	return query.Where(fmt.Sprintf("(%[1]s.rfc_emisor IN (?) OR %[1]s.rfc_receptor IN (?))", tabla), rfcs, rfcs)
+}
+
+// rfcsDelUsuario is a subquery of the RFCs registered by the user.
+func rfcsDelUsuario(query *gorm.DB, userID uuid.UUID) *gorm.DB {
+	return query.Session(&gorm.Session{NewDB: true}).Model(&model.DatosFiscalesSAT{}).
+		Select("rfc").Where("user_id = ?", userID)
+}
+
+func (s *cfdiService) filtrar(query *gorm.DB, params *validation.QueryCFDI) (*gorm.DB, error) {
+	if err := validarRangoFechas(params.FechaInicial, params.FechaFinal); err != nil {
+		return nil, err
+	}
+
+	totalMin, err := parseTotal(params.TotalMin, "total_min")
+	if err != nil {
+		return nil, err
+	}
+
+	totalMax, err := parseTotal(params.TotalMax, "total_max")
+	if err != nil {
+		return nil, err
+	}
+
+	if totalMin != nil && totalMax != nil && totalMax.Cmp(*totalMin) < 0 {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "total_max must not be less than total_min")
+	}
+
+	query = filtrarFechas(query, "cfdis.fecha", params.FechaInicial, params.FechaFinal)
+
+	if rfc := strings.ToUpper(params.RFC); rfc != "" {
+		query = query.Where("(rfc_emisor = ? OR rfc_receptor = ?)", rfc, rfc)
+	}
+
+	if params.Tipo != "" {
+		query = query.Where("tipo_comprobante = ?", params.Tipo)
+	}
+
+	if totalMin != nil {
+		query = query.Where("total >= ?", *totalMin)
+	}
+
+	if totalMax != nil {
+		query = query.Where("total <= ?", *totalMax)
+	}
+
+	if search := params.Search; search != "" {
+		query = query.Where("EXISTS (SELECT 1 FROM cfdis_conceptos WHERE cfdis_conceptos.cfdi_uuid = cfdis.uuid AND cfdis_conceptos.descripcion ILIKE ?)",
+			"%"+search+"%")
+	}
+
+	return query, nil
+}
+
+// parseTotal parses an amount filter, nil when it wasn't given.
+func parseTotal(valor, campo string) (*cfdi.Decimal, error) {
+	if valor == "" {
+		return nil, nil
+	}
+
+	total, err := cfdi.ParseDecimal(valor)
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, campo+" must be a decimal amount")
+	}
+
+	return &total, nil
+}
+
+func validarRangoFechas(inicial, final string) error {
+	if inicial != "" && final != "" && final < inicial {
+		return fiber.NewError(fiber.StatusBadRequest, "fecha_final must not be before fecha_inicial")
+	}
+
+	return nil
+}
+
+// filtrarFechas filters columna by the dates, already validated as
+// YYYY-MM-DD. The whole final day is included.
+func filtrarFechas(query *gorm.DB, columna, inicial, final string) *gorm.DB {
+	if inicial != "" {
+		query = query.Where(columna+" >= ?", inicial)
+	}
+
+	if final != "" {
+		fin, _ := time.Parse(time.DateOnly, final)
+		query = query.Where(columna+" < ?", fin.AddDate(0, 0, 1).Format(time.DateOnly))
+	}
+
+	return query
+}
@@ -1,17 +1,17 @@
 package service
 
 import (
+	"app/src/fiel"
 	"app/src/model"
+	"app/src/secrets"
 	"app/src/utils"
 	"app/src/validation"
-	"crypto/aes"
-	"crypto/cipher"
-	"crypto/rand"
-	"crypto/sha256"
-	"encoding/base64"
+	"context"
+	"crypto/x509"
 	"errors"
 	"io"
 	"mime/multipart"
+	"strings"
 	"time"
 
 	"github.com/go-playground/validator/v10"
@@ -19,100 +19,116 @@ import (
 	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type DatosFiscalesService interface {
-	CreateDatosFiscales(c *fiber.Ctx, userID uuid.UUID, req *validation.DatosFiscalesRequest, cerFile, keyFile *multipart.FileHeader) error
-	GetDatosFiscalesByUserID(c *fiber.Ctx, userID uuid.UUID) (*model.DatosFiscalesSAT, error)
-	UpdateDatosFiscales(c *fiber.Ctx, userID uuid.UUID, req *validation.DatosFiscalesRequest) error
-	DeleteDatosFiscales(c *fiber.Ctx, userID uuid.UUID) error
+	CreateDatosFiscales(c *fiber.Ctx, userID uuid.UUID, req *validation.DatosFiscalesRequest, cerFile, keyFile *multipart.FileHeader) (*model.DatosFiscalesSAT, error)
+	GetDatosFiscales(c *fiber.Ctx, userID uuid.UUID, params *validation.QueryDatosFiscales) ([]model.DatosFiscalesSAT, int64, error)
+	GetDatosFiscalesByID(c *fiber.Ctx, userID uuid.UUID, id string) (*model.DatosFiscalesSAT, error)
+	UpdateDatosFiscales(c *fiber.Ctx, userID uuid.UUID, id string, req *validation.UpdateDatosFiscalesRequest) error
+	DeleteDatosFiscales(c *fiber.Ctx, userID uuid.UUID, id string) error
+	RenovarEfirma(
+		c *fiber.Ctx, userID uuid.UUID, id string, req *validation.RenovarEfirmaRequest, cerFile, keyFile *multipart.FileHeader,
+	) (*model.DatosFiscalesSAT, error)
+	GetHistorialCertificados(c *fiber.Ctx, userID uuid.UUID, id string) ([]model.HistorialCertificadoFiscal, error)
 }
 
 type datosFiscalesService struct {
-	Log           *logrus.Logger
-	DB            *gorm.DB
-	Validate      *validator.Validate
-	EncryptionKey string
+	Log         *logrus.Logger
+	DB          *gorm.DB
+	Validate    *validator.Validate
+	Secrets     *fiscalSecrets
+	Autoridades *x509.CertPool
 }
 
-func NewDatosFiscalesService(db *gorm.DB, validate *validator.Validate, encryptionKey string) DatosFiscalesService {
+// NewDatosFiscalesService builds the service. Certificates must chain up to
+// one of autoridades, the SAT certification authorities; a nil pool rejects
+// every certificate.
+func NewDatosFiscalesService(
+	db *gorm.DB, validate *validator.Validate, keyProvider secrets.KeyProvider, autoridades *x509.CertPool,
+) DatosFiscalesService {
 	return &datosFiscalesService{
-		Log:           utils.Log,
-		DB:            db,
-		Validate:      validate,
-		EncryptionKey: encryptionKey,
+		Log:         utils.Log,
+		DB:          db,
+		Validate:    validate,
+		Secrets:     &fiscalSecrets{Provider: keyProvider},
+		Autoridades: autoridades,
 	}
 }
 
-func (s *datosFiscalesService) CreateDatosFiscales(c *fiber.Ctx, userID uuid.UUID, req *validation.DatosFiscalesRequest, cerFile, keyFile *multipart.FileHeader) error {
+func (s *datosFiscalesService) CreateDatosFiscales(
+	c *fiber.Ctx, userID uuid.UUID, req *validation.DatosFiscalesRequest, cerFile, keyFile *multipart.FileHeader,
+) (*model.DatosFiscalesSAT, error) {
 	if err := s.Validate.Struct(req); err != nil {
-		return err
+		return nil, err
 	}
 
 	var existingData model.DatosFiscalesSAT
-	result := s.DB.WithContext(c.Context()).Where("user_id = ?", userID).First(&existingData)
+	result := s.DB.WithContext(c.Context()).Where("user_id = ? AND rfc = ?", userID, strings.ToUpper(req.RFC)).First(&existingData)
 	if result.Error == nil {
-		return fiber.NewError(fiber.StatusConflict, "Fiscal data already exists for this user")
+		return nil, fiber.NewError(fiber.StatusConflict, "Fiscal data already exists for this RFC")
 	}
 
-	if err := s.validateFileExtensions(cerFile, keyFile); err != nil {
-		return err
-	}
-
-	cerB64, err := s.fileToBase64(cerFile)
+	datosFiscales, err := s.loadEfirma(c.Context(), cerFile, keyFile, req.RFC, req.Password)
 	if err != nil {
-		s.Log.Errorf("Error processing .cer file: %+v", err)
-		return fiber.NewError(fiber.StatusBadRequest, "Error processing certificate file")
+		return nil, err
 	}
 
-	keyB64, err := s.fileToBase64(keyFile)
-	if err != nil {
-		s.Log.Errorf("Error processing .key file: %+v", err)
-		return fiber.NewError(fiber.StatusBadRequest, "Error processing key file")
+	datosFiscales.UserID = userID
+	datosFiscales.CreatedBy = &userID
+	datosFiscales.CreatedAt = time.Now()
+	datosFiscales.UpdatedAt = time.Now()
+
+	result = s.DB.WithContext(c.Context()).Create(datosFiscales)
+	if errors.Is(result.Error, gorm.ErrDuplicatedKey) {
+		return nil, fiber.NewError(fiber.StatusConflict, "Fiscal data already exists for this RFC")
 	}
 
-	cerEncrypted, err := s.encrypt(cerB64)
-	if err != nil {
-		s.Log.Errorf("Error encrypting .cer file: %+v", err)
-		return fiber.NewError(fiber.StatusInternalServerError, "Error processing certificate")
+	if result.Error != nil {
+		s.Log.Errorf("Failed to create fiscal data: %+v", result.Error)
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to save fiscal data")
 	}
 
-	keyEncrypted, err := s.encrypt(keyB64)
-	if err != nil {
-		s.Log.Errorf("Error encrypting .key file: %+v", err)
-		return fiber.NewError(fiber.StatusInternalServerError, "Error processing key")
+	return datosFiscales, nil
+}
+
+func (s *datosFiscalesService) GetDatosFiscales(
+	c *fiber.Ctx, userID uuid.UUID, params *validation.QueryDatosFiscales,
+) ([]model.DatosFiscalesSAT, int64, error) {
+	var datosFiscales []model.DatosFiscalesSAT
+	var totalResults int64
+
+	if err := s.Validate.Struct(params); err != nil {
+		return nil, 0, err
 	}
 
-	passwordEncrypted, err := s.encrypt(req.Password)
-	if err != nil {
-		s.Log.Errorf("Error encrypting password: %+v", err)
-		return fiber.NewError(fiber.StatusInternalServerError, "Error processing password")
+	offset := (params.Page - 1) * params.Limit
+	query := s.DB.WithContext(c.Context()).Where("user_id = ?", userID).Order("rfc asc")
+
+	if search := params.Search; search != "" {
+		query = query.Where("rfc ILIKE ?", "%"+search+"%")
 	}
 
-	datosFiscales := &model.DatosFiscalesSAT{
-		UserID:               userID,
-		RFC:                  req.RFC,
-		CerB64Encriptado:     cerEncrypted,
-		KeyB64Encriptado:     keyEncrypted,
-		PasswordEfirmaEncrip: passwordEncrypted,
-		CreatedBy:            &userID,
-		CreatedAt:            time.Now(),
-		UpdatedAt:            time.Now(),
+	result := query.Model(&model.DatosFiscalesSAT{}).Count(&totalResults)
+	if result.Error != nil {
+		s.Log.Errorf("Failed to count fiscal data: %+v", result.Error)
+		return nil, 0, result.Error
 	}
 
-	result = s.DB.WithContext(c.Context()).Create(datosFiscales)
+	result = query.Limit(params.Limit).Offset(offset).Find(&datosFiscales)
 	if result.Error != nil {
-		s.Log.Errorf("Failed to create fiscal data: %+v", result.Error)
-		return fiber.NewError(fiber.StatusInternalServerError, "Failed to save fiscal data")
+		s.Log.Errorf("Failed to get fiscal data: %+v", result.Error)
+		return nil, 0, result.Error
 	}
 
-	return nil
+	return datosFiscales, totalResults, nil
 }
 
-func (s *datosFiscalesService) GetDatosFiscalesByUserID(c *fiber.Ctx, userID uuid.UUID) (*model.DatosFiscalesSAT, error) {
+func (s *datosFiscalesService) GetDatosFiscalesByID(c *fiber.Ctx, userID uuid.UUID, id string) (*model.DatosFiscalesSAT, error) {
 	datosFiscales := new(model.DatosFiscalesSAT)
 
-	result := s.DB.WithContext(c.Context()).Where("user_id = ?", userID).First(datosFiscales)
+	result := s.DB.WithContext(c.Context()).Where("uuid = ? AND user_id = ?", id, userID).First(datosFiscales)
 
 	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
 		return nil, fiber.NewError(fiber.StatusNotFound, "Fiscal data not found")
@@ -126,27 +142,28 @@ func (s *datosFiscalesService) GetDatosFiscalesByUserID(c *fiber.Ctx, userID uui
 	return datosFiscales, nil
 }
 
-func (s *datosFiscalesService) UpdateDatosFiscales(c *fiber.Ctx, userID uuid.UUID, req *validation.DatosFiscalesRequest) error {
+// UpdateDatosFiscales changes the password of the stored e.firma. The key is
+// only decrypted again when a new password comes in.
+func (s *datosFiscalesService) UpdateDatosFiscales(
+	c *fiber.Ctx, userID uuid.UUID, id string, req *validation.UpdateDatosFiscalesRequest,
+) error {
 	if err := s.Validate.Struct(req); err != nil {
 		return err
 	}
 
-	datosFiscales, err := s.GetDatosFiscalesByUserID(c, userID)
+	datosFiscales, err := s.GetDatosFiscalesByID(c, userID, id)
 	if err != nil {
 		return err
 	}
 
-	if req.Password != "" {
-		passwordEncrypted, err := s.encrypt(req.Password)
-		if err != nil {
-			s.Log.Errorf("Error encrypting password: %+v", err)
-			return fiber.NewError(fiber.StatusInternalServerError, "Error processing password")
-		}
-		datosFiscales.PasswordEfirmaEncrip = passwordEncrypted
+	if req.RFC != "" && !strings.EqualFold(req.RFC, datosFiscales.RFC) {
+		return fiber.NewError(fiber.StatusBadRequest, "RFC does not match the registered certificate")
 	}
 
-	if req.RFC != "" {
-		datosFiscales.RFC = req.RFC
+	if req.Password != "" {
+		if err := s.updatePassword(c.Context(), datosFiscales, req.Password); err != nil {
+			return err
+		}
 	}
 
 	datosFiscales.UpdatedAt = time.Now()
@@ -161,8 +178,8 @@ func (s *datosFiscalesService) UpdateDatosFiscales(c *fiber.Ctx, userID uuid.UUI
 	return nil
 }
 
-func (s *datosFiscalesService) DeleteDatosFiscales(c *fiber.Ctx, userID uuid.UUID) error {
-	result := s.DB.WithContext(c.Context()).Where("user_id = ?", userID).Delete(&model.DatosFiscalesSAT{})
+func (s *datosFiscalesService) DeleteDatosFiscales(c *fiber.Ctx, userID uuid.UUID, id string) error {
+	result := s.DB.WithContext(c.Context()).Where("uuid = ? AND user_id = ?", id, userID).Delete(&model.DatosFiscalesSAT{})
 
 	if result.Error != nil {
 		s.Log.Errorf("Failed to delete fiscal data: %+v", result.Error)
@@ -176,6 +193,152 @@ func (s *datosFiscalesService) DeleteDatosFiscales(c *fiber.Ctx, userID uuid.UUI
 	return nil
 }
 
+var errCertificadoRegistrado = errors.New("certificate is already registered")
+
+func (s *datosFiscalesService) RenovarEfirma(
+	c *fiber.Ctx, userID uuid.UUID, id string, req *validation.RenovarEfirmaRequest, cerFile, keyFile *multipart.FileHeader,
+) (*model.DatosFiscalesSAT, error) {
+	if err := s.Validate.Struct(req); err != nil {
+		return nil, err
+	}
+
+	datosFiscales, err := s.GetDatosFiscalesByID(c, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	renewed, err := s.loadEfirma(c.Context(), cerFile, keyFile, datosFiscales.RFC, req.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	// The row is read again and locked so concurrent renewals swap one
+	// certificate at a time and each lands in the history.
+	actual := new(model.DatosFiscalesSAT)
+	now := time.Now()
+
+	err = s.DB.WithContext(c.Context()).Transaction(func(tx *gorm.DB) error {
+		result := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("uuid = ? AND user_id = ?", datosFiscales.UUID, userID).
+			First(actual)
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if renewed.NoCertificado == actual.NoCertificado {
+			return errCertificadoRegistrado
+		}
+
+		historial := &model.HistorialCertificadoFiscal{
+			DatosFiscalesUUID: actual.UUID,
+			NoCertificado:     actual.NoCertificado,
+			EmisorCertificado: actual.EmisorCertificado,
+			ValidoDesde:       actual.ValidoDesde,
+			ValidoHasta:       actual.ValidoHasta,
+			ReemplazadoAt:     now,
+			ReemplazadoBy:     &userID,
+		}
+		if err := tx.Create(historial).Error; err != nil {
+			return err
+		}
+
+		actual.CerB64Encriptado = renewed.CerB64Encriptado
+		actual.KeyB64Encriptado = renewed.KeyB64Encriptado
+		actual.PasswordEfirmaEncrip = renewed.PasswordEfirmaEncrip
+		actual.DataKeyEncriptada = renewed.DataKeyEncriptada
+		actual.KeyVersion = renewed.KeyVersion
+		actual.NoCertificado = renewed.NoCertificado
+		actual.EmisorCertificado = renewed.EmisorCertificado
+		actual.ValidoDesde = renewed.ValidoDesde
+		actual.ValidoHasta = renewed.ValidoHasta
+		actual.UpdatedAt = now
+		actual.UpdatedBy = &userID
+
+		return tx.Save(actual).Error
+	})
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fiber.NewError(fiber.StatusNotFound, "Fiscal data not found")
+	}
+
+	if errors.Is(err, errCertificadoRegistrado) {
+		return nil, fiber.NewError(fiber.StatusConflict, "Certificate is already registered")
+	}
+
+	if err != nil {
+		s.Log.Errorf("Failed to renew e.firma: %+v", err)
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to renew e.firma")
+	}
+
+	return actual, nil
+}
+
+func (s *datosFiscalesService) GetHistorialCertificados(c *fiber.Ctx, userID uuid.UUID, id string) ([]model.HistorialCertificadoFiscal, error) {
+	datosFiscales, err := s.GetDatosFiscalesByID(c, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	var historial []model.HistorialCertificadoFiscal
+	result := s.DB.WithContext(c.Context()).
+		Where("datos_fiscales_uuid = ?", datosFiscales.UUID).
+		Order("reemplazado_at desc").
+		Find(&historial)
+	if result.Error != nil {
+		s.Log.Errorf("Failed to get certificate history: %+v", result.Error)
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to retrieve certificate history")
+	}
+
+	return historial, nil
+}
+
+// loadEfirma validates the uploaded cer/key pair against the RFC and
+// password and returns the encrypted blobs with the certificate attributes.
+func (s *datosFiscalesService) loadEfirma(ctx context.Context, cerFile, keyFile *multipart.FileHeader, rfc, password string) (*model.DatosFiscalesSAT, error) {
+	if err := s.validateFileExtensions(cerFile, keyFile); err != nil {
+		return nil, err
+	}
+
+	cerBytes, err := s.readFile(cerFile)
+	if err != nil {
+		s.Log.Errorf("Error processing .cer file: %+v", err)
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Error processing certificate file")
+	}
+
+	keyBytes, err := s.readFile(keyFile)
+	if err != nil {
+		s.Log.Errorf("Error processing .key file: %+v", err)
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Error processing key file")
+	}
+
+	certificate, err := s.parseCertificate(cerBytes, rfc)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.verifyKeyPair(certificate, keyBytes, password); err != nil {
+		return nil, err
+	}
+
+	datosFiscales := &model.DatosFiscalesSAT{
+		RFC:               certificate.RFC,
+		NoCertificado:     certificate.NoCertificado,
+		EmisorCertificado: certificate.Issuer,
+		ValidoDesde:       certificate.NotBefore,
+		ValidoHasta:       certificate.NotAfter,
+	}
+
+	plain := &efirmaSecrets{Cer: cerBytes, Key: keyBytes, Password: []byte(password)}
+	defer plain.wipe()
+
+	if err := s.Secrets.seal(ctx, datosFiscales, plain); err != nil {
+		s.Log.Errorf("Error encrypting e.firma: %+v", err)
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Error processing e.firma")
+	}
+
+	return datosFiscales, nil
+}
+
 func (s *datosFiscalesService) validateFileExtensions(cerFile, keyFile *multipart.FileHeader) error {
 	if cerFile == nil || keyFile == nil {
 		return fiber.NewError(fiber.StatusBadRequest, "Both .cer and .key files are required")
@@ -197,42 +360,84 @@ func (s *datosFiscalesService) validateFileExtensions(cerFile, keyFile *multipar
 	return nil
 }
 
-func (s *datosFiscalesService) fileToBase64(fileHeader *multipart.FileHeader) (string, error) {
+func (s *datosFiscalesService) readFile(fileHeader *multipart.FileHeader) ([]byte, error) {
 	file, err := fileHeader.Open()
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 	defer file.Close()
 
-	fileBytes, err := io.ReadAll(file)
+	return io.ReadAll(file)
+}
+
+func (s *datosFiscalesService) parseCertificate(cerBytes []byte, rfc string) (*fiel.Certificate, error) {
+	certificate, err := fiel.ParseCertificate(cerBytes)
 	if err != nil {
-		return "", err
+		s.Log.Errorf("Error parsing certificate: %+v", err)
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Invalid certificate file")
 	}
 
-	return base64.StdEncoding.EncodeToString(fileBytes), nil
+	if !certificate.MatchesRFC(rfc) {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "RFC does not match the certificate")
+	}
+
+	switch err := certificate.Validate(time.Now(), s.Autoridades); {
+	case errors.Is(err, fiel.ErrExpiredCertificate):
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Certificate is expired or not yet valid")
+	case errors.Is(err, fiel.ErrUntrustedIssuer):
+		s.Log.Warnf("Rejected certificate %s: %+v", certificate.NoCertificado, err)
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Certificate was not issued by the SAT")
+	case err != nil:
+		return nil, err
+	}
+
+	return certificate, nil
 }
 
-func (s *datosFiscalesService) encrypt(plaintext string) (string, error) {
-	hash := sha256.Sum256([]byte(s.EncryptionKey))
-	key := hash[:]
+func (s *datosFiscalesService) verifyKeyPair(certificate *fiel.Certificate, keyBytes []byte, password string) error {
+	key, err := fiel.DecryptPrivateKey(keyBytes, []byte(password))
+	switch {
+	case errors.Is(err, fiel.ErrInvalidPassword):
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid e.firma password")
+	case err != nil:
+		s.Log.Errorf("Error decrypting private key: %+v", err)
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid key file")
+	}
+
+	if err := fiel.VerifyKeyPair(key, certificate); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Key file does not belong to the certificate")
+	}
+
+	return nil
+}
 
-	block, err := aes.NewCipher(key)
+// updatePassword checks the new password against the stored key and seals
+// the e.firma again with it.
+func (s *datosFiscalesService) updatePassword(ctx context.Context, datosFiscales *model.DatosFiscalesSAT, password string) error {
+	plain, err := s.Secrets.open(ctx, datosFiscales)
 	if err != nil {
-		return "", err
+		s.Log.Errorf("Error decrypting stored e.firma: %+v", err)
+		return fiber.NewError(fiber.StatusInternalServerError, "Error processing e.firma")
 	}
+	defer plain.wipe()
 
-	// Crear GCM
-	gcm, err := cipher.NewGCM(block)
+	certificate, err := fiel.ParseCertificate(plain.Cer)
 	if err != nil {
-		return "", err
+		s.Log.Errorf("Error parsing stored certificate: %+v", err)
+		return fiber.NewError(fiber.StatusInternalServerError, "Error processing certificate")
 	}
 
-	nonce := make([]byte, gcm.NonceSize())
-	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
-		return "", err
+	if err := s.verifyKeyPair(certificate, plain.Key, password); err != nil {
+		return err
 	}
 
-	ciphertext := gcm.Seal(nonce, nonce, []byte(plaintext), nil)
+	fiel.Zero(plain.Password)
+	plain.Password = []byte(password)
+
+	if err := s.Secrets.seal(ctx, datosFiscales, plain); err != nil {
+		s.Log.Errorf("Error encrypting e.firma: %+v", err)
+		return fiber.NewError(fiber.StatusInternalServerError, "Error processing e.firma")
+	}
 
-	return base64.StdEncoding.EncodeToString(ciphertext), nil
-}
\ No newline at end of file
+	return nil
+}
@@ -0,0 +1,169 @@
+package service
+
+import (
+	"app/src/model"
+	"app/src/sat"
+	"app/src/utils"
+	"app/src/validation"
+	"errors"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type DescargaService interface {
+	SolicitarDescarga(c *fiber.Ctx, userID uuid.UUID, req *validation.SolicitarDescarga) (*model.SolicitudDescarga, error)
+	GetPaquetes(c *fiber.Ctx, userID uuid.UUID, id string) ([]model.PaqueteDescarga, error)
+}
+
+type descargaService struct {
+	Log            *logrus.Logger
+	DB             *gorm.DB
+	Validate       *validator.Validate
+	FielService    FielService
+	SATAuthService SATAuthService
+	Client         *sat.Client
+}
+
+func NewDescargaService(
+	db *gorm.DB, validate *validator.Validate, fielService FielService, satAuthService SATAuthService, client *sat.Client,
+) DescargaService {
+	return &descargaService{
+		Log:            utils.Log,
+		DB:             db,
+		Validate:       validate,
+		FielService:    fielService,
+		SATAuthService: satAuthService,
+		Client:         client,
+	}
+}
+
+// SolicitarDescarga sends a SolicitaDescarga request signed with the
+// e.firma of the selected RFC. The solicitud is stored whatever the SAT
+// answers, rejected ones keep the CodEstatus and message for reference.
+func (s *descargaService) SolicitarDescarga(
+	c *fiber.Ctx, userID uuid.UUID, req *validation.SolicitarDescarga,
+) (*model.SolicitudDescarga, error) {
+	if err := s.Validate.Struct(req); err != nil {
+		return nil, err
+	}
+
+	datosFiscalesID := uuid.MustParse(req.DatosFiscalesID)
+
+	signer, err := s.FielService.LoadSigner(c.Context(), userID, datosFiscalesID)
+	if err != nil {
+		return nil, err
+	}
+	defer signer.Close()
+
+	token, err := s.SATAuthService.Token(c.Context(), datosFiscalesID, signer)
+	if err != nil {
+		return nil, err
+	}
+
+	rfc := signer.Certificate().RFC
+
+	result, err := s.Client.SolicitaDescarga(c.Context(), token.Value, signer, sat.SolicitudParams{
+		RFCSolicitante:    rfc,
+		TipoDescarga:      req.TipoDescarga,
+		TipoSolicitud:     req.TipoSolicitud,
+		FechaInicial:      req.FechaInicial,
+		FechaFinal:        req.FechaFinal,
+		TipoComprobante:   req.TipoComprobante,
+		EstadoComprobante: req.EstadoComprobante,
+		RFCContraparte:    req.RFCContraparte,
+	})
+	if err != nil {
+		s.Log.Errorf("SAT download request failed for %s: %+v", rfc, err)
+		return nil, fiber.NewError(fiber.StatusBadGateway, "SAT download request failed")
+	}
+
+	if result.CodEstatus == sat.CodUsuarioNoValido {
+		s.SATAuthService.Invalidate(datosFiscalesID)
+	}
+
+	solicitud := &model.SolicitudDescarga{
+		UserID:            userID,
+		DatosFiscalesUUID: datosFiscalesID,
+		RFCSolicitante:    rfc,
+		TipoDescarga:      req.TipoDescarga,
+		TipoSolicitud:     req.TipoSolicitud,
+		FechaInicial:      req.FechaInicial,
+		FechaFinal:        req.FechaFinal,
+		TipoComprobante:   req.TipoComprobante,
+		EstadoComprobante: req.EstadoComprobante,
+		RFCContraparte:    req.RFCContraparte,
+		CodEstatus:        result.CodEstatus,
+		Mensaje:           result.Mensaje,
+		Estado:            model.EstadoSolicitudAceptada,
+		CreatedBy:         &userID,
+	}
+
+	if result.IDSolicitud != "" {
+		solicitud.IDSolicitud = &result.IDSolicitud
+	}
+
+	if !result.Aceptada() {
+		solicitud.Estado = model.EstadoSolicitudRechazada
+	}
+
+	if err := s.DB.WithContext(c.Context()).Create(solicitud).Error; err != nil {
+		s.Log.Errorf("Failed to save download request: %+v", err)
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to save download request")
+	}
+
+	if err := solicitudError(result); err != nil {
+		return nil, err
+	}
+
+	return solicitud, nil
+}
+
+func (s *descargaService) GetPaquetes(c *fiber.Ctx, userID uuid.UUID, id string) ([]model.PaqueteDescarga, error) {
+	solicitud := new(model.SolicitudDescarga)
+
+	result := s.DB.WithContext(c.Context()).Where("uuid = ? AND user_id = ?", id, userID).First(solicitud)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, fiber.NewError(fiber.StatusNotFound, "Download request not found")
+	}
+
+	if result.Error != nil {
+		s.Log.Errorf("Failed to get download request: %+v", result.Error)
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to retrieve download request")
+	}
+
+	var paquetes []model.PaqueteDescarga
+
+	result = s.DB.WithContext(c.Context()).Where("solicitud_uuid = ?", solicitud.UUID).Order("id_paquete asc").Find(&paquetes)
+	if result.Error != nil {
+		s.Log.Errorf("Failed to get packages: %+v", result.Error)
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to retrieve packages")
+	}
+
+	return paquetes, nil
+}
+
+// solicitudError maps the CodEstatus of a rejected request to an API error.
+func solicitudError(result *sat.SolicitudResult) error {
+	switch result.CodEstatus {
+	case sat.CodSolicitudAceptada:
+		return nil
+	case sat.CodSolicitudesAgotadas:
+		return fiber.NewError(fiber.StatusTooManyRequests, "SAT request limit reached for these parameters")
+	case sat.CodTopeMaximo:
+		return fiber.NewError(fiber.StatusUnprocessableEntity, "The request exceeds the maximum number of CFDIs, narrow the date range")
+	case sat.CodSinInformacion:
+		return fiber.NewError(fiber.StatusNotFound, "SAT found no CFDIs for the requested parameters")
+	case sat.CodSolicitudDuplicada:
+		return fiber.NewError(fiber.StatusConflict, "A download request with the same parameters already exists")
+	case sat.CodTerceroNoAutorizado:
+		return fiber.NewError(fiber.StatusForbidden, "RFC is not authorized to download these CFDIs")
+	case sat.CodSelloNoCorresponde, sat.CodCertificadoRevocado, sat.CodCertificadoInvalido:
+		return fiber.NewError(fiber.StatusUnprocessableEntity, "The e.firma was rejected by the SAT: "+result.Mensaje)
+	default:
+		return fiber.NewError(fiber.StatusBadGateway, "SAT rejected the download request: "+result.Mensaje)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"app/src/config"
 	"app/src/utils"
 	"fmt"
+	"time"
 
 	"github.com/sirupsen/logrus"
 	"gopkg.in/gomail.v2"
@@ -13,6 +14,7 @@ type EmailService interface {
 	SendEmail(to, subject, body string) error
 	SendResetPasswordEmail(to, token string) error
 	SendVerificationEmail(to, token string) error
+	SendVencimientoSuscripcionEmail(to, plan string, fechaFin time.Time) error
 }
 
 type emailService struct {
@@ -72,3 +74,14 @@ To verify your email, click on this link: %s
 If you did not create an account, then ignore this email.`, verificationEmailURL)
 	return s.SendEmail(to, subject, body)
 }
+
+func (s *emailService) SendVencimientoSuscripcionEmail(to, plan string, fechaFin time.Time) error {
+	subject := "Your subscription is about to expire"
+
+	body := fmt.Sprintf(`Dear user,
+
+Your %s subscription expires on %s. Renew it before that date to keep requesting SAT downloads.
+
+If you already renewed it, then ignore this email.`, plan, fechaFin.Format(time.DateOnly))
+	return s.SendEmail(to, subject, body)
+}
@@ -0,0 +1,180 @@
+package service
+
+import (
+	"app/src/model"
+	"app/src/sat"
+	"app/src/utils"
+	"context"
+	"errors"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type EstadoCFDIService interface {
+	GetEstado(c *fiber.Ctx, userID uuid.UUID, id uuid.UUID) (*model.CFDI, *sat.ConsultaResult, error)
+	RevisarRecientes(ctx context.Context) (int, error)
+}
+
+type estadoCFDIService struct {
+	Log           *logrus.Logger
+	DB            *gorm.DB
+	Client        *sat.Client
+	Cache         *sat.ConsultaCache
+	DiasRecientes int
+	BatchSize     int
+	Now           func() time.Time
+}
+
+func NewEstadoCFDIService(db *gorm.DB, client *sat.Client, cache *sat.ConsultaCache, diasRecientes int) EstadoCFDIService {
+	return &estadoCFDIService{
+		Log:           utils.Log,
+		DB:            db,
+		Client:        client,
+		Cache:         cache,
+		DiasRecientes: diasRecientes,
+		BatchSize:     100,
+		Now:           time.Now,
+	}
+}
+
+// GetEstado returns the status of a CFDI of the user RFCs as reported by the
+// SAT, asking again only once the cached answer expired. A fresh answer
+// updates the stored estado.
+func (s *estadoCFDIService) GetEstado(
+	c *fiber.Ctx, userID uuid.UUID, id uuid.UUID,
+) (*model.CFDI, *sat.ConsultaResult, error) {
+	registro := new(model.CFDI)
+
+	result := propios(s.DB.WithContext(c.Context()), "cfdis", userID).
+		Where("cfdis.uuid = ?", id).
+		First(registro)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil, fiber.NewError(fiber.StatusNotFound, "CFDI not found")
+	}
+	if result.Error != nil {
+		s.Log.Errorf("Failed to get CFDI %s: %+v", id, result.Error)
+		return nil, nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to retrieve CFDI")
+	}
+
+	if consulta, ok := s.Cache.Get(id.String()); ok {
+		return registro, consulta, nil
+	}
+
+	consulta, err := s.consultar(c.Context(), registro)
+	if err != nil {
+		s.Log.Errorf("Failed to query the status of CFDI %s: %+v", id, err)
+		return nil, nil, fiber.NewError(fiber.StatusBadGateway, "Failed to query the CFDI status at the SAT")
+	}
+
+	if err := s.actualizar(c.Context(), registro, consulta); err != nil {
+		s.Log.Errorf("Failed to update the status of CFDI %s: %+v", id, err)
+		return nil, nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to update the CFDI status")
+	}
+
+	return registro, consulta, nil
+}
+
+// RevisarRecientes asks the SAT again for the status of the CFDIs issued in
+// the last DiasRecientes days that are not cancelled yet and weren't checked
+// within the cache TTL. It returns how many changed estado. A failing query
+// does not stop the run and is retried on the next one.
+func (s *estadoCFDIService) RevisarRecientes(ctx context.Context) (int, error) {
+	now := s.Now()
+
+	var cfdis []model.CFDI
+
+	cambiados := 0
+	result := s.DB.WithContext(ctx).
+		Where("estado <> ? AND fecha >= ? AND (estado_consultado_at IS NULL OR estado_consultado_at < ?)",
+			model.EstadoCFDICancelado, now.AddDate(0, 0, -s.DiasRecientes), now.Add(-s.Cache.TTL)).
+		FindInBatches(&cfdis, s.BatchSize, func(_ *gorm.DB, _ int) error {
+			for i := range cfdis {
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+
+				registro := &cfdis[i]
+				anterior := registro.Estado
+
+				consulta, err := s.consultar(ctx, registro)
+				if err != nil {
+					s.Log.Errorf("Failed to query the status of CFDI %s: %+v", registro.UUID, err)
+					continue
+				}
+
+				if err := s.actualizar(ctx, registro, consulta); err != nil {
+					s.Log.Errorf("Failed to update the status of CFDI %s: %+v", registro.UUID, err)
+					continue
+				}
+
+				if registro.Estado != anterior {
+					cambiados++
+				}
+			}
+
+			return nil
+		})
+
+	if cambiados > 0 {
+		s.Log.Infof("Updated the estado of %d CFDIs", cambiados)
+	}
+
+	return cambiados, result.Error
+}
+
+func (s *estadoCFDIService) consultar(ctx context.Context, registro *model.CFDI) (*sat.ConsultaResult, error) {
+	expresion := sat.ExpresionImpresa(registro.RFCEmisor, registro.RFCReceptor, registro.Total, registro.UUID.String())
+
+	consulta, err := s.Client.ConsultaCFDI(ctx, expresion)
+	if err != nil {
+		return nil, err
+	}
+
+	s.Cache.Set(registro.UUID.String(), consulta)
+
+	return consulta, nil
+}
+
+// actualizar stores when the CFDI was checked and its new estado. No
+// Encontrado keeps the stored estado, the SAT takes up to 72 hours to list
+// newly stamped CFDIs.
+func (s *estadoCFDIService) actualizar(ctx context.Context, registro *model.CFDI, consulta *sat.ConsultaResult) error {
+	consultadoAt := consulta.ConsultadoAt
+	updates := map[string]interface{}{"estado_consultado_at": consultadoAt}
+
+	estado := estadoCFDI(consulta)
+	if estado != "" {
+		updates["estado"] = estado
+	}
+
+	result := s.DB.WithContext(ctx).Model(&model.CFDI{}).Where("uuid = ?", registro.UUID).Updates(updates)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	registro.EstadoConsultadoAt = &consultadoAt
+	if estado != "" {
+		registro.Estado = estado
+	}
+
+	return nil
+}
+
+// estadoCFDI maps the SAT answer to the stored estado, empty if the SAT
+// doesn't know the CFDI.
+func estadoCFDI(consulta *sat.ConsultaResult) string {
+	switch {
+	case consulta.Estado == sat.EstadoCFDICancelado:
+		return model.EstadoCFDICancelado
+	case consulta.Estado == sat.EstadoCFDIVigente && consulta.EstatusCancelacion == sat.EstatusCancelacionCurso:
+		return model.EstadoCFDIEnCancelacion
+	case consulta.Estado == sat.EstadoCFDIVigente:
+		return model.EstadoCFDIVigente
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,65 @@
+package service
+
+import (
+	"app/src/fiel"
+	"app/src/model"
+	"app/src/secrets"
+	"app/src/utils"
+	"context"
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type FielService interface {
+	LoadSigner(ctx context.Context, userID, datosFiscalesID uuid.UUID) (*fiel.Signer, error)
+}
+
+type fielService struct {
+	Log     *logrus.Logger
+	DB      *gorm.DB
+	Secrets *fiscalSecrets
+}
+
+func NewFielService(db *gorm.DB, keyProvider secrets.KeyProvider) FielService {
+	return &fielService{
+		Log:     utils.Log,
+		DB:      db,
+		Secrets: &fiscalSecrets{Provider: keyProvider},
+	}
+}
+
+// LoadSigner decrypts the e.firma stored for one of the RFCs of the user.
+// The decrypted cer, key and password only live for the duration of this
+// call; the caller owns the returned signer and must Close it.
+func (s *fielService) LoadSigner(ctx context.Context, userID, datosFiscalesID uuid.UUID) (*fiel.Signer, error) {
+	datosFiscales := new(model.DatosFiscalesSAT)
+
+	result := s.DB.WithContext(ctx).Where("uuid = ? AND user_id = ?", datosFiscalesID, userID).First(datosFiscales)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, fiber.NewError(fiber.StatusNotFound, "Fiscal data not found")
+	}
+
+	if result.Error != nil {
+		s.Log.Errorf("Failed to get fiscal data: %+v", result.Error)
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to retrieve fiscal data")
+	}
+
+	plain, err := s.Secrets.open(ctx, datosFiscales)
+	if err != nil {
+		s.Log.Errorf("Error decrypting stored e.firma: %+v", err)
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Error processing e.firma")
+	}
+	defer plain.wipe()
+
+	signer, err := fiel.NewSigner(plain.Cer, plain.Key, plain.Password)
+	if err != nil {
+		s.Log.Errorf("Error loading e.firma: %+v", err)
+		return nil, fiber.NewError(fiber.StatusUnprocessableEntity, "Stored e.firma is not usable, please renew it")
+	}
+
+	return signer, nil
+}
@@ -0,0 +1,141 @@
+package service
+
+import (
+	"app/src/fiel"
+	"app/src/model"
+	"app/src/secrets"
+	"context"
+	"encoding/base64"
+	"errors"
+)
+
+// efirmaSecrets is the decrypted e.firma of a DatosFiscalesSAT record.
+type efirmaSecrets struct {
+	Cer      []byte
+	Key      []byte
+	Password []byte
+}
+
+func (e *efirmaSecrets) wipe() {
+	fiel.Zero(e.Key)
+	fiel.Zero(e.Password)
+}
+
+// fiscalSecrets implements envelope encryption for the e.firma: every record
+// gets its own data key, which is stored wrapped by a versioned master key
+// held by the KeyProvider.
+type fiscalSecrets struct {
+	Provider secrets.KeyProvider
+}
+
+// seal encrypts the e.firma with a fresh data key and stores the result in
+// the record.
+func (f *fiscalSecrets) seal(ctx context.Context, datosFiscales *model.DatosFiscalesSAT, plain *efirmaSecrets) error {
+	dataKey, err := secrets.NewDataKey()
+	if err != nil {
+		return err
+	}
+	defer fiel.Zero(dataKey)
+
+	cerEncrypted, err := secrets.Seal(dataKey, plain.Cer)
+	if err != nil {
+		return err
+	}
+
+	keyEncrypted, err := secrets.Seal(dataKey, plain.Key)
+	if err != nil {
+		return err
+	}
+
+	passwordEncrypted, err := secrets.Seal(dataKey, plain.Password)
+	if err != nil {
+		return err
+	}
+
+	wrapped, version, err := f.Provider.Wrap(ctx, dataKey)
+	if err != nil {
+		return err
+	}
+
+	datosFiscales.CerB64Encriptado = cerEncrypted
+	datosFiscales.KeyB64Encriptado = keyEncrypted
+	datosFiscales.PasswordEfirmaEncrip = passwordEncrypted
+	datosFiscales.DataKeyEncriptada = wrapped
+	datosFiscales.KeyVersion = version
+
+	return nil
+}
+
+// open decrypts the e.firma of the record. The caller must wipe the result.
+func (f *fiscalSecrets) open(ctx context.Context, datosFiscales *model.DatosFiscalesSAT) (*efirmaSecrets, error) {
+	if datosFiscales.DataKeyEncriptada == "" {
+		return f.openLegacy(datosFiscales)
+	}
+
+	dataKey, err := f.Provider.Unwrap(ctx, datosFiscales.DataKeyEncriptada, datosFiscales.KeyVersion)
+	if err != nil {
+		return nil, err
+	}
+	defer fiel.Zero(dataKey)
+
+	plain := new(efirmaSecrets)
+	if plain.Cer, err = secrets.Open(dataKey, datosFiscales.CerB64Encriptado); err != nil {
+		return nil, err
+	}
+
+	if plain.Key, err = secrets.Open(dataKey, datosFiscales.KeyB64Encriptado); err != nil {
+		return nil, err
+	}
+
+	if plain.Password, err = secrets.Open(dataKey, datosFiscales.PasswordEfirmaEncrip); err != nil {
+		plain.wipe()
+		return nil, err
+	}
+
+	return plain, nil
+}
+
+// openLegacy handles records sealed directly with the master key, where the
+// cer and key were stored as the encrypted base64 of the file contents.
+func (f *fiscalSecrets) openLegacy(datosFiscales *model.DatosFiscalesSAT) (*efirmaSecrets, error) {
+	legacy, ok := f.Provider.(secrets.LegacyDecrypter)
+	if !ok {
+		return nil, errors.New("record predates data keys, re-encrypt it with the local key provider first")
+	}
+
+	var err error
+	plain := new(efirmaSecrets)
+
+	if plain.Cer, err = openLegacyFile(legacy, datosFiscales.CerB64Encriptado, datosFiscales.KeyVersion); err != nil {
+		return nil, err
+	}
+
+	if plain.Key, err = openLegacyFile(legacy, datosFiscales.KeyB64Encriptado, datosFiscales.KeyVersion); err != nil {
+		return nil, err
+	}
+
+	plain.Password, err = legacy.DecryptLegacy(datosFiscales.PasswordEfirmaEncrip, datosFiscales.KeyVersion)
+	if err != nil {
+		plain.wipe()
+		return nil, err
+	}
+
+	return plain, nil
+}
+
+func openLegacyFile(legacy secrets.LegacyDecrypter, ciphertext string, version int) ([]byte, error) {
+	fileB64, err := legacy.DecryptLegacy(ciphertext, version)
+	if err != nil {
+		return nil, err
+	}
+	defer fiel.Zero(fileB64)
+
+	file := make([]byte, base64.StdEncoding.DecodedLen(len(fileB64)))
+	n, err := base64.StdEncoding.Decode(file, fileB64)
+	if err != nil {
+		fiel.Zero(file)
+		return nil, err
+	}
+
+	return file[:n], nil
+}
@@ -0,0 +1,684 @@
+package service
+
+import (
+	"app/src/cfdi"
+	"app/src/model"
+	"app/src/sat"
+	"app/src/sello"
+	"app/src/storage"
+	"app/src/utils"
+	"archive/zip"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// maxIntentosIndexacion is how many times a package that can't be indexed
+// is retried. After that it leaves the queue, with the last error kept in
+// error_indexacion, so it doesn't hold back the packages behind it.
+const maxIntentosIndexacion = 3
+
+type IndexacionService interface {
+	IndexarPendientes(ctx context.Context) (int, error)
+}
+
+type indexacionService struct {
+	Log       *logrus.Logger
+	DB        *gorm.DB
+	Store     storage.BlobStore
+	Almacen   *sello.Almacen
+	BatchSize int
+}
+
+func NewIndexacionService(db *gorm.DB, store storage.BlobStore, almacen *sello.Almacen) IndexacionService {
+	return &indexacionService{
+		Log:       utils.Log,
+		DB:        db,
+		Store:     store,
+		Almacen:   almacen,
+		BatchSize: 20,
+	}
+}
+
+// IndexarPendientes indexes the downloaded packages that are not indexed yet
+// and returns how many were indexed: the XMLs of CFDI packages and the rows
+// of Metadata packages. Files that can't be parsed or that the database
+// rejects are logged and skipped, a package that can't be read is retried
+// on the next runs, up to maxIntentosIndexacion times.
+func (s *indexacionService) IndexarPendientes(ctx context.Context) (int, error) {
+	var paquetes []model.PaqueteDescarga
+
+	result := s.DB.WithContext(ctx).Joins("Solicitud").
+		Where("solicitudes_descarga_paquetes.estado = ? AND solicitudes_descarga_paquetes.indexado_at IS NULL "+
+			"AND solicitudes_descarga_paquetes.intentos_indexacion < ?",
+			model.EstadoPaqueteDescargado, maxIntentosIndexacion).
+		Order("solicitudes_descarga_paquetes.descargado_at ASC").
+		Limit(s.BatchSize).
+		Find(&paquetes)
+	if result.Error != nil {
+		return 0, result.Error
+	}
+
+	indexados := 0
+	for i := range paquetes {
+		if ctx.Err() != nil {
+			return indexados, ctx.Err()
+		}
+
+		paquete := &paquetes[i]
+		if err := s.indexar(ctx, paquete); err != nil {
+			s.Log.Errorf("Failed to index package %s: %+v", paquete.IDPaquete, err)
+			s.fallo(ctx, paquete, err.Error())
+			continue
+		}
+
+		indexados++
+	}
+
+	return indexados, nil
+}
+
+func (s *indexacionService) indexar(ctx context.Context, paquete *model.PaqueteDescarga) error {
+	data, err := s.Store.Get(ctx, paquete.StorageKey)
+	if err != nil {
+		return fmt.Errorf("reading package: %w", err)
+	}
+
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return fmt.Errorf("%w: %w", sat.ErrPaqueteInvalido, err)
+	}
+
+	indexar := s.indexarXML
+	if paquete.Solicitud != nil && paquete.Solicitud.TipoSolicitud == sat.TipoSolicitudMetadata {
+		indexar = s.indexarMetadata
+	}
+
+	cfdis := 0
+	for _, file := range archive.File {
+		if file.FileInfo().IsDir() {
+			continue
+		}
+
+		contenido, err := readZipFile(file)
+		if err != nil {
+			return fmt.Errorf("%w: %s: %w", sat.ErrPaqueteInvalido, file.Name, err)
+		}
+
+		indexados, err := indexar(ctx, paquete, file.Name, contenido)
+		if err != nil {
+			return err
+		}
+
+		cfdis += indexados
+	}
+
+	now := time.Now()
+
+	return s.DB.WithContext(ctx).Model(paquete).Updates(map[string]interface{}{
+		"cfdis_indexados":  cfdis,
+		"indexado_at":      now,
+		"error_indexacion": "",
+		"updated_at":       now,
+	}).Error
+}
+
+func (s *indexacionService) fallo(ctx context.Context, paquete *model.PaqueteDescarga, mensaje string) {
+	err := s.DB.WithContext(ctx).Model(paquete).Updates(map[string]interface{}{
+		"intentos_indexacion": paquete.IntentosIndexacion + 1,
+		"error_indexacion":    mensaje,
+		"updated_at":          time.Now(),
+	}).Error
+	if err != nil {
+		s.Log.Errorf("Failed to update package %s: %+v", paquete.IDPaquete, err)
+	}
+
+	if paquete.IntentosIndexacion+1 >= maxIntentosIndexacion {
+		s.Log.Errorf("Giving up indexing package %s after %d attempts", paquete.IDPaquete, maxIntentosIndexacion)
+	}
+}
+
+func (s *indexacionService) indexarXML(
+	ctx context.Context, paquete *model.PaqueteDescarga, nombre string, documento []byte,
+) (int, error) {
+	if !strings.EqualFold(path.Ext(nombre), ".xml") {
+		return 0, nil
+	}
+
+	comprobante, err := cfdi.Parse(documento)
+	if err != nil {
+		s.Log.Warnf("Skipping %s of package %s: %v", nombre, paquete.IDPaquete, err)
+		return 0, nil
+	}
+
+	id, err := uuid.Parse(comprobante.UUID())
+	if err != nil {
+		s.Log.Warnf("Skipping %s of package %s: missing or invalid UUID", nombre, paquete.IDPaquete)
+		return 0, nil
+	}
+
+	key := fmt.Sprintf("cfdis/%s.xml", id)
+	if err := s.Store.Put(ctx, key, documento); err != nil {
+		return 0, fmt.Errorf("storing CFDI %s: %w", id, err)
+	}
+
+	// A document the database rejects, say a TipoDeComprobante out of the
+	// catalog, would fail the package on every run.
+	if err := s.guardar(ctx, paquete, id, key, comprobante, documento); err != nil {
+		if ctx.Err() != nil {
+			return 0, ctx.Err()
+		}
+
+		s.Log.Warnf("Skipping %s of package %s: %v", nombre, paquete.IDPaquete, err)
+		return 0, nil
+	}
+
+	return 1, nil
+}
+
+// indexarMetadata stores the rows of a metadata file, updating the status of
+// the ones already known, and marks as cancelado the indexed XMLs that were
+// cancelled since they were downloaded.
+func (s *indexacionService) indexarMetadata(
+	ctx context.Context, paquete *model.PaqueteDescarga, nombre string, contenido []byte,
+) (int, error) {
+	if !strings.EqualFold(path.Ext(nombre), ".txt") {
+		return 0, nil
+	}
+
+	filas, err := sat.ParseMetadata(bytes.NewReader(contenido))
+	if err != nil {
+		s.Log.Warnf("Invalid rows in %s of package %s: %v", nombre, paquete.IDPaquete, err)
+	}
+
+	registros := make([]model.CFDIMetadata, 0, len(filas))
+	posiciones := make(map[uuid.UUID]int, len(filas))
+	var cancelados []uuid.UUID
+
+	for _, fila := range filas {
+		id, err := uuid.Parse(fila.UUID)
+		if err != nil {
+			s.Log.Warnf("Skipping metadata of %q in package %s: invalid UUID", fila.UUID, paquete.IDPaquete)
+			continue
+		}
+
+		registro := newCFDIMetadata(id, &fila)
+		registro.PaqueteUUID = &paquete.UUID
+
+		// An upsert can't touch the same row twice, the last row wins.
+		if i, ok := posiciones[id]; ok {
+			registros[i] = *registro
+		} else {
+			posiciones[id] = len(registros)
+			registros = append(registros, *registro)
+		}
+
+		if fila.Cancelado {
+			cancelados = append(cancelados, id)
+		}
+	}
+
+	if len(registros) == 0 {
+		return 0, nil
+	}
+
+	err = s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "uuid"}},
+			DoUpdates: clause.AssignmentColumns([]string{"paquete_uuid", "estatus", "fecha_cancelacion", "updated_at"}),
+		}).CreateInBatches(&registros, 500).Error
+		if err != nil {
+			return err
+		}
+
+		if len(cancelados) == 0 {
+			return nil
+		}
+
+		return tx.Model(&model.CFDI{}).
+			Where("uuid IN ? AND estado <> ?", cancelados, model.EstadoCFDICancelado).
+			Updates(map[string]interface{}{
+				"estado":     model.EstadoCFDICancelado,
+				"updated_at": time.Now(),
+			}).Error
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return len(registros), nil
+}
+
+// guardar indexes the CFDI stored at key with the result of the offline
+// seal verification. CFDIs are immutable once stamped, so one that is
+// already indexed is left as is.
+func (s *indexacionService) guardar(
+	ctx context.Context, paquete *model.PaqueteDescarga, id uuid.UUID, key string, comprobante *cfdi.Comprobante,
+	documento []byte,
+) error {
+	registro := newCFDI(id, comprobante)
+	registro.PaqueteUUID = &paquete.UUID
+	registro.StorageKey = key
+
+	if err := sello.Verificar(documento, comprobante, s.Almacen); err != nil {
+		registro.SelloError = err.Error()
+	} else {
+		registro.SelloValido = true
+	}
+
+	return s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Omit(clause.Associations).Create(registro)
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+
+		if len(registro.Conceptos) > 0 {
+			if err := tx.Create(&registro.Conceptos).Error; err != nil {
+				return err
+			}
+		}
+
+		if len(registro.Pagos) > 0 {
+			if err := tx.Create(&registro.Pagos).Error; err != nil {
+				return err
+			}
+		}
+
+		if len(registro.Nominas) > 0 {
+			if err := tx.Create(&registro.Nominas).Error; err != nil {
+				return err
+			}
+		}
+
+		if registro.CartaPorte == nil {
+			return nil
+		}
+
+		return tx.Create(registro.CartaPorte).Error
+	})
+}
+
+func newCFDI(id uuid.UUID, c *cfdi.Comprobante) *model.CFDI {
+	registro := &model.CFDI{
+		UUID:                    id,
+		Version:                 c.Version,
+		Serie:                   c.Serie,
+		Folio:                   c.Folio,
+		Fecha:                   c.Fecha.Time,
+		FechaTimbrado:           c.Complemento.TimbreFiscalDigital.FechaTimbrado.Time,
+		TipoComprobante:         c.TipoDeComprobante,
+		RFCEmisor:               strings.ToUpper(strings.TrimSpace(c.Emisor.RFC)),
+		NombreEmisor:            c.Emisor.Nombre,
+		RegimenFiscalEmisor:     c.Emisor.RegimenFiscal,
+		RFCReceptor:             strings.ToUpper(strings.TrimSpace(c.Receptor.RFC)),
+		NombreReceptor:          c.Receptor.Nombre,
+		RegimenFiscalReceptor:   c.Receptor.RegimenFiscalReceptor,
+		DomicilioFiscalReceptor: c.Receptor.DomicilioFiscalReceptor,
+		UsoCFDI:                 c.Receptor.UsoCFDI,
+		SubTotal:                c.SubTotal,
+		Descuento:               c.Descuento,
+		Total:                   c.Total,
+		Moneda:                  c.Moneda,
+		MetodoPago:              c.MetodoPago,
+		FormaPago:               c.FormaPago,
+		LugarExpedicion:         c.LugarExpedicion,
+		Estado:                  model.EstadoCFDIVigente,
+	}
+
+	if c.Impuestos != nil {
+		registro.TotalImpuestosTrasladados = c.Impuestos.TotalImpuestosTrasladados
+		registro.TotalImpuestosRetenidos = c.Impuestos.TotalImpuestosRetenidos
+	}
+
+	if !c.TipoCambio.IsZero() {
+		tipoCambio := c.TipoCambio
+		registro.TipoCambio = &tipoCambio
+	}
+
+	for i, concepto := range c.Conceptos {
+		registro.Conceptos = append(registro.Conceptos, model.CFDIConcepto{
+			CFDIUUID:         id,
+			Numero:           i + 1,
+			ClaveProdServ:    concepto.ClaveProdServ,
+			NoIdentificacion: concepto.NoIdentificacion,
+			Cantidad:         concepto.Cantidad,
+			ClaveUnidad:      concepto.ClaveUnidad,
+			Unidad:           concepto.Unidad,
+			Descripcion:      concepto.Descripcion,
+			ValorUnitario:    concepto.ValorUnitario,
+			Importe:          concepto.Importe,
+			Descuento:        concepto.Descuento,
+			ObjetoImp:        concepto.ObjetoImp,
+		})
+	}
+
+	if c.Complemento.Pagos != nil {
+		registro.Pagos = newCFDIPagos(id, c.Complemento.Pagos)
+	}
+
+	for i := range c.Complemento.Nomina {
+		registro.Nominas = append(registro.Nominas, *newCFDINomina(id, i+1, c, &c.Complemento.Nomina[i]))
+	}
+
+	if c.Complemento.CartaPorte != nil {
+		registro.CartaPorte = newCFDICartaPorte(id, c.Complemento.CartaPorte)
+	}
+
+	return registro
+}
+
+// newCFDIPagos flattens the payments of a Recepción de Pagos complement into
+// one row per invoice paid. Documents with an invalid IdDocumento can't be
+// linked to an invoice and are skipped.
+func newCFDIPagos(id uuid.UUID, pagos *cfdi.Pagos) []model.CFDIPago {
+	var registros []model.CFDIPago
+
+	for i, pago := range pagos.Pago {
+		var tipoCambio *cfdi.Decimal
+		if !pago.TipoCambioP.IsZero() {
+			tipoCambio = &pago.TipoCambioP
+		}
+
+		for j, docto := range pago.Doctos {
+			idDocumento, err := uuid.Parse(docto.UUID())
+			if err != nil {
+				continue
+			}
+
+			// TipoCambioDR of Pagos 1.0 became EquivalenciaDR in 2.0.
+			equivalencia := docto.EquivalenciaDR
+			if equivalencia.IsZero() {
+				equivalencia = docto.TipoCambioDR
+			}
+
+			registro := model.CFDIPago{
+				CFDIUUID:         id,
+				NumeroPago:       i + 1,
+				Numero:           j + 1,
+				FechaPago:        pago.FechaPago.Time,
+				FormaPago:        pago.FormaDePagoP,
+				MonedaPago:       pago.MonedaP,
+				TipoCambioPago:   tipoCambio,
+				Monto:            pago.Monto,
+				NumOperacion:     pago.NumOperacion,
+				IDDocumento:      idDocumento,
+				Serie:            docto.Serie,
+				Folio:            docto.Folio,
+				MonedaDR:         docto.MonedaDR,
+				NumParcialidad:   docto.NumParcialidad,
+				ImpSaldoAnt:      docto.ImpSaldoAnt,
+				ImpPagado:        docto.ImpPagado,
+				ImpSaldoInsoluto: docto.ImpSaldoInsoluto,
+			}
+			if !equivalencia.IsZero() {
+				registro.EquivalenciaDR = &equivalencia
+			}
+
+			registros = append(registros, registro)
+		}
+	}
+
+	return registros
+}
+
+// newCFDINomina maps a Nómina complement and its conceptos. The conceptos
+// get their IDs here so they can be inserted along with the complement.
+func newCFDINomina(id uuid.UUID, numero int, c *cfdi.Comprobante, nomina *cfdi.Nomina) *model.CFDINomina {
+	receptor := nomina.Receptor
+
+	registro := &model.CFDINomina{
+		ID:                     uuid.New(),
+		CFDIUUID:               id,
+		Numero:                 numero,
+		TipoNomina:             nomina.TipoNomina,
+		FechaPago:              nomina.FechaPago.Time,
+		FechaInicialPago:       nomina.FechaInicialPago.Time,
+		FechaFinalPago:         nomina.FechaFinalPago.Time,
+		NumDiasPagados:         nomina.NumDiasPagados,
+		RFCEmpleado:            strings.ToUpper(strings.TrimSpace(c.Receptor.RFC)),
+		NombreEmpleado:         c.Receptor.Nombre,
+		Curp:                   strings.ToUpper(receptor.Curp),
+		NumSeguridadSocial:     receptor.NumSeguridadSocial,
+		NumEmpleado:            receptor.NumEmpleado,
+		Departamento:           receptor.Departamento,
+		Puesto:                 receptor.Puesto,
+		TipoContrato:           receptor.TipoContrato,
+		TipoRegimen:            receptor.TipoRegimen,
+		PeriodicidadPago:       receptor.PeriodicidadPago,
+		SalarioBaseCotApor:     receptor.SalarioBaseCotApor,
+		SalarioDiarioIntegrado: receptor.SalarioDiarioIntegrado,
+		ClaveEntFed:            receptor.ClaveEntFed,
+		TotalPercepciones:      nomina.TotalPercepciones,
+		TotalDeducciones:       nomina.TotalDeducciones,
+		TotalOtrosPagos:        nomina.TotalOtrosPagos,
+		ISRRetenido:            nomina.ISRRetenido(),
+		SubsidioCausado:        nomina.SubsidioCausado(),
+	}
+
+	if nomina.Emisor != nil {
+		registro.RegistroPatronal = nomina.Emisor.RegistroPatronal
+	}
+
+	if !receptor.FechaInicioRelLaboral.IsZero() {
+		inicio := receptor.FechaInicioRelLaboral.Time
+		registro.FechaInicioRelLaboral = &inicio
+	}
+
+	concepto := func(tipo, tipoSAT, clave, nombre string, gravado, exento, importe cfdi.Decimal) {
+		registro.Conceptos = append(registro.Conceptos, model.CFDINominaConcepto{
+			ID:             uuid.New(),
+			NominaID:       registro.ID,
+			Numero:         len(registro.Conceptos) + 1,
+			Tipo:           tipo,
+			TipoSAT:        tipoSAT,
+			Clave:          clave,
+			Concepto:       nombre,
+			ImporteGravado: gravado,
+			ImporteExento:  exento,
+			Importe:        importe,
+		})
+	}
+
+	if nomina.Percepciones != nil {
+		registro.TotalGravado = nomina.Percepciones.TotalGravado
+		registro.TotalExento = nomina.Percepciones.TotalExento
+
+		for _, percepcion := range nomina.Percepciones.Percepcion {
+			concepto(model.TipoConceptoPercepcion, percepcion.TipoPercepcion, percepcion.Clave, percepcion.Concepto,
+				percepcion.ImporteGravado, percepcion.ImporteExento, percepcion.ImporteGravado.Add(percepcion.ImporteExento))
+		}
+	}
+
+	if nomina.Deducciones != nil {
+		for _, deduccion := range nomina.Deducciones.Deduccion {
+			concepto(model.TipoConceptoDeduccion, deduccion.TipoDeduccion, deduccion.Clave, deduccion.Concepto,
+				cfdi.Decimal{}, cfdi.Decimal{}, deduccion.Importe)
+		}
+	}
+
+	for _, otroPago := range nomina.OtrosPagos {
+		concepto(model.TipoConceptoOtroPago, otroPago.TipoOtroPago, otroPago.Clave, otroPago.Concepto,
+			cfdi.Decimal{}, cfdi.Decimal{}, otroPago.Importe)
+	}
+
+	return registro
+}
+
+// newCFDICartaPorte maps a Carta Porte complement along with the
+// incidencias of its structural validation. Like the Nómina, the rows get
+// their IDs here to be inserted in one go.
+func newCFDICartaPorte(id uuid.UUID, cartaPorte *cfdi.CartaPorte) *model.CFDICartaPorte {
+	registro := &model.CFDICartaPorte{
+		ID:                uuid.New(),
+		CFDIUUID:          id,
+		Version:           cartaPorte.Version,
+		IdCCP:             strings.ToUpper(cartaPorte.IdCCP),
+		TranspInternac:    cartaPorte.TranspInternac,
+		EntradaSalidaMerc: cartaPorte.EntradaSalidaMerc,
+		PaisOrigenDestino: cartaPorte.PaisOrigenDestino,
+		ViaEntradaSalida:  cartaPorte.ViaEntradaSalida,
+		TotalDistRec:      cartaPorte.TotalDistRec,
+		MedioTransporte:   cartaPorte.MedioTransporte(),
+	}
+
+	if cartaPorte.Ubicaciones != nil {
+		for i, ubicacion := range cartaPorte.Ubicaciones.Ubicacion {
+			fila := model.CFDICartaPorteUbicacion{
+				ID:                          uuid.New(),
+				CartaPorteID:                registro.ID,
+				Numero:                      i + 1,
+				TipoUbicacion:               ubicacion.TipoUbicacion,
+				IDUbicacion:                 ubicacion.IDUbicacion,
+				RFCRemitenteDestinatario:    strings.ToUpper(strings.TrimSpace(ubicacion.RFCRemitenteDestinatario)),
+				NombreRemitenteDestinatario: ubicacion.NombreRemitenteDestinatario,
+				DistanciaRecorrida:          ubicacion.DistanciaRecorrida,
+			}
+
+			if !ubicacion.FechaHoraSalidaLlegada.IsZero() {
+				fecha := ubicacion.FechaHoraSalidaLlegada.Time
+				fila.FechaHoraSalidaLlegada = &fecha
+			}
+
+			if domicilio := ubicacion.Domicilio; domicilio != nil {
+				fila.Calle = domicilio.Calle
+				fila.NumeroExterior = domicilio.NumeroExterior
+				fila.Colonia = domicilio.Colonia
+				fila.Municipio = domicilio.Municipio
+				fila.Estado = domicilio.Estado
+				fila.Pais = domicilio.Pais
+				fila.CodigoPostal = domicilio.CodigoPostal
+			}
+
+			registro.Ubicaciones = append(registro.Ubicaciones, fila)
+		}
+	}
+
+	if mercancias := cartaPorte.Mercancias; mercancias != nil {
+		registro.PesoBrutoTotal = mercancias.PesoBrutoTotal
+		registro.UnidadPeso = mercancias.UnidadPeso
+		registro.PesoNetoTotal = mercancias.PesoNetoTotal
+		registro.NumTotalMercancias = mercancias.NumTotalMercancias
+
+		for i, mercancia := range mercancias.Mercancia {
+			registro.Mercancias = append(registro.Mercancias, model.CFDICartaPorteMercancia{
+				ID:                   uuid.New(),
+				CartaPorteID:         registro.ID,
+				Numero:               i + 1,
+				BienesTransp:         mercancia.BienesTransp,
+				Descripcion:          mercancia.Descripcion,
+				Cantidad:             mercancia.Cantidad,
+				ClaveUnidad:          mercancia.ClaveUnidad,
+				MaterialPeligroso:    mercancia.MaterialPeligroso,
+				CveMaterialPeligroso: mercancia.CveMaterialPeligroso,
+				Embalaje:             mercancia.Embalaje,
+				PesoEnKg:             mercancia.PesoEnKg,
+				ValorMercancia:       mercancia.ValorMercancia,
+				Moneda:               mercancia.Moneda,
+			})
+		}
+
+		if autotransporte := mercancias.Autotransporte; autotransporte != nil {
+			registro.PermSCT = autotransporte.PermSCT
+			registro.NumPermisoSCT = autotransporte.NumPermisoSCT
+
+			if vehiculo := autotransporte.IdentificacionVehicular; vehiculo != nil {
+				registro.ConfigVehicular = vehiculo.ConfigVehicular
+				registro.PesoBrutoVehicular = vehiculo.PesoBrutoVehicular
+				registro.PlacaVM = vehiculo.PlacaVM
+				registro.AnioModeloVM = vehiculo.AnioModeloVM
+			}
+
+			if seguros := autotransporte.Seguros; seguros != nil {
+				registro.AseguraRespCivil = seguros.AseguraRespCivil
+				registro.PolizaRespCivil = seguros.PolizaRespCivil
+				registro.AseguraCarga = seguros.AseguraCarga
+				registro.PolizaCarga = seguros.PolizaCarga
+			}
+
+			for i, remolque := range autotransporte.Remolques {
+				registro.Remolques = append(registro.Remolques, model.CFDICartaPorteRemolque{
+					ID:           uuid.New(),
+					CartaPorteID: registro.ID,
+					Numero:       i + 1,
+					SubTipoRem:   remolque.SubTipoRem,
+					Placa:        remolque.Placa,
+				})
+			}
+		}
+	}
+
+	if cartaPorte.FiguraTransporte != nil {
+		for i, figura := range cartaPorte.FiguraTransporte.TiposFigura {
+			registro.Figuras = append(registro.Figuras, model.CFDICartaPorteFigura{
+				ID:           uuid.New(),
+				CartaPorteID: registro.ID,
+				Numero:       i + 1,
+				TipoFigura:   figura.TipoFigura,
+				RFCFigura:    strings.ToUpper(strings.TrimSpace(figura.RFCFigura)),
+				NumLicencia:  figura.NumLicencia,
+				NombreFigura: figura.NombreFigura,
+			})
+		}
+	}
+
+	for i, incidencia := range cartaPorte.Validar() {
+		registro.Incidencias = append(registro.Incidencias, model.CFDICartaPorteIncidencia{
+			ID:           uuid.New(),
+			CartaPorteID: registro.ID,
+			Numero:       i + 1,
+			Tipo:         incidencia.Tipo,
+			Nodo:         incidencia.Nodo,
+			Mensaje:      incidencia.Mensaje,
+		})
+	}
+
+	registro.Valida = len(registro.Incidencias) == 0
+
+	return registro
+}
+
+func newCFDIMetadata(id uuid.UUID, fila *sat.Metadata) *model.CFDIMetadata {
+	registro := &model.CFDIMetadata{
+		UUID:              id,
+		RFCEmisor:         fila.RFCEmisor,
+		NombreEmisor:      fila.NombreEmisor,
+		RFCReceptor:       fila.RFCReceptor,
+		NombreReceptor:    fila.NombreReceptor,
+		RFCPac:            fila.RFCPac,
+		FechaEmision:      fila.FechaEmision,
+		Monto:             fila.Monto,
+		EfectoComprobante: fila.EfectoComprobante,
+		Estatus:           model.EstadoCFDIVigente,
+		FechaCancelacion:  fila.FechaCancelacion,
+	}
+
+	if !fila.FechaCertificacion.IsZero() {
+		fechaCertificacion := fila.FechaCertificacion
+		registro.FechaCertificacion = &fechaCertificacion
+	}
+
+	if fila.Cancelado {
+		registro.Estatus = model.EstadoCFDICancelado
+	}
+
+	return registro
+}
+
+func readZipFile(file *zip.File) ([]byte, error) {
+	reader, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	return io.ReadAll(reader)
+}
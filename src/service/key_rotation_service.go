@@ -0,0 +1,90 @@
+package service
+
+import (
+	"app/src/fiel"
+	"app/src/model"
+	"app/src/secrets"
+	"app/src/utils"
+	"context"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type KeyRotationService interface {
+	ReencryptAll(ctx context.Context) (int, error)
+}
+
+type keyRotationService struct {
+	Log     *logrus.Logger
+	DB      *gorm.DB
+	Secrets *fiscalSecrets
+}
+
+func NewKeyRotationService(db *gorm.DB, keyProvider secrets.KeyProvider) KeyRotationService {
+	return &keyRotationService{
+		Log:     utils.Log,
+		DB:      db,
+		Secrets: &fiscalSecrets{Provider: keyProvider},
+	}
+}
+
+// ReencryptAll moves every fiscal data record, including soft deleted ones,
+// to the current master key version. Records that already use data keys only
+// get their data key re-wrapped; legacy records are sealed again from scratch.
+func (s *keyRotationService) ReencryptAll(ctx context.Context) (int, error) {
+	current, err := s.Secrets.Provider.CurrentVersion(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	migrated := 0
+
+	var batch []model.DatosFiscalesSAT
+	result := s.DB.WithContext(ctx).Unscoped().
+		Where("key_version <> ? OR data_key_encriptada IS NULL OR data_key_encriptada = ''", current).
+		FindInBatches(&batch, 100, func(tx *gorm.DB, _ int) error {
+			for i := range batch {
+				if err := s.reencrypt(ctx, tx, &batch[i]); err != nil {
+					s.Log.Errorf("Failed to re-encrypt fiscal data %s: %+v", batch[i].UUID, err)
+					return err
+				}
+				migrated++
+			}
+			return nil
+		})
+
+	return migrated, result.Error
+}
+
+func (s *keyRotationService) reencrypt(ctx context.Context, tx *gorm.DB, datosFiscales *model.DatosFiscalesSAT) error {
+	if datosFiscales.DataKeyEncriptada != "" {
+		dataKey, err := s.Secrets.Provider.Unwrap(ctx, datosFiscales.DataKeyEncriptada, datosFiscales.KeyVersion)
+		if err != nil {
+			return err
+		}
+		defer fiel.Zero(dataKey)
+
+		wrapped, version, err := s.Secrets.Provider.Wrap(ctx, dataKey)
+		if err != nil {
+			return err
+		}
+
+		datosFiscales.DataKeyEncriptada = wrapped
+		datosFiscales.KeyVersion = version
+	} else {
+		plain, err := s.Secrets.open(ctx, datosFiscales)
+		if err != nil {
+			return err
+		}
+		defer plain.wipe()
+
+		if err := s.Secrets.seal(ctx, datosFiscales, plain); err != nil {
+			return err
+		}
+	}
+
+	return tx.Unscoped().Model(datosFiscales).
+		Select("cer_b64_encriptado", "key_b64_encriptado", "password_efirma_encrip", "data_key_encriptada", "key_version").
+		Updates(datosFiscales).Error
+}
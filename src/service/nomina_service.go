@@ -0,0 +1,160 @@
+package service
+
+import (
+	"app/src/model"
+	"app/src/utils"
+	"app/src/validation"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// sumasNomina are the totals every payroll summary adds up.
+const sumasNomina = "COUNT(DISTINCT cfdis_nominas.id) AS recibos, " +
+	"COUNT(DISTINCT cfdis_nominas.rfc_empleado) AS empleados, " +
+	"SUM(cfdis_nominas.total_percepciones) AS total_percepciones, " +
+	"SUM(cfdis_nominas.total_gravado) AS total_gravado, " +
+	"SUM(cfdis_nominas.total_exento) AS total_exento, " +
+	"SUM(cfdis_nominas.total_deducciones) AS total_deducciones, " +
+	"SUM(cfdis_nominas.total_otros_pagos) AS total_otros_pagos, " +
+	"SUM(cfdis_nominas.isr_retenido) AS isr_retenido, " +
+	"SUM(cfdis_nominas.subsidio_causado) AS subsidio_causado, " +
+	"SUM(cfdis_nominas.total_percepciones + cfdis_nominas.total_otros_pagos - cfdis_nominas.total_deducciones) AS neto"
+
+type NominaService interface {
+	GetResumenPeriodos(c *fiber.Ctx, userID uuid.UUID, params *validation.QueryNomina) ([]model.ResumenNominaPeriodo, error)
+	GetResumenEmpleados(c *fiber.Ctx, userID uuid.UUID, params *validation.QueryNomina) ([]model.ResumenNominaEmpleado, error)
+	GetResumenConceptos(c *fiber.Ctx, userID uuid.UUID, params *validation.QueryNomina) ([]model.ResumenNominaConcepto, error)
+}
+
+type nominaService struct {
+	Log      *logrus.Logger
+	DB       *gorm.DB
+	Validate *validator.Validate
+}
+
+func NewNominaService(db *gorm.DB, validate *validator.Validate) NominaService {
+	return &nominaService{
+		Log:      utils.Log,
+		DB:       db,
+		Validate: validate,
+	}
+}
+
+// GetResumenPeriodos adds up the payroll by pay period, oldest first.
+func (s *nominaService) GetResumenPeriodos(
+	c *fiber.Ctx, userID uuid.UUID, params *validation.QueryNomina,
+) ([]model.ResumenNominaPeriodo, error) {
+	var resumen []model.ResumenNominaPeriodo
+
+	query, err := s.nominas(c, userID, params)
+	if err != nil {
+		return nil, err
+	}
+
+	result := query.
+		Select("cfdis_nominas.fecha_inicial_pago, cfdis_nominas.fecha_final_pago, " + sumasNomina).
+		Group("cfdis_nominas.fecha_inicial_pago, cfdis_nominas.fecha_final_pago").
+		Order("cfdis_nominas.fecha_inicial_pago asc, cfdis_nominas.fecha_final_pago asc").
+		Scan(&resumen)
+	if result.Error != nil {
+		s.Log.Errorf("Failed to summarize payroll by period: %+v", result.Error)
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to summarize payroll")
+	}
+
+	return resumen, nil
+}
+
+// GetResumenEmpleados adds up the payroll by employee RFC, by name.
+func (s *nominaService) GetResumenEmpleados(
+	c *fiber.Ctx, userID uuid.UUID, params *validation.QueryNomina,
+) ([]model.ResumenNominaEmpleado, error) {
+	var resumen []model.ResumenNominaEmpleado
+
+	query, err := s.nominas(c, userID, params)
+	if err != nil {
+		return nil, err
+	}
+
+	// The last receipt has the current name and employee number.
+	result := query.
+		Select("cfdis_nominas.rfc_empleado, " +
+			"(array_agg(cfdis_nominas.nombre_empleado ORDER BY cfdis_nominas.fecha_pago DESC))[1] AS nombre_empleado, " +
+			"(array_agg(cfdis_nominas.curp ORDER BY cfdis_nominas.fecha_pago DESC))[1] AS curp, " +
+			"(array_agg(cfdis_nominas.num_empleado ORDER BY cfdis_nominas.fecha_pago DESC))[1] AS num_empleado, " +
+			sumasNomina).
+		Group("cfdis_nominas.rfc_empleado").
+		Order("nombre_empleado asc, cfdis_nominas.rfc_empleado asc").
+		Scan(&resumen)
+	if result.Error != nil {
+		s.Log.Errorf("Failed to summarize payroll by employee: %+v", result.Error)
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to summarize payroll")
+	}
+
+	return resumen, nil
+}
+
+// GetResumenConceptos adds up the percepciones, deducciones and otros pagos
+// by their SAT type and the employer's clave.
+func (s *nominaService) GetResumenConceptos(
+	c *fiber.Ctx, userID uuid.UUID, params *validation.QueryNomina,
+) ([]model.ResumenNominaConcepto, error) {
+	var resumen []model.ResumenNominaConcepto
+
+	query, err := s.nominas(c, userID, params)
+	if err != nil {
+		return nil, err
+	}
+
+	result := query.
+		Joins("JOIN cfdis_nominas_conceptos ON cfdis_nominas_conceptos.nomina_id = cfdis_nominas.id").
+		Select("cfdis_nominas_conceptos.tipo, cfdis_nominas_conceptos.tipo_sat, cfdis_nominas_conceptos.clave, " +
+			"MIN(cfdis_nominas_conceptos.concepto) AS concepto, " +
+			"COUNT(DISTINCT cfdis_nominas.id) AS recibos, " +
+			"COUNT(DISTINCT cfdis_nominas.rfc_empleado) AS empleados, " +
+			"SUM(cfdis_nominas_conceptos.importe_gravado) AS importe_gravado, " +
+			"SUM(cfdis_nominas_conceptos.importe_exento) AS importe_exento, " +
+			"SUM(cfdis_nominas_conceptos.importe) AS importe").
+		Group("cfdis_nominas_conceptos.tipo, cfdis_nominas_conceptos.tipo_sat, cfdis_nominas_conceptos.clave").
+		Order("cfdis_nominas_conceptos.tipo desc, cfdis_nominas_conceptos.tipo_sat asc, cfdis_nominas_conceptos.clave asc").
+		Scan(&resumen)
+	if result.Error != nil {
+		s.Log.Errorf("Failed to summarize payroll by concept: %+v", result.Error)
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to summarize payroll")
+	}
+
+	return resumen, nil
+}
+
+// nominas selects the payroll complements of the vigente CFDIs emitted by
+// the user RFCs, paid between the dates.
+func (s *nominaService) nominas(c *fiber.Ctx, userID uuid.UUID, params *validation.QueryNomina) (*gorm.DB, error) {
+	if err := s.Validate.Struct(params); err != nil {
+		return nil, err
+	}
+
+	if err := validarRangoFechas(params.FechaInicial, params.FechaFinal); err != nil {
+		return nil, err
+	}
+
+	db := s.DB.WithContext(c.Context())
+
+	query := db.Table("cfdis_nominas").
+		Joins("JOIN cfdis ON cfdis.uuid = cfdis_nominas.cfdi_uuid").
+		Where("cfdis.rfc_emisor IN (?) AND cfdis.estado <> ?", rfcsDelUsuario(db, userID), model.EstadoCFDICancelado)
+	query = filtrarFechas(query, "cfdis_nominas.fecha_pago", params.FechaInicial, params.FechaFinal)
+
+	if rfc := strings.ToUpper(params.RFC); rfc != "" {
+		query = query.Where("cfdis.rfc_emisor = ?", rfc)
+	}
+
+	if empleado := strings.ToUpper(params.Empleado); empleado != "" {
+		query = query.Where("cfdis_nominas.rfc_empleado = ?", empleado)
+	}
+
+	return query, nil
+}
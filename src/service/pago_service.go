@@ -0,0 +1,165 @@
+package service
+
+import (
+	"app/src/cfdi"
+	"app/src/model"
+	"app/src/utils"
+	"app/src/validation"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type PagoService interface {
+	GetPagos(c *fiber.Ctx, userID uuid.UUID, id uuid.UUID) (*model.CFDI, []model.CFDIPago, error)
+	GetAntiguedadSaldos(
+		c *fiber.Ctx, userID uuid.UUID, params *validation.QueryAntiguedadSaldos,
+	) ([]model.SaldoPendiente, []model.TotalAntiguedad, int64, error)
+}
+
+type pagoService struct {
+	Log      *logrus.Logger
+	DB       *gorm.DB
+	Validate *validator.Validate
+}
+
+func NewPagoService(db *gorm.DB, validate *validator.Validate) PagoService {
+	return &pagoService{
+		Log:      utils.Log,
+		DB:       db,
+		Validate: validate,
+	}
+}
+
+// GetPagos returns a CFDI of the user RFCs and the payments applied to it
+// by the vigente payment complements of its emisor, in order of parcialidad.
+func (s *pagoService) GetPagos(
+	c *fiber.Ctx, userID uuid.UUID, id uuid.UUID,
+) (*model.CFDI, []model.CFDIPago, error) {
+	var pagos []model.CFDIPago
+	registro := new(model.CFDI)
+
+	db := s.DB.WithContext(c.Context())
+
+	result := propios(db, "cfdis", userID).Where("cfdis.uuid = ?", id).First(registro)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil, fiber.NewError(fiber.StatusNotFound, "CFDI not found")
+	}
+	if result.Error != nil {
+		s.Log.Errorf("Failed to get CFDI %s: %+v", id, result.Error)
+		return nil, nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to retrieve CFDI")
+	}
+
+	result = db.Where("id_documento = ? AND cfdi_uuid IN (?)", id, complementosVigentes(db, registro.RFCEmisor)).
+		Order("num_parcialidad asc, fecha_pago asc, cfdi_uuid asc").
+		Find(&pagos)
+	if result.Error != nil {
+		s.Log.Errorf("Failed to get the payments of CFDI %s: %+v", id, result.Error)
+		return nil, nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to retrieve payments")
+	}
+
+	return registro, pagos, nil
+}
+
+// GetAntiguedadSaldos lists a page of the vigente PPD invoices of the user
+// RFCs issued up to fecha_corte that still had an outstanding balance on
+// that date, oldest first, and the totals by aging bucket of all of them.
+// Payments after fecha_corte are not counted.
+func (s *pagoService) GetAntiguedadSaldos(
+	c *fiber.Ctx, userID uuid.UUID, params *validation.QueryAntiguedadSaldos,
+) ([]model.SaldoPendiente, []model.TotalAntiguedad, int64, error) {
+	var saldos []model.SaldoPendiente
+	var totales []model.TotalAntiguedad
+	var totalResults int64
+
+	if err := s.Validate.Struct(params); err != nil {
+		return nil, nil, 0, err
+	}
+
+	corte, _ := time.Parse(time.DateOnly, params.FechaCorte)
+	finCorte := corte.AddDate(0, 0, 1).Format(time.DateOnly)
+
+	db := s.DB.WithContext(c.Context())
+
+	pagados := db.Session(&gorm.Session{NewDB: true}).Table("cfdis_pagos").
+		Select("cfdis_pagos.id_documento, complementos.rfc_emisor, SUM(cfdis_pagos.imp_pagado) AS pagado, "+
+			"MAX(cfdis_pagos.num_parcialidad) AS parcialidades, MAX(cfdis_pagos.fecha_pago) AS ultimo_pago").
+		Joins("JOIN cfdis complementos ON complementos.uuid = cfdis_pagos.cfdi_uuid").
+		Where("complementos.estado <> ? AND cfdis_pagos.fecha_pago < ?", model.EstadoCFDICancelado, finCorte).
+		Group("cfdis_pagos.id_documento, complementos.rfc_emisor")
+
+	pendientes := propios(db.Model(&model.CFDI{}), "cfdis", userID).
+		Select("cfdis.uuid, cfdis.serie, cfdis.folio, cfdis.fecha, cfdis.rfc_emisor, cfdis.nombre_emisor, "+
+			"cfdis.rfc_receptor, cfdis.nombre_receptor, cfdis.moneda, cfdis.total, "+
+			"COALESCE(pagados.pagado, 0) AS pagado, cfdis.total - COALESCE(pagados.pagado, 0) AS saldo, "+
+			"COALESCE(pagados.parcialidades, 0) AS parcialidades, pagados.ultimo_pago, "+
+			"CAST(? AS DATE) - CAST(cfdis.fecha AS DATE) AS dias", params.FechaCorte).
+		Joins("LEFT JOIN (?) pagados ON pagados.id_documento = cfdis.uuid AND pagados.rfc_emisor = cfdis.rfc_emisor", pagados).
+		Where("cfdis.tipo_comprobante = ? AND cfdis.metodo_pago = ? AND cfdis.estado <> ?",
+			cfdi.TipoIngreso, cfdi.MetodoPagoPPD, model.EstadoCFDICancelado).
+		Where("cfdis.fecha < ? AND cfdis.total - COALESCE(pagados.pagado, 0) > 0", finCorte)
+
+	switch params.Rol {
+	case "emisor":
+		pendientes = pendientes.Where("cfdis.rfc_emisor IN (?)", rfcsDelUsuario(db, userID))
+	case "receptor":
+		pendientes = pendientes.Where("cfdis.rfc_receptor IN (?)", rfcsDelUsuario(db, userID))
+	}
+
+	if rfc := strings.ToUpper(params.RFC); rfc != "" {
+		pendientes = pendientes.Where("(cfdis.rfc_emisor = ? OR cfdis.rfc_receptor = ?)", rfc, rfc)
+	}
+
+	documentos := db.Session(&gorm.Session{NewDB: true}).Table("(?) AS pendientes", pendientes).
+		Select("pendientes.*, CASE WHEN pendientes.dias <= 30 THEN ? WHEN pendientes.dias <= 60 THEN ? "+
+			"WHEN pendientes.dias <= 90 THEN ? ELSE ? END AS rango",
+			model.RangoDe0a30, model.RangoDe31a60, model.RangoDe61a90, model.RangoMasDe90)
+
+	result := db.Table("(?) AS documentos", documentos).
+		Select("documentos.moneda, "+
+			"SUM(CASE WHEN documentos.rango = ? THEN documentos.saldo ELSE 0 END) AS de_0_a_30, "+
+			"SUM(CASE WHEN documentos.rango = ? THEN documentos.saldo ELSE 0 END) AS de_31_a_60, "+
+			"SUM(CASE WHEN documentos.rango = ? THEN documentos.saldo ELSE 0 END) AS de_61_a_90, "+
+			"SUM(CASE WHEN documentos.rango = ? THEN documentos.saldo ELSE 0 END) AS mas_de_90, "+
+			"SUM(documentos.saldo) AS total, COUNT(*) AS documentos",
+			model.RangoDe0a30, model.RangoDe31a60, model.RangoDe61a90, model.RangoMasDe90).
+		Group("documentos.moneda").
+		Order("documentos.moneda asc").
+		Scan(&totales)
+	if result.Error != nil {
+		s.Log.Errorf("Failed to add up outstanding balances: %+v", result.Error)
+		return nil, nil, 0, fiber.NewError(fiber.StatusInternalServerError, "Failed to retrieve outstanding balances")
+	}
+
+	for _, total := range totales {
+		totalResults += int64(total.Documentos)
+	}
+
+	offset := (params.Page - 1) * params.Limit
+
+	result = db.Table("(?) AS documentos", documentos).
+		Order("documentos.fecha asc, documentos.uuid asc").
+		Limit(params.Limit).Offset(offset).
+		Scan(&saldos)
+	if result.Error != nil {
+		s.Log.Errorf("Failed to get outstanding balances: %+v", result.Error)
+		return nil, nil, 0, fiber.NewError(fiber.StatusInternalServerError, "Failed to retrieve outstanding balances")
+	}
+
+	return saldos, totales, totalResults, nil
+}
+
+// complementosVigentes is a subquery of the payment complements issued by
+// rfcEmisor that are not cancelled. Only the emisor of an invoice can issue
+// the complements that pay it.
+func complementosVigentes(query *gorm.DB, rfcEmisor string) *gorm.DB {
+	return query.Session(&gorm.Session{NewDB: true}).Model(&model.CFDI{}).
+		Select("uuid").
+		Where("tipo_comprobante = ? AND rfc_emisor = ? AND estado <> ?", cfdi.TipoPago, rfcEmisor, model.EstadoCFDICancelado)
+}
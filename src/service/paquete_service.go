@@ -0,0 +1,170 @@
+package service
+
+import (
+	"app/src/model"
+	"app/src/sat"
+	"app/src/storage"
+	"app/src/utils"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// maxIntentosPaquete is how many times a failing package is retried. The
+// SAT itself only serves every package a couple of times.
+const maxIntentosPaquete = 3
+
+type PaqueteService interface {
+	DescargarPendientes(ctx context.Context) (int, error)
+}
+
+type paqueteService struct {
+	Log            *logrus.Logger
+	DB             *gorm.DB
+	FielService    FielService
+	SATAuthService SATAuthService
+	Client         *sat.Client
+	Store          storage.BlobStore
+	BatchSize      int
+}
+
+func NewPaqueteService(
+	db *gorm.DB, fielService FielService, satAuthService SATAuthService, client *sat.Client, store storage.BlobStore,
+) PaqueteService {
+	return &paqueteService{
+		Log:            utils.Log,
+		DB:             db,
+		FielService:    fielService,
+		SATAuthService: satAuthService,
+		Client:         client,
+		Store:          store,
+		BatchSize:      20,
+	}
+}
+
+// DescargarPendientes downloads the packages of finished requests that are
+// not stored yet and returns how many were stored.
+func (s *paqueteService) DescargarPendientes(ctx context.Context) (int, error) {
+	var paquetes []model.PaqueteDescarga
+
+	result := s.DB.WithContext(ctx).Preload("Solicitud").
+		Where("estado = ? OR (estado = ? AND intentos < ?)",
+			model.EstadoPaquetePendiente, model.EstadoPaqueteError, maxIntentosPaquete).
+		Order("created_at ASC").
+		Limit(s.BatchSize).
+		Find(&paquetes)
+	if result.Error != nil {
+		return 0, result.Error
+	}
+
+	descargados := 0
+	for i := range paquetes {
+		if ctx.Err() != nil {
+			return descargados, ctx.Err()
+		}
+
+		paquete := &paquetes[i]
+		if err := s.descargar(ctx, paquete); err != nil {
+			s.Log.Errorf("Failed to download package %s: %+v", paquete.IDPaquete, err)
+			s.fallo(ctx, paquete, err.Error())
+			continue
+		}
+
+		descargados++
+	}
+
+	return descargados, nil
+}
+
+func (s *paqueteService) descargar(ctx context.Context, paquete *model.PaqueteDescarga) error {
+	solicitud := paquete.Solicitud
+	key := fmt.Sprintf("paquetes/%s/%s/%s.zip", solicitud.RFCSolicitante, solicitud.UUID, paquete.IDPaquete)
+
+	// A retry may follow a package that was stored but never marked as
+	// downloaded. The SAT only serves a package a couple of times, so the
+	// stored copy is used instead of downloading it again.
+	if paquete.Estado == model.EstadoPaqueteError {
+		data, err := s.Store.Get(ctx, key)
+		if err == nil {
+			archivos, err := sat.ValidatePaquete(data)
+			if err == nil {
+				return s.descargado(ctx, paquete, key, data, archivos)
+			}
+			s.Log.Warnf("Downloading again package %s, the stored copy is invalid: %+v", paquete.IDPaquete, err)
+		} else if !errors.Is(err, storage.ErrNotFound) {
+			return fmt.Errorf("reading stored package: %w", err)
+		}
+	}
+
+	signer, err := s.FielService.LoadSigner(ctx, solicitud.UserID, solicitud.DatosFiscalesUUID)
+	if err != nil {
+		return err
+	}
+	defer signer.Close()
+
+	token, err := s.SATAuthService.Token(ctx, solicitud.DatosFiscalesUUID, signer)
+	if err != nil {
+		return err
+	}
+
+	result, err := s.Client.Descargar(ctx, token.Value, signer, solicitud.RFCSolicitante, paquete.IDPaquete)
+	if err != nil {
+		return err
+	}
+
+	if result.CodEstatus != sat.CodSolicitudAceptada {
+		if result.CodEstatus == sat.CodUsuarioNoValido {
+			s.SATAuthService.Invalidate(solicitud.DatosFiscalesUUID)
+		}
+		return fmt.Errorf("SAT answered %s: %s", result.CodEstatus, result.Mensaje)
+	}
+
+	archivos, err := sat.ValidatePaquete(result.Paquete)
+	if err != nil {
+		return err
+	}
+
+	if err := s.Store.Put(ctx, key, result.Paquete); err != nil {
+		return fmt.Errorf("storing package: %w", err)
+	}
+
+	return s.descargado(ctx, paquete, key, result.Paquete, archivos)
+}
+
+// descargado marks the package as downloaded and stored under key.
+func (s *paqueteService) descargado(
+	ctx context.Context, paquete *model.PaqueteDescarga, key string, data []byte, archivos int,
+) error {
+	checksum := sha256.Sum256(data)
+	now := time.Now()
+
+	return s.DB.WithContext(ctx).Model(paquete).Updates(map[string]interface{}{
+		"estado":          model.EstadoPaqueteDescargado,
+		"tamano_bytes":    len(data),
+		"sha256":          hex.EncodeToString(checksum[:]),
+		"storage_key":     key,
+		"numero_archivos": archivos,
+		"intentos":        paquete.Intentos + 1,
+		"mensaje":         "",
+		"descargado_at":   now,
+		"updated_at":      now,
+	}).Error
+}
+
+func (s *paqueteService) fallo(ctx context.Context, paquete *model.PaqueteDescarga, mensaje string) {
+	err := s.DB.WithContext(ctx).Model(paquete).Updates(map[string]interface{}{
+		"estado":     model.EstadoPaqueteError,
+		"intentos":   paquete.Intentos + 1,
+		"mensaje":    mensaje,
+		"updated_at": time.Now(),
+	}).Error
+	if err != nil {
+		s.Log.Errorf("Failed to update package %s: %+v", paquete.IDPaquete, err)
+	}
+}
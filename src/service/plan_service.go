@@ -0,0 +1,196 @@
+package service
+
+import (
+	"app/src/model"
+	"app/src/utils"
+	"app/src/validation"
+	"errors"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type PlanService interface {
+	GetPlanes(c *fiber.Ctx, params *validation.QueryPlan) ([]model.PlanSuscripcion, int64, error)
+	GetPlanByID(c *fiber.Ctx, id string) (*model.PlanSuscripcion, error)
+	CreatePlan(c *fiber.Ctx, req *validation.CreatePlan) (*model.PlanSuscripcion, error)
+	UpdatePlan(c *fiber.Ctx, id string, req *validation.UpdatePlan) (*model.PlanSuscripcion, error)
+	SetPlanActivo(c *fiber.Ctx, id string, activo bool) (*model.PlanSuscripcion, error)
+	DeletePlan(c *fiber.Ctx, id string) error
+}
+
+type planService struct {
+	Log      *logrus.Logger
+	DB       *gorm.DB
+	Validate *validator.Validate
+}
+
+func NewPlanService(db *gorm.DB, validate *validator.Validate) PlanService {
+	return &planService{
+		Log:      utils.Log,
+		DB:       db,
+		Validate: validate,
+	}
+}
+
+func (s *planService) GetPlanes(c *fiber.Ctx, params *validation.QueryPlan) ([]model.PlanSuscripcion, int64, error) {
+	var planes []model.PlanSuscripcion
+	var totalResults int64
+
+	if err := s.Validate.Struct(params); err != nil {
+		return nil, 0, err
+	}
+
+	offset := (params.Page - 1) * params.Limit
+	query := s.DB.WithContext(c.Context()).Model(&model.PlanSuscripcion{}).Order("precio asc, nombre asc")
+
+	if search := params.Search; search != "" {
+		query = query.Where("nombre ILIKE ?", "%"+search+"%")
+	}
+
+	if params.Activo != "" {
+		query = query.Where("activo = ?", params.Activo == "true")
+	}
+
+	result := query.Count(&totalResults)
+	if result.Error != nil {
+		s.Log.Errorf("Failed to count subscription plans: %+v", result.Error)
+		return nil, 0, fiber.NewError(fiber.StatusInternalServerError, "Failed to retrieve subscription plans")
+	}
+
+	result = query.Limit(params.Limit).Offset(offset).Find(&planes)
+	if result.Error != nil {
+		s.Log.Errorf("Failed to get subscription plans: %+v", result.Error)
+		return nil, 0, fiber.NewError(fiber.StatusInternalServerError, "Failed to retrieve subscription plans")
+	}
+
+	return planes, totalResults, nil
+}
+
+func (s *planService) GetPlanByID(c *fiber.Ctx, id string) (*model.PlanSuscripcion, error) {
+	plan := new(model.PlanSuscripcion)
+
+	result := s.DB.WithContext(c.Context()).First(plan, "id = ?", id)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, fiber.NewError(fiber.StatusNotFound, "Subscription plan not found")
+	}
+
+	if result.Error != nil {
+		s.Log.Errorf("Failed to get subscription plan: %+v", result.Error)
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to retrieve subscription plan")
+	}
+
+	return plan, nil
+}
+
+func (s *planService) CreatePlan(c *fiber.Ctx, req *validation.CreatePlan) (*model.PlanSuscripcion, error) {
+	if err := s.Validate.Struct(req); err != nil {
+		return nil, err
+	}
+
+	plan := &model.PlanSuscripcion{
+		Nombre:                   req.Nombre,
+		Descripcion:              req.Descripcion,
+		LimiteDescargasMensuales: req.LimiteDescargasMensuales,
+		Precio:                   req.Precio.Round(2),
+		Activo:                   req.Activo,
+	}
+
+	result := s.DB.WithContext(c.Context()).Create(plan)
+	if errors.Is(result.Error, gorm.ErrDuplicatedKey) {
+		return nil, fiber.NewError(fiber.StatusConflict, "A subscription plan with this name already exists")
+	}
+
+	if result.Error != nil {
+		s.Log.Errorf("Failed to create subscription plan: %+v", result.Error)
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to create subscription plan")
+	}
+
+	return plan, nil
+}
+
+// UpdatePlan changes the plan for future subscriptions. Existing ones keep
+// the monthly limit they were sold with.
+func (s *planService) UpdatePlan(c *fiber.Ctx, id string, req *validation.UpdatePlan) (*model.PlanSuscripcion, error) {
+	if err := s.Validate.Struct(req); err != nil {
+		return nil, err
+	}
+
+	updates := make(map[string]interface{})
+
+	if req.Nombre != "" {
+		updates["nombre"] = req.Nombre
+	}
+
+	if req.Descripcion != "" {
+		updates["descripcion"] = req.Descripcion
+	}
+
+	if req.LimiteDescargasMensuales != nil {
+		updates["limite_descargas_mensuales"] = *req.LimiteDescargasMensuales
+	}
+
+	if req.Precio != nil {
+		updates["precio"] = req.Precio.Round(2)
+	}
+
+	if len(updates) == 0 {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Invalid Request")
+	}
+
+	return s.updatePlan(c, id, updates)
+}
+
+func (s *planService) SetPlanActivo(c *fiber.Ctx, id string, activo bool) (*model.PlanSuscripcion, error) {
+	return s.updatePlan(c, id, map[string]interface{}{"activo": activo})
+}
+
+// DeletePlan soft deletes the plan. Plans with active subscriptions can only
+// be deactivated.
+func (s *planService) DeletePlan(c *fiber.Ctx, id string) error {
+	var suscripciones int64
+
+	result := s.DB.WithContext(c.Context()).Model(&model.SuscripcionUsuario{}).
+		Where("plan_id = ? AND estatus = ?", id, model.EstatusSuscripcionActivo).
+		Count(&suscripciones)
+	if result.Error != nil {
+		s.Log.Errorf("Failed to count plan subscriptions: %+v", result.Error)
+		return fiber.NewError(fiber.StatusInternalServerError, "Failed to delete subscription plan")
+	}
+
+	if suscripciones > 0 {
+		return fiber.NewError(fiber.StatusConflict, "Subscription plan has active subscriptions, deactivate it instead")
+	}
+
+	result = s.DB.WithContext(c.Context()).Delete(&model.PlanSuscripcion{}, "id = ?", id)
+	if result.Error != nil {
+		s.Log.Errorf("Failed to delete subscription plan: %+v", result.Error)
+		return fiber.NewError(fiber.StatusInternalServerError, "Failed to delete subscription plan")
+	}
+
+	if result.RowsAffected == 0 {
+		return fiber.NewError(fiber.StatusNotFound, "Subscription plan not found")
+	}
+
+	return nil
+}
+
+func (s *planService) updatePlan(c *fiber.Ctx, id string, updates map[string]interface{}) (*model.PlanSuscripcion, error) {
+	result := s.DB.WithContext(c.Context()).Model(&model.PlanSuscripcion{}).Where("id = ?", id).Updates(updates)
+	if errors.Is(result.Error, gorm.ErrDuplicatedKey) {
+		return nil, fiber.NewError(fiber.StatusConflict, "A subscription plan with this name already exists")
+	}
+
+	if result.Error != nil {
+		s.Log.Errorf("Failed to update subscription plan: %+v", result.Error)
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to update subscription plan")
+	}
+
+	if result.RowsAffected == 0 {
+		return nil, fiber.NewError(fiber.StatusNotFound, "Subscription plan not found")
+	}
+
+	return s.GetPlanByID(c, id)
+}
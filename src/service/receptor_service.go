@@ -0,0 +1,299 @@
+package service
+
+import (
+	"app/src/catalogo"
+	"app/src/cfdi"
+	"app/src/model"
+	"app/src/response"
+	"app/src/rfc"
+	"app/src/utils"
+	"app/src/validation"
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type ReceptorService interface {
+	GetValidaciones(
+		c *fiber.Ctx, userID uuid.UUID, params *validation.QueryValidacionReceptor,
+	) ([]response.ValidacionReceptor, int64, error)
+	ValidarXML(c *fiber.Ctx, data []byte) (*response.ValidacionReceptor, error)
+	Validar(ctx context.Context, comprobante *cfdi.Comprobante) (*response.ValidacionReceptor, error)
+}
+
+type receptorService struct {
+	Log             *logrus.Logger
+	DB              *gorm.DB
+	Validate        *validator.Validate
+	CatalogoService CatalogoService
+}
+
+func NewReceptorService(db *gorm.DB, validate *validator.Validate, catalogoService CatalogoService) ReceptorService {
+	return &receptorService{
+		Log:             utils.Log,
+		DB:              db,
+		Validate:        validate,
+		CatalogoService: catalogoService,
+	}
+}
+
+// GetValidaciones checks the Receptor of the CFDI 4.0 emitted or received
+// by the user RFCs, newest first, against the catalogs in effect when each
+// one was issued.
+func (s *receptorService) GetValidaciones(
+	c *fiber.Ctx, userID uuid.UUID, params *validation.QueryValidacionReceptor,
+) ([]response.ValidacionReceptor, int64, error) {
+	var cfdis []model.CFDI
+	var totalResults int64
+
+	if err := s.Validate.Struct(params); err != nil {
+		return nil, 0, err
+	}
+
+	if err := validarRangoFechas(params.FechaInicial, params.FechaFinal); err != nil {
+		return nil, 0, err
+	}
+
+	db := s.DB.WithContext(c.Context())
+
+	query := propios(db.Model(&model.CFDI{}), "cfdis", userID).Where("cfdis.version = ?", cfdi.Version40)
+	query = filtrarFechas(query, "cfdis.fecha", params.FechaInicial, params.FechaFinal)
+
+	if rfc := strings.ToUpper(params.RFC); rfc != "" {
+		query = query.Where("(rfc_emisor = ? OR rfc_receptor = ?)", rfc, rfc)
+	}
+
+	if result := query.Count(&totalResults); result.Error != nil {
+		s.Log.Errorf("Failed to count CFDIs: %+v", result.Error)
+		return nil, 0, fiber.NewError(fiber.StatusInternalServerError, "Failed to validate CFDIs")
+	}
+
+	offset := (params.Page - 1) * params.Limit
+
+	result := query.Order("fecha desc, uuid asc").Limit(params.Limit).Offset(offset).Find(&cfdis)
+	if result.Error != nil {
+		s.Log.Errorf("Failed to get CFDIs: %+v", result.Error)
+		return nil, 0, fiber.NewError(fiber.StatusInternalServerError, "Failed to validate CFDIs")
+	}
+
+	validaciones := make([]response.ValidacionReceptor, 0, len(cfdis))
+	for i := range cfdis {
+		validacion, err := s.Validar(c.Context(), comprobanteIndexado(&cfdis[i]))
+		if err != nil {
+			s.Log.Errorf("Failed to validate the receptor of CFDI %s: %+v", cfdis[i].UUID, err)
+			return nil, 0, fiber.NewError(fiber.StatusInternalServerError, "Failed to validate CFDIs")
+		}
+
+		validacion.UUID = &cfdis[i].UUID
+		validaciones = append(validaciones, *validacion)
+	}
+
+	return validaciones, totalResults, nil
+}
+
+// ValidarXML checks the Receptor of a CFDI 4.0 that hasn't been indexed,
+// stamped or not.
+func (s *receptorService) ValidarXML(c *fiber.Ctx, data []byte) (*response.ValidacionReceptor, error) {
+	comprobante, err := cfdi.Parse(data)
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Invalid CFDI XML")
+	}
+
+	if comprobante.Version != cfdi.Version40 {
+		return nil, fiber.NewError(fiber.StatusUnprocessableEntity, "Receptor rules only apply to CFDI 4.0")
+	}
+
+	validacion, err := s.Validar(c.Context(), comprobante)
+	if err != nil {
+		s.Log.Errorf("Failed to validate the receptor: %+v", err)
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to validate the CFDI")
+	}
+
+	if id, err := uuid.Parse(comprobante.UUID()); err == nil {
+		validacion.UUID = &id
+	}
+
+	return validacion, nil
+}
+
+// Validar checks the Receptor of a CFDI 4.0 with the rules of the
+// comprobante and against c_UsoCFDI, c_RegimenFiscal and c_CodigoPostal:
+// the claves exist and were in effect, the régimen and the UsoCFDI apply
+// to the persona física or moral of the RFC, and the UsoCFDI applies to the
+// régimen.
+func (s *receptorService) Validar(
+	ctx context.Context, comprobante *cfdi.Comprobante,
+) (*response.ValidacionReceptor, error) {
+	r := comprobante.Receptor
+
+	validacion := &response.ValidacionReceptor{
+		Fecha:                   comprobante.Fecha.Time,
+		TipoComprobante:         comprobante.TipoDeComprobante,
+		RFCEmisor:               strings.ToUpper(strings.TrimSpace(comprobante.Emisor.RFC)),
+		RFCReceptor:             strings.ToUpper(strings.TrimSpace(r.RFC)),
+		NombreReceptor:          r.Nombre,
+		RegimenFiscalReceptor:   r.RegimenFiscalReceptor,
+		DomicilioFiscalReceptor: r.DomicilioFiscalReceptor,
+		UsoCFDI:                 r.UsoCFDI,
+		Incidencias:             comprobante.ValidarReceptor(),
+	}
+
+	if validacion.Incidencias == nil {
+		validacion.Incidencias = []cfdi.Incidencia{}
+	}
+
+	if err := s.validarCatalogos(ctx, comprobante, validacion); err != nil {
+		return nil, err
+	}
+
+	validacion.Valido = len(validacion.Incidencias) == 0
+
+	return validacion, nil
+}
+
+func (s *receptorService) validarCatalogos(
+	ctx context.Context, comprobante *cfdi.Comprobante, validacion *response.ValidacionReceptor,
+) error {
+	fecha := comprobante.Fecha.Time
+	r := comprobante.Receptor
+
+	agregar := func(tipo, nodo, mensaje string, args ...interface{}) {
+		validacion.Incidencias = append(validacion.Incidencias,
+			cfdi.Incidencia{Tipo: tipo, Nodo: nodo, Mensaje: fmt.Sprintf(mensaje, args...)})
+	}
+
+	reportado := func(nodo string) bool {
+		for _, incidencia := range validacion.Incidencias {
+			if incidencia.Nodo == nodo {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	// buscar returns the row of the clave, nil when the attribute was
+	// already reported, the catalog hasn't been imported or the clave is
+	// not in it.
+	buscar := func(nombre, nodo, clave string) (*model.CatalogoRegistro, error) {
+		if reportado(nodo) {
+			return nil, nil
+		}
+
+		publicacion, err := s.CatalogoService.Publicacion(ctx, nombre, fecha)
+		if err != nil {
+			return nil, err
+		}
+
+		if publicacion == nil {
+			validacion.CatalogosFaltantes = append(validacion.CatalogosFaltantes, nombre)
+			return nil, nil
+		}
+
+		registro, err := s.CatalogoService.Registro(ctx, nombre, clave, fecha)
+		if err != nil {
+			return nil, err
+		}
+
+		if registro == nil {
+			agregar(cfdi.IncidenciaClaveInvalida, nodo, "%q is not in %s", clave, nombre)
+			return nil, nil
+		}
+
+		if !registro.Vigente(fecha) {
+			agregar(cfdi.IncidenciaClaveNoVigente, nodo, "%q of %s was not in effect on %s",
+				clave, nombre, fecha.Format(time.DateOnly))
+		}
+
+		return registro, nil
+	}
+
+	if _, err := buscar(catalogo.CodigoPostal, "Receptor@DomicilioFiscalReceptor", r.DomicilioFiscalReceptor); err != nil {
+		return err
+	}
+
+	regimen, err := buscar(catalogo.RegimenFiscal, "Receptor@RegimenFiscalReceptor", r.RegimenFiscalReceptor)
+	if err != nil {
+		return err
+	}
+
+	uso, err := buscar(catalogo.UsoCFDI, "Receptor@UsoCFDI", r.UsoCFDI)
+	if err != nil {
+		return err
+	}
+
+	// The type of persona is only known from a well formed RFC.
+	persona := ""
+	if !reportado("Receptor@Rfc") {
+		if rfc.IsPersonaMoral(validacion.RFCReceptor) {
+			persona = "moral"
+		} else {
+			persona = "fisica"
+		}
+	}
+
+	if regimen != nil && persona != "" && !aplica(regimen.Atributos[persona]) {
+		agregar(cfdi.IncidenciaTipoPersona, "Receptor@RegimenFiscalReceptor",
+			"RegimenFiscalReceptor %s doesn't apply to personas %s", r.RegimenFiscalReceptor, personas[persona])
+	}
+
+	if uso == nil {
+		return nil
+	}
+
+	if persona != "" && !aplica(uso.Atributos["aplica_para_tipo_persona_"+persona]) {
+		agregar(cfdi.IncidenciaTipoPersona, "Receptor@UsoCFDI",
+			"UsoCFDI %s doesn't apply to personas %s", r.UsoCFDI, personas[persona])
+	}
+
+	if regimenes, ok := uso.Atributos["regimen_fiscal_receptor"]; ok && regimen != nil {
+		permitido := false
+		for _, clave := range strings.Split(regimenes, ",") {
+			if strings.TrimSpace(clave) == regimen.Clave {
+				permitido = true
+				break
+			}
+		}
+
+		if !permitido {
+			agregar(cfdi.IncidenciaUsoCFDIRegimen, "Receptor@UsoCFDI",
+				"UsoCFDI %s doesn't apply to RegimenFiscalReceptor %s", r.UsoCFDI, regimen.Clave)
+		}
+	}
+
+	return nil
+}
+
+// personas names the type of persona of the c_RegimenFiscal columns.
+var personas = map[string]string{"fisica": "físicas", "moral": "morales"}
+
+// aplica reads the Sí/No columns of the catalogs.
+func aplica(valor string) bool {
+	return catalogo.Normalizar(valor) == "si"
+}
+
+// comprobanteIndexado rebuilds the part of the comprobante the receptor
+// rules look at from an indexed CFDI.
+func comprobanteIndexado(registro *model.CFDI) *cfdi.Comprobante {
+	return &cfdi.Comprobante{
+		Version:           registro.Version,
+		Fecha:             cfdi.Fecha{Time: registro.Fecha},
+		TipoDeComprobante: registro.TipoComprobante,
+		LugarExpedicion:   registro.LugarExpedicion,
+		Emisor:            cfdi.Emisor{RFC: registro.RFCEmisor},
+		Receptor: cfdi.Receptor{
+			RFC:                     registro.RFCReceptor,
+			Nombre:                  registro.NombreReceptor,
+			DomicilioFiscalReceptor: registro.DomicilioFiscalReceptor,
+			RegimenFiscalReceptor:   registro.RegimenFiscalReceptor,
+			UsoCFDI:                 registro.UsoCFDI,
+		},
+	}
+}
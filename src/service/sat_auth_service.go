@@ -0,0 +1,58 @@
+package service
+
+import (
+	"app/src/fiel"
+	"app/src/sat"
+	"app/src/utils"
+	"context"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+type SATAuthService interface {
+	Token(ctx context.Context, datosFiscalesID uuid.UUID, signer *fiel.Signer) (*sat.Token, error)
+	Invalidate(datosFiscalesID uuid.UUID)
+}
+
+type satAuthService struct {
+	Log    *logrus.Logger
+	Client *sat.Client
+	Cache  *sat.TokenCache
+}
+
+func NewSATAuthService(client *sat.Client) SATAuthService {
+	return &satAuthService{
+		Log:    utils.Log,
+		Client: client,
+		Cache:  sat.NewTokenCache(),
+	}
+}
+
+// Token returns a SAT access token for the fiscal data the signer was
+// loaded from, which the caller needs anyway to sign its request, so the
+// e.firma is decrypted once per call. Tokens are cached per fiscal data
+// until they expire. Another user with the same RFC never gets them, since
+// loading the signer checks who owns the fiscal data.
+func (s *satAuthService) Token(ctx context.Context, datosFiscalesID uuid.UUID, signer *fiel.Signer) (*sat.Token, error) {
+	if token, ok := s.Cache.Get(datosFiscalesID); ok {
+		return token, nil
+	}
+
+	token, err := s.Client.Autentica(ctx, signer)
+	if err != nil {
+		s.Log.Errorf("SAT authentication failed for %s: %+v", signer.Certificate().RFC, err)
+		return nil, fiber.NewError(fiber.StatusBadGateway, "SAT authentication failed")
+	}
+
+	s.Cache.Set(datosFiscalesID, token)
+
+	return token, nil
+}
+
+// Invalidate drops the cached token of the fiscal data, used when the SAT
+// no longer accepts it before its expiry.
+func (s *satAuthService) Invalidate(datosFiscalesID uuid.UUID) {
+	s.Cache.Delete(datosFiscalesID)
+}
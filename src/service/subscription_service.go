@@ -0,0 +1,273 @@
+package service
+
+import (
+	"app/src/model"
+	"app/src/utils"
+	"app/src/validation"
+	"errors"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type SubscriptionService interface {
+	GetSuscripcionActiva(c *fiber.Ctx, userID uuid.UUID) (*model.SuscripcionUsuario, error)
+	ReservarDescarga(c *fiber.Ctx, userID uuid.UUID) (*model.SuscripcionUsuario, error)
+	LiberarDescarga(c *fiber.Ctx, suscripcionID uuid.UUID) (*model.SuscripcionUsuario, error)
+	Suscribir(c *fiber.Ctx, userID uuid.UUID, req *validation.Suscribir) (*model.SuscripcionUsuario, error)
+	CancelarSuscripcion(c *fiber.Ctx, userID uuid.UUID) (*model.SuscripcionUsuario, error)
+	RenovarSuscripcion(c *fiber.Ctx, userID uuid.UUID, req *validation.RenovarSuscripcion) (*model.SuscripcionUsuario, error)
+}
+
+type subscriptionService struct {
+	Log      *logrus.Logger
+	DB       *gorm.DB
+	Validate *validator.Validate
+	Now      func() time.Time
+}
+
+func NewSubscriptionService(db *gorm.DB, validate *validator.Validate) SubscriptionService {
+	return &subscriptionService{
+		Log:      utils.Log,
+		DB:       db,
+		Validate: validate,
+		Now:      time.Now,
+	}
+}
+
+// GetSuscripcionActiva returns the subscription in force today with its plan,
+// with the monthly counter of the current period.
+func (s *subscriptionService) GetSuscripcionActiva(c *fiber.Ctx, userID uuid.UUID) (*model.SuscripcionUsuario, error) {
+	now := s.Now().UTC()
+
+	suscripcion := new(model.SuscripcionUsuario)
+
+	result := s.activas(s.DB.WithContext(c.Context()), userID, now).Preload("Plan").First(suscripcion)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, fiber.NewError(fiber.StatusNotFound, "No active subscription found")
+	}
+
+	if result.Error != nil {
+		s.Log.Errorf("Failed to get subscription: %+v", result.Error)
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to retrieve subscription")
+	}
+
+	reiniciarPeriodo(suscripcion, now)
+
+	return suscripcion, nil
+}
+
+// ReservarDescarga takes one download from the monthly quota of the active
+// subscription. The row is locked so concurrent requests can't overdraw the
+// quota. When the quota is exhausted the subscription is returned together
+// with the error so callers can still report it.
+func (s *subscriptionService) ReservarDescarga(c *fiber.Ctx, userID uuid.UUID) (*model.SuscripcionUsuario, error) {
+	now := s.Now().UTC()
+
+	suscripcion := new(model.SuscripcionUsuario)
+
+	err := s.DB.WithContext(c.Context()).Transaction(func(tx *gorm.DB) error {
+		result := s.activas(tx, userID, now).Clauses(clause.Locking{Strength: "UPDATE"}).First(suscripcion)
+		if result.Error != nil {
+			return result.Error
+		}
+
+		reiniciarPeriodo(suscripcion, now)
+
+		if suscripcion.DescargasRestantes() == 0 {
+			return errQuotaAgotada
+		}
+
+		suscripcion.DescargasUsadas++
+
+		return tx.Model(suscripcion).Updates(map[string]interface{}{
+			"descargas_usadas": suscripcion.DescargasUsadas,
+			"periodo_inicio":   suscripcion.PeriodoInicio,
+		}).Error
+	})
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fiber.NewError(fiber.StatusPaymentRequired, "An active subscription is required to request downloads")
+	}
+
+	if errors.Is(err, errQuotaAgotada) {
+		return suscripcion, fiber.NewError(fiber.StatusTooManyRequests, "Monthly download quota exhausted")
+	}
+
+	if err != nil {
+		s.Log.Errorf("Failed to reserve download quota: %+v", err)
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to reserve download quota")
+	}
+
+	return suscripcion, nil
+}
+
+// LiberarDescarga gives back a download reserved for a request that failed.
+func (s *subscriptionService) LiberarDescarga(c *fiber.Ctx, suscripcionID uuid.UUID) (*model.SuscripcionUsuario, error) {
+	suscripcion := new(model.SuscripcionUsuario)
+
+	result := s.DB.WithContext(c.Context()).Model(suscripcion).Clauses(clause.Returning{}).
+		Where("id = ?", suscripcionID).
+		Update("descargas_usadas", gorm.Expr("GREATEST(descargas_usadas - 1, 0)"))
+	if result.Error != nil {
+		s.Log.Errorf("Failed to release download quota: %+v", result.Error)
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to release download quota")
+	}
+
+	return suscripcion, nil
+}
+
+// Suscribir subscribes the user to an active plan starting today. Users can
+// only have one active subscription, so they have to renew or cancel it
+// before changing plans.
+func (s *subscriptionService) Suscribir(
+	c *fiber.Ctx, userID uuid.UUID, req *validation.Suscribir,
+) (*model.SuscripcionUsuario, error) {
+	if err := s.Validate.Struct(req); err != nil {
+		return nil, err
+	}
+
+	plan := new(model.PlanSuscripcion)
+
+	result := s.DB.WithContext(c.Context()).First(plan, "id = ?", req.PlanID)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, fiber.NewError(fiber.StatusNotFound, "Subscription plan not found")
+	}
+
+	if result.Error != nil {
+		s.Log.Errorf("Failed to get subscription plan: %+v", result.Error)
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to create subscription")
+	}
+
+	if !plan.Activo {
+		return nil, fiber.NewError(fiber.StatusUnprocessableEntity, "Subscription plan is not available")
+	}
+
+	meses := req.Meses
+	if meses == 0 {
+		meses = 1
+	}
+
+	today := s.Now().UTC().Truncate(24 * time.Hour)
+
+	suscripcion := &model.SuscripcionUsuario{
+		UsuarioID:                userID,
+		PlanID:                   plan.ID,
+		LimiteDescargasMensuales: plan.LimiteDescargasMensuales,
+		PeriodoInicio:            today,
+		FechaInicio:              today,
+		FechaFin:                 model.FinVigencia(today, meses),
+		Estatus:                  model.EstatusSuscripcionActivo,
+	}
+
+	err := s.DB.WithContext(c.Context()).Transaction(func(tx *gorm.DB) error {
+		// Subscriptions past fecha_fin the scheduled job has not expired yet
+		// would still hold the one active subscription per user index.
+		err := tx.Model(&model.SuscripcionUsuario{}).
+			Where("usuario_id = ? AND estatus = ? AND fecha_fin < ?",
+				userID, model.EstatusSuscripcionActivo, today.Format(time.DateOnly)).
+			Update("estatus", model.EstatusSuscripcionExpirado).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Create(suscripcion).Error
+	})
+
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return nil, fiber.NewError(fiber.StatusConflict, "User already has an active subscription, renew or cancel it first")
+	}
+
+	if err != nil {
+		s.Log.Errorf("Failed to create subscription: %+v", err)
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to create subscription")
+	}
+
+	suscripcion.Plan = plan
+
+	return suscripcion, nil
+}
+
+// CancelarSuscripcion cancels the active subscription right away, the
+// downloads left in the period are lost.
+func (s *subscriptionService) CancelarSuscripcion(c *fiber.Ctx, userID uuid.UUID) (*model.SuscripcionUsuario, error) {
+	suscripcion, err := s.GetSuscripcionActiva(c, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.Now()
+	suscripcion.Estatus = model.EstatusSuscripcionCancelado
+	suscripcion.CanceladaAt = &now
+
+	result := s.DB.WithContext(c.Context()).Model(suscripcion).Updates(map[string]interface{}{
+		"estatus":      suscripcion.Estatus,
+		"cancelada_at": suscripcion.CanceladaAt,
+	})
+	if result.Error != nil {
+		s.Log.Errorf("Failed to cancel subscription: %+v", result.Error)
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to cancel subscription")
+	}
+
+	return suscripcion, nil
+}
+
+// RenovarSuscripcion extends the active subscription by the requested
+// months, one by default. The monthly limit it was sold with is kept.
+func (s *subscriptionService) RenovarSuscripcion(
+	c *fiber.Ctx, userID uuid.UUID, req *validation.RenovarSuscripcion,
+) (*model.SuscripcionUsuario, error) {
+	if err := s.Validate.Struct(req); err != nil {
+		return nil, err
+	}
+
+	meses := req.Meses
+	if meses == 0 {
+		meses = 1
+	}
+
+	suscripcion, err := s.GetSuscripcionActiva(c, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	suscripcion.Extender(meses)
+	suscripcion.AvisoVencimientoAt = nil
+
+	result := s.DB.WithContext(c.Context()).Model(suscripcion).Updates(map[string]interface{}{
+		"fecha_fin":            suscripcion.FechaFin,
+		"aviso_vencimiento_at": nil,
+	})
+	if result.Error != nil {
+		s.Log.Errorf("Failed to renew subscription: %+v", result.Error)
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to renew subscription")
+	}
+
+	return suscripcion, nil
+}
+
+// activas scopes the query to the active subscriptions of the user in force
+// on now's UTC date.
+func (s *subscriptionService) activas(db *gorm.DB, userID uuid.UUID, now time.Time) *gorm.DB {
+	today := now.UTC().Format(time.DateOnly)
+
+	return db.Where("usuario_id = ? AND estatus = ? AND fecha_inicio <= ? AND fecha_fin >= ?",
+		userID, model.EstatusSuscripcionActivo, today, today).
+		Order("fecha_fin desc")
+}
+
+var errQuotaAgotada = errors.New("monthly download quota exhausted")
+
+// reiniciarPeriodo starts a new monthly period when the stored one is over.
+func reiniciarPeriodo(suscripcion *model.SuscripcionUsuario, now time.Time) {
+	periodo := suscripcion.PeriodoActual(now)
+	if !periodo.Equal(suscripcion.PeriodoInicio.UTC()) {
+		suscripcion.PeriodoInicio = periodo
+		suscripcion.DescargasUsadas = 0
+	}
+}
@@ -0,0 +1,154 @@
+package service
+
+import (
+	"app/src/model"
+	"app/src/utils"
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type VencimientoService interface {
+	ExpirarSuscripciones(ctx context.Context) (int, error)
+	ReiniciarPeriodos(ctx context.Context) (int, error)
+	AvisarVencimientos(ctx context.Context) (int, error)
+}
+
+type vencimientoService struct {
+	Log          *logrus.Logger
+	DB           *gorm.DB
+	EmailService EmailService
+	DiasAviso    int
+	BatchSize    int
+	Now          func() time.Time
+}
+
+func NewVencimientoService(db *gorm.DB, emailService EmailService, diasAviso int) VencimientoService {
+	return &vencimientoService{
+		Log:          utils.Log,
+		DB:           db,
+		EmailService: emailService,
+		DiasAviso:    diasAviso,
+		BatchSize:    100,
+		Now:          time.Now,
+	}
+}
+
+// ExpirarSuscripciones marks as expirado the active subscriptions whose
+// fecha_fin already passed and returns how many were expired. Subscription
+// dates are UTC, like the ones Suscribir stores.
+func (s *vencimientoService) ExpirarSuscripciones(ctx context.Context) (int, error) {
+	today := s.Now().UTC().Format(time.DateOnly)
+
+	result := s.DB.WithContext(ctx).Model(&model.SuscripcionUsuario{}).
+		Where("estatus = ? AND fecha_fin < ?", model.EstatusSuscripcionActivo, today).
+		Update("estatus", model.EstatusSuscripcionExpirado)
+	if result.Error != nil {
+		return 0, result.Error
+	}
+
+	if result.RowsAffected > 0 {
+		s.Log.Infof("Expired %d subscriptions", result.RowsAffected)
+	}
+
+	return int(result.RowsAffected), nil
+}
+
+// ReiniciarPeriodos starts a new monthly period on the active subscriptions
+// whose period is over, resetting their download counter. Downloads reset
+// lazily too, this keeps the stored counters accurate for reporting.
+func (s *vencimientoService) ReiniciarPeriodos(ctx context.Context) (int, error) {
+	now := s.Now().UTC()
+
+	// Months have at least 28 days, so newer periods can't be over yet.
+	limite := now.AddDate(0, 0, -28).Format(time.DateOnly)
+
+	var suscripciones []model.SuscripcionUsuario
+
+	reiniciadas := 0
+	result := s.DB.WithContext(ctx).
+		Where("estatus = ? AND periodo_inicio <= ?", model.EstatusSuscripcionActivo, limite).
+		FindInBatches(&suscripciones, s.BatchSize, func(_ *gorm.DB, _ int) error {
+			for i := range suscripciones {
+				suscripcion := &suscripciones[i]
+
+				periodo := suscripcion.PeriodoActual(now)
+				if periodo.Equal(suscripcion.PeriodoInicio.UTC()) {
+					continue
+				}
+
+				// The previous periodo_inicio guards against a download
+				// reserved meanwhile, which already reset the counter.
+				result := s.DB.WithContext(ctx).Model(&model.SuscripcionUsuario{}).
+					Where("id = ? AND periodo_inicio = ?", suscripcion.ID, suscripcion.PeriodoInicio.Format(time.DateOnly)).
+					Updates(map[string]interface{}{
+						"descargas_usadas": 0,
+						"periodo_inicio":   periodo,
+					})
+				if result.Error != nil {
+					return result.Error
+				}
+
+				reiniciadas += int(result.RowsAffected)
+			}
+
+			return nil
+		})
+
+	return reiniciadas, result.Error
+}
+
+// AvisarVencimientos emails the users whose active subscription ends within
+// DiasAviso days, once per subscription term, and returns how many were
+// notified. A failing email does not stop the batch and is retried on the
+// next run.
+func (s *vencimientoService) AvisarVencimientos(ctx context.Context) (int, error) {
+	now := s.Now()
+	today := now.UTC()
+
+	var suscripciones []model.SuscripcionUsuario
+
+	result := s.DB.WithContext(ctx).Preload("Usuario").Preload("Plan").
+		Where("estatus = ? AND aviso_vencimiento_at IS NULL AND fecha_fin BETWEEN ? AND ?",
+			model.EstatusSuscripcionActivo, today.Format(time.DateOnly), today.AddDate(0, 0, s.DiasAviso).Format(time.DateOnly)).
+		Order("fecha_fin asc").
+		Limit(s.BatchSize).
+		Find(&suscripciones)
+	if result.Error != nil {
+		return 0, result.Error
+	}
+
+	avisadas := 0
+	for i := range suscripciones {
+		if ctx.Err() != nil {
+			return avisadas, ctx.Err()
+		}
+
+		suscripcion := &suscripciones[i]
+		if suscripcion.Usuario == nil {
+			continue
+		}
+
+		plan := "current"
+		if suscripcion.Plan != nil {
+			plan = suscripcion.Plan.Nombre
+		}
+
+		if err := s.EmailService.SendVencimientoSuscripcionEmail(suscripcion.Usuario.Email, plan, suscripcion.FechaFin); err != nil {
+			s.Log.Errorf("Failed to send expiry notice for subscription %s: %+v", suscripcion.ID, err)
+			continue
+		}
+
+		result := s.DB.WithContext(ctx).Model(suscripcion).Update("aviso_vencimiento_at", now)
+		if result.Error != nil {
+			s.Log.Errorf("Failed to save expiry notice for subscription %s: %+v", suscripcion.ID, result.Error)
+			continue
+		}
+
+		avisadas++
+	}
+
+	return avisadas, nil
+}
@@ -0,0 +1,145 @@
+package service
+
+import (
+	"app/src/model"
+	"app/src/sat"
+	"app/src/utils"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type VerificacionService interface {
+	VerificarPendientes(ctx context.Context) (int, error)
+}
+
+type verificacionService struct {
+	Log            *logrus.Logger
+	DB             *gorm.DB
+	FielService    FielService
+	SATAuthService SATAuthService
+	Client         *sat.Client
+	BatchSize      int
+}
+
+func NewVerificacionService(
+	db *gorm.DB, fielService FielService, satAuthService SATAuthService, client *sat.Client,
+) VerificacionService {
+	return &verificacionService{
+		Log:            utils.Log,
+		DB:             db,
+		FielService:    fielService,
+		SATAuthService: satAuthService,
+		Client:         client,
+		BatchSize:      50,
+	}
+}
+
+var estadosSolicitud = map[int]string{
+	sat.EstadoSolicitudAceptada:  model.EstadoSolicitudAceptada,
+	sat.EstadoSolicitudEnProceso: model.EstadoSolicitudEnProceso,
+	sat.EstadoSolicitudTerminada: model.EstadoSolicitudTerminada,
+	sat.EstadoSolicitudError:     model.EstadoSolicitudError,
+	sat.EstadoSolicitudRechazada: model.EstadoSolicitudRechazada,
+	sat.EstadoSolicitudVencida:   model.EstadoSolicitudVencida,
+}
+
+// VerificarPendientes checks with the SAT the download requests that are
+// still aceptada or en proceso, least recently verified first, and returns
+// how many were verified. A failing request does not stop the batch.
+func (s *verificacionService) VerificarPendientes(ctx context.Context) (int, error) {
+	var solicitudes []model.SolicitudDescarga
+
+	result := s.DB.WithContext(ctx).
+		Where("estado IN ? AND id_solicitud IS NOT NULL",
+			[]string{model.EstadoSolicitudAceptada, model.EstadoSolicitudEnProceso}).
+		Order("verificada_at ASC NULLS FIRST").
+		Limit(s.BatchSize).
+		Find(&solicitudes)
+	if result.Error != nil {
+		return 0, result.Error
+	}
+
+	verificadas := 0
+	for i := range solicitudes {
+		if ctx.Err() != nil {
+			return verificadas, ctx.Err()
+		}
+
+		if err := s.verificar(ctx, &solicitudes[i]); err != nil {
+			s.Log.Errorf("Failed to verify download request %s: %+v", solicitudes[i].UUID, err)
+			continue
+		}
+
+		verificadas++
+	}
+
+	return verificadas, nil
+}
+
+func (s *verificacionService) verificar(ctx context.Context, solicitud *model.SolicitudDescarga) error {
+	signer, err := s.FielService.LoadSigner(ctx, solicitud.UserID, solicitud.DatosFiscalesUUID)
+	if err != nil {
+		return err
+	}
+	defer signer.Close()
+
+	token, err := s.SATAuthService.Token(ctx, solicitud.DatosFiscalesUUID, signer)
+	if err != nil {
+		return err
+	}
+
+	result, err := s.Client.VerificaSolicitudDescarga(
+		ctx, token.Value, signer, solicitud.RFCSolicitante, *solicitud.IDSolicitud,
+	)
+	if err != nil {
+		return err
+	}
+
+	if result.CodEstatus == sat.CodUsuarioNoValido {
+		s.SATAuthService.Invalidate(solicitud.DatosFiscalesUUID)
+		return fmt.Errorf("SAT rejected the token: %s", result.Mensaje)
+	}
+
+	now := time.Now()
+	updates := map[string]interface{}{
+		"mensaje":       result.Mensaje,
+		"verificada_at": now,
+		"updated_at":    now,
+	}
+
+	// Only a successful verification carries a meaningful EstadoSolicitud,
+	// otherwise the message is kept and the request is retried later.
+	if result.CodEstatus == sat.CodSolicitudAceptada {
+		if estado, ok := estadosSolicitud[result.EstadoSolicitud]; ok {
+			updates["estado"] = estado
+		}
+		updates["codigo_estado_solicitud"] = result.CodigoEstadoSolicitud
+		updates["numero_cfdis"] = result.NumeroCFDIs
+	}
+
+	return s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(solicitud).Updates(updates).Error; err != nil {
+			return err
+		}
+
+		for _, idPaquete := range result.IDsPaquetes {
+			paquete := &model.PaqueteDescarga{
+				SolicitudUUID: solicitud.UUID,
+				IDPaquete:     idPaquete,
+				Estado:        model.EstadoPaquetePendiente,
+			}
+
+			err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(paquete).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
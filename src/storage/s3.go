@@ -0,0 +1,117 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// S3Store keeps the objects in a bucket of an S3-compatible server, such
+// as AWS S3 or MinIO. Requests use path-style URLs so any endpoint works
+// without DNS wildcard records.
+type S3Store struct {
+	Endpoint  string
+	Region    string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+	Client    *http.Client
+	Now       func() time.Time
+}
+
+func NewS3Store(endpoint, region, bucket, accessKey, secretKey string) (*S3Store, error) {
+	if endpoint == "" || bucket == "" {
+		return nil, errors.New("storage: s3 endpoint and bucket are required")
+	}
+
+	if region == "" {
+		region = "us-east-1"
+	}
+
+	return &S3Store{
+		Endpoint:  strings.TrimRight(endpoint, "/"),
+		Region:    region,
+		Bucket:    bucket,
+		AccessKey: accessKey,
+		SecretKey: secretKey,
+		Client:    &http.Client{Timeout: 5 * time.Minute},
+		Now:       time.Now,
+	}, nil
+}
+
+func (s *S3Store) Put(ctx context.Context, key string, data []byte) error {
+	res, err := s.do(ctx, http.MethodPut, key, data)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	return s.check(res, key)
+}
+
+func (s *S3Store) Get(ctx context.Context, key string) ([]byte, error) {
+	res, err := s.do(ctx, http.MethodGet, key, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if err := s.check(res, key); err != nil {
+		return nil, err
+	}
+
+	return io.ReadAll(res.Body)
+}
+
+func (s *S3Store) Delete(ctx context.Context, key string) error {
+	res, err := s.do(ctx, http.MethodDelete, key, nil)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode == http.StatusNotFound {
+		return nil
+	}
+
+	return s.check(res, key)
+}
+
+func (s *S3Store) do(ctx context.Context, method, key string, data []byte) (*http.Response, error) {
+	if err := validateKey(key); err != nil {
+		return nil, err
+	}
+
+	url := fmt.Sprintf("%s/%s/%s", s.Endpoint, s.Bucket, uriEncode(key, false))
+
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	if data != nil {
+		req.ContentLength = int64(len(data))
+		req.Header.Set("Content-Type", "application/octet-stream")
+	}
+
+	signRequest(req, sha256Hex(data), s.AccessKey, s.SecretKey, s.Region, s.Now())
+
+	return s.Client.Do(req)
+}
+
+func (s *S3Store) check(res *http.Response, key string) error {
+	switch {
+	case res.StatusCode == http.StatusNotFound:
+		return ErrNotFound
+	case res.StatusCode >= 300:
+		body, _ := io.ReadAll(io.LimitReader(res.Body, 4096))
+		return fmt.Errorf("storage: s3 %s %s returned HTTP %d: %s", res.Request.Method, key, res.StatusCode, body)
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,139 @@
+package storage
+
+import (
+	"crypto/hmac"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// S3StandIn is an in-memory server implementing the subset of the S3 API
+// used by S3Store: PUT, GET and DELETE of objects with path-style URLs and
+// Signature Version 4. It plays the role of a local MinIO in tests and
+// development; objects are lost when it stops.
+type S3StandIn struct {
+	mu        sync.RWMutex
+	accessKey string
+	secretKey string
+	region    string
+	buckets   map[string]map[string][]byte
+}
+
+func NewS3StandIn(accessKey, secretKey, region string, buckets ...string) *S3StandIn {
+	if region == "" {
+		region = "us-east-1"
+	}
+
+	standIn := &S3StandIn{
+		accessKey: accessKey,
+		secretKey: secretKey,
+		region:    region,
+		buckets:   make(map[string]map[string][]byte),
+	}
+
+	for _, bucket := range buckets {
+		standIn.buckets[bucket] = make(map[string][]byte)
+	}
+
+	return standIn
+}
+
+// Objects returns the keys stored in a bucket.
+func (s *S3StandIn) Objects(bucket string) []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	keys := make([]string, 0, len(s.buckets[bucket]))
+	for key := range s.buckets[bucket] {
+		keys = append(keys, key)
+	}
+
+	return keys
+}
+
+func (s *S3StandIn) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeS3Error(w, http.StatusBadRequest, "IncompleteBody")
+		return
+	}
+
+	if !s.authorized(r, body) {
+		writeS3Error(w, http.StatusForbidden, "SignatureDoesNotMatch")
+		return
+	}
+
+	bucket, key, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	objects, ok := s.buckets[bucket]
+	if !ok {
+		writeS3Error(w, http.StatusNotFound, "NoSuchBucket")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodPut:
+		objects[key] = body
+		w.WriteHeader(http.StatusOK)
+	case http.MethodGet:
+		data, ok := objects[key]
+		if !ok {
+			writeS3Error(w, http.StatusNotFound, "NoSuchKey")
+			return
+		}
+		w.Header().Set("Content-Type", "application/octet-stream")
+		_, _ = w.Write(data)
+	case http.MethodDelete:
+		delete(objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeS3Error(w, http.StatusMethodNotAllowed, "MethodNotAllowed")
+	}
+}
+
+// authorized recomputes the Signature Version 4 of the request and checks
+// that the payload matches the signed hash.
+func (s *S3StandIn) authorized(r *http.Request, body []byte) bool {
+	payloadHash := r.Header.Get(headerAmzContent)
+	if payloadHash != sha256Hex(body) {
+		return false
+	}
+
+	amzDate := r.Header.Get(headerAmzDate)
+	signedAt, err := time.Parse(sigV4DateFormat, amzDate)
+	if err != nil || time.Since(signedAt).Abs() > 15*time.Minute {
+		return false
+	}
+
+	scope := fmt.Sprintf("%s/%s/%s/aws4_request", amzDate[:8], s.region, sigV4Service)
+
+	clone := r.Clone(r.Context())
+	clone.Header = http.Header{}
+	for name, values := range r.Header {
+		if strings.HasPrefix(strings.ToLower(name), "x-amz-") {
+			clone.Header[name] = values
+		}
+	}
+
+	signedHeaders, canonical := canonicalRequest(clone, payloadHash)
+	signature := hmacSHA256(signingKey(s.secretKey, amzDate[:8], s.region), stringToSign(amzDate, scope, canonical))
+
+	expected := fmt.Sprintf(
+		"%s Credential=%s/%s, SignedHeaders=%s, Signature=%x",
+		sigV4Algorithm, s.accessKey, scope, signedHeaders, signature,
+	)
+
+	return hmac.Equal([]byte(r.Header.Get("Authorization")), []byte(expected))
+}
+
+func writeS3Error(w http.ResponseWriter, status int, code string) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(status)
+	_, _ = fmt.Fprintf(w, `<?xml version="1.0" encoding="UTF-8"?><Error><Code>%s</Code></Error>`, code)
+}
@@ -0,0 +1,8 @@
+package validation
+
+type QueryCatalogo struct {
+	Page  int    `validate:"number,gte=1"`
+	Limit int    `validate:"number,gte=1,lte=100"`
+	Q     string `validate:"omitempty,max=100"`
+	Fecha string `validate:"omitempty,datetime=2006-01-02"`
+}
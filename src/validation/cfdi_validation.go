@@ -0,0 +1,42 @@
+package validation
+
+type QueryCFDI struct {
+	Page         int    `validate:"number,gte=1"`
+	Limit        int    `validate:"number,gte=1,lte=100"`
+	FechaInicial string `validate:"omitempty,datetime=2006-01-02"`
+	FechaFinal   string `validate:"omitempty,datetime=2006-01-02"`
+	RFC          string `validate:"omitempty,rfc"`
+	Tipo         string `validate:"omitempty,oneof=I E T N P"`
+	TotalMin     string `validate:"omitempty,numeric"`
+	TotalMax     string `validate:"omitempty,numeric"`
+	Search       string `validate:"omitempty,max=100"`
+}
+
+type QueryConciliacion struct {
+	FechaInicial string `validate:"omitempty,datetime=2006-01-02"`
+	FechaFinal   string `validate:"omitempty,datetime=2006-01-02"`
+}
+
+type QueryAntiguedadSaldos struct {
+	Page       int    `validate:"number,gte=1"`
+	Limit      int    `validate:"number,gte=1,lte=100"`
+	FechaCorte string `validate:"required,datetime=2006-01-02"`
+	RFC        string `validate:"omitempty,rfc"`
+	Rol        string `validate:"omitempty,oneof=emisor receptor"`
+}
+
+type QueryNomina struct {
+	FechaInicial string `validate:"omitempty,datetime=2006-01-02"`
+	FechaFinal   string `validate:"omitempty,datetime=2006-01-02"`
+	RFC          string `validate:"omitempty,rfc"`
+	Empleado     string `validate:"omitempty,rfc"`
+	Format       string `validate:"omitempty,oneof=json csv"`
+}
+
+type QueryValidacionReceptor struct {
+	Page         int    `validate:"number,gte=1"`
+	Limit        int    `validate:"number,gte=1,lte=100"`
+	FechaInicial string `validate:"omitempty,datetime=2006-01-02"`
+	FechaFinal   string `validate:"omitempty,datetime=2006-01-02"`
+	RFC          string `validate:"omitempty,rfc"`
+}
@@ -1,6 +1,7 @@
 package validation
 
 import (
+	"app/src/cfdi"
 	"regexp"
 
 	"github.com/go-playground/validator/v10"
@@ -19,3 +20,17 @@ func Password(field validator.FieldLevel) bool {
 
 	return true
 }
+
+// MaxPrecio is the largest price a DECIMAL(10, 2) column can hold.
+const MaxPrecio = "99999999.99"
+
+// Precio accepts non negative amounts with at most two decimals. Decimal
+// fields reach it as their text, see Validator.
+func Precio(field validator.FieldLevel) bool {
+	value, err := cfdi.ParseDecimal(field.Field().String())
+	if err != nil || value.Sign() < 0 || value.Cmp(cfdi.MustParseDecimal(MaxPrecio)) > 0 {
+		return false
+	}
+
+	return value.Round(2).Equal(value)
+}
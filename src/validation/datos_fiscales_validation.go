@@ -1,6 +1,23 @@
 package validation
 
 type DatosFiscalesRequest struct {
-	RFC      string `json:"rfc" validate:"required,min=12,max=13" example:"XAXX010101000"`
+	RFC      string `json:"rfc" validate:"required,rfc" example:"EKU9003173C9"`
 	Password string `json:"password" validate:"required,min=8,max=50" example:"efirma_password"`
 }
+
+// UpdateDatosFiscalesRequest changes the password of the stored e.firma, the
+// RFC is only checked against the registered one. Both are optional.
+type UpdateDatosFiscalesRequest struct {
+	RFC      string `json:"rfc" validate:"omitempty,rfc" example:"EKU9003173C9"`
+	Password string `json:"password" validate:"omitempty,min=8,max=50" example:"efirma_password"`
+}
+
+type RenovarEfirmaRequest struct {
+	Password string `json:"password" validate:"required,min=8,max=50" example:"efirma_password"`
+}
+
+type QueryDatosFiscales struct {
+	Page   int    `validate:"omitempty,number,max=50"`
+	Limit  int    `validate:"omitempty,number,max=50"`
+	Search string `validate:"omitempty,max=13"`
+}
@@ -0,0 +1,14 @@
+package validation
+
+import "time"
+
+type SolicitarDescarga struct {
+	DatosFiscalesID   string    `json:"datos_fiscales_id" validate:"required,uuid" example:"e088d183-9eea-4a11-8d5d-74d7ec91bdf5"`
+	FechaInicial      time.Time `json:"fecha_inicial" validate:"required" example:"2024-01-01T00:00:00Z"`
+	FechaFinal        time.Time `json:"fecha_final" validate:"required,gtfield=FechaInicial" example:"2024-01-31T23:59:59Z"`
+	TipoDescarga      string    `json:"tipo_descarga" validate:"required,oneof=emitidos recibidos" example:"recibidos"`
+	TipoSolicitud     string    `json:"tipo_solicitud" validate:"required,oneof=CFDI Metadata" example:"CFDI"`
+	TipoComprobante   string    `json:"tipo_comprobante,omitempty" validate:"omitempty,oneof=I E T N P" example:"I"`
+	EstadoComprobante string    `json:"estado_comprobante,omitempty" validate:"omitempty,oneof=Vigente Cancelado" example:"Vigente"`
+	RFCContraparte    string    `json:"rfc_contraparte,omitempty" validate:"omitempty,rfc" example:"XIA190128J61"`
+}
@@ -0,0 +1,25 @@
+package validation
+
+import "app/src/cfdi"
+
+type CreatePlan struct {
+	Nombre                   string        `json:"nombre" validate:"required,max=100" example:"Básico"`
+	Descripcion              string        `json:"descripcion" validate:"required,max=255" example:"10 descargas masivas al mes"`
+	LimiteDescargasMensuales int           `json:"limite_descargas_mensuales" validate:"required,gte=1,lte=100000" example:"10"`
+	Precio                   *cfdi.Decimal `json:"precio" validate:"required,precio" example:"199.00"`
+	Activo                   bool          `json:"activo" example:"false"`
+}
+
+type UpdatePlan struct {
+	Nombre                   string        `json:"nombre,omitempty" validate:"omitempty,max=100" example:"Básico"`
+	Descripcion              string        `json:"descripcion,omitempty" validate:"omitempty,max=255" example:"10 descargas masivas al mes"`
+	LimiteDescargasMensuales *int          `json:"limite_descargas_mensuales,omitempty" validate:"omitempty,gte=1,lte=100000" example:"10"`
+	Precio                   *cfdi.Decimal `json:"precio,omitempty" validate:"omitempty,precio" example:"199.00"`
+}
+
+type QueryPlan struct {
+	Page   int    `validate:"omitempty,number,max=50"`
+	Limit  int    `validate:"omitempty,number,max=50"`
+	Search string `validate:"omitempty,max=100"`
+	Activo string `validate:"omitempty,oneof=true false"`
+}
@@ -0,0 +1,17 @@
+package validation
+
+import (
+	"app/src/rfc"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// RFC is the validator for the "rfc" tag.
+func RFC(field validator.FieldLevel) bool {
+	value, ok := field.Field().Interface().(string)
+	if !ok {
+		return false
+	}
+
+	return rfc.Check(value) == nil
+}
@@ -0,0 +1,10 @@
+package validation
+
+type Suscribir struct {
+	PlanID string `json:"plan_id" validate:"required,uuid" example:"e088d183-9eea-4a11-8d5d-74d7ec91bdf5"`
+	Meses  int    `json:"meses" validate:"omitempty,gte=1,lte=12" example:"1"`
+}
+
+type RenovarSuscripcion struct {
+	Meses int `json:"meses" validate:"omitempty,gte=1,lte=12" example:"1"`
+}
@@ -1,8 +1,10 @@
 package validation
 
 import (
+	"app/src/cfdi"
 	"errors"
 	"fmt"
+	"reflect"
 
 	"github.com/go-playground/validator/v10"
 )
@@ -18,6 +20,12 @@ var customMessages = map[string]string{
 	"alphanum": "Field %s must contain only alphanumeric characters",
 	"oneof":    "Invalid value for field %s",
 	"password": "Field %s must contain at least 1 letter and 1 number",
+	"rfc":      "Field %s must be a valid RFC (12 characters for personas morales, 13 for personas físicas)",
+	"gte":      "Field %s must be greater than or equal to %s",
+	"lte":      "Field %s must be less than or equal to %s",
+	"precio":   "Field %s must be a non negative amount with at most 2 decimals",
+	"datetime": "Field %s must be a date formatted as YYYY-MM-DD",
+	"numeric":  "Field %s must be a number",
 }
 
 func CustomErrorMessages(err error) map[string]string {
@@ -45,7 +53,7 @@ func generateErrorMessages(validationErrors validator.ValidationErrors) map[stri
 }
 
 func formatErrorMessage(customMessage string, err validator.FieldError, tag string) string {
-	if tag == "min" || tag == "max" || tag == "len" {
+	if tag == "min" || tag == "max" || tag == "len" || tag == "gte" || tag == "lte" {
 		return fmt.Sprintf(customMessage, err.Field(), err.Param())
 	}
 	return fmt.Sprintf(customMessage, err.Field())
@@ -58,9 +66,25 @@ func defaultErrorMessage(err validator.FieldError) string {
 func Validator() *validator.Validate {
 	validate := validator.New()
 
+	// Decimals are validated as their text, like the amounts they came from.
+	validate.RegisterCustomTypeFunc(func(field reflect.Value) interface{} {
+		if decimal, ok := field.Interface().(cfdi.Decimal); ok {
+			return decimal.String()
+		}
+		return nil
+	}, cfdi.Decimal{})
+
 	if err := validate.RegisterValidation("password", Password); err != nil {
 		return nil
 	}
 
+	if err := validate.RegisterValidation("rfc", RFC); err != nil {
+		return nil
+	}
+
+	if err := validate.RegisterValidation("precio", Precio); err != nil {
+		return nil
+	}
+
 	return validate
 }
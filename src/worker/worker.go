@@ -0,0 +1,100 @@
+package worker
+
+import (
+	"app/src/utils"
+	"context"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Job is one run of a background task. It receives a context that is
+// cancelled when the worker stops.
+type Job func(ctx context.Context) error
+
+// Worker runs a job periodically in its own goroutine, next to the Fiber
+// app, until it is stopped.
+type Worker struct {
+	Name     string
+	Interval time.Duration
+	Job      Job
+	Log      *logrus.Logger
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func New(name string, interval time.Duration, job Job) *Worker {
+	return &Worker{
+		Name:     name,
+		Interval: interval,
+		Job:      job,
+		Log:      utils.Log,
+	}
+}
+
+// Start runs the job right away and then every Interval. Calling Start on
+// a running worker does nothing.
+func (w *Worker) Start(ctx context.Context) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.cancel != nil {
+		return
+	}
+
+	ctx, w.cancel = context.WithCancel(ctx)
+	w.done = make(chan struct{})
+
+	go w.loop(ctx, w.done)
+
+	w.Log.Infof("Worker %s started, running every %s", w.Name, w.Interval)
+}
+
+// Stop cancels the running job and waits for it to return.
+func (w *Worker) Stop() {
+	w.mu.Lock()
+	cancel, done := w.cancel, w.done
+	w.cancel, w.done = nil, nil
+	w.mu.Unlock()
+
+	if cancel == nil {
+		return
+	}
+
+	cancel()
+	<-done
+
+	w.Log.Infof("Worker %s stopped", w.Name)
+}
+
+func (w *Worker) loop(ctx context.Context, done chan<- struct{}) {
+	defer close(done)
+
+	ticker := time.NewTicker(w.Interval)
+	defer ticker.Stop()
+
+	for {
+		w.run(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (w *Worker) run(ctx context.Context) {
+	defer func() {
+		if r := recover(); r != nil {
+			w.Log.Errorf("Worker %s panicked: %v", w.Name, r)
+		}
+	}()
+
+	if err := w.Job(ctx); err != nil && ctx.Err() == nil {
+		w.Log.Errorf("Worker %s failed: %v", w.Name, err)
+	}
+}
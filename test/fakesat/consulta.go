@@ -0,0 +1,112 @@
+package fakesat
+
+import (
+	"encoding/xml"
+	"fmt"
+	"math/big"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+const nsConsulta = "http://tempuri.org/"
+
+// Estado values of the ConsultaCFDIService.
+const (
+	EstadoVigente       = "Vigente"
+	EstadoCancelado     = "Cancelado"
+	EstadoNoEncontrado  = "No Encontrado"
+	CancelacionEnCurso  = "En proceso"
+	CancelacionAceptada = "Cancelado sin aceptación"
+)
+
+// CFDI is a stamped CFDI as known by the ConsultaCFDIService. Queries must
+// match the RFCs and the total, like the SAT does.
+type CFDI struct {
+	RFCEmisor          string
+	RFCReceptor        string
+	Total              string
+	Estado             string
+	EsCancelable       string
+	EstatusCancelacion string
+}
+
+// expresionImpresa splits ?re=..&rr=..&tt=..&id=.. keeping any & inside the
+// RFCs, which the SAT doesn't escape.
+var expresionImpresa = regexp.MustCompile(`^\?re=(.+)&rr=(.+)&tt=([0-9.]+)&id=([0-9A-Fa-f-]{36})$`)
+
+// SetCFDI registers the status the service reports for a folio fiscal.
+func (s *Server) SetCFDI(uuid string, cfdi CFDI) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if cfdi.Estado == "" {
+		cfdi.Estado = EstadoVigente
+	}
+	if cfdi.EsCancelable == "" {
+		cfdi.EsCancelable = "Cancelable sin aceptación"
+	}
+
+	s.cfdis[strings.ToUpper(uuid)] = &cfdi
+}
+
+type consultaEnvelope struct {
+	Body struct {
+		Consulta struct {
+			ExpresionImpresa string `xml:"expresionImpresa"`
+		} `xml:"Consulta"`
+	} `xml:"Body"`
+}
+
+func (s *Server) consulta(w http.ResponseWriter, body []byte) {
+	var env consultaEnvelope
+	if err := xml.Unmarshal(body, &env); err != nil {
+		writeFault(w, "s:Client", "Malformed request")
+		return
+	}
+
+	match := expresionImpresa.FindStringSubmatch(strings.TrimSpace(env.Body.Consulta.ExpresionImpresa))
+	if match == nil {
+		writeConsulta(w, "N - 601: La expresión impresa proporcionada no es válida.", "", EstadoNoEncontrado, "")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cfdi, ok := s.cfdis[strings.ToUpper(match[4])]
+	if !ok || !strings.EqualFold(cfdi.RFCEmisor, match[1]) || !strings.EqualFold(cfdi.RFCReceptor, match[2]) ||
+		!mismoTotal(cfdi.Total, match[3]) {
+		writeConsulta(w, "N - 602: Comprobante no encontrado.", "", EstadoNoEncontrado, "")
+		return
+	}
+
+	writeConsulta(w, "S - Comprobante obtenido satisfactoriamente.", cfdi.EsCancelable, cfdi.Estado, cfdi.EstatusCancelacion)
+}
+
+func mismoTotal(a, b string) bool {
+	x, ok := new(big.Rat).SetString(a)
+	if !ok {
+		return false
+	}
+
+	y, ok := new(big.Rat).SetString(b)
+	return ok && x.Cmp(y) == 0
+}
+
+func writeConsulta(w http.ResponseWriter, codigo, cancelable, estado, cancelacion string) {
+	var escaped [4]strings.Builder
+	for i, value := range []string{codigo, cancelable, estado, cancelacion} {
+		_ = xml.EscapeText(&escaped[i], []byte(value))
+	}
+
+	writeXML(w, fmt.Sprintf(
+		`<s:Envelope xmlns:s="%s"><s:Body><ConsultaResponse xmlns="%s">`+
+			`<ConsultaResult xmlns:a="http://schemas.datacontract.org/2004/07/Sat.Cfdi.Negocio.ConsultaCfdi.Servicio" `+
+			`xmlns:i="http://www.w3.org/2001/XMLSchema-instance">`+
+			`<a:CodigoEstatus>%s</a:CodigoEstatus><a:EsCancelable>%s</a:EsCancelable><a:Estado>%s</a:Estado>`+
+			`<a:EstatusCancelacion>%s</a:EstatusCancelacion><a:ValidacionEFOS>200</a:ValidacionEFOS>`+
+			`</ConsultaResult></ConsultaResponse></s:Body></s:Envelope>`,
+		nsSOAP, nsConsulta, escaped[0].String(), escaped[1].String(), escaped[2].String(), escaped[3].String(),
+	))
+}
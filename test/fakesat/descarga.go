@@ -0,0 +1,73 @@
+package fakesat
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+)
+
+// maxDescargas is how many times the SAT serves the same package.
+const maxDescargas = 2
+
+// SetPaquete sets the content served for a package ID.
+func (s *Server) SetPaquete(idPaquete string, content []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.paquetes[idPaquete] = content
+}
+
+// Descargas returns how many times a package was downloaded.
+func (s *Server) Descargas(idPaquete string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.descargas[idPaquete]
+}
+
+func (s *Server) descargar(w http.ResponseWriter, r *http.Request, body []byte) {
+	reply := func(code, paquete string) {
+		writeXML(w, fmt.Sprintf(
+			`<s:Envelope xmlns:s="%s"><s:Header><h:respuesta CodEstatus="%s" Mensaje="%s" xmlns:h="%s"/></s:Header>`+
+				`<s:Body><RespuestaDescargaMasivaTercerosSalida xmlns="%s"><Paquete>%s</Paquete>`+
+				`</RespuestaDescargaMasivaTercerosSalida></s:Body></s:Envelope>`,
+			nsSOAP, code, mensajes[code], nsDescargaSAT, nsDescargaSAT, paquete,
+		))
+	}
+
+	tokenRFC, ok := s.tokenRFC(r)
+	if !ok {
+		reply("300", "")
+		return
+	}
+
+	request, err := verifySignedRequest(body)
+	if err != nil {
+		reply("302", "")
+		return
+	}
+
+	if request.RFC != request.Attrs["RfcSolicitante"] || tokenRFC != request.RFC {
+		reply("303", "")
+		return
+	}
+
+	idPaquete := request.Attrs["IdPaquete"]
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	content, ok := s.paquetes[idPaquete]
+	if !ok {
+		reply("5004", "")
+		return
+	}
+
+	if s.descargas[idPaquete] >= maxDescargas {
+		reply("5008", "")
+		return
+	}
+	s.descargas[idPaquete]++
+
+	reply("5000", base64.StdEncoding.EncodeToString(content))
+}
@@ -0,0 +1,220 @@
+// Package fakesat is a local stand-in for the SAT Descarga Masiva web
+// services and the ConsultaCFDIService. It checks the signatures of the
+// requests the way the SAT does and keeps its state in memory so tests can
+// inspect it.
+package fakesat
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	nsSOAP           = "http://schemas.xmlsoap.org/soap/envelope/"
+	nsWSU            = "http://docs.oasis-open.org/wss/2004/01/oasis-200401-wss-wssecurity-utility-1.0.xsd"
+	nsWSSE           = "http://docs.oasis-open.org/wss/2004/01/oasis-200401-wss-wssecurity-secext-1.0.xsd"
+	nsDescargaMasiva = "http://DescargaMasivaTerceros.gob.mx"
+	nsDescargaSAT    = "http://DescargaMasivaTerceros.sat.gob.mx"
+
+	ActionAutentica         = "http://DescargaMasivaTerceros.gob.mx/IAutenticacion/Autentica"
+	ActionSolicitaEmitidos  = "http://DescargaMasivaTerceros.sat.gob.mx/ISolicitaDescargaService/SolicitaDescargaEmitidos"
+	ActionSolicitaRecibidos = "http://DescargaMasivaTerceros.sat.gob.mx/ISolicitaDescargaService/SolicitaDescargaRecibidos"
+	ActionVerifica          = "http://DescargaMasivaTerceros.sat.gob.mx/IVerificaSolicitudDescargaService/VerificaSolicitudDescarga"
+	ActionDescargar         = "http://DescargaMasivaTerceros.sat.gob.mx/IDescargaMasivaTercerosService/Descargar"
+	ActionConsulta          = "http://tempuri.org/IConsultaCFDIService/Consulta"
+
+	timestampFormat = "2006-01-02T15:04:05.000Z"
+)
+
+type Server struct {
+	mu            sync.Mutex
+	TokenLifetime time.Duration
+	Now           func() time.Time
+	// SolicitudCodEstatus forces the CodEstatus of the next download
+	// requests, e.g. "5002" or "5004", instead of accepting them.
+	SolicitudCodEstatus string
+	tokens              map[string]issuedToken
+	calls               map[string]int
+	solicitudes         map[string]*Solicitud
+	paquetes            map[string][]byte
+	descargas           map[string]int
+	cfdis               map[string]*CFDI
+}
+
+type issuedToken struct {
+	rfc     string
+	expires time.Time
+}
+
+func New() *Server {
+	return &Server{
+		TokenLifetime: 5 * time.Minute,
+		Now:           time.Now,
+		tokens:        make(map[string]issuedToken),
+		calls:         make(map[string]int),
+		solicitudes:   make(map[string]*Solicitud),
+		paquetes:      make(map[string][]byte),
+		descargas:     make(map[string]int),
+		cfdis:         make(map[string]*CFDI),
+	}
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	action := strings.Trim(r.Header.Get("SOAPAction"), `"`)
+
+	s.mu.Lock()
+	s.calls[action]++
+	s.mu.Unlock()
+
+	switch action {
+	case ActionAutentica:
+		s.autentica(w, body)
+	case ActionSolicitaEmitidos:
+		s.solicitaDescarga(w, r, body, "SolicitaDescargaEmitidos")
+	case ActionSolicitaRecibidos:
+		s.solicitaDescarga(w, r, body, "SolicitaDescargaRecibidos")
+	case ActionVerifica:
+		s.verificaSolicitud(w, r, body)
+	case ActionDescargar:
+		s.descargar(w, r, body)
+	case ActionConsulta:
+		s.consulta(w, body)
+	default:
+		writeFault(w, "a:ActionNotSupported", "The action "+action+" is not supported")
+	}
+}
+
+// Calls returns how many requests the server received for a SOAP action.
+func (s *Server) Calls(action string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.calls[action]
+}
+
+// RFCForToken returns the RFC a token was issued to, if it is still valid.
+func (s *Server) RFCForToken(token string) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	issued, ok := s.tokens[token]
+	if !ok || s.Now().After(issued.expires) {
+		return "", false
+	}
+
+	return issued.rfc, true
+}
+
+type authEnvelope struct {
+	Header struct {
+		Security struct {
+			Timestamp struct {
+				Created string `xml:"Created"`
+				Expires string `xml:"Expires"`
+			} `xml:"Timestamp"`
+			BinarySecurityToken string    `xml:"BinarySecurityToken"`
+			Signature           signature `xml:"Signature"`
+		} `xml:"Security"`
+	} `xml:"Header"`
+}
+
+// tokenRFC returns the RFC of the token sent in the WRAP Authorization
+// header of the request.
+func (s *Server) tokenRFC(r *http.Request) (string, bool) {
+	header := r.Header.Get("Authorization")
+	token := strings.TrimSuffix(strings.TrimPrefix(header, `WRAP access_token="`), `"`)
+	if token == header {
+		return "", false
+	}
+
+	return s.RFCForToken(token)
+}
+
+func (s *Server) autentica(w http.ResponseWriter, body []byte) {
+	var env authEnvelope
+	if err := xml.Unmarshal(body, &env); err != nil {
+		writeFault(w, "s:Client", "Malformed request")
+		return
+	}
+
+	security := env.Header.Security
+	timestamp := fmt.Sprintf(
+		`<u:Timestamp xmlns:u="%s" u:Id="_0"><u:Created>%s</u:Created><u:Expires>%s</u:Expires></u:Timestamp>`,
+		nsWSU, security.Timestamp.Created, security.Timestamp.Expires,
+	)
+
+	rfc, err := verifySignature(security.Signature, security.BinarySecurityToken, "#_0", []byte(timestamp))
+	if err != nil {
+		writeFault(w, "a:InvalidSecurity", "An error occurred when verifying security for the message.")
+		return
+	}
+
+	now := s.Now().UTC()
+	expires := now.Add(s.TokenLifetime)
+	token := randomToken()
+
+	s.mu.Lock()
+	s.tokens[token] = issuedToken{rfc: rfc, expires: expires}
+	s.mu.Unlock()
+
+	writeXML(w, fmt.Sprintf(
+		`<s:Envelope xmlns:s="%s" xmlns:u="%s"><s:Header><o:Security s:mustUnderstand="1" xmlns:o="%s">`+
+			`<u:Timestamp u:Id="_0"><u:Created>%s</u:Created><u:Expires>%s</u:Expires></u:Timestamp>`+
+			`</o:Security></s:Header><s:Body><AutenticaResponse xmlns="%s"><AutenticaResult>%s</AutenticaResult>`+
+			`</AutenticaResponse></s:Body></s:Envelope>`,
+		nsSOAP, nsWSU, nsWSSE, now.Format(timestampFormat), expires.Format(timestampFormat), nsDescargaMasiva, token,
+	))
+}
+
+func randomToken() string {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		panic(err)
+	}
+
+	return hex.EncodeToString(raw)
+}
+
+func writeXML(w http.ResponseWriter, body string) {
+	w.Header().Set("Content-Type", "text/xml; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	_, _ = io.WriteString(w, body)
+}
+
+func writeResult(w http.ResponseWriter, operation, attrs string) {
+	writeResultWithContent(w, operation, attrs, "")
+}
+
+func writeResultWithContent(w http.ResponseWriter, operation, attrs, content string) {
+	writeXML(w, fmt.Sprintf(
+		`<s:Envelope xmlns:s="%s"><s:Body><%sResponse xmlns="%s"><%sResult%s>%s</%sResult></%sResponse></s:Body></s:Envelope>`,
+		nsSOAP, operation, nsDescargaSAT, operation, attrs, content, operation, operation,
+	))
+}
+
+func writeFault(w http.ResponseWriter, code, message string) {
+	w.Header().Set("Content-Type", "text/xml; charset=utf-8")
+	w.WriteHeader(http.StatusInternalServerError)
+	_, _ = fmt.Fprintf(w,
+		`<s:Envelope xmlns:s="%s"><s:Body><s:Fault><faultcode>%s</faultcode><faultstring>%s</faultstring></s:Fault></s:Body></s:Envelope>`,
+		nsSOAP, code, message,
+	)
+}
@@ -0,0 +1,168 @@
+package fakesat
+
+import (
+	"app/src/fiel"
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const (
+	nsDSig       = "http://www.w3.org/2000/09/xmldsig#"
+	algExcC14N   = "http://www.w3.org/2001/10/xml-exc-c14n#"
+	algEnveloped = "http://www.w3.org/2000/09/xmldsig#enveloped-signature"
+	algRSASHA1   = "http://www.w3.org/2000/09/xmldsig#rsa-sha1"
+	algSHA1      = "http://www.w3.org/2000/09/xmldsig#sha1"
+)
+
+var errInvalidSignature = errors.New("invalid signature")
+
+type signature struct {
+	SignedInfo struct {
+		Reference struct {
+			URI         string `xml:"URI,attr"`
+			DigestValue string `xml:"DigestValue"`
+		} `xml:"Reference"`
+	} `xml:"SignedInfo"`
+	SignatureValue string `xml:"SignatureValue"`
+	Certificate    string `xml:"KeyInfo>X509Data>X509Certificate"`
+}
+
+// verifySignature checks an RSA-SHA1 XML signature over the canonical form
+// of the referenced element and returns the RFC of the signing certificate.
+// The empty URI references the whole request with the enveloped transform.
+func verifySignature(sig signature, certificateB64, uri string, canonical []byte) (string, error) {
+	if sig.SignedInfo.Reference.URI != uri {
+		return "", errInvalidSignature
+	}
+
+	digest := sha1.Sum(canonical)
+	if base64.StdEncoding.EncodeToString(digest[:]) != strings.TrimSpace(sig.SignedInfo.Reference.DigestValue) {
+		return "", errInvalidSignature
+	}
+
+	der, err := base64.StdEncoding.DecodeString(strings.TrimSpace(certificateB64))
+	if err != nil {
+		return "", errInvalidSignature
+	}
+
+	certificate, err := fiel.ParseCertificate(der)
+	if err != nil {
+		return "", err
+	}
+
+	publicKey, ok := certificate.X509.PublicKey.(*rsa.PublicKey)
+	if !ok {
+		return "", errInvalidSignature
+	}
+
+	signatureValue, err := base64.StdEncoding.DecodeString(strings.TrimSpace(sig.SignatureValue))
+	if err != nil {
+		return "", errInvalidSignature
+	}
+
+	transform := algExcC14N
+	if uri == "" {
+		transform = algEnveloped
+	}
+
+	signedInfo := fmt.Sprintf(
+		`<SignedInfo xmlns="%s">`+
+			`<CanonicalizationMethod Algorithm="%s"></CanonicalizationMethod>`+
+			`<SignatureMethod Algorithm="%s"></SignatureMethod>`+
+			`<Reference URI="%s"><Transforms><Transform Algorithm="%s"></Transform></Transforms>`+
+			`<DigestMethod Algorithm="%s"></DigestMethod><DigestValue>%s</DigestValue></Reference>`+
+			`</SignedInfo>`,
+		nsDSig, algExcC14N, algRSASHA1, uri, transform, algSHA1, sig.SignedInfo.Reference.DigestValue,
+	)
+
+	hashed := sha1.Sum([]byte(signedInfo))
+	if err := rsa.VerifyPKCS1v15(publicKey, crypto.SHA1, hashed[:], signatureValue); err != nil {
+		return "", errInvalidSignature
+	}
+
+	return certificate.RFC, nil
+}
+
+type signedBody struct {
+	Body struct {
+		Operation struct {
+			XMLName xml.Name
+			Element struct {
+				XMLName       xml.Name
+				Attrs         []xml.Attr `xml:",any,attr"`
+				RfcReceptores []string   `xml:"RfcReceptores>RfcReceptor"`
+				Signature     signature  `xml:"Signature"`
+			} `xml:",any"`
+		} `xml:",any"`
+	} `xml:"Body"`
+}
+
+// signedRequest is a verified Descarga Masiva request.
+type signedRequest struct {
+	Operation     string
+	RFC           string
+	Attrs         map[string]string
+	RfcReceptores []string
+}
+
+// verifySignedRequest checks the enveloped signature of a solicitud,
+// verificación or descarga request, rebuilding the canonical form of the
+// operation element without the signature.
+func verifySignedRequest(body []byte) (*signedRequest, error) {
+	var parsed signedBody
+	if err := xml.Unmarshal(body, &parsed); err != nil {
+		return nil, err
+	}
+
+	operation := parsed.Body.Operation
+	element := operation.Element
+
+	attrs := make(map[string]string, len(element.Attrs))
+	names := make([]string, 0, len(element.Attrs))
+	for _, attr := range element.Attrs {
+		if attr.Name.Space == "xmlns" || attr.Name.Local == "xmlns" {
+			continue
+		}
+		attrs[attr.Name.Local] = attr.Value
+		names = append(names, attr.Name.Local)
+	}
+	sort.Strings(names)
+
+	var canonical strings.Builder
+	fmt.Fprintf(&canonical, `<des:%s xmlns:des="%s"><des:%s`, operation.XMLName.Local, nsDescargaSAT, element.XMLName.Local)
+	for _, name := range names {
+		fmt.Fprintf(&canonical, ` %s="%s"`, name, escapeAttr(attrs[name]))
+	}
+	canonical.WriteString(">")
+	if len(element.RfcReceptores) > 0 {
+		canonical.WriteString("<des:RfcReceptores>")
+		for _, rfc := range element.RfcReceptores {
+			fmt.Fprintf(&canonical, "<des:RfcReceptor>%s</des:RfcReceptor>", rfc)
+		}
+		canonical.WriteString("</des:RfcReceptores>")
+	}
+	fmt.Fprintf(&canonical, `</des:%s></des:%s>`, element.XMLName.Local, operation.XMLName.Local)
+
+	rfc, err := verifySignature(element.Signature, element.Signature.Certificate, "", []byte(canonical.String()))
+	if err != nil {
+		return nil, err
+	}
+
+	return &signedRequest{
+		Operation:     operation.XMLName.Local,
+		RFC:           rfc,
+		Attrs:         attrs,
+		RfcReceptores: element.RfcReceptores,
+	}, nil
+}
+
+func escapeAttr(value string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", `"`, "&quot;", "\t", "&#x9;", "\n", "&#xA;", "\r", "&#xD;").Replace(value)
+}
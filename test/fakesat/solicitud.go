@@ -0,0 +1,199 @@
+package fakesat
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// EstadoSolicitud values of the verification service.
+const (
+	EstadoAceptada  = 1
+	EstadoEnProceso = 2
+	EstadoTerminada = 3
+	EstadoError     = 4
+	EstadoRechazada = 5
+	EstadoVencida   = 6
+)
+
+var mensajes = map[string]string{
+	"300":  "Usuario No Válido",
+	"301":  "XML Mal Formado",
+	"302":  "Sello Mal Formado",
+	"303":  "Sello no corresponde con RfcSolicitante",
+	"304":  "Certificado Revocado o Caduco",
+	"305":  "Certificado Inválido",
+	"5000": "Solicitud Aceptada",
+	"5001": "Tercero no autorizado",
+	"5002": "Se han agotado las solicitudes de por vida",
+	"5003": "Tope máximo",
+	"5004": "No se encontró la información",
+	"5005": "Solicitud duplicada",
+	"5008": "Máximo de descargas permitidas",
+}
+
+// Solicitud is a download request accepted by the fake server.
+type Solicitud struct {
+	ID          string
+	RFC         string
+	Operation   string
+	Attrs       map[string]string
+	CreatedAt   time.Time
+	Estado      int
+	NumeroCFDIs int
+	Paquetes    []string
+	key         string
+}
+
+// Solicitudes returns the accepted download requests.
+func (s *Server) Solicitudes() []*Solicitud {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	solicitudes := make([]*Solicitud, 0, len(s.solicitudes))
+	for _, solicitud := range s.solicitudes {
+		solicitudes = append(solicitudes, solicitud)
+	}
+
+	return solicitudes
+}
+
+func (s *Server) solicitaDescarga(w http.ResponseWriter, r *http.Request, body []byte, operation string) {
+	reply := func(id, rfc, code string) {
+		writeResult(w, operation, fmt.Sprintf(
+			` IdSolicitud="%s" RfcSolicitante="%s" CodEstatus="%s" Mensaje="%s"`, id, rfc, code, mensajes[code],
+		))
+	}
+
+	tokenRFC, ok := s.tokenRFC(r)
+	if !ok {
+		reply("", "", "300")
+		return
+	}
+
+	request, err := verifySignedRequest(body)
+	if err != nil {
+		reply("", "", "302")
+		return
+	}
+
+	rfc := request.Attrs["RfcSolicitante"]
+	if request.RFC != rfc || tokenRFC != rfc {
+		reply("", rfc, "303")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if code := s.SolicitudCodEstatus; code != "" {
+		reply("", rfc, code)
+		return
+	}
+
+	key := solicitudKey(request)
+	for _, solicitud := range s.solicitudes {
+		if solicitud.key == key {
+			reply("", rfc, "5005")
+			return
+		}
+	}
+
+	solicitud := &Solicitud{
+		ID:        uuid.NewString(),
+		RFC:       rfc,
+		Operation: request.Operation,
+		Attrs:     request.Attrs,
+		CreatedAt: s.Now(),
+		Estado:    EstadoAceptada,
+		key:       key,
+	}
+	s.solicitudes[solicitud.ID] = solicitud
+
+	reply(solicitud.ID, rfc, "5000")
+}
+
+// SetEstado changes the EstadoSolicitud reported for a download request.
+func (s *Server) SetEstado(id string, estado int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if solicitud, ok := s.solicitudes[id]; ok {
+		solicitud.Estado = estado
+	}
+}
+
+// Complete marks a download request as terminada with its packages.
+func (s *Server) Complete(id string, numeroCFDIs int, paquetes ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if solicitud, ok := s.solicitudes[id]; ok {
+		solicitud.Estado = EstadoTerminada
+		solicitud.NumeroCFDIs = numeroCFDIs
+		solicitud.Paquetes = paquetes
+	}
+}
+
+func (s *Server) verificaSolicitud(w http.ResponseWriter, r *http.Request, body []byte) {
+	reply := func(code string, estado, numeroCFDIs int, paquetes []string) {
+		var ids strings.Builder
+		for _, paquete := range paquetes {
+			fmt.Fprintf(&ids, "<IdsPaquetes>%s</IdsPaquetes>", paquete)
+		}
+
+		codigoEstado := code
+		if estado == EstadoRechazada {
+			codigoEstado = "5004"
+		}
+
+		writeResultWithContent(w, "VerificaSolicitudDescarga", fmt.Sprintf(
+			` CodEstatus="%s" EstadoSolicitud="%d" CodigoEstadoSolicitud="%s" NumeroCFDIs="%d" Mensaje="%s"`,
+			code, estado, codigoEstado, numeroCFDIs, mensajes[code],
+		), ids.String())
+	}
+
+	tokenRFC, ok := s.tokenRFC(r)
+	if !ok {
+		reply("300", 0, 0, nil)
+		return
+	}
+
+	request, err := verifySignedRequest(body)
+	if err != nil {
+		reply("302", 0, 0, nil)
+		return
+	}
+
+	if request.RFC != request.Attrs["RfcSolicitante"] || tokenRFC != request.RFC {
+		reply("303", 0, 0, nil)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	solicitud, ok := s.solicitudes[request.Attrs["IdSolicitud"]]
+	if !ok || solicitud.RFC != request.RFC {
+		reply("5004", 0, 0, nil)
+		return
+	}
+
+	reply("5000", solicitud.Estado, solicitud.NumeroCFDIs, solicitud.Paquetes)
+}
+
+// solicitudKey identifies requests with the same parameters, which the SAT
+// rejects as duplicated.
+func solicitudKey(request *signedRequest) string {
+	parts := []string{request.Operation, strings.Join(request.RfcReceptores, ",")}
+	for name, value := range request.Attrs {
+		parts = append(parts, name+"="+value)
+	}
+	sort.Strings(parts[2:])
+
+	return strings.Join(parts, "|")
+}
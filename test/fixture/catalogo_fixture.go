@@ -0,0 +1,29 @@
+package fixture
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+// Catalog fixtures under test/fixture/catalogo, small excerpts of the
+// catCFDI.xls sheets exported to CSV that cover the claves of the CFDI
+// fixtures. c_FormaPago is exported the way Excel does in es-MX, in
+// Windows-1252 and delimited by semicolons.
+const (
+	CatalogoClaveProdServ = "c_ClaveProdServ.csv"
+	CatalogoCodigoPostal  = "c_CodigoPostal.csv"
+	CatalogoFormaPago     = "c_FormaPago.csv"
+	CatalogoRegimenFiscal = "c_RegimenFiscal.csv"
+	CatalogoUsoCFDI       = "c_UsoCFDI.csv"
+)
+
+var Catalogos = []string{
+	CatalogoClaveProdServ, CatalogoCodigoPostal, CatalogoFormaPago, CatalogoRegimenFiscal, CatalogoUsoCFDI,
+}
+
+// Catalogo reads a fixture from test/fixture/catalogo.
+func Catalogo(name string) ([]byte, error) {
+	_, file, _, _ := runtime.Caller(0)
+	return os.ReadFile(filepath.Join(filepath.Dir(file), "catalogo", name))
+}
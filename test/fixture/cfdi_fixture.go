@@ -0,0 +1,37 @@
+package fixture
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+// CFDI fixtures under test/fixture/cfdi, next to the UUID each one is
+// stamped with. ingreso_33 is a PPD invoice paid in parcialidades by pago_33 and
+// pago_40, egreso_40 is a credit note of ingreso_40 and carta_porte_40 moves
+// goods by autotransporte with a Carta Porte 3.1 that validates.
+//
+// metadata.txt lists ingreso_40, egreso_40 as cancelled, traslado_40 and
+// 8D1F2E3A-4B5C-4D6E-9F70-81A2B3C4D5E6, which has no XML fixture.
+const (
+	CFDIIngreso40       = "ingreso_40.xml"        // 5B4B4D8E-2C6F-4A0B-9F4E-0E5C1A2B3C4D
+	CFDIIngreso33       = "ingreso_33.xml"        // 3F2A1B0C-9D8E-4F7A-8B6C-5D4E3F2A1B0C
+	CFDIIngreso40Latin1 = "ingreso_40_latin1.xml" // 6C5C5E9F-3D7A-4B1C-8A5F-1F6D2B3C4D5E
+	CFDIEgreso40        = "egreso_40.xml"         // 7A8B9C0D-1E2F-4A3B-9C4D-5E6F7A8B9C0D
+	CFDITraslado40      = "traslado_40.xml"       // 1C2D3E4F-5A6B-4C7D-8E9F-0A1B2C3D4E5F
+	CFDINomina40        = "nomina_40.xml"         // 9E8D7C6B-5A4F-4E3D-8C2B-1A0F9E8D7C6B
+	CFDIPago33          = "pago_33.xml"           // 2B3C4D5E-6F7A-4B8C-9D0E-1F2A3B4C5D6E
+	CFDIPago40          = "pago_40.xml"           // 4D5E6F7A-8B9C-4D0E-8F1A-2B3C4D5E6F7A
+	CFDICartaPorte40    = "carta_porte_40.xml"    // 2E3F4A5B-6C7D-4E8F-9A0B-1C2D3E4F5A6B
+	CFDIMetadata        = "metadata.txt"
+)
+
+// CFDI reads a fixture from test/fixture/cfdi.
+func CFDI(name string) ([]byte, error) {
+	return os.ReadFile(filepath.Join(cfdiDir(), name))
+}
+
+func cfdiDir() string {
+	_, file, _, _ := runtime.Caller(0)
+	return filepath.Join(filepath.Dir(file), "cfdi")
+}
@@ -0,0 +1,282 @@
+package fixture
+
+import (
+	"app/src/fiel"
+	"crypto/cipher"
+	"crypto/des"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+const SATIssuer = "AC DEL SERVICIO DE ADMINISTRACION TRIBUTARIA"
+
+type Efirma struct {
+	Certificate []byte
+	PrivateKey  *rsa.PrivateKey
+}
+
+// Autoridad is a certification authority that issues e.firma fixtures.
+type Autoridad struct {
+	Certificate *x509.Certificate
+	PrivateKey  *rsa.PrivateKey
+}
+
+var (
+	satAutoridad     *Autoridad
+	satAutoridadErr  error
+	satAutoridadOnce sync.Once
+)
+
+// NewAutoridad generates a self-signed certification authority. Only the
+// one of SATAutoridad is trusted by the tests, any other authority forges
+// the certificates it issues, whatever its name.
+func NewAutoridad(name string) (*Autoridad, error) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, err
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: name, Organization: []string{name}},
+		NotBefore:             time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC),
+		NotAfter:              time.Date(2100, 1, 1, 0, 0, 0, 0, time.UTC),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return nil, err
+	}
+
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Autoridad{Certificate: cert, PrivateKey: key}, nil
+}
+
+// SATAutoridad is the test stand-in for the SAT certification authority,
+// generated once so every e.firma fixture chains up to the same one.
+func SATAutoridad() (*Autoridad, error) {
+	satAutoridadOnce.Do(func() {
+		satAutoridad, satAutoridadErr = NewAutoridad(SATIssuer)
+	})
+
+	return satAutoridad, satAutoridadErr
+}
+
+// SATAutoridades returns a pool that trusts SATAutoridad.
+func SATAutoridades() (*x509.CertPool, error) {
+	autoridad, err := SATAutoridad()
+	if err != nil {
+		return nil, err
+	}
+
+	pool := x509.NewCertPool()
+	pool.AddCert(autoridad.Certificate)
+
+	return pool, nil
+}
+
+// SATAutoridadesDir writes SATAutoridad to a temporary directory, laid out
+// like SAT_AUTORIDADES_DIR.
+func SATAutoridadesDir() (string, error) {
+	autoridad, err := SATAutoridad()
+	if err != nil {
+		return "", err
+	}
+
+	dir, err := os.MkdirTemp("", "sat-autoridades")
+	if err != nil {
+		return "", err
+	}
+
+	return dir, os.WriteFile(filepath.Join(dir, "ac_sat.cer"), autoridad.Certificate.Raw, 0o600)
+}
+
+// NewEfirma generates a certificate shaped like the ones issued by the SAT:
+// the RFC goes in the x500UniqueIdentifier attribute and the serial number
+// bytes are the ASCII digits of the "número de certificado". Certificates
+// of SATIssuer are issued by SATAutoridad, any other issuer gets a fresh,
+// untrusted authority.
+func NewEfirma(rfc, noCertificado, issuer string, notBefore, notAfter time.Time) (*Efirma, error) {
+	var autoridad *Autoridad
+	var err error
+	if issuer == SATIssuer {
+		autoridad, err = SATAutoridad()
+	} else {
+		autoridad, err = NewAutoridad(issuer)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return NewEfirmaDe(autoridad, rfc, noCertificado, notBefore, notAfter)
+}
+
+// NewEfirmaDe generates an e.firma certificate issued by autoridad.
+func NewEfirmaDe(autoridad *Autoridad, rfc, noCertificado string, notBefore, notAfter time.Time) (*Efirma, error) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, err
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, efirmaTemplate(rfc, noCertificado, notBefore, notAfter),
+		autoridad.Certificate, &key.PublicKey, autoridad.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Efirma{Certificate: der, PrivateKey: key}, nil
+}
+
+// ForgedEfirma generates a certificate that names the SAT as its issuer but
+// is signed with its own key, as anyone could make one.
+func ForgedEfirma(rfc string, notBefore, notAfter time.Time) (*Efirma, error) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, err
+	}
+
+	issuer := &x509.Certificate{
+		Subject: pkix.Name{CommonName: SATIssuer, Organization: []string{SATIssuer}},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, efirmaTemplate(rfc, "30001000000500003416", notBefore, notAfter),
+		issuer, &key.PublicKey, key)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Efirma{Certificate: der, PrivateKey: key}, nil
+}
+
+func efirmaTemplate(rfc, noCertificado string, notBefore, notAfter time.Time) *x509.Certificate {
+	return &x509.Certificate{
+		SerialNumber: new(big.Int).SetBytes([]byte(noCertificado)),
+		Subject: pkix.Name{
+			CommonName: "CONTRIBUYENTE DE PRUEBA",
+			ExtraNames: []pkix.AttributeTypeAndValue{
+				{Type: asn1.ObjectIdentifier{2, 5, 4, 45}, Value: rfc + " / " + rfc},
+			},
+		},
+		NotBefore: notBefore,
+		NotAfter:  notAfter,
+		KeyUsage:  x509.KeyUsageDigitalSignature | x509.KeyUsageContentCommitment,
+	}
+}
+
+// ValidEfirma generates a currently valid SAT certificate for the RFC.
+func ValidEfirma(rfc string) (*Efirma, error) {
+	now := time.Now()
+	return NewEfirma(rfc, "30001000000500003416", SATIssuer, now.AddDate(0, -1, 0), now.AddDate(4, 0, 0))
+}
+
+// ValidSigner returns a signer loaded from a freshly generated e.firma.
+func ValidSigner(rfc string) (*fiel.Signer, error) {
+	efirma, err := ValidEfirma(rfc)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := EncryptPrivateKey(efirma.PrivateKey, "12345678a")
+	if err != nil {
+		return nil, err
+	}
+
+	return fiel.NewSigner(efirma.Certificate, key, []byte("12345678a"))
+}
+
+// EncryptPrivateKey encrypts the key the same way the SAT does: PKCS#8
+// EncryptedPrivateKeyInfo with PBES2, PBKDF2-SHA1 and DES-EDE3-CBC.
+func EncryptPrivateKey(key *rsa.PrivateKey, password string) ([]byte, error) {
+	return EncryptPrivateKeyDeclaring(key, password, 2048)
+}
+
+// EncryptPrivateKeyDeclaring encrypts the key like EncryptPrivateKey but
+// declares the given PBKDF2 iteration count, which is not used to derive
+// the key, to check the limits on it.
+func EncryptPrivateKeyDeclaring(key *rsa.PrivateKey, password string, iterations int) ([]byte, error) {
+	plaintext, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	salt := make([]byte, 8)
+	iv := make([]byte, des.BlockSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	if _, err := rand.Read(iv); err != nil {
+		return nil, err
+	}
+
+	block, err := des.NewTripleDESCipher(pbkdf2.Key([]byte(password), salt, 2048, 24, sha1.New))
+	if err != nil {
+		return nil, err
+	}
+
+	padding := des.BlockSize - len(plaintext)%des.BlockSize
+	for range padding {
+		plaintext = append(plaintext, byte(padding))
+	}
+
+	encrypted := make([]byte, len(plaintext))
+	cipher.NewCBCEncrypter(block, iv).CryptBlocks(encrypted, plaintext)
+
+	kdfParams, err := asn1.Marshal(struct {
+		Salt           []byte
+		IterationCount int
+	}{salt, iterations})
+	if err != nil {
+		return nil, err
+	}
+
+	ivParam, err := asn1.Marshal(iv)
+	if err != nil {
+		return nil, err
+	}
+
+	pbes2Params, err := asn1.Marshal(struct {
+		KeyDerivationFunc pkix.AlgorithmIdentifier
+		EncryptionScheme  pkix.AlgorithmIdentifier
+	}{
+		pkix.AlgorithmIdentifier{
+			Algorithm:  asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 5, 12},
+			Parameters: asn1.RawValue{FullBytes: kdfParams},
+		},
+		pkix.AlgorithmIdentifier{
+			Algorithm:  asn1.ObjectIdentifier{1, 2, 840, 113549, 3, 7},
+			Parameters: asn1.RawValue{FullBytes: ivParam},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return asn1.Marshal(struct {
+		Algorithm     pkix.AlgorithmIdentifier
+		EncryptedData []byte
+	}{
+		pkix.AlgorithmIdentifier{
+			Algorithm:  asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 5, 13},
+			Parameters: asn1.RawValue{FullBytes: pbes2Params},
+		},
+		encrypted,
+	})
+}
@@ -0,0 +1,37 @@
+package fixture
+
+import (
+	"archive/zip"
+	"bytes"
+	"sort"
+)
+
+// Paquete builds a ZIP archive like the packages served by the SAT, with
+// one entry per file name.
+func Paquete(files map[string][]byte) ([]byte, error) {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	buffer := new(bytes.Buffer)
+	writer := zip.NewWriter(buffer)
+
+	for _, name := range names {
+		entry, err := writer.Create(name)
+		if err != nil {
+			return nil, err
+		}
+
+		if _, err := entry.Write(files[name]); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
@@ -0,0 +1,17 @@
+package fixture
+
+import (
+	"app/src/cfdi"
+	"app/src/model"
+
+	"github.com/google/uuid"
+)
+
+var PlanBasico = &model.PlanSuscripcion{
+	ID:                       uuid.New(),
+	Nombre:                   "Básico",
+	Descripcion:              "Plan con 10 descargas mensuales",
+	LimiteDescargasMensuales: 10,
+	Precio:                   cfdi.NewDecimal(19900, 2),
+	Activo:                   true,
+}
@@ -4,7 +4,9 @@ import (
 	"app/src/config"
 	"app/src/model"
 	"app/src/utils"
+	"bytes"
 	"errors"
+	"mime/multipart"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -16,6 +18,22 @@ import (
 func ClearAll(db *gorm.DB) {
 	ClearToken(db)
 	ClearUsers(db)
+	ClearPlanes(db)
+	ClearCFDIs(db)
+}
+
+func ClearCFDIs(db *gorm.DB) {
+	err := db.Where("uuid is not null").Delete(&model.CFDI{}).Error
+	if err != nil {
+		logrus.Fatalf("Failed clear CFDIs : %+v", err)
+	}
+}
+
+func ClearPlanes(db *gorm.DB) {
+	err := db.Unscoped().Where("id is not null").Delete(&model.PlanSuscripcion{}).Error
+	if err != nil {
+		logrus.Fatalf("Failed clear subscription plans : %+v", err)
+	}
 }
 
 func ClearUsers(db *gorm.DB) {
@@ -165,3 +183,132 @@ func GetUserByID(db *gorm.DB, id string) (*model.User, error) {
 
 	return user, result.Error
 }
+
+func GetDatosFiscalesByUserID(db *gorm.DB, userID string) ([]model.DatosFiscalesSAT, error) {
+	var datosFiscales []model.DatosFiscalesSAT
+
+	result := db.Where("user_id = ?", userID).Find(&datosFiscales)
+	if result.Error != nil {
+		logrus.Errorf("Failed get fiscal data by user id: %+v", result.Error)
+	}
+
+	return datosFiscales, result.Error
+}
+
+func InsertPlan(db *gorm.DB, planes ...*model.PlanSuscripcion) {
+	for _, plan := range planes {
+		if errDB := db.Create(plan).Error; errDB != nil {
+			logrus.Errorf("Failed to create subscription plan: %+v", errDB)
+		}
+	}
+}
+
+// InsertSuscripcion subscribes the user to the plan for a year starting
+// today, with descargasUsadas already used in the current period.
+func InsertSuscripcion(
+	db *gorm.DB, userID uuid.UUID, plan *model.PlanSuscripcion, descargasUsadas int,
+) (*model.SuscripcionUsuario, error) {
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+
+	suscripcion := &model.SuscripcionUsuario{
+		UsuarioID:                userID,
+		PlanID:                   plan.ID,
+		LimiteDescargasMensuales: plan.LimiteDescargasMensuales,
+		DescargasUsadas:          descargasUsadas,
+		PeriodoInicio:            today,
+		FechaInicio:              today,
+		FechaFin:                 today.AddDate(1, 0, 0),
+		Estatus:                  model.EstatusSuscripcionActivo,
+	}
+
+	result := db.Create(suscripcion)
+
+	return suscripcion, result.Error
+}
+
+func GetSuscripcionByID(db *gorm.DB, id uuid.UUID) (*model.SuscripcionUsuario, error) {
+	suscripcion := new(model.SuscripcionUsuario)
+
+	result := db.First(suscripcion, "id = ?", id)
+
+	return suscripcion, result.Error
+}
+
+// InsertPaquete stores a finished download request of the given type for the
+// RFC with one package already downloaded to storageKey.
+func InsertPaquete(
+	db *gorm.DB, datosFiscales *model.DatosFiscalesSAT, tipoSolicitud, storageKey string,
+) (*model.PaqueteDescarga, error) {
+	now := time.Now()
+
+	solicitud := &model.SolicitudDescarga{
+		UserID:            datosFiscales.UserID,
+		DatosFiscalesUUID: datosFiscales.UUID,
+		RFCSolicitante:    datosFiscales.RFC,
+		TipoDescarga:      "recibidos",
+		TipoSolicitud:     tipoSolicitud,
+		FechaInicial:      now.AddDate(0, -1, 0),
+		FechaFinal:        now,
+		CodEstatus:        "5000",
+		Estado:            model.EstadoSolicitudTerminada,
+	}
+	if err := db.Create(solicitud).Error; err != nil {
+		return nil, err
+	}
+
+	paquete := &model.PaqueteDescarga{
+		SolicitudUUID: solicitud.UUID,
+		IDPaquete:     uuid.NewString() + "_01",
+		Estado:        model.EstadoPaqueteDescargado,
+		StorageKey:    storageKey,
+		DescargadoAt:  &now,
+	}
+	result := db.Create(paquete)
+
+	return paquete, result.Error
+}
+
+func GetPaqueteByID(db *gorm.DB, id uuid.UUID) (*model.PaqueteDescarga, error) {
+	paquete := new(model.PaqueteDescarga)
+
+	result := db.First(paquete, "uuid = ?", id)
+
+	return paquete, result.Error
+}
+
+// EfirmaForm builds the multipart body used to register or renew an e.firma.
+func EfirmaForm(fields map[string]string, cer, key []byte) (*bytes.Buffer, string, error) {
+	body := new(bytes.Buffer)
+	writer := multipart.NewWriter(body)
+
+	for name, value := range fields {
+		if err := writer.WriteField(name, value); err != nil {
+			return nil, "", err
+		}
+	}
+
+	files := []struct {
+		field, name string
+		content     []byte
+	}{
+		{"cer_file", "efirma.cer", cer},
+		{"key_file", "efirma.key", key},
+	}
+
+	for _, file := range files {
+		part, err := writer.CreateFormFile(file.field, file.name)
+		if err != nil {
+			return nil, "", err
+		}
+
+		if _, err := part.Write(file.content); err != nil {
+			return nil, "", err
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		return nil, "", err
+	}
+
+	return body, writer.FormDataContentType(), nil
+}
@@ -1,9 +1,11 @@
 package test
 
 import (
+	"app/src/config"
 	"app/src/database"
 	"app/src/router"
 	"app/src/utils"
+	"app/test/fixture"
 
 	"github.com/gofiber/fiber/v2"
 	"gorm.io/gorm"
@@ -19,6 +21,15 @@ var Log = utils.Log
 func init() {
 	// TODO: You can modify host and database configuration for tests
 	DB = database.Connect("localhost", "testdb")
-	router.Routes(App, DB)
+
+	// The e.firma fixtures are issued by a test authority, trust it instead
+	// of the SAT ones.
+	autoridades, err := fixture.SATAutoridadesDir()
+	if err != nil {
+		Log.Fatalf("Failed to write the SAT authorities fixture: %v", err)
+	}
+	config.SATAutoridadesDir = autoridades
+
+	router.Routes(App, DB, router.NewServices(DB))
 	App.Use(utils.NotFoundHandler)
 }
@@ -0,0 +1,104 @@
+package integration
+
+import (
+	"app/src/cfdi"
+	"app/src/model"
+	"app/src/sat"
+	"app/test"
+	"app/test/fixture"
+	"app/test/helper"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const uuidCartaPorte40 = "2E3F4A5B-6C7D-4E8F-9A0B-1C2D3E4F5A6B"
+
+func TestCartaPorteRoutes(t *testing.T) {
+	helper.ClearAll(test.DB)
+	helper.InsertUser(test.DB, fixture.UserOne, fixture.UserTwo)
+
+	datosFiscales := registrarRFC(t, fixture.UserOne, "EKU9003173C9")
+	indexarPaquete(t, datosFiscales, sat.TipoSolicitudCFDI, fixture.CFDICartaPorte40, fixture.CFDITraslado40)
+
+	userOneAccessToken, err := fixture.AccessToken(fixture.UserOne)
+	assert.Nil(t, err)
+
+	request := func(uuid, accessToken string) (*http.Response, *model.CFDICartaPorte) {
+		request := httptest.NewRequest(http.MethodGet, "/v1/cfdis/"+uuid+"/carta-porte", nil)
+		request.Header.Set("Authorization", "Bearer "+accessToken)
+
+		apiResponse, err := test.App.Test(request, -1)
+		assert.Nil(t, err)
+
+		responseBody := &struct {
+			Data model.CFDICartaPorte `json:"data"`
+		}{}
+		if apiResponse.StatusCode == http.StatusOK {
+			assert.Nil(t, json.NewDecoder(apiResponse.Body).Decode(responseBody))
+		}
+
+		return apiResponse, &responseBody.Data
+	}
+
+	t.Run("GET /v1/cfdis/:uuid/carta-porte", func(t *testing.T) {
+		t.Run("should return 200 with the complement and its validation", func(t *testing.T) {
+			apiResponse, cartaPorte := request(uuidCartaPorte40, userOneAccessToken)
+
+			assert.Equal(t, http.StatusOK, apiResponse.StatusCode)
+			assert.Equal(t, cfdi.VersionCartaPorte31, cartaPorte.Version)
+			assert.Equal(t, cfdi.MedioAutotransporte, cartaPorte.MedioTransporte)
+			assert.Equal(t, "1250.500", cartaPorte.PesoBrutoTotal.String())
+			assert.Equal(t, "C2R2", cartaPorte.ConfigVehicular)
+			assert.Len(t, cartaPorte.Ubicaciones, 2)
+			assert.Equal(t, cfdi.TipoUbicacionOrigen, cartaPorte.Ubicaciones[0].TipoUbicacion)
+			assert.Equal(t, cfdi.TipoUbicacionDestino, cartaPorte.Ubicaciones[1].TipoUbicacion)
+			assert.Len(t, cartaPorte.Mercancias, 2)
+			assert.Len(t, cartaPorte.Remolques, 1)
+			assert.Len(t, cartaPorte.Figuras, 1)
+			assert.True(t, cartaPorte.Valida)
+			assert.Empty(t, cartaPorte.Incidencias)
+		})
+
+		t.Run("should return the stored incidencias", func(t *testing.T) {
+			var registro model.CFDICartaPorte
+			assert.Nil(t, test.DB.First(&registro, "cfdi_uuid = ?", uuidCartaPorte40).Error)
+
+			err := test.DB.Create(&model.CFDICartaPorteIncidencia{
+				CartaPorteID: registro.ID,
+				Numero:       1,
+				Tipo:         cfdi.IncidenciaPesoInconsistente,
+				Nodo:         "CartaPorte/Mercancias@PesoBrutoTotal",
+				Mensaje:      "PesoBrutoTotal 1250 doesn't match the PesoEnKg of the mercancías, 1250.500",
+			}).Error
+			assert.Nil(t, err)
+
+			apiResponse, cartaPorte := request(uuidCartaPorte40, userOneAccessToken)
+
+			assert.Equal(t, http.StatusOK, apiResponse.StatusCode)
+			assert.Len(t, cartaPorte.Incidencias, 1)
+			assert.Equal(t, cfdi.IncidenciaPesoInconsistente, cartaPorte.Incidencias[0].Tipo)
+		})
+
+		t.Run("should return 404 if the CFDI has no Carta Porte", func(t *testing.T) {
+			apiResponse, _ := request("1C2D3E4F-5A6B-4C7D-8E9F-0A1B2C3D4E5F", userOneAccessToken)
+			assert.Equal(t, http.StatusNotFound, apiResponse.StatusCode)
+		})
+
+		t.Run("should return 400 if the UUID is invalid", func(t *testing.T) {
+			apiResponse, _ := request("not-a-uuid", userOneAccessToken)
+			assert.Equal(t, http.StatusBadRequest, apiResponse.StatusCode)
+		})
+
+		t.Run("should return 404 for CFDIs of RFCs the user didn't register", func(t *testing.T) {
+			userTwoAccessToken, err := fixture.AccessToken(fixture.UserTwo)
+			assert.Nil(t, err)
+
+			apiResponse, _ := request(uuidCartaPorte40, userTwoAccessToken)
+			assert.Equal(t, http.StatusNotFound, apiResponse.StatusCode)
+		})
+	})
+}
@@ -0,0 +1,211 @@
+package integration
+
+import (
+	"app/src/catalogo"
+	"app/src/config"
+	"app/src/model"
+	"app/src/response"
+	"app/src/service"
+	"app/test"
+	"app/test/fixture"
+	"app/test/helper"
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// publicacionCatalogos is the publication date the catalog fixtures are
+// imported as.
+var publicacionCatalogos = time.Date(2024, 6, 17, 0, 0, 0, 0, time.UTC)
+
+// importarCatalogos imports the catalog fixtures. Catalogs are reference
+// data shared by every user, so they are not cleared between tests and
+// importing them again keeps the lookups cached by the API valid.
+func importarCatalogos(t *testing.T) {
+	catalogoService := service.NewCatalogoService(test.DB, nil, config.CatalogosCacheTTL())
+
+	for _, name := range fixture.Catalogos {
+		data, err := fixture.Catalogo(name)
+		assert.Nil(t, err)
+
+		definicion, registros, err := catalogo.LeerCSV(bytes.NewReader(data))
+		assert.Nil(t, err)
+
+		importados, err := catalogoService.Importar(context.Background(), definicion.Nombre, publicacionCatalogos, registros)
+		assert.Nil(t, err)
+		assert.Equal(t, len(registros), importados)
+	}
+}
+
+func TestCatalogoRoutes(t *testing.T) {
+	helper.ClearAll(test.DB)
+	helper.InsertUser(test.DB, fixture.UserOne)
+
+	importarCatalogos(t)
+
+	// An older publication of c_RegimenFiscal that only had 601.
+	anterior := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	_, err := service.NewCatalogoService(test.DB, nil, time.Hour).Importar(context.Background(),
+		catalogo.RegimenFiscal, anterior, []catalogo.Registro{{Clave: "601", Descripcion: "General de Ley Personas Morales"}})
+	assert.Nil(t, err)
+
+	accessToken, err := fixture.AccessToken(fixture.UserOne)
+	assert.Nil(t, err)
+
+	request := func(target string) *http.Response {
+		request := httptest.NewRequest(http.MethodGet, target, nil)
+		request.Header.Set("Authorization", "Bearer "+accessToken)
+
+		apiResponse, err := test.App.Test(request, -1)
+		assert.Nil(t, err)
+
+		return apiResponse
+	}
+
+	buscar := func(target string) (*http.Response, *response.SuccessWithPaginate[model.CatalogoRegistro]) {
+		apiResponse := request(target)
+
+		responseBody := new(response.SuccessWithPaginate[model.CatalogoRegistro])
+		if apiResponse.StatusCode == http.StatusOK {
+			assert.Nil(t, json.NewDecoder(apiResponse.Body).Decode(responseBody))
+		}
+
+		return apiResponse, responseBody
+	}
+
+	t.Run("importing a publication again keeps it", func(t *testing.T) {
+		importarCatalogos(t)
+
+		var publicaciones int64
+		assert.Nil(t, test.DB.Model(&model.CatalogoPublicacion{}).Where("catalogo = ?", catalogo.UsoCFDI).
+			Count(&publicaciones).Error)
+		assert.Equal(t, int64(1), publicaciones)
+	})
+
+	t.Run("GET /v1/catalogos", func(t *testing.T) {
+		t.Run("should list the catalogs with their newest publication", func(t *testing.T) {
+			apiResponse := request("/v1/catalogos")
+			assert.Equal(t, http.StatusOK, apiResponse.StatusCode)
+
+			responseBody := &struct {
+				Data []response.Catalogo `json:"data"`
+			}{}
+			assert.Nil(t, json.NewDecoder(apiResponse.Body).Decode(responseBody))
+			assert.Len(t, responseBody.Data, len(catalogo.Catalogos))
+
+			catalogos := make(map[string]response.Catalogo)
+			for _, item := range responseBody.Data {
+				catalogos[item.Slug] = item
+			}
+
+			assert.Equal(t, catalogo.UsoCFDI, catalogos["uso-cfdi"].Nombre)
+			assert.Equal(t, publicacionCatalogos, catalogos["uso-cfdi"].FechaPublicacion.UTC())
+			assert.Equal(t, 9, catalogos["uso-cfdi"].Registros)
+			assert.Equal(t, 8, catalogos["regimen-fiscal"].Registros)
+			assert.Nil(t, catalogos["moneda"].FechaPublicacion)
+		})
+
+		t.Run("should return 401 without a token", func(t *testing.T) {
+			apiResponse, err := test.App.Test(httptest.NewRequest(http.MethodGet, "/v1/catalogos", nil), -1)
+			assert.Nil(t, err)
+			assert.Equal(t, http.StatusUnauthorized, apiResponse.StatusCode)
+		})
+	})
+
+	t.Run("GET /v1/catalogos/:catalogo", func(t *testing.T) {
+		t.Run("should search by description and similar words", func(t *testing.T) {
+			apiResponse, responseBody := buscar("/v1/catalogos/clave-prod-serv?q=laptop")
+
+			assert.Equal(t, http.StatusOK, apiResponse.StatusCode)
+			assert.Equal(t, int64(1), responseBody.TotalResults)
+			assert.Equal(t, "43211503", responseBody.Results[0].Clave)
+			assert.Equal(t, "Computadoras notebook", responseBody.Results[0].Descripcion)
+
+			_, responseBody = buscar("/v1/catalogos/clave-prod-serv?q=COMPUTA")
+			assert.Equal(t, int64(2), responseBody.TotalResults)
+		})
+
+		t.Run("should search by clave prefix and paginate", func(t *testing.T) {
+			apiResponse, responseBody := buscar("/v1/catalogos/clave-prod-serv?q=4321&limit=1&page=2")
+
+			assert.Equal(t, http.StatusOK, apiResponse.StatusCode)
+			assert.Equal(t, int64(2), responseBody.TotalResults)
+			assert.Equal(t, int64(2), responseBody.TotalPages)
+			assert.Len(t, responseBody.Results, 1)
+			assert.Equal(t, "43211507", responseBody.Results[0].Clave)
+		})
+
+		t.Run("should use the publication in effect on fecha", func(t *testing.T) {
+			_, responseBody := buscar("/v1/catalogos/regimen-fiscal")
+			assert.Equal(t, int64(8), responseBody.TotalResults)
+
+			_, responseBody = buscar("/v1/catalogos/regimen-fiscal?fecha=2023-05-01")
+			assert.Equal(t, int64(1), responseBody.TotalResults)
+
+			// Older than every publication, the oldest one is the closest.
+			_, responseBody = buscar("/v1/catalogos/regimen-fiscal?fecha=2020-01-01")
+			assert.Equal(t, int64(1), responseBody.TotalResults)
+		})
+
+		t.Run("should return 404 for unknown or not imported catalogs", func(t *testing.T) {
+			apiResponse, _ := buscar("/v1/catalogos/desconocido")
+			assert.Equal(t, http.StatusNotFound, apiResponse.StatusCode)
+
+			apiResponse, _ = buscar("/v1/catalogos/moneda")
+			assert.Equal(t, http.StatusNotFound, apiResponse.StatusCode)
+		})
+
+		t.Run("should return 400 for invalid parameters", func(t *testing.T) {
+			apiResponse, _ := buscar("/v1/catalogos/uso-cfdi?fecha=17-06-2024")
+			assert.Equal(t, http.StatusBadRequest, apiResponse.StatusCode)
+
+			apiResponse, _ = buscar("/v1/catalogos/uso-cfdi?limit=500")
+			assert.Equal(t, http.StatusBadRequest, apiResponse.StatusCode)
+		})
+	})
+
+	t.Run("GET /v1/catalogos/:catalogo/:clave", func(t *testing.T) {
+		registro := func(target string) (*http.Response, *model.CatalogoRegistro) {
+			apiResponse := request(target)
+
+			responseBody := &struct {
+				Data *model.CatalogoRegistro `json:"data"`
+			}{}
+			if apiResponse.StatusCode == http.StatusOK {
+				assert.Nil(t, json.NewDecoder(apiResponse.Body).Decode(responseBody))
+			}
+
+			return apiResponse, responseBody.Data
+		}
+
+		t.Run("should return the entry with its attributes", func(t *testing.T) {
+			apiResponse, d01 := registro("/v1/catalogos/uso-cfdi/D01")
+
+			assert.Equal(t, http.StatusOK, apiResponse.StatusCode)
+			assert.Equal(t, "Honorarios médicos, dentales y gastos hospitalarios.", d01.Descripcion)
+			assert.Equal(t, "No", d01.Atributos["aplica_para_tipo_persona_moral"])
+		})
+
+		t.Run("should restore the leading zeros of the clave", func(t *testing.T) {
+			apiResponse, transferencia := registro("/v1/catalogos/forma-pago/3")
+
+			assert.Equal(t, http.StatusOK, apiResponse.StatusCode)
+			assert.Equal(t, "03", transferencia.Clave)
+			assert.Equal(t, "Transferencia electrónica de fondos", transferencia.Descripcion)
+		})
+
+		t.Run("should return 404 for unknown claves", func(t *testing.T) {
+			apiResponse, _ := registro("/v1/catalogos/uso-cfdi/X99")
+			assert.Equal(t, http.StatusNotFound, apiResponse.StatusCode)
+
+			apiResponse, _ = registro("/v1/catalogos/regimen-fiscal/626?fecha=2023-05-01")
+			assert.Equal(t, http.StatusNotFound, apiResponse.StatusCode)
+		})
+	})
+}
@@ -0,0 +1,362 @@
+package integration
+
+import (
+	"app/src/cfdi"
+	"app/src/model"
+	"app/src/response"
+	"app/src/sat"
+	"app/src/sello"
+	"app/src/service"
+	"app/src/storage"
+	"app/test"
+	"app/test/fixture"
+	"app/test/helper"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// registrarRFC registers an e.firma for the RFC and returns its datos
+// fiscales.
+func registrarRFC(t *testing.T, user *model.User, rfc string) *model.DatosFiscalesSAT {
+	accessToken, err := fixture.AccessToken(user)
+	assert.Nil(t, err)
+
+	apiResponse := registerEfirma(t, accessToken, rfc)
+	assert.Equal(t, http.StatusCreated, apiResponse.StatusCode)
+
+	datosFiscales, err := helper.GetDatosFiscalesByUserID(test.DB, user.ID.String())
+	assert.Nil(t, err)
+
+	for i := range datosFiscales {
+		if datosFiscales[i].RFC == rfc {
+			return &datosFiscales[i]
+		}
+	}
+
+	t.Fatalf("RFC %s was not registered", rfc)
+	return nil
+}
+
+// indexarPaquete indexes a downloaded package of the RFC with the given
+// fixtures and returns the package.
+func indexarPaquete(
+	t *testing.T, datosFiscales *model.DatosFiscalesSAT, tipoSolicitud string, names ...string,
+) *model.PaqueteDescarga {
+	files := map[string][]byte{"LEEME.md": []byte("not a CFDI")}
+	for _, name := range names {
+		data, err := fixture.CFDI(name)
+		assert.Nil(t, err)
+		files[name] = data
+	}
+
+	paquete, store := guardarPaquete(t, datosFiscales, tipoSolicitud, files)
+
+	indexados, err := service.NewIndexacionService(test.DB, store, sello.NewAlmacen()).IndexarPendientes(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, 1, indexados)
+
+	return paquete
+}
+
+// guardarPaquete stores a downloaded package of the RFC with the given
+// files, ready to be indexed.
+func guardarPaquete(
+	t *testing.T, datosFiscales *model.DatosFiscalesSAT, tipoSolicitud string, files map[string][]byte,
+) (*model.PaqueteDescarga, storage.BlobStore) {
+	data, err := fixture.Paquete(files)
+	assert.Nil(t, err)
+
+	store, err := storage.NewLocalStore(t.TempDir())
+	assert.Nil(t, err)
+
+	key := "paquetes/" + datosFiscales.RFC + "/paquete.zip"
+	assert.Nil(t, store.Put(context.Background(), key, data))
+
+	paquete, err := helper.InsertPaquete(test.DB, datosFiscales, tipoSolicitud, key)
+	assert.Nil(t, err)
+
+	return paquete, store
+}
+
+func cfdisRequest(t *testing.T, target, accessToken string) (*http.Response, *response.SuccessWithPaginate[model.CFDI]) {
+	request := httptest.NewRequest(http.MethodGet, target, nil)
+	request.Header.Set("Authorization", "Bearer "+accessToken)
+
+	apiResponse, err := test.App.Test(request, -1)
+	assert.Nil(t, err)
+
+	responseBody := new(response.SuccessWithPaginate[model.CFDI])
+	if apiResponse.StatusCode == http.StatusOK {
+		assert.Nil(t, json.NewDecoder(apiResponse.Body).Decode(responseBody))
+	}
+
+	return apiResponse, responseBody
+}
+
+func TestIndexacionService(t *testing.T) {
+	t.Run("should index the stamped CFDIs of the package once", func(t *testing.T) {
+		helper.ClearAll(test.DB)
+		helper.InsertUser(test.DB, fixture.UserOne)
+
+		datosFiscales := registrarRFC(t, fixture.UserOne, "EKU9003173C9")
+		paquete := indexarPaquete(t, datosFiscales, sat.TipoSolicitudCFDI,
+			fixture.CFDIIngreso40, fixture.CFDIIngreso33, fixture.CFDIEgreso40)
+
+		stored, err := helper.GetPaqueteByID(test.DB, paquete.UUID)
+		assert.Nil(t, err)
+		assert.Equal(t, 3, stored.CFDIsIndexados)
+		assert.NotNil(t, stored.IndexadoAt)
+
+		var registro model.CFDI
+		err = test.DB.Preload("Conceptos").First(&registro, "uuid = ?", "5B4B4D8E-2C6F-4A0B-9F4E-0E5C1A2B3C4D").Error
+		assert.Nil(t, err)
+		assert.Equal(t, "EKU9003173C9", registro.RFCEmisor)
+		assert.Equal(t, "XIA190128J61", registro.RFCReceptor)
+		assert.Equal(t, "1624.00", registro.Total.String())
+		assert.Equal(t, "1451.50", registro.SubTotal.String())
+		assert.Equal(t, "224.00", registro.TotalImpuestosTrasladados.String())
+		assert.Equal(t, model.EstadoCFDIVigente, registro.Estado)
+		assert.Equal(t, "cfdis/5b4b4d8e-2c6f-4a0b-9f4e-0e5c1a2b3c4d.xml", registro.StorageKey)
+		assert.Len(t, registro.Conceptos, 2)
+		// the fixtures carry placeholder seals
+		assert.False(t, registro.SelloValido)
+		assert.NotEmpty(t, registro.SelloError)
+
+		indexados, err := service.NewIndexacionService(test.DB, nil, nil).IndexarPendientes(context.Background())
+		assert.Nil(t, err)
+		assert.Equal(t, 0, indexados)
+	})
+
+	t.Run("should skip a CFDI the database rejects", func(t *testing.T) {
+		helper.ClearAll(test.DB)
+		helper.InsertUser(test.DB, fixture.UserOne)
+
+		ingreso, err := fixture.CFDI(fixture.CFDIIngreso40)
+		assert.Nil(t, err)
+		invalido := strings.NewReplacer(`TipoDeComprobante="I"`, `TipoDeComprobante="X"`,
+			"5b4b4d8e-2c6f-4a0b-9f4e-0e5c1a2b3c4d", "6c5c5e9f-3d7a-4b1c-8a5f-1f6d2b3c4d5e").Replace(string(ingreso))
+
+		datosFiscales := registrarRFC(t, fixture.UserOne, "EKU9003173C9")
+		paquete, store := guardarPaquete(t, datosFiscales, sat.TipoSolicitudCFDI, map[string][]byte{
+			"invalido.xml": []byte(invalido),
+			"ingreso.xml":  ingreso,
+		})
+
+		indexados, err := service.NewIndexacionService(test.DB, store, sello.NewAlmacen()).IndexarPendientes(context.Background())
+		assert.Nil(t, err)
+		assert.Equal(t, 1, indexados)
+
+		stored, err := helper.GetPaqueteByID(test.DB, paquete.UUID)
+		assert.Nil(t, err)
+		assert.Equal(t, 1, stored.CFDIsIndexados)
+		assert.NotNil(t, stored.IndexadoAt)
+
+		var total int64
+		assert.Nil(t, test.DB.Model(&model.CFDI{}).Where("uuid = ?", "6c5c5e9f-3d7a-4b1c-8a5f-1f6d2b3c4d5e").
+			Count(&total).Error)
+		assert.Zero(t, total)
+	})
+
+	t.Run("should give up on a package that can't be read", func(t *testing.T) {
+		helper.ClearAll(test.DB)
+		helper.InsertUser(test.DB, fixture.UserOne)
+
+		datosFiscales := registrarRFC(t, fixture.UserOne, "EKU9003173C9")
+		paquete, err := helper.InsertPaquete(test.DB, datosFiscales, sat.TipoSolicitudCFDI, "paquetes/perdido.zip")
+		assert.Nil(t, err)
+
+		store, err := storage.NewLocalStore(t.TempDir())
+		assert.Nil(t, err)
+		indexacionService := service.NewIndexacionService(test.DB, store, sello.NewAlmacen())
+
+		for intento := 1; intento <= 4; intento++ {
+			indexados, err := indexacionService.IndexarPendientes(context.Background())
+			assert.Nil(t, err)
+			assert.Equal(t, 0, indexados)
+		}
+
+		stored, err := helper.GetPaqueteByID(test.DB, paquete.UUID)
+		assert.Nil(t, err)
+		assert.Equal(t, 3, stored.IntentosIndexacion)
+		assert.Contains(t, stored.ErrorIndexacion, "reading package")
+		assert.Nil(t, stored.IndexadoAt)
+	})
+
+	t.Run("should store the metadata rows and cancel the indexed XMLs", func(t *testing.T) {
+		helper.ClearAll(test.DB)
+		helper.InsertUser(test.DB, fixture.UserOne)
+
+		datosFiscales := registrarRFC(t, fixture.UserOne, "EKU9003173C9")
+		indexarPaquete(t, datosFiscales, sat.TipoSolicitudCFDI, fixture.CFDIEgreso40)
+		paquete := indexarPaquete(t, datosFiscales, sat.TipoSolicitudMetadata, fixture.CFDIMetadata)
+
+		stored, err := helper.GetPaqueteByID(test.DB, paquete.UUID)
+		assert.Nil(t, err)
+		assert.Equal(t, 4, stored.CFDIsIndexados)
+
+		var metadata model.CFDIMetadata
+		err = test.DB.First(&metadata, "uuid = ?", "7A8B9C0D-1E2F-4A3B-9C4D-5E6F7A8B9C0D").Error
+		assert.Nil(t, err)
+		assert.Equal(t, model.EstadoCFDICancelado, metadata.Estatus)
+		assert.Equal(t, "116.00", metadata.Monto.String())
+		assert.NotNil(t, metadata.FechaCancelacion)
+
+		var registro model.CFDI
+		err = test.DB.First(&registro, "uuid = ?", metadata.UUID).Error
+		assert.Nil(t, err)
+		assert.Equal(t, model.EstadoCFDICancelado, registro.Estado)
+	})
+}
+
+func TestCFDIRoutes(t *testing.T) {
+	t.Run("GET /v1/cfdis", func(t *testing.T) {
+		helper.ClearAll(test.DB)
+		helper.InsertUser(test.DB, fixture.UserOne, fixture.UserTwo)
+
+		datosFiscales := registrarRFC(t, fixture.UserOne, "EKU9003173C9")
+		indexarPaquete(t, datosFiscales, sat.TipoSolicitudCFDI,
+			fixture.CFDIIngreso40, fixture.CFDIIngreso33, fixture.CFDIEgreso40, fixture.CFDITraslado40)
+
+		userOneAccessToken, err := fixture.AccessToken(fixture.UserOne)
+		assert.Nil(t, err)
+
+		t.Run("should return 200 and the CFDIs of the user RFCs, newest first", func(t *testing.T) {
+			apiResponse, responseBody := cfdisRequest(t, "/v1/cfdis?limit=2", userOneAccessToken)
+
+			assert.Equal(t, http.StatusOK, apiResponse.StatusCode)
+			assert.Equal(t, int64(4), responseBody.TotalResults)
+			assert.Equal(t, int64(2), responseBody.TotalPages)
+			assert.Len(t, responseBody.Results, 2)
+			assert.Equal(t, cfdi.TipoTraslado, responseBody.Results[0].TipoComprobante)
+		})
+
+		t.Run("should filter the CFDIs", func(t *testing.T) {
+			cases := map[string][]string{
+				"tipo=E": {"7a8b9c0d-1e2f-4a3b-9c4d-5e6f7a8b9c0d"},
+				"rfc=xia190128j61": {
+					"7a8b9c0d-1e2f-4a3b-9c4d-5e6f7a8b9c0d",
+					"5b4b4d8e-2c6f-4a0b-9f4e-0e5c1a2b3c4d",
+				},
+				"fecha_inicial=2024-01-15&fecha_final=2024-01-15": {"5b4b4d8e-2c6f-4a0b-9f4e-0e5c1a2b3c4d"},
+				"total_min=1000&total_max=9533.34": {
+					"5b4b4d8e-2c6f-4a0b-9f4e-0e5c1a2b3c4d",
+					"3f2a1b0c-9d8e-4f7a-8b6c-5d4e3f2a1b0c",
+				},
+				"search=COMPUTADORA": {"5b4b4d8e-2c6f-4a0b-9f4e-0e5c1a2b3c4d"},
+			}
+
+			for filter, expected := range cases {
+				apiResponse, responseBody := cfdisRequest(t, "/v1/cfdis?"+filter, userOneAccessToken)
+				assert.Equal(t, http.StatusOK, apiResponse.StatusCode, filter)
+
+				uuids := []string{}
+				for _, registro := range responseBody.Results {
+					uuids = append(uuids, registro.UUID.String())
+				}
+				assert.Equal(t, expected, uuids, filter)
+			}
+		})
+
+		t.Run("should return 400 if a filter is invalid", func(t *testing.T) {
+			for _, filter := range []string{
+				"tipo=X",
+				"fecha_inicial=15-01-2024",
+				"fecha_inicial=2024-02-01&fecha_final=2024-01-01",
+				"total_min=abc",
+				"total_min=10&total_max=5",
+				"rfc=NOTANRFC",
+				"limit=0",
+			} {
+				apiResponse, _ := cfdisRequest(t, "/v1/cfdis?"+filter, userOneAccessToken)
+				assert.Equal(t, http.StatusBadRequest, apiResponse.StatusCode, filter)
+			}
+		})
+
+		t.Run("should not return CFDIs of RFCs the user didn't register", func(t *testing.T) {
+			userTwoAccessToken, err := fixture.AccessToken(fixture.UserTwo)
+			assert.Nil(t, err)
+
+			apiResponse, responseBody := cfdisRequest(t, "/v1/cfdis", userTwoAccessToken)
+
+			assert.Equal(t, http.StatusOK, apiResponse.StatusCode)
+			assert.Equal(t, int64(0), responseBody.TotalResults)
+			assert.Empty(t, responseBody.Results)
+		})
+
+		t.Run("should return 401 if access token is missing", func(t *testing.T) {
+			apiResponse, _ := cfdisRequest(t, "/v1/cfdis", "")
+			assert.Equal(t, http.StatusUnauthorized, apiResponse.StatusCode)
+		})
+	})
+
+	t.Run("GET /v1/cfdis/conciliacion", func(t *testing.T) {
+		helper.ClearAll(test.DB)
+		helper.InsertUser(test.DB, fixture.UserOne, fixture.UserTwo)
+
+		datosFiscales := registrarRFC(t, fixture.UserOne, "EKU9003173C9")
+		indexarPaquete(t, datosFiscales, sat.TipoSolicitudCFDI,
+			fixture.CFDIIngreso40, fixture.CFDIEgreso40, fixture.CFDITraslado40)
+		indexarPaquete(t, datosFiscales, sat.TipoSolicitudMetadata, fixture.CFDIMetadata)
+
+		conciliacionRequest := func(target, accessToken string) (*http.Response, *response.Conciliacion) {
+			request := httptest.NewRequest(http.MethodGet, target, nil)
+			request.Header.Set("Authorization", "Bearer "+accessToken)
+
+			apiResponse, err := test.App.Test(request, -1)
+			assert.Nil(t, err)
+
+			responseBody := &struct {
+				Data response.Conciliacion `json:"data"`
+			}{}
+			if apiResponse.StatusCode == http.StatusOK {
+				assert.Nil(t, json.NewDecoder(apiResponse.Body).Decode(responseBody))
+			}
+
+			return apiResponse, &responseBody.Data
+		}
+
+		userOneAccessToken, err := fixture.AccessToken(fixture.UserOne)
+		assert.Nil(t, err)
+
+		t.Run("should return 200 with the metadata without XML and the cancelled XMLs", func(t *testing.T) {
+			apiResponse, conciliacion := conciliacionRequest("/v1/cfdis/conciliacion", userOneAccessToken)
+
+			assert.Equal(t, http.StatusOK, apiResponse.StatusCode)
+			assert.Len(t, conciliacion.SinXML, 1)
+			assert.Equal(t, "8d1f2e3a-4b5c-4d6e-9f70-81a2b3c4d5e6", conciliacion.SinXML[0].UUID.String())
+			assert.Len(t, conciliacion.Cancelados, 1)
+			assert.Equal(t, "7a8b9c0d-1e2f-4a3b-9c4d-5e6f7a8b9c0d", conciliacion.Cancelados[0].UUID.String())
+			assert.NotNil(t, conciliacion.Cancelados[0].Metadata)
+			assert.NotNil(t, conciliacion.Cancelados[0].Metadata.FechaCancelacion)
+		})
+
+		t.Run("should filter by date", func(t *testing.T) {
+			apiResponse, conciliacion := conciliacionRequest(
+				"/v1/cfdis/conciliacion?fecha_inicial=2024-01-01&fecha_final=2024-01-20", userOneAccessToken)
+
+			assert.Equal(t, http.StatusOK, apiResponse.StatusCode)
+			assert.Empty(t, conciliacion.SinXML)
+			assert.Len(t, conciliacion.Cancelados, 1)
+
+			apiResponse, _ = conciliacionRequest("/v1/cfdis/conciliacion?fecha_final=2024-1-20", userOneAccessToken)
+			assert.Equal(t, http.StatusBadRequest, apiResponse.StatusCode)
+		})
+
+		t.Run("should not include other users RFCs", func(t *testing.T) {
+			userTwoAccessToken, err := fixture.AccessToken(fixture.UserTwo)
+			assert.Nil(t, err)
+
+			apiResponse, conciliacion := conciliacionRequest("/v1/cfdis/conciliacion", userTwoAccessToken)
+
+			assert.Equal(t, http.StatusOK, apiResponse.StatusCode)
+			assert.Empty(t, conciliacion.SinXML)
+			assert.Empty(t, conciliacion.Cancelados)
+		})
+	})
+}
@@ -0,0 +1,553 @@
+package integration
+
+import (
+	"app/src/model"
+	"app/src/response"
+	"app/test"
+	"app/test/fixture"
+	"app/test/helper"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type datosFiscalesResponse struct {
+	Code    int                    `json:"code"`
+	Status  string                 `json:"status"`
+	Message string                 `json:"message"`
+	Data    model.DatosFiscalesSAT `json:"data"`
+}
+
+func registerEfirma(t *testing.T, accessToken, rfc string) *http.Response {
+	efirma, err := fixture.ValidEfirma(rfc)
+	assert.Nil(t, err)
+
+	return postEfirma(t, accessToken, rfc, efirma)
+}
+
+// postEfirma registers the e.firma for the RFC, whatever its certificate.
+func postEfirma(t *testing.T, accessToken, rfc string, efirma *fixture.Efirma) *http.Response {
+	key, err := fixture.EncryptPrivateKey(efirma.PrivateKey, "efirma-password")
+	assert.Nil(t, err)
+
+	return postEfirmaForm(t, accessToken, rfc, "efirma-password", efirma.Certificate, key)
+}
+
+func postEfirmaForm(t *testing.T, accessToken, rfc, password string, cer, key []byte) *http.Response {
+	body, contentType, err := helper.EfirmaForm(map[string]string{
+		"rfc":      rfc,
+		"password": password,
+	}, cer, key)
+	assert.Nil(t, err)
+
+	request := httptest.NewRequest(http.MethodPost, "/v1/datos-fiscales", body)
+	request.Header.Set("Content-Type", contentType)
+	request.Header.Set("Accept", "application/json")
+	request.Header.Set("Authorization", "Bearer "+accessToken)
+
+	apiResponse, err := test.App.Test(request, -1)
+	assert.Nil(t, err)
+
+	return apiResponse
+}
+
+func patchDatosFiscales(t *testing.T, accessToken, id, body string) *http.Response {
+	request := httptest.NewRequest(http.MethodPatch, "/v1/datos-fiscales/"+id, strings.NewReader(body))
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Authorization", "Bearer "+accessToken)
+
+	apiResponse, err := test.App.Test(request, -1)
+	assert.Nil(t, err)
+
+	return apiResponse
+}
+
+// renovarEfirma replaces the e.firma of the fiscal data with a new one.
+func renovarEfirma(t *testing.T, accessToken, id string, efirma *fixture.Efirma) *http.Response {
+	key, err := fixture.EncryptPrivateKey(efirma.PrivateKey, "efirma-password")
+	assert.Nil(t, err)
+
+	body, contentType, err := helper.EfirmaForm(map[string]string{"password": "efirma-password"}, efirma.Certificate, key)
+	assert.Nil(t, err)
+
+	request := httptest.NewRequest(http.MethodPut, "/v1/datos-fiscales/"+id+"/efirma", body)
+	request.Header.Set("Content-Type", contentType)
+	request.Header.Set("Authorization", "Bearer "+accessToken)
+
+	apiResponse, err := test.App.Test(request, -1)
+	assert.Nil(t, err)
+
+	return apiResponse
+}
+
+// assertMessage checks the error message of a response.
+func assertMessage(t *testing.T, apiResponse *http.Response, message string) {
+	t.Helper()
+
+	responseBody := new(response.Common)
+	assert.Nil(t, json.NewDecoder(apiResponse.Body).Decode(responseBody))
+	assert.Equal(t, message, responseBody.Message)
+}
+
+func TestDatosFiscalesRoutes(t *testing.T) {
+	t.Run("POST /v1/datos-fiscales", func(t *testing.T) {
+		t.Run("should return 201 and register several RFCs for the same user", func(t *testing.T) {
+			helper.ClearAll(test.DB)
+			helper.InsertUser(test.DB, fixture.UserOne)
+
+			userOneAccessToken, err := fixture.AccessToken(fixture.UserOne)
+			assert.Nil(t, err)
+
+			for _, rfc := range []string{"EKU9003173C9", "XIA190128J61"} {
+				apiResponse := registerEfirma(t, userOneAccessToken, rfc)
+
+				bytes, err := io.ReadAll(apiResponse.Body)
+				assert.Nil(t, err)
+
+				responseBody := new(datosFiscalesResponse)
+				err = json.Unmarshal(bytes, responseBody)
+				assert.Nil(t, err)
+
+				assert.Equal(t, http.StatusCreated, apiResponse.StatusCode)
+				assert.Equal(t, rfc, responseBody.Data.RFC)
+				assert.NotContains(t, string(bytes), "encriptado")
+			}
+
+			datosFiscales, err := helper.GetDatosFiscalesByUserID(test.DB, fixture.UserOne.ID.String())
+			assert.Nil(t, err)
+			assert.Len(t, datosFiscales, 2)
+		})
+
+		t.Run("should return 409 if the RFC is already registered by the user", func(t *testing.T) {
+			helper.ClearAll(test.DB)
+			helper.InsertUser(test.DB, fixture.UserOne)
+
+			userOneAccessToken, err := fixture.AccessToken(fixture.UserOne)
+			assert.Nil(t, err)
+
+			apiResponse := registerEfirma(t, userOneAccessToken, "EKU9003173C9")
+			assert.Equal(t, http.StatusCreated, apiResponse.StatusCode)
+
+			apiResponse = registerEfirma(t, userOneAccessToken, "EKU9003173C9")
+			assert.Equal(t, http.StatusConflict, apiResponse.StatusCode)
+		})
+
+		t.Run("should allow different users to register the same RFC", func(t *testing.T) {
+			helper.ClearAll(test.DB)
+			helper.InsertUser(test.DB, fixture.UserOne, fixture.UserTwo)
+
+			userOneAccessToken, err := fixture.AccessToken(fixture.UserOne)
+			assert.Nil(t, err)
+
+			userTwoAccessToken, err := fixture.AccessToken(fixture.UserTwo)
+			assert.Nil(t, err)
+
+			apiResponse := registerEfirma(t, userOneAccessToken, "EKU9003173C9")
+			assert.Equal(t, http.StatusCreated, apiResponse.StatusCode)
+
+			apiResponse = registerEfirma(t, userTwoAccessToken, "EKU9003173C9")
+			assert.Equal(t, http.StatusCreated, apiResponse.StatusCode)
+		})
+
+		t.Run("should return 400 for a certificate that can't be used", func(t *testing.T) {
+			helper.ClearAll(test.DB)
+			helper.InsertUser(test.DB, fixture.UserOne)
+
+			userOneAccessToken, err := fixture.AccessToken(fixture.UserOne)
+			assert.Nil(t, err)
+
+			now := time.Now()
+			forgedCA, err := fixture.NewAutoridad(fixture.SATIssuer)
+			assert.Nil(t, err)
+
+			otroRFC, err := fixture.ValidEfirma("XIA190128J61")
+			assert.Nil(t, err)
+			vencido, err := fixture.NewEfirma("EKU9003173C9", "30001000000500003416", fixture.SATIssuer,
+				now.AddDate(-4, 0, 0), now.AddDate(0, 0, -1))
+			assert.Nil(t, err)
+			futuro, err := fixture.NewEfirma("EKU9003173C9", "30001000000500003416", fixture.SATIssuer,
+				now.AddDate(0, 0, 1), now.AddDate(4, 0, 0))
+			assert.Nil(t, err)
+			autofirmado, err := fixture.ForgedEfirma("EKU9003173C9", now.AddDate(0, -1, 0), now.AddDate(4, 0, 0))
+			assert.Nil(t, err)
+			otraAC, err := fixture.NewEfirmaDe(forgedCA, "EKU9003173C9", "30001000000500003416",
+				now.AddDate(0, -1, 0), now.AddDate(4, 0, 0))
+			assert.Nil(t, err)
+
+			for _, tc := range []struct {
+				name    string
+				efirma  *fixture.Efirma
+				message string
+			}{
+				{"RFC mismatch", otroRFC, "RFC does not match the certificate"},
+				{"expired", vencido, "Certificate is expired or not yet valid"},
+				{"not yet valid", futuro, "Certificate is expired or not yet valid"},
+				{"self-signed with the SAT name", autofirmado, "Certificate was not issued by the SAT"},
+				{"untrusted authority with the SAT name", otraAC, "Certificate was not issued by the SAT"},
+			} {
+				apiResponse := postEfirma(t, userOneAccessToken, "EKU9003173C9", tc.efirma)
+				assert.Equal(t, http.StatusBadRequest, apiResponse.StatusCode, tc.name)
+				assertMessage(t, apiResponse, tc.message)
+			}
+
+			datosFiscales, err := helper.GetDatosFiscalesByUserID(test.DB, fixture.UserOne.ID.String())
+			assert.Nil(t, err)
+			assert.Empty(t, datosFiscales)
+		})
+
+		t.Run("should return 400 for a wrong password or a key of another certificate", func(t *testing.T) {
+			helper.ClearAll(test.DB)
+			helper.InsertUser(test.DB, fixture.UserOne)
+
+			userOneAccessToken, err := fixture.AccessToken(fixture.UserOne)
+			assert.Nil(t, err)
+
+			efirma, err := fixture.ValidEfirma("EKU9003173C9")
+			assert.Nil(t, err)
+			key, err := fixture.EncryptPrivateKey(efirma.PrivateKey, "efirma-password")
+			assert.Nil(t, err)
+
+			apiResponse := postEfirmaForm(t, userOneAccessToken, "EKU9003173C9", "wrong-password", efirma.Certificate, key)
+			assert.Equal(t, http.StatusBadRequest, apiResponse.StatusCode)
+			assertMessage(t, apiResponse, "Invalid e.firma password")
+
+			otra, err := fixture.ValidEfirma("EKU9003173C9")
+			assert.Nil(t, err)
+			otraKey, err := fixture.EncryptPrivateKey(otra.PrivateKey, "efirma-password")
+			assert.Nil(t, err)
+
+			apiResponse = postEfirmaForm(t, userOneAccessToken, "EKU9003173C9", "efirma-password", efirma.Certificate, otraKey)
+			assert.Equal(t, http.StatusBadRequest, apiResponse.StatusCode)
+			assertMessage(t, apiResponse, "Key file does not belong to the certificate")
+
+			datosFiscales, err := helper.GetDatosFiscalesByUserID(test.DB, fixture.UserOne.ID.String())
+			assert.Nil(t, err)
+			assert.Empty(t, datosFiscales)
+		})
+	})
+
+	t.Run("PATCH /v1/datos-fiscales/:rfcId", func(t *testing.T) {
+		helper.ClearAll(test.DB)
+		helper.InsertUser(test.DB, fixture.UserOne, fixture.UserTwo)
+
+		userOneAccessToken, err := fixture.AccessToken(fixture.UserOne)
+		assert.Nil(t, err)
+
+		apiResponse := registerEfirma(t, userOneAccessToken, "EKU9003173C9")
+		assert.Equal(t, http.StatusCreated, apiResponse.StatusCode)
+
+		responseBody := new(datosFiscalesResponse)
+		assert.Nil(t, json.NewDecoder(apiResponse.Body).Decode(responseBody))
+		id := responseBody.Data.UUID.String()
+
+		t.Run("should return 200 when only the password is sent", func(t *testing.T) {
+			apiResponse := patchDatosFiscales(t, userOneAccessToken, id, `{"password":"efirma-password"}`)
+			assert.Equal(t, http.StatusOK, apiResponse.StatusCode)
+
+			apiResponse = patchDatosFiscales(t, userOneAccessToken, id, `{"rfc":"EKU9003173C9","password":"efirma-password"}`)
+			assert.Equal(t, http.StatusOK, apiResponse.StatusCode)
+		})
+
+		t.Run("should return 400 if the password doesn't decrypt the stored key", func(t *testing.T) {
+			apiResponse := patchDatosFiscales(t, userOneAccessToken, id, `{"password":"wrong-password"}`)
+			assert.Equal(t, http.StatusBadRequest, apiResponse.StatusCode)
+			assertMessage(t, apiResponse, "Invalid e.firma password")
+		})
+
+		t.Run("should return 400 for another RFC or an invalid password", func(t *testing.T) {
+			apiResponse := patchDatosFiscales(t, userOneAccessToken, id, `{"rfc":"XIA190128J61"}`)
+			assert.Equal(t, http.StatusBadRequest, apiResponse.StatusCode)
+			assertMessage(t, apiResponse, "RFC does not match the registered certificate")
+
+			apiResponse = patchDatosFiscales(t, userOneAccessToken, id, `{"password":"short"}`)
+			assert.Equal(t, http.StatusBadRequest, apiResponse.StatusCode)
+		})
+
+		t.Run("should return 404 when the RFC belongs to another user", func(t *testing.T) {
+			userTwoAccessToken, err := fixture.AccessToken(fixture.UserTwo)
+			assert.Nil(t, err)
+
+			apiResponse := patchDatosFiscales(t, userTwoAccessToken, id, `{"password":"efirma-password"}`)
+			assert.Equal(t, http.StatusNotFound, apiResponse.StatusCode)
+		})
+	})
+
+	t.Run("GET /v1/datos-fiscales", func(t *testing.T) {
+		t.Run("should return 200 and only the RFCs of the user, paginated", func(t *testing.T) {
+			helper.ClearAll(test.DB)
+			helper.InsertUser(test.DB, fixture.UserOne, fixture.UserTwo)
+
+			userOneAccessToken, err := fixture.AccessToken(fixture.UserOne)
+			assert.Nil(t, err)
+
+			userTwoAccessToken, err := fixture.AccessToken(fixture.UserTwo)
+			assert.Nil(t, err)
+
+			registerEfirma(t, userOneAccessToken, "EKU9003173C9")
+			registerEfirma(t, userOneAccessToken, "XIA190128J61")
+			registerEfirma(t, userTwoAccessToken, "EKU9003173C9")
+
+			request := httptest.NewRequest(http.MethodGet, "/v1/datos-fiscales?page=1&limit=1", nil)
+			request.Header.Set("Authorization", "Bearer "+userOneAccessToken)
+
+			apiResponse, err := test.App.Test(request)
+			assert.Nil(t, err)
+
+			bytes, err := io.ReadAll(apiResponse.Body)
+			assert.Nil(t, err)
+
+			responseBody := new(response.SuccessWithPaginate[model.DatosFiscalesSAT])
+			err = json.Unmarshal(bytes, responseBody)
+			assert.Nil(t, err)
+
+			assert.Equal(t, http.StatusOK, apiResponse.StatusCode)
+			assert.Equal(t, int64(2), responseBody.TotalResults)
+			assert.Equal(t, int64(2), responseBody.TotalPages)
+			assert.Len(t, responseBody.Results, 1)
+			assert.Equal(t, "EKU9003173C9", responseBody.Results[0].RFC)
+			assert.Equal(t, fixture.UserOne.ID, responseBody.Results[0].UserID)
+		})
+	})
+
+	t.Run("GET /v1/datos-fiscales/:rfcId", func(t *testing.T) {
+		t.Run("should return 404 when the RFC belongs to another user", func(t *testing.T) {
+			helper.ClearAll(test.DB)
+			helper.InsertUser(test.DB, fixture.UserOne, fixture.UserTwo)
+
+			userOneAccessToken, err := fixture.AccessToken(fixture.UserOne)
+			assert.Nil(t, err)
+
+			userTwoAccessToken, err := fixture.AccessToken(fixture.UserTwo)
+			assert.Nil(t, err)
+
+			apiResponse := registerEfirma(t, userOneAccessToken, "EKU9003173C9")
+
+			responseBody := new(datosFiscalesResponse)
+			err = json.NewDecoder(apiResponse.Body).Decode(responseBody)
+			assert.Nil(t, err)
+
+			request := httptest.NewRequest(http.MethodGet, "/v1/datos-fiscales/"+responseBody.Data.UUID.String(), nil)
+			request.Header.Set("Authorization", "Bearer "+userTwoAccessToken)
+
+			apiResponse, err = test.App.Test(request)
+			assert.Nil(t, err)
+			assert.Equal(t, http.StatusNotFound, apiResponse.StatusCode)
+
+			request = httptest.NewRequest(http.MethodGet, "/v1/datos-fiscales/"+responseBody.Data.UUID.String(), nil)
+			request.Header.Set("Authorization", "Bearer "+userOneAccessToken)
+
+			apiResponse, err = test.App.Test(request)
+			assert.Nil(t, err)
+			assert.Equal(t, http.StatusOK, apiResponse.StatusCode)
+		})
+
+		t.Run("should return 400 if the id is not a valid uuid", func(t *testing.T) {
+			helper.ClearAll(test.DB)
+			helper.InsertUser(test.DB, fixture.UserOne)
+
+			userOneAccessToken, err := fixture.AccessToken(fixture.UserOne)
+			assert.Nil(t, err)
+
+			request := httptest.NewRequest(http.MethodGet, "/v1/datos-fiscales/invalidId", nil)
+			request.Header.Set("Authorization", "Bearer "+userOneAccessToken)
+
+			apiResponse, err := test.App.Test(request)
+			assert.Nil(t, err)
+			assert.Equal(t, http.StatusBadRequest, apiResponse.StatusCode)
+		})
+	})
+
+	t.Run("DELETE /v1/datos-fiscales/:rfcId", func(t *testing.T) {
+		t.Run("should delete only the selected RFC", func(t *testing.T) {
+			helper.ClearAll(test.DB)
+			helper.InsertUser(test.DB, fixture.UserOne)
+
+			userOneAccessToken, err := fixture.AccessToken(fixture.UserOne)
+			assert.Nil(t, err)
+
+			apiResponse := registerEfirma(t, userOneAccessToken, "EKU9003173C9")
+			registerEfirma(t, userOneAccessToken, "XIA190128J61")
+
+			responseBody := new(datosFiscalesResponse)
+			err = json.NewDecoder(apiResponse.Body).Decode(responseBody)
+			assert.Nil(t, err)
+
+			request := httptest.NewRequest(http.MethodDelete, "/v1/datos-fiscales/"+responseBody.Data.UUID.String(), nil)
+			request.Header.Set("Authorization", "Bearer "+userOneAccessToken)
+
+			apiResponse, err = test.App.Test(request)
+			assert.Nil(t, err)
+			assert.Equal(t, http.StatusOK, apiResponse.StatusCode)
+
+			datosFiscales, err := helper.GetDatosFiscalesByUserID(test.DB, fixture.UserOne.ID.String())
+			assert.Nil(t, err)
+			assert.Len(t, datosFiscales, 1)
+			assert.Equal(t, "XIA190128J61", datosFiscales[0].RFC)
+		})
+	})
+
+	t.Run("PUT /v1/datos-fiscales/:rfcId/efirma", func(t *testing.T) {
+		now := time.Now()
+
+		setup := func(t *testing.T) (string, *datosFiscalesResponse) {
+			helper.ClearAll(test.DB)
+			helper.InsertUser(test.DB, fixture.UserOne, fixture.UserTwo)
+
+			userOneAccessToken, err := fixture.AccessToken(fixture.UserOne)
+			assert.Nil(t, err)
+
+			apiResponse := registerEfirma(t, userOneAccessToken, "EKU9003173C9")
+			assert.Equal(t, http.StatusCreated, apiResponse.StatusCode)
+
+			registered := new(datosFiscalesResponse)
+			assert.Nil(t, json.NewDecoder(apiResponse.Body).Decode(registered))
+
+			return userOneAccessToken, registered
+		}
+
+		t.Run("should replace the certificate keeping the fiscal data and its history", func(t *testing.T) {
+			userOneAccessToken, registered := setup(t)
+			id := registered.Data.UUID.String()
+
+			nueva, err := fixture.NewEfirma("EKU9003173C9", "30001000000500003417", fixture.SATIssuer,
+				now.AddDate(0, 0, -1), now.AddDate(4, 0, 0))
+			assert.Nil(t, err)
+
+			apiResponse := renovarEfirma(t, userOneAccessToken, id, nueva)
+			assert.Equal(t, http.StatusOK, apiResponse.StatusCode)
+
+			responseBody := new(datosFiscalesResponse)
+			assert.Nil(t, json.NewDecoder(apiResponse.Body).Decode(responseBody))
+			assert.Equal(t, registered.Data.UUID, responseBody.Data.UUID)
+			assert.Equal(t, "EKU9003173C9", responseBody.Data.RFC)
+			assert.Equal(t, "30001000000500003417", responseBody.Data.NoCertificado)
+
+			datosFiscales, err := helper.GetDatosFiscalesByUserID(test.DB, fixture.UserOne.ID.String())
+			assert.Nil(t, err)
+			assert.Len(t, datosFiscales, 1)
+			assert.Equal(t, "30001000000500003417", datosFiscales[0].NoCertificado)
+
+			request := httptest.NewRequest(http.MethodGet, "/v1/datos-fiscales/"+id+"/historial", nil)
+			request.Header.Set("Authorization", "Bearer "+userOneAccessToken)
+
+			apiResponse, err = test.App.Test(request)
+			assert.Nil(t, err)
+			assert.Equal(t, http.StatusOK, apiResponse.StatusCode)
+
+			historial := &struct {
+				Data []model.HistorialCertificadoFiscal `json:"data"`
+			}{}
+			assert.Nil(t, json.NewDecoder(apiResponse.Body).Decode(historial))
+			assert.Len(t, historial.Data, 1)
+			assert.Equal(t, registered.Data.UUID, historial.Data[0].DatosFiscalesUUID)
+			assert.Equal(t, registered.Data.NoCertificado, historial.Data[0].NoCertificado)
+			assert.Equal(t, fixture.UserOne.ID, *historial.Data[0].ReemplazadoBy)
+
+			apiResponse = renovarEfirma(t, userOneAccessToken, id, nueva)
+			assert.Equal(t, http.StatusConflict, apiResponse.StatusCode)
+		})
+
+		t.Run("should keep every replaced certificate when renewals race", func(t *testing.T) {
+			userOneAccessToken, registered := setup(t)
+			id := registered.Data.UUID.String()
+
+			numeros := []string{"30001000000500003417", "30001000000500003418"}
+			efirmas := make([]*fixture.Efirma, len(numeros))
+			for i, numero := range numeros {
+				efirma, err := fixture.NewEfirma("EKU9003173C9", numero, fixture.SATIssuer,
+					now.AddDate(0, 0, -1), now.AddDate(4, 0, 0))
+				assert.Nil(t, err)
+				efirmas[i] = efirma
+			}
+
+			var wg sync.WaitGroup
+			codes := make([]int, len(efirmas))
+			for i, efirma := range efirmas {
+				wg.Add(1)
+				go func(i int, efirma *fixture.Efirma) {
+					defer wg.Done()
+					codes[i] = renovarEfirma(t, userOneAccessToken, id, efirma).StatusCode
+				}(i, efirma)
+			}
+			wg.Wait()
+
+			for _, code := range codes {
+				assert.Equal(t, http.StatusOK, code)
+			}
+
+			var historial []model.HistorialCertificadoFiscal
+			assert.Nil(t, test.DB.Where("datos_fiscales_uuid = ?", registered.Data.UUID).Find(&historial).Error)
+			assert.Len(t, historial, 2)
+
+			datosFiscales, err := helper.GetDatosFiscalesByUserID(test.DB, fixture.UserOne.ID.String())
+			assert.Nil(t, err)
+			assert.Len(t, datosFiscales, 1)
+
+			certificados := []string{datosFiscales[0].NoCertificado}
+			for _, registro := range historial {
+				certificados = append(certificados, registro.NoCertificado)
+			}
+			assert.ElementsMatch(t, append(numeros, registered.Data.NoCertificado), certificados)
+		})
+
+		t.Run("should return 400 for a certificate of another RFC or expired", func(t *testing.T) {
+			userOneAccessToken, registered := setup(t)
+			id := registered.Data.UUID.String()
+
+			otroRFC, err := fixture.NewEfirma("XIA190128J61", "30001000000500003417", fixture.SATIssuer,
+				now.AddDate(0, 0, -1), now.AddDate(4, 0, 0))
+			assert.Nil(t, err)
+
+			apiResponse := renovarEfirma(t, userOneAccessToken, id, otroRFC)
+			assert.Equal(t, http.StatusBadRequest, apiResponse.StatusCode)
+			assertMessage(t, apiResponse, "RFC does not match the certificate")
+
+			vencido, err := fixture.NewEfirma("EKU9003173C9", "30001000000500003417", fixture.SATIssuer,
+				now.AddDate(-4, 0, 0), now.AddDate(0, 0, -1))
+			assert.Nil(t, err)
+
+			apiResponse = renovarEfirma(t, userOneAccessToken, id, vencido)
+			assert.Equal(t, http.StatusBadRequest, apiResponse.StatusCode)
+			assertMessage(t, apiResponse, "Certificate is expired or not yet valid")
+
+			datosFiscales, err := helper.GetDatosFiscalesByUserID(test.DB, fixture.UserOne.ID.String())
+			assert.Nil(t, err)
+			assert.Len(t, datosFiscales, 1)
+			assert.Equal(t, registered.Data.NoCertificado, datosFiscales[0].NoCertificado)
+
+			var historial int64
+			assert.Nil(t, test.DB.Model(&model.HistorialCertificadoFiscal{}).
+				Where("datos_fiscales_uuid = ?", registered.Data.UUID).Count(&historial).Error)
+			assert.Zero(t, historial)
+		})
+
+		t.Run("should return 404 for the fiscal data or the history of another user", func(t *testing.T) {
+			_, registered := setup(t)
+			id := registered.Data.UUID.String()
+
+			userTwoAccessToken, err := fixture.AccessToken(fixture.UserTwo)
+			assert.Nil(t, err)
+
+			nueva, err := fixture.NewEfirma("EKU9003173C9", "30001000000500003417", fixture.SATIssuer,
+				now.AddDate(0, 0, -1), now.AddDate(4, 0, 0))
+			assert.Nil(t, err)
+
+			apiResponse := renovarEfirma(t, userTwoAccessToken, id, nueva)
+			assert.Equal(t, http.StatusNotFound, apiResponse.StatusCode)
+
+			request := httptest.NewRequest(http.MethodGet, "/v1/datos-fiscales/"+id+"/historial", nil)
+			request.Header.Set("Authorization", "Bearer "+userTwoAccessToken)
+
+			apiResponse, err = test.App.Test(request)
+			assert.Nil(t, err)
+			assert.Equal(t, http.StatusNotFound, apiResponse.StatusCode)
+		})
+	})
+}
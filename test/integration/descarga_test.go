@@ -0,0 +1,386 @@
+package integration
+
+import (
+	"app/src/config"
+	"app/src/model"
+	"app/src/router"
+	"app/src/sat"
+	"app/src/service"
+	"app/src/storage"
+	"app/src/utils"
+	"app/src/validation"
+	"app/test"
+	"app/test/fakesat"
+	"app/test/fixture"
+	"app/test/helper"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+// descargaSAT is the download pipeline wired to a local stand-in of the
+// SAT: the descargas routes and the workers that verify the requests and
+// download their packages, sharing the SAT token cache.
+type descargaSAT struct {
+	App          *fiber.App
+	Verificacion service.VerificacionService
+	Paquetes     service.PaqueteService
+	Store        storage.BlobStore
+}
+
+func newDescargaSAT(t *testing.T, fake *fakesat.Server) *descargaSAT {
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	client := sat.NewClient(sat.Endpoints{
+		Autenticacion: server.URL,
+		Solicitud:     server.URL,
+		Verificacion:  server.URL,
+		Descarga:      server.URL,
+	})
+
+	keyProvider, err := config.KeyProvider()
+	assert.Nil(t, err)
+
+	store, err := storage.NewLocalStore(t.TempDir())
+	assert.Nil(t, err)
+
+	validate := validation.Validator()
+	fielService := service.NewFielService(test.DB, keyProvider)
+	satAuthService := service.NewSATAuthService(client)
+
+	app := fiber.New(fiber.Config{
+		CaseSensitive: true,
+		ErrorHandler:  utils.ErrorHandler,
+	})
+	router.DescargaRoutes(app.Group("/v1"),
+		service.NewDescargaService(test.DB, validate, fielService, satAuthService, client),
+		service.NewSubscriptionService(test.DB, validate), service.NewUserService(test.DB, validate))
+
+	return &descargaSAT{
+		App:          app,
+		Verificacion: service.NewVerificacionService(test.DB, fielService, satAuthService, client),
+		Paquetes:     service.NewPaqueteService(test.DB, fielService, satAuthService, client, store),
+		Store:        store,
+	}
+}
+
+type solicitudResponse struct {
+	Code    int                     `json:"code"`
+	Message string                  `json:"message"`
+	Data    model.SolicitudDescarga `json:"data"`
+}
+
+func (d *descargaSAT) solicitar(t *testing.T, accessToken, body string) (*http.Response, *solicitudResponse) {
+	request := httptest.NewRequest(http.MethodPost, "/v1/descargas", strings.NewReader(body))
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Authorization", "Bearer "+accessToken)
+
+	apiResponse, err := d.App.Test(request, -1)
+	assert.Nil(t, err)
+
+	responseBody := new(solicitudResponse)
+	assert.Nil(t, json.NewDecoder(apiResponse.Body).Decode(responseBody))
+
+	return apiResponse, responseBody
+}
+
+func (d *descargaSAT) paquetes(t *testing.T, accessToken, id string) (*http.Response, []model.PaqueteDescarga) {
+	request := httptest.NewRequest(http.MethodGet, "/v1/descargas/"+id+"/paquetes", nil)
+	request.Header.Set("Authorization", "Bearer "+accessToken)
+
+	apiResponse, err := d.App.Test(request, -1)
+	assert.Nil(t, err)
+
+	responseBody := &struct {
+		Data []model.PaqueteDescarga `json:"data"`
+	}{}
+	if apiResponse.StatusCode == http.StatusOK {
+		assert.Nil(t, json.NewDecoder(apiResponse.Body).Decode(responseBody))
+	}
+
+	return apiResponse, responseBody.Data
+}
+
+// solicitudBody requests the CFDIs received in January 2024.
+func solicitudBody(datosFiscales *model.DatosFiscalesSAT, extra string) string {
+	return `{"datos_fiscales_id":"` + datosFiscales.UUID.String() + `",` +
+		`"fecha_inicial":"2024-01-01T00:00:00Z","fecha_final":"2024-01-31T23:59:59Z",` +
+		`"tipo_descarga":"recibidos","tipo_solicitud":"CFDI"` + extra + `}`
+}
+
+func descargasUsadas(t *testing.T, suscripcion *model.SuscripcionUsuario) int {
+	actual, err := helper.GetSuscripcionByID(test.DB, suscripcion.ID)
+	assert.Nil(t, err)
+
+	return actual.DescargasUsadas
+}
+
+func TestDescargaRoutes(t *testing.T) {
+	t.Run("POST /v1/descargas", func(t *testing.T) {
+		setup := func(t *testing.T) (*model.DatosFiscalesSAT, *model.SuscripcionUsuario, string) {
+			helper.ClearAll(test.DB)
+			helper.InsertUser(test.DB, fixture.UserOne, fixture.UserTwo)
+			helper.InsertPlan(test.DB, fixture.PlanBasico)
+
+			suscripcion, err := helper.InsertSuscripcion(test.DB, fixture.UserOne.ID, fixture.PlanBasico, 0)
+			assert.Nil(t, err)
+
+			accessToken, err := fixture.AccessToken(fixture.UserOne)
+			assert.Nil(t, err)
+
+			return registrarRFC(t, fixture.UserOne, "EKU9003173C9"), suscripcion, accessToken
+		}
+
+		t.Run("should return 201, store the solicitud and use one download", func(t *testing.T) {
+			datosFiscales, suscripcion, accessToken := setup(t)
+			fake := fakesat.New()
+			descargas := newDescargaSAT(t, fake)
+
+			apiResponse, responseBody := descargas.solicitar(t, accessToken,
+				solicitudBody(datosFiscales, `,"tipo_comprobante":"I","rfc_contraparte":"XIA190128J61"`))
+
+			assert.Equal(t, http.StatusCreated, apiResponse.StatusCode)
+			assert.Equal(t, "9", apiResponse.Header.Get("X-Quota-Remaining"))
+			assert.Equal(t, 1, descargasUsadas(t, suscripcion))
+
+			solicitudes := fake.Solicitudes()
+			assert.Len(t, solicitudes, 1)
+			assert.Equal(t, "EKU9003173C9", solicitudes[0].RFC)
+			assert.Equal(t, "XIA190128J61", solicitudes[0].Attrs["RfcEmisor"])
+
+			solicitud := responseBody.Data
+			assert.Equal(t, datosFiscales.UUID, solicitud.DatosFiscalesUUID)
+			assert.Equal(t, "EKU9003173C9", solicitud.RFCSolicitante)
+			assert.Equal(t, sat.CodSolicitudAceptada, solicitud.CodEstatus)
+			assert.Equal(t, model.EstadoSolicitudAceptada, solicitud.Estado)
+			assert.Equal(t, solicitudes[0].ID, *solicitud.IDSolicitud)
+
+			// The token is reused for the next request of the same e.firma.
+			apiResponse, _ = descargas.solicitar(t, accessToken, solicitudBody(datosFiscales, `,"tipo_solicitud":"Metadata"`))
+			assert.Equal(t, http.StatusCreated, apiResponse.StatusCode)
+			assert.Equal(t, 1, fake.Calls(fakesat.ActionAutentica))
+		})
+
+		t.Run("should return 400 for invalid parameters without using the quota", func(t *testing.T) {
+			datosFiscales, suscripcion, accessToken := setup(t)
+			fake := fakesat.New()
+			descargas := newDescargaSAT(t, fake)
+
+			for _, body := range []string{
+				`{}`,
+				solicitudBody(datosFiscales, `,"tipo_descarga":"todos"`),
+				solicitudBody(datosFiscales, `,"tipo_solicitud":"PDF"`),
+				solicitudBody(datosFiscales, `,"fecha_final":"2023-12-31T00:00:00Z"`),
+				solicitudBody(datosFiscales, `,"tipo_comprobante":"X"`),
+				solicitudBody(datosFiscales, `,"estado_comprobante":"Pendiente"`),
+				solicitudBody(datosFiscales, `,"rfc_contraparte":"INVALIDO"`),
+				strings.Replace(solicitudBody(datosFiscales, ""), datosFiscales.UUID.String(), "not-a-uuid", 1),
+			} {
+				apiResponse, _ := descargas.solicitar(t, accessToken, body)
+				assert.Equal(t, http.StatusBadRequest, apiResponse.StatusCode, body)
+			}
+
+			assert.Equal(t, 0, descargasUsadas(t, suscripcion))
+			assert.Zero(t, fake.Calls(fakesat.ActionAutentica))
+		})
+
+		t.Run("should return 404 for the fiscal data of another user", func(t *testing.T) {
+			_, suscripcion, accessToken := setup(t)
+			fake := fakesat.New()
+			descargas := newDescargaSAT(t, fake)
+
+			ajenos := registrarRFC(t, fixture.UserTwo, "XIA190128J61")
+
+			apiResponse, _ := descargas.solicitar(t, accessToken, solicitudBody(ajenos, ""))
+			assert.Equal(t, http.StatusNotFound, apiResponse.StatusCode)
+			assert.Equal(t, "10", apiResponse.Header.Get("X-Quota-Remaining"))
+			assert.Equal(t, 0, descargasUsadas(t, suscripcion))
+			assert.Zero(t, fake.Calls(fakesat.ActionAutentica))
+			assert.Empty(t, fake.Solicitudes())
+		})
+
+		t.Run("should return 402 without a subscription and 429 without downloads left", func(t *testing.T) {
+			datosFiscales, suscripcion, accessToken := setup(t)
+			fake := fakesat.New()
+			descargas := newDescargaSAT(t, fake)
+
+			assert.Nil(t, test.DB.Model(suscripcion).Update("descargas_usadas", fixture.PlanBasico.LimiteDescargasMensuales).Error)
+
+			apiResponse, _ := descargas.solicitar(t, accessToken, solicitudBody(datosFiscales, ""))
+			assert.Equal(t, http.StatusTooManyRequests, apiResponse.StatusCode)
+			assert.Equal(t, "0", apiResponse.Header.Get("X-Quota-Remaining"))
+
+			assert.Nil(t, test.DB.Delete(suscripcion).Error)
+
+			apiResponse, _ = descargas.solicitar(t, accessToken, solicitudBody(datosFiscales, ""))
+			assert.Equal(t, http.StatusPaymentRequired, apiResponse.StatusCode)
+			assert.Empty(t, fake.Solicitudes())
+		})
+
+		t.Run("should map the SAT rejections and give the download back", func(t *testing.T) {
+			datosFiscales, suscripcion, accessToken := setup(t)
+			fake := fakesat.New()
+			descargas := newDescargaSAT(t, fake)
+
+			apiResponse, _ := descargas.solicitar(t, accessToken, solicitudBody(datosFiscales, ""))
+			assert.Equal(t, http.StatusCreated, apiResponse.StatusCode)
+
+			apiResponse, responseBody := descargas.solicitar(t, accessToken, solicitudBody(datosFiscales, ""))
+			assert.Equal(t, http.StatusConflict, apiResponse.StatusCode)
+			assert.Equal(t, "A download request with the same parameters already exists", responseBody.Message)
+
+			for code, status := range map[string]int{
+				sat.CodSolicitudesAgotadas: http.StatusTooManyRequests,
+				sat.CodSinInformacion:      http.StatusNotFound,
+				sat.CodTopeMaximo:          http.StatusUnprocessableEntity,
+			} {
+				fake.SolicitudCodEstatus = code
+
+				apiResponse, _ := descargas.solicitar(t, accessToken, solicitudBody(datosFiscales, ""))
+				assert.Equal(t, status, apiResponse.StatusCode, code)
+			}
+
+			assert.Equal(t, 1, descargasUsadas(t, suscripcion))
+
+			var rechazadas int64
+			assert.Nil(t, test.DB.Model(&model.SolicitudDescarga{}).
+				Where("user_id = ? AND estado = ?", fixture.UserOne.ID, model.EstadoSolicitudRechazada).
+				Count(&rechazadas).Error)
+			assert.Equal(t, int64(4), rechazadas)
+		})
+
+		t.Run("should authenticate each user with their own e.firma for the same RFC", func(t *testing.T) {
+			datosFiscales, _, accessToken := setup(t)
+			fake := fakesat.New()
+			descargas := newDescargaSAT(t, fake)
+
+			_, err := helper.InsertSuscripcion(test.DB, fixture.UserTwo.ID, fixture.PlanBasico, 0)
+			assert.Nil(t, err)
+
+			userTwoAccessToken, err := fixture.AccessToken(fixture.UserTwo)
+			assert.Nil(t, err)
+
+			mismoRFC := registrarRFC(t, fixture.UserTwo, "EKU9003173C9")
+
+			apiResponse, _ := descargas.solicitar(t, accessToken, solicitudBody(datosFiscales, ""))
+			assert.Equal(t, http.StatusCreated, apiResponse.StatusCode)
+
+			apiResponse, _ = descargas.solicitar(t, userTwoAccessToken, solicitudBody(mismoRFC, `,"tipo_solicitud":"Metadata"`))
+			assert.Equal(t, http.StatusCreated, apiResponse.StatusCode)
+			assert.Equal(t, 2, fake.Calls(fakesat.ActionAutentica))
+		})
+	})
+
+	t.Run("GET /v1/descargas/:descargaId/paquetes", func(t *testing.T) {
+		helper.ClearAll(test.DB)
+		helper.InsertUser(test.DB, fixture.UserOne, fixture.UserTwo)
+		helper.InsertPlan(test.DB, fixture.PlanBasico)
+
+		_, err := helper.InsertSuscripcion(test.DB, fixture.UserOne.ID, fixture.PlanBasico, 0)
+		assert.Nil(t, err)
+
+		accessToken, err := fixture.AccessToken(fixture.UserOne)
+		assert.Nil(t, err)
+
+		datosFiscales := registrarRFC(t, fixture.UserOne, "EKU9003173C9")
+
+		fake := fakesat.New()
+		descargas := newDescargaSAT(t, fake)
+
+		apiResponse, responseBody := descargas.solicitar(t, accessToken, solicitudBody(datosFiscales, ""))
+		assert.Equal(t, http.StatusCreated, apiResponse.StatusCode)
+		id := responseBody.Data.UUID.String()
+
+		t.Run("should return 200 and no packages while the SAT is working", func(t *testing.T) {
+			apiResponse, paquetes := descargas.paquetes(t, accessToken, id)
+
+			assert.Equal(t, http.StatusOK, apiResponse.StatusCode)
+			assert.Empty(t, paquetes)
+		})
+
+		t.Run("should return the packages with their download metadata", func(t *testing.T) {
+			ingreso, err := fixture.CFDI(fixture.CFDIIngreso40)
+			assert.Nil(t, err)
+
+			contenido, err := fixture.Paquete(map[string][]byte{"ingreso.xml": ingreso, "LEEME.md": []byte("not a CFDI")})
+			assert.Nil(t, err)
+
+			idSolicitud := *responseBody.Data.IDSolicitud
+			fake.Complete(idSolicitud, 1, idSolicitud+"_01", idSolicitud+"_02")
+			fake.SetPaquete(idSolicitud+"_01", contenido)
+
+			verificadas, err := descargas.Verificacion.VerificarPendientes(context.Background())
+			assert.Nil(t, err)
+			assert.Equal(t, 1, verificadas)
+
+			descargados, err := descargas.Paquetes.DescargarPendientes(context.Background())
+			assert.Nil(t, err)
+			assert.Equal(t, 1, descargados)
+
+			apiResponse, paquetes := descargas.paquetes(t, accessToken, id)
+
+			assert.Equal(t, http.StatusOK, apiResponse.StatusCode)
+			assert.Len(t, paquetes, 2)
+
+			descargado := paquetes[0]
+			assert.Equal(t, idSolicitud+"_01", descargado.IDPaquete)
+			assert.Equal(t, model.EstadoPaqueteDescargado, descargado.Estado)
+			assert.Equal(t, int64(len(contenido)), descargado.TamanoBytes)
+			assert.Len(t, descargado.SHA256, 64)
+			assert.Equal(t, 2, descargado.NumeroArchivos)
+			assert.NotNil(t, descargado.DescargadoAt)
+
+			fallido := paquetes[1]
+			assert.Equal(t, idSolicitud+"_02", fallido.IDPaquete)
+			assert.Equal(t, model.EstadoPaqueteError, fallido.Estado)
+			assert.Equal(t, 1, fallido.Intentos)
+			assert.Contains(t, fallido.Mensaje, sat.CodSinInformacion)
+			assert.Nil(t, fallido.DescargadoAt)
+			assert.Equal(t, 1, fake.Descargas(idSolicitud+"_01"))
+		})
+
+		t.Run("should not download again a package stored before it failed", func(t *testing.T) {
+			ingreso, err := fixture.CFDI(fixture.CFDIIngreso40)
+			assert.Nil(t, err)
+
+			contenido, err := fixture.Paquete(map[string][]byte{"ingreso.xml": ingreso})
+			assert.Nil(t, err)
+
+			idPaquete := *responseBody.Data.IDSolicitud + "_02"
+			key := "paquetes/" + datosFiscales.RFC + "/" + id + "/" + idPaquete + ".zip"
+			assert.Nil(t, descargas.Store.Put(context.Background(), key, contenido))
+			descargasSAT := fake.Descargas(idPaquete)
+
+			descargados, err := descargas.Paquetes.DescargarPendientes(context.Background())
+			assert.Nil(t, err)
+			assert.Equal(t, 1, descargados)
+			assert.Equal(t, descargasSAT, fake.Descargas(idPaquete))
+
+			_, paquetes := descargas.paquetes(t, accessToken, id)
+			assert.Len(t, paquetes, 2)
+			assert.Equal(t, model.EstadoPaqueteDescargado, paquetes[1].Estado)
+			assert.Equal(t, 1, paquetes[1].NumeroArchivos)
+			assert.Equal(t, 2, paquetes[1].Intentos)
+		})
+
+		t.Run("should return 404 for the request of another user", func(t *testing.T) {
+			userTwoAccessToken, err := fixture.AccessToken(fixture.UserTwo)
+			assert.Nil(t, err)
+
+			apiResponse, _ := descargas.paquetes(t, userTwoAccessToken, id)
+			assert.Equal(t, http.StatusNotFound, apiResponse.StatusCode)
+		})
+
+		t.Run("should return 400 if the id is not a valid uuid", func(t *testing.T) {
+			apiResponse, _ := descargas.paquetes(t, accessToken, "invalidId")
+			assert.Equal(t, http.StatusBadRequest, apiResponse.StatusCode)
+		})
+	})
+}
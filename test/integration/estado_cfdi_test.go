@@ -0,0 +1,197 @@
+package integration
+
+import (
+	"app/src/model"
+	"app/src/response"
+	"app/src/router"
+	"app/src/sat"
+	"app/src/service"
+	"app/src/utils"
+	"app/src/validation"
+	"app/test"
+	"app/test/fakesat"
+	"app/test/fixture"
+	"app/test/helper"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	uuidIngreso40 = "5B4B4D8E-2C6F-4A0B-9F4E-0E5C1A2B3C4D"
+	uuidIngreso33 = "3F2A1B0C-9D8E-4F7A-8B6C-5D4E3F2A1B0C"
+	uuidEgreso40  = "7A8B9C0D-1E2F-4A3B-9C4D-5E6F7A8B9C0D"
+
+	// diasFixtures covers the dates of the CFDI fixtures.
+	diasFixtures = 20 * 365
+)
+
+// estadoCFDIService queries the status of the CFDIs at a local stand-in
+// of the ConsultaCFDIService.
+func estadoCFDIService(t *testing.T, fake *fakesat.Server) service.EstadoCFDIService {
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	client := sat.NewClient(sat.Endpoints{Consulta: server.URL})
+
+	return service.NewEstadoCFDIService(test.DB, client, sat.NewConsultaCache(time.Hour), diasFixtures)
+}
+
+func estadoCFDIApp(estadoService service.EstadoCFDIService) *fiber.App {
+	validate := validation.Validator()
+
+	app := fiber.New(fiber.Config{
+		CaseSensitive: true,
+		ErrorHandler:  utils.ErrorHandler,
+	})
+	router.CFDIRoutes(app.Group("/v1"), service.NewCFDIService(test.DB, validate), estadoService,
+		service.NewPagoService(test.DB, validate), service.NewCartaPorteService(test.DB),
+		service.NewReceptorService(test.DB, validate, service.NewCatalogoService(test.DB, validate, time.Hour)),
+		service.NewUserService(test.DB, validate))
+
+	return app
+}
+
+func estadoCFDI(t *testing.T, uuid string) string {
+	var registro model.CFDI
+	assert.Nil(t, test.DB.First(&registro, "uuid = ?", uuid).Error)
+
+	return registro.Estado
+}
+
+func TestEstadoCFDIRoutes(t *testing.T) {
+	t.Run("GET /v1/cfdis/:uuid/estado", func(t *testing.T) {
+		helper.ClearAll(test.DB)
+		helper.InsertUser(test.DB, fixture.UserOne, fixture.UserTwo)
+
+		datosFiscales := registrarRFC(t, fixture.UserOne, "EKU9003173C9")
+		indexarPaquete(t, datosFiscales, sat.TipoSolicitudCFDI,
+			fixture.CFDIIngreso40, fixture.CFDIIngreso33, fixture.CFDIEgreso40)
+
+		fake := fakesat.New()
+		fake.SetCFDI(uuidIngreso40, fakesat.CFDI{
+			RFCEmisor: "EKU9003173C9", RFCReceptor: "XIA190128J61", Total: "1624.00", Estado: fakesat.EstadoVigente,
+		})
+		fake.SetCFDI(uuidEgreso40, fakesat.CFDI{
+			RFCEmisor: "EKU9003173C9", RFCReceptor: "XIA190128J61", Total: "116.00", Estado: fakesat.EstadoCancelado,
+			EstatusCancelacion: fakesat.CancelacionAceptada,
+		})
+
+		app := estadoCFDIApp(estadoCFDIService(t, fake))
+
+		estadoRequest := func(uuid, accessToken string) (*http.Response, *response.EstadoCFDI) {
+			request := httptest.NewRequest(http.MethodGet, "/v1/cfdis/"+uuid+"/estado", nil)
+			if accessToken != "" {
+				request.Header.Set("Authorization", "Bearer "+accessToken)
+			}
+
+			apiResponse, err := app.Test(request, -1)
+			assert.Nil(t, err)
+
+			responseBody := &struct {
+				Data response.EstadoCFDI `json:"data"`
+			}{}
+			if apiResponse.StatusCode == http.StatusOK {
+				assert.Nil(t, json.NewDecoder(apiResponse.Body).Decode(responseBody))
+			}
+
+			return apiResponse, &responseBody.Data
+		}
+
+		userOneAccessToken, err := fixture.AccessToken(fixture.UserOne)
+		assert.Nil(t, err)
+
+		t.Run("should return 200 with the SAT status and cache it", func(t *testing.T) {
+			apiResponse, estado := estadoRequest(uuidIngreso40, userOneAccessToken)
+
+			assert.Equal(t, http.StatusOK, apiResponse.StatusCode)
+			assert.Equal(t, model.EstadoCFDIVigente, estado.Estado)
+			assert.Equal(t, sat.EstadoCFDIVigente, estado.SAT.Estado)
+			assert.Equal(t, 1, fake.Calls(fakesat.ActionConsulta))
+
+			apiResponse, _ = estadoRequest(uuidIngreso40, userOneAccessToken)
+			assert.Equal(t, http.StatusOK, apiResponse.StatusCode)
+			assert.Equal(t, 1, fake.Calls(fakesat.ActionConsulta))
+		})
+
+		t.Run("should update the stored estado of a cancelled CFDI", func(t *testing.T) {
+			apiResponse, estado := estadoRequest(uuidEgreso40, userOneAccessToken)
+
+			assert.Equal(t, http.StatusOK, apiResponse.StatusCode)
+			assert.Equal(t, model.EstadoCFDICancelado, estado.Estado)
+			assert.Equal(t, sat.EstadoCFDICancelado, estado.SAT.Estado)
+			assert.Equal(t, model.EstadoCFDICancelado, estadoCFDI(t, uuidEgreso40))
+		})
+
+		t.Run("should keep the estado of a CFDI the SAT doesn't know", func(t *testing.T) {
+			apiResponse, estado := estadoRequest(uuidIngreso33, userOneAccessToken)
+
+			assert.Equal(t, http.StatusOK, apiResponse.StatusCode)
+			assert.Equal(t, model.EstadoCFDIVigente, estado.Estado)
+			assert.Equal(t, sat.EstadoCFDINoEncontrado, estado.SAT.Estado)
+		})
+
+		t.Run("should return 400 if the UUID is invalid", func(t *testing.T) {
+			apiResponse, _ := estadoRequest("not-a-uuid", userOneAccessToken)
+			assert.Equal(t, http.StatusBadRequest, apiResponse.StatusCode)
+		})
+
+		t.Run("should return 404 for CFDIs of RFCs the user didn't register", func(t *testing.T) {
+			userTwoAccessToken, err := fixture.AccessToken(fixture.UserTwo)
+			assert.Nil(t, err)
+
+			apiResponse, _ := estadoRequest(uuidIngreso40, userTwoAccessToken)
+			assert.Equal(t, http.StatusNotFound, apiResponse.StatusCode)
+		})
+
+		t.Run("should return 401 if access token is missing", func(t *testing.T) {
+			apiResponse, _ := estadoRequest(uuidIngreso40, "")
+			assert.Equal(t, http.StatusUnauthorized, apiResponse.StatusCode)
+		})
+	})
+}
+
+func TestEstadoCFDIService(t *testing.T) {
+	t.Run("should refresh the estado of the recent CFDIs", func(t *testing.T) {
+		helper.ClearAll(test.DB)
+		helper.InsertUser(test.DB, fixture.UserOne)
+
+		datosFiscales := registrarRFC(t, fixture.UserOne, "EKU9003173C9")
+		indexarPaquete(t, datosFiscales, sat.TipoSolicitudCFDI,
+			fixture.CFDIIngreso40, fixture.CFDIIngreso33, fixture.CFDIEgreso40)
+
+		fake := fakesat.New()
+		fake.SetCFDI(uuidIngreso40, fakesat.CFDI{
+			RFCEmisor: "EKU9003173C9", RFCReceptor: "XIA190128J61", Total: "1624.00", Estado: fakesat.EstadoVigente,
+			EstatusCancelacion: fakesat.CancelacionEnCurso,
+		})
+		fake.SetCFDI(uuidIngreso33, fakesat.CFDI{
+			RFCEmisor: "CACX7605101P8", RFCReceptor: "EKU9003173C9", Total: "9533.34", Estado: fakesat.EstadoVigente,
+		})
+		fake.SetCFDI(uuidEgreso40, fakesat.CFDI{
+			RFCEmisor: "EKU9003173C9", RFCReceptor: "XIA190128J61", Total: "116.00", Estado: fakesat.EstadoCancelado,
+		})
+
+		estadoService := estadoCFDIService(t, fake)
+
+		cambiados, err := estadoService.RevisarRecientes(context.Background())
+		assert.Nil(t, err)
+		assert.Equal(t, 2, cambiados)
+		assert.Equal(t, 3, fake.Calls(fakesat.ActionConsulta))
+
+		assert.Equal(t, model.EstadoCFDIEnCancelacion, estadoCFDI(t, uuidIngreso40))
+		assert.Equal(t, model.EstadoCFDIVigente, estadoCFDI(t, uuidIngreso33))
+		assert.Equal(t, model.EstadoCFDICancelado, estadoCFDI(t, uuidEgreso40))
+
+		cambiados, err = estadoService.RevisarRecientes(context.Background())
+		assert.Nil(t, err)
+		assert.Equal(t, 0, cambiados)
+		assert.Equal(t, 3, fake.Calls(fakesat.ActionConsulta))
+	})
+}
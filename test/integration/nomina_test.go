@@ -0,0 +1,167 @@
+package integration
+
+import (
+	"app/src/model"
+	"app/src/sat"
+	"app/test"
+	"app/test/fixture"
+	"app/test/helper"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNominaRoutes(t *testing.T) {
+	helper.ClearAll(test.DB)
+	helper.InsertUser(test.DB, fixture.UserOne, fixture.UserTwo)
+
+	datosFiscales := registrarRFC(t, fixture.UserOne, "EKU9003173C9")
+	indexarPaquete(t, datosFiscales, sat.TipoSolicitudCFDI, fixture.CFDIIngreso40, fixture.CFDINomina40)
+
+	userOneAccessToken, err := fixture.AccessToken(fixture.UserOne)
+	assert.Nil(t, err)
+
+	request := func(target, accessToken string, data interface{}) *http.Response {
+		request := httptest.NewRequest(http.MethodGet, target, nil)
+		request.Header.Set("Authorization", "Bearer "+accessToken)
+
+		apiResponse, err := test.App.Test(request, -1)
+		assert.Nil(t, err)
+
+		responseBody := &struct {
+			Data interface{} `json:"data"`
+		}{Data: data}
+		if apiResponse.StatusCode == http.StatusOK && data != nil {
+			assert.Nil(t, json.NewDecoder(apiResponse.Body).Decode(responseBody))
+		}
+
+		return apiResponse
+	}
+
+	t.Run("should index the receipt and its conceptos", func(t *testing.T) {
+		var nominas []model.CFDINomina
+		assert.Nil(t, test.DB.Preload("Conceptos").Find(&nominas).Error)
+
+		assert.Len(t, nominas, 1)
+		assert.Equal(t, "CACX7605101P8", nominas[0].RFCEmpleado)
+		assert.Equal(t, "XOCHILT CASAS CHAVEZ", nominas[0].NombreEmpleado)
+		assert.Equal(t, "2000.00", nominas[0].ISRRetenido.String())
+		assert.Equal(t, "500.00", nominas[0].SubsidioCausado.String())
+		assert.Len(t, nominas[0].Conceptos, 5)
+	})
+
+	t.Run("GET /v1/nomina/periodos", func(t *testing.T) {
+		t.Run("should return 200 with the totals of every period", func(t *testing.T) {
+			var resumen []model.ResumenNominaPeriodo
+			apiResponse := request("/v1/nomina/periodos", userOneAccessToken, &resumen)
+
+			assert.Equal(t, http.StatusOK, apiResponse.StatusCode)
+			assert.Len(t, resumen, 1)
+			assert.Equal(t, "2024-01-01", resumen[0].FechaInicialPago.Format("2006-01-02"))
+			assert.Equal(t, "2024-01-15", resumen[0].FechaFinalPago.Format("2006-01-02"))
+			assert.Equal(t, 1, resumen[0].Recibos)
+			assert.Equal(t, 1, resumen[0].Empleados)
+			assert.Equal(t, "14500.00", resumen[0].TotalPercepciones.String())
+			assert.Equal(t, "13000.00", resumen[0].TotalGravado.String())
+			assert.Equal(t, "1500.00", resumen[0].TotalExento.String())
+			assert.Equal(t, "12500.00", resumen[0].Neto.String())
+		})
+
+		t.Run("should filter by fecha de pago", func(t *testing.T) {
+			var resumen []model.ResumenNominaPeriodo
+			apiResponse := request("/v1/nomina/periodos?fecha_inicial=2024-02-01", userOneAccessToken, &resumen)
+
+			assert.Equal(t, http.StatusOK, apiResponse.StatusCode)
+			assert.Empty(t, resumen)
+		})
+
+		t.Run("should export a CSV", func(t *testing.T) {
+			apiResponse := request("/v1/nomina/periodos?format=csv", userOneAccessToken, nil)
+
+			assert.Equal(t, http.StatusOK, apiResponse.StatusCode)
+			assert.Contains(t, apiResponse.Header.Get("Content-Type"), "text/csv")
+			assert.Contains(t, apiResponse.Header.Get("Content-Disposition"), "nomina-periodos.csv")
+
+			body, err := io.ReadAll(apiResponse.Body)
+			assert.Nil(t, err)
+
+			records, err := csv.NewReader(strings.NewReader(strings.TrimPrefix(string(body), "\uFEFF"))).ReadAll()
+			assert.Nil(t, err)
+			assert.Len(t, records, 2)
+			assert.Equal(t, "fecha_inicial_pago", records[0][0])
+			assert.Equal(t, "2024-01-01", records[1][0])
+			assert.Equal(t, "12500.00", records[1][len(records[1])-1])
+		})
+
+		t.Run("should return 400 if a filter is invalid", func(t *testing.T) {
+			apiResponse := request("/v1/nomina/periodos?format=xlsx", userOneAccessToken, nil)
+			assert.Equal(t, http.StatusBadRequest, apiResponse.StatusCode)
+
+			apiResponse = request("/v1/nomina/periodos?fecha_inicial=2024-02-01&fecha_final=2024-01-01", userOneAccessToken, nil)
+			assert.Equal(t, http.StatusBadRequest, apiResponse.StatusCode)
+		})
+
+		t.Run("should not include other users RFCs", func(t *testing.T) {
+			userTwoAccessToken, err := fixture.AccessToken(fixture.UserTwo)
+			assert.Nil(t, err)
+
+			var resumen []model.ResumenNominaPeriodo
+			apiResponse := request("/v1/nomina/periodos", userTwoAccessToken, &resumen)
+
+			assert.Equal(t, http.StatusOK, apiResponse.StatusCode)
+			assert.Empty(t, resumen)
+		})
+	})
+
+	t.Run("GET /v1/nomina/empleados", func(t *testing.T) {
+		t.Run("should return 200 with the totals of every employee", func(t *testing.T) {
+			var resumen []model.ResumenNominaEmpleado
+			apiResponse := request("/v1/nomina/empleados?empleado=cacx7605101p8", userOneAccessToken, &resumen)
+
+			assert.Equal(t, http.StatusOK, apiResponse.StatusCode)
+			assert.Len(t, resumen, 1)
+			assert.Equal(t, "CACX7605101P8", resumen[0].RFCEmpleado)
+			assert.Equal(t, "CACX760510MGTSHC04", resumen[0].Curp)
+			assert.Equal(t, "0001", resumen[0].NumEmpleado)
+			assert.Equal(t, "2000.00", resumen[0].ISRRetenido.String())
+			assert.Equal(t, "500.00", resumen[0].SubsidioCausado.String())
+		})
+	})
+
+	t.Run("GET /v1/nomina/conceptos", func(t *testing.T) {
+		t.Run("should return 200 with the totals of every concepto", func(t *testing.T) {
+			var resumen []model.ResumenNominaConcepto
+			apiResponse := request("/v1/nomina/conceptos", userOneAccessToken, &resumen)
+
+			assert.Equal(t, http.StatusOK, apiResponse.StatusCode)
+			assert.Len(t, resumen, 5)
+
+			importes := map[string]string{}
+			for _, concepto := range resumen {
+				importes[concepto.Tipo+"/"+concepto.TipoSAT] = concepto.Importe.String()
+			}
+			assert.Equal(t, "13000.00", importes[model.TipoConceptoPercepcion+"/001"])
+			assert.Equal(t, "1500.00", importes[model.TipoConceptoPercepcion+"/029"])
+			assert.Equal(t, "2000.00", importes[model.TipoConceptoDeduccion+"/002"])
+			assert.Equal(t, "500.00", importes[model.TipoConceptoOtroPago+"/002"])
+		})
+
+		t.Run("should not count cancelled CFDIs", func(t *testing.T) {
+			err := test.DB.Model(&model.CFDI{}).Where("uuid = ?", "9E8D7C6B-5A4F-4E3D-8C2B-1A0F9E8D7C6B").
+				Update("estado", model.EstadoCFDICancelado).Error
+			assert.Nil(t, err)
+
+			var resumen []model.ResumenNominaConcepto
+			apiResponse := request("/v1/nomina/conceptos", userOneAccessToken, &resumen)
+
+			assert.Equal(t, http.StatusOK, apiResponse.StatusCode)
+			assert.Empty(t, resumen)
+		})
+	})
+}
@@ -0,0 +1,191 @@
+package integration
+
+import (
+	"app/src/model"
+	"app/src/response"
+	"app/src/sat"
+	"app/test"
+	"app/test/fixture"
+	"app/test/helper"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPagoRoutes(t *testing.T) {
+	helper.ClearAll(test.DB)
+	helper.InsertUser(test.DB, fixture.UserOne, fixture.UserTwo)
+
+	datosFiscales := registrarRFC(t, fixture.UserOne, "EKU9003173C9")
+	indexarPaquete(t, datosFiscales, sat.TipoSolicitudCFDI,
+		fixture.CFDIIngreso33, fixture.CFDIIngreso40, fixture.CFDIPago33, fixture.CFDIPago40)
+
+	userOneAccessToken, err := fixture.AccessToken(fixture.UserOne)
+	assert.Nil(t, err)
+
+	request := func(target, accessToken string, data interface{}) *http.Response {
+		request := httptest.NewRequest(http.MethodGet, target, nil)
+		request.Header.Set("Authorization", "Bearer "+accessToken)
+
+		apiResponse, err := test.App.Test(request, -1)
+		assert.Nil(t, err)
+
+		responseBody := &struct {
+			Data interface{} `json:"data"`
+		}{Data: data}
+		if apiResponse.StatusCode == http.StatusOK {
+			assert.Nil(t, json.NewDecoder(apiResponse.Body).Decode(responseBody))
+		}
+
+		return apiResponse
+	}
+
+	t.Run("should index a row per invoice paid", func(t *testing.T) {
+		var pagos []model.CFDIPago
+		assert.Nil(t, test.DB.Order("num_parcialidad asc").Find(&pagos).Error)
+
+		assert.Len(t, pagos, 2)
+		assert.Equal(t, "2b3c4d5e-6f7a-4b8c-9d0e-1f2a3b4c5d6e", pagos[0].CFDIUUID.String())
+		assert.Equal(t, "3f2a1b0c-9d8e-4f7a-8b6c-5d4e3f2a1b0c", pagos[0].IDDocumento.String())
+		assert.Equal(t, "5000.00", pagos[0].ImpPagado.String())
+		assert.Nil(t, pagos[0].EquivalenciaDR)
+		assert.Equal(t, "4d5e6f7a-8b9c-4d0e-8f1a-2b3c4d5e6f7a", pagos[1].CFDIUUID.String())
+		assert.Equal(t, "2533.34", pagos[1].ImpSaldoInsoluto.String())
+		assert.NotNil(t, pagos[1].EquivalenciaDR)
+	})
+
+	t.Run("GET /v1/cfdis/:uuid/pagos", func(t *testing.T) {
+		t.Run("should return 200 with the payments and the outstanding balance", func(t *testing.T) {
+			saldo := new(response.SaldoCFDI)
+			apiResponse := request("/v1/cfdis/"+uuidIngreso33+"/pagos", userOneAccessToken, saldo)
+
+			assert.Equal(t, http.StatusOK, apiResponse.StatusCode)
+			assert.Equal(t, "9533.34", saldo.Total.String())
+			assert.Equal(t, "7000.00", saldo.Pagado.String())
+			assert.Equal(t, "2533.34", saldo.Saldo.String())
+			assert.Len(t, saldo.Pagos, 2)
+			assert.Equal(t, 1, saldo.Pagos[0].NumParcialidad)
+			assert.Equal(t, 2, saldo.Pagos[1].NumParcialidad)
+		})
+
+		t.Run("should report PUE invoices as paid", func(t *testing.T) {
+			saldo := new(response.SaldoCFDI)
+			apiResponse := request("/v1/cfdis/"+uuidIngreso40+"/pagos", userOneAccessToken, saldo)
+
+			assert.Equal(t, http.StatusOK, apiResponse.StatusCode)
+			assert.Equal(t, "1624.00", saldo.Pagado.String())
+			assert.True(t, saldo.Saldo.IsZero())
+			assert.Empty(t, saldo.Pagos)
+		})
+
+		t.Run("should return 400 if the UUID is invalid", func(t *testing.T) {
+			apiResponse := request("/v1/cfdis/not-a-uuid/pagos", userOneAccessToken, nil)
+			assert.Equal(t, http.StatusBadRequest, apiResponse.StatusCode)
+		})
+
+		t.Run("should return 404 for CFDIs of RFCs the user didn't register", func(t *testing.T) {
+			userTwoAccessToken, err := fixture.AccessToken(fixture.UserTwo)
+			assert.Nil(t, err)
+
+			apiResponse := request("/v1/cfdis/"+uuidIngreso33+"/pagos", userTwoAccessToken, nil)
+			assert.Equal(t, http.StatusNotFound, apiResponse.StatusCode)
+		})
+	})
+
+	t.Run("GET /v1/cfdis/antiguedad-saldos", func(t *testing.T) {
+		t.Run("should only count the payments up to fecha_corte", func(t *testing.T) {
+			reporte := new(response.AntiguedadSaldos)
+			apiResponse := request("/v1/cfdis/antiguedad-saldos?fecha_corte=2022-04-30", userOneAccessToken, reporte)
+
+			assert.Equal(t, http.StatusOK, apiResponse.StatusCode)
+			assert.Equal(t, "2022-04-30", reporte.FechaCorte)
+			assert.Len(t, reporte.Documentos, 1)
+			assert.Equal(t, uuid.MustParse(uuidIngreso33), reporte.Documentos[0].UUID)
+			assert.Equal(t, "4533.34", reporte.Documentos[0].Saldo.String())
+			assert.Equal(t, 1, reporte.Documentos[0].Parcialidades)
+			assert.Equal(t, 51, reporte.Documentos[0].Dias)
+			assert.Equal(t, model.RangoDe31a60, reporte.Documentos[0].Rango)
+			assert.Len(t, reporte.Totales, 1)
+			assert.Equal(t, "MXN", reporte.Totales[0].Moneda)
+			assert.Equal(t, "4533.34", reporte.Totales[0].De31a60.String())
+			assert.Equal(t, "4533.34", reporte.Totales[0].Total.String())
+		})
+
+		t.Run("should age the balance left after every payment", func(t *testing.T) {
+			reporte := new(response.AntiguedadSaldos)
+			apiResponse := request("/v1/cfdis/antiguedad-saldos?fecha_corte=2023-03-01", userOneAccessToken, reporte)
+
+			assert.Equal(t, http.StatusOK, apiResponse.StatusCode)
+			assert.Len(t, reporte.Documentos, 1)
+			assert.Equal(t, "2533.34", reporte.Documentos[0].Saldo.String())
+			assert.Equal(t, model.RangoMasDe90, reporte.Documentos[0].Rango)
+			assert.Equal(t, "2533.34", reporte.Totales[0].MasDe90.String())
+		})
+
+		t.Run("should page the documents keeping the totals of all of them", func(t *testing.T) {
+			reporte := new(response.AntiguedadSaldos)
+			apiResponse := request("/v1/cfdis/antiguedad-saldos?fecha_corte=2023-03-01&limit=1&page=2", userOneAccessToken, reporte)
+
+			assert.Equal(t, http.StatusOK, apiResponse.StatusCode)
+			assert.Empty(t, reporte.Documentos)
+			assert.Equal(t, 2, reporte.Page)
+			assert.Equal(t, int64(1), reporte.TotalPages)
+			assert.Equal(t, int64(1), reporte.TotalResults)
+			assert.Len(t, reporte.Totales, 1)
+			assert.Equal(t, "2533.34", reporte.Totales[0].Total.String())
+			assert.Equal(t, 1, reporte.Totales[0].Documentos)
+		})
+
+		t.Run("should filter by rol", func(t *testing.T) {
+			reporte := new(response.AntiguedadSaldos)
+			apiResponse := request("/v1/cfdis/antiguedad-saldos?fecha_corte=2023-03-01&rol=emisor", userOneAccessToken, reporte)
+
+			assert.Equal(t, http.StatusOK, apiResponse.StatusCode)
+			assert.Empty(t, reporte.Documentos)
+
+			apiResponse = request("/v1/cfdis/antiguedad-saldos?fecha_corte=2023-03-01&rol=receptor", userOneAccessToken, reporte)
+
+			assert.Equal(t, http.StatusOK, apiResponse.StatusCode)
+			assert.Len(t, reporte.Documentos, 1)
+		})
+
+		t.Run("should not count payments of cancelled complements", func(t *testing.T) {
+			err := test.DB.Model(&model.CFDI{}).Where("uuid = ?", "4D5E6F7A-8B9C-4D0E-8F1A-2B3C4D5E6F7A").
+				Update("estado", model.EstadoCFDICancelado).Error
+			assert.Nil(t, err)
+
+			reporte := new(response.AntiguedadSaldos)
+			apiResponse := request("/v1/cfdis/antiguedad-saldos?fecha_corte=2023-03-01", userOneAccessToken, reporte)
+
+			assert.Equal(t, http.StatusOK, apiResponse.StatusCode)
+			assert.Len(t, reporte.Documentos, 1)
+			assert.Equal(t, "4533.34", reporte.Documentos[0].Saldo.String())
+		})
+
+		t.Run("should return 400 if a filter is invalid", func(t *testing.T) {
+			apiResponse := request("/v1/cfdis/antiguedad-saldos?fecha_corte=2023-3-1", userOneAccessToken, nil)
+			assert.Equal(t, http.StatusBadRequest, apiResponse.StatusCode)
+
+			apiResponse = request("/v1/cfdis/antiguedad-saldos?rol=tercero", userOneAccessToken, nil)
+			assert.Equal(t, http.StatusBadRequest, apiResponse.StatusCode)
+
+			apiResponse = request("/v1/cfdis/antiguedad-saldos?limit=101", userOneAccessToken, nil)
+			assert.Equal(t, http.StatusBadRequest, apiResponse.StatusCode)
+		})
+
+		t.Run("should not include other users RFCs", func(t *testing.T) {
+			userTwoAccessToken, err := fixture.AccessToken(fixture.UserTwo)
+			assert.Nil(t, err)
+
+			reporte := new(response.AntiguedadSaldos)
+			apiResponse := request("/v1/cfdis/antiguedad-saldos?fecha_corte=2023-03-01", userTwoAccessToken, reporte)
+
+			assert.Equal(t, http.StatusOK, apiResponse.StatusCode)
+			assert.Empty(t, reporte.Documentos)
+		})
+	})
+}
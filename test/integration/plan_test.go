@@ -0,0 +1,184 @@
+package integration
+
+import (
+	"app/src/cfdi"
+	"app/src/model"
+	"app/src/response"
+	"app/test"
+	"app/test/fixture"
+	"app/test/helper"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type planResponse struct {
+	Code    int                   `json:"code"`
+	Status  string                `json:"status"`
+	Message string                `json:"message"`
+	Data    model.PlanSuscripcion `json:"data"`
+}
+
+func TestPlanRoutes(t *testing.T) {
+	planRequest := func(method, target, accessToken, body string) *http.Response {
+		request := httptest.NewRequest(method, target, strings.NewReader(body))
+		request.Header.Set("Content-Type", "application/json")
+		request.Header.Set("Authorization", "Bearer "+accessToken)
+
+		apiResponse, err := test.App.Test(request)
+		assert.Nil(t, err)
+
+		return apiResponse
+	}
+
+	t.Run("POST /v1/planes", func(t *testing.T) {
+		t.Run("should return 201 and create an inactive plan", func(t *testing.T) {
+			helper.ClearAll(test.DB)
+			helper.InsertUser(test.DB, fixture.Admin)
+
+			adminAccessToken, err := fixture.AccessToken(fixture.Admin)
+			assert.Nil(t, err)
+
+			apiResponse := planRequest(http.MethodPost, "/v1/planes", adminAccessToken,
+				`{"nombre":"Pro","descripcion":"100 descargas","limite_descargas_mensuales":100,"precio":999.50}`)
+
+			responseBody := new(planResponse)
+			err = json.NewDecoder(apiResponse.Body).Decode(responseBody)
+			assert.Nil(t, err)
+
+			assert.Equal(t, http.StatusCreated, apiResponse.StatusCode)
+			assert.Equal(t, "Pro", responseBody.Data.Nombre)
+			assert.Equal(t, "999.50", responseBody.Data.Precio.String())
+			assert.False(t, responseBody.Data.Activo)
+
+			apiResponse = planRequest(http.MethodPost, "/v1/planes", adminAccessToken,
+				`{"nombre":"Pro","descripcion":"Otro","limite_descargas_mensuales":10,"precio":1}`)
+			assert.Equal(t, http.StatusConflict, apiResponse.StatusCode)
+		})
+
+		t.Run("should return 400 for an invalid price or limit", func(t *testing.T) {
+			helper.ClearAll(test.DB)
+			helper.InsertUser(test.DB, fixture.Admin)
+
+			adminAccessToken, err := fixture.AccessToken(fixture.Admin)
+			assert.Nil(t, err)
+
+			apiResponse := planRequest(http.MethodPost, "/v1/planes", adminAccessToken,
+				`{"nombre":"Pro","descripcion":"100 descargas","limite_descargas_mensuales":0,"precio":-1}`)
+			assert.Equal(t, http.StatusBadRequest, apiResponse.StatusCode)
+		})
+
+		t.Run("should return 403 for regular users", func(t *testing.T) {
+			helper.ClearAll(test.DB)
+			helper.InsertUser(test.DB, fixture.UserOne)
+
+			userOneAccessToken, err := fixture.AccessToken(fixture.UserOne)
+			assert.Nil(t, err)
+
+			apiResponse := planRequest(http.MethodPost, "/v1/planes", userOneAccessToken,
+				`{"nombre":"Pro","descripcion":"100 descargas","limite_descargas_mensuales":100,"precio":999.50}`)
+			assert.Equal(t, http.StatusForbidden, apiResponse.StatusCode)
+		})
+	})
+
+	t.Run("GET /v1/planes", func(t *testing.T) {
+		t.Run("should return 200 and filter by active flag", func(t *testing.T) {
+			helper.ClearAll(test.DB)
+			helper.InsertUser(test.DB, fixture.Admin)
+			helper.InsertPlan(test.DB, fixture.PlanBasico, &model.PlanSuscripcion{
+				Nombre:                   "Legacy",
+				Descripcion:              "Plan descontinuado",
+				LimiteDescargasMensuales: 5,
+				Precio:                   cfdi.NewDecimal(99, 0),
+			})
+
+			adminAccessToken, err := fixture.AccessToken(fixture.Admin)
+			assert.Nil(t, err)
+
+			apiResponse := planRequest(http.MethodGet, "/v1/planes?activo=true", adminAccessToken, "")
+
+			responseBody := new(response.SuccessWithPaginate[model.PlanSuscripcion])
+			err = json.NewDecoder(apiResponse.Body).Decode(responseBody)
+			assert.Nil(t, err)
+
+			assert.Equal(t, http.StatusOK, apiResponse.StatusCode)
+			assert.Equal(t, int64(1), responseBody.TotalResults)
+			assert.Equal(t, fixture.PlanBasico.Nombre, responseBody.Results[0].Nombre)
+		})
+	})
+
+	t.Run("PATCH /v1/planes/:planId", func(t *testing.T) {
+		t.Run("should update the plan and deactivate it", func(t *testing.T) {
+			helper.ClearAll(test.DB)
+			helper.InsertUser(test.DB, fixture.Admin)
+			helper.InsertPlan(test.DB, fixture.PlanBasico)
+
+			adminAccessToken, err := fixture.AccessToken(fixture.Admin)
+			assert.Nil(t, err)
+
+			target := "/v1/planes/" + fixture.PlanBasico.ID.String()
+
+			apiResponse := planRequest(http.MethodPatch, target, adminAccessToken, `{"precio":249.99}`)
+
+			responseBody := new(planResponse)
+			err = json.NewDecoder(apiResponse.Body).Decode(responseBody)
+			assert.Nil(t, err)
+
+			assert.Equal(t, http.StatusOK, apiResponse.StatusCode)
+			assert.Equal(t, "249.99", responseBody.Data.Precio.String())
+			assert.Equal(t, fixture.PlanBasico.LimiteDescargasMensuales, responseBody.Data.LimiteDescargasMensuales)
+
+			apiResponse = planRequest(http.MethodPost, target+"/desactivar", adminAccessToken, "")
+
+			responseBody = new(planResponse)
+			err = json.NewDecoder(apiResponse.Body).Decode(responseBody)
+			assert.Nil(t, err)
+
+			assert.Equal(t, http.StatusOK, apiResponse.StatusCode)
+			assert.False(t, responseBody.Data.Activo)
+		})
+	})
+
+	t.Run("DELETE /v1/planes/:planId", func(t *testing.T) {
+		t.Run("should return 409 while the plan has active subscriptions", func(t *testing.T) {
+			helper.ClearAll(test.DB)
+			helper.InsertUser(test.DB, fixture.Admin, fixture.UserOne)
+			helper.InsertPlan(test.DB, fixture.PlanBasico)
+
+			_, err := helper.InsertSuscripcion(test.DB, fixture.UserOne.ID, fixture.PlanBasico, 0)
+			assert.Nil(t, err)
+
+			adminAccessToken, err := fixture.AccessToken(fixture.Admin)
+			assert.Nil(t, err)
+
+			apiResponse := planRequest(http.MethodDelete, "/v1/planes/"+fixture.PlanBasico.ID.String(), adminAccessToken, "")
+			assert.Equal(t, http.StatusConflict, apiResponse.StatusCode)
+		})
+
+		t.Run("should soft delete a plan without subscriptions", func(t *testing.T) {
+			helper.ClearAll(test.DB)
+			helper.InsertUser(test.DB, fixture.Admin)
+			helper.InsertPlan(test.DB, fixture.PlanBasico)
+
+			adminAccessToken, err := fixture.AccessToken(fixture.Admin)
+			assert.Nil(t, err)
+
+			target := "/v1/planes/" + fixture.PlanBasico.ID.String()
+
+			apiResponse := planRequest(http.MethodDelete, target, adminAccessToken, "")
+			assert.Equal(t, http.StatusOK, apiResponse.StatusCode)
+
+			apiResponse = planRequest(http.MethodGet, target, adminAccessToken, "")
+			assert.Equal(t, http.StatusNotFound, apiResponse.StatusCode)
+
+			var count int64
+			err = test.DB.Unscoped().Model(&model.PlanSuscripcion{}).Where("id = ?", fixture.PlanBasico.ID).Count(&count).Error
+			assert.Nil(t, err)
+			assert.Equal(t, int64(1), count)
+		})
+	})
+}
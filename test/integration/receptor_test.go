@@ -0,0 +1,213 @@
+package integration
+
+import (
+	"app/src/catalogo"
+	"app/src/cfdi"
+	"app/src/model"
+	"app/src/response"
+	"app/src/sat"
+	"app/test"
+	"app/test/fixture"
+	"app/test/helper"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	uuidNomina40 = "9E8D7C6B-5A4F-4E3D-8C2B-1A0F9E8D7C6B"
+	uuidPago40   = "4D5E6F7A-8B9C-4D0E-8F1A-2B3C4D5E6F7A"
+)
+
+func TestReceptorRoutes(t *testing.T) {
+	helper.ClearAll(test.DB)
+	helper.InsertUser(test.DB, fixture.UserOne)
+
+	importarCatalogos(t)
+
+	datosFiscales := registrarRFC(t, fixture.UserOne, "EKU9003173C9")
+	indexarPaquete(t, datosFiscales, sat.TipoSolicitudCFDI,
+		fixture.CFDIIngreso40, fixture.CFDINomina40, fixture.CFDIPago40, fixture.CFDIIngreso33)
+
+	accessToken, err := fixture.AccessToken(fixture.UserOne)
+	assert.Nil(t, err)
+
+	t.Run("GET /v1/cfdis/validacion-receptor", func(t *testing.T) {
+		request := func(target string) (*http.Response, *response.SuccessWithPaginate[response.ValidacionReceptor]) {
+			request := httptest.NewRequest(http.MethodGet, target, nil)
+			request.Header.Set("Authorization", "Bearer "+accessToken)
+
+			apiResponse, err := test.App.Test(request, -1)
+			assert.Nil(t, err)
+
+			responseBody := new(response.SuccessWithPaginate[response.ValidacionReceptor])
+			if apiResponse.StatusCode == http.StatusOK {
+				assert.Nil(t, json.NewDecoder(apiResponse.Body).Decode(responseBody))
+			}
+
+			return apiResponse, responseBody
+		}
+
+		t.Run("should report the incidencias of each CFDI 4.0", func(t *testing.T) {
+			assert.Nil(t, test.DB.Model(&model.CFDI{}).Where("uuid = ?", uuidNomina40).
+				Update("uso_cfdi", "G03").Error)
+
+			apiResponse, responseBody := request("/v1/cfdis/validacion-receptor")
+
+			assert.Equal(t, http.StatusOK, apiResponse.StatusCode)
+			assert.Equal(t, int64(3), responseBody.TotalResults)
+
+			nomina := responseBody.Results[0]
+			assert.Equal(t, uuidNomina40, strings.ToUpper(nomina.UUID.String()))
+			assert.False(t, nomina.Valido)
+			assert.Len(t, nomina.Incidencias, 1)
+			assert.Equal(t, cfdi.IncidenciaUsoCFDITipo, nomina.Incidencias[0].Tipo)
+
+			for _, validacion := range responseBody.Results[1:] {
+				assert.True(t, validacion.Valido, validacion.UUID.String())
+				assert.Empty(t, validacion.Incidencias)
+				assert.Empty(t, validacion.CatalogosFaltantes)
+			}
+
+			assert.Equal(t, uuidIngreso40, strings.ToUpper(responseBody.Results[1].UUID.String()))
+			assert.Equal(t, uuidPago40, strings.ToUpper(responseBody.Results[2].UUID.String()))
+		})
+
+		t.Run("should filter by date and RFC", func(t *testing.T) {
+			_, responseBody := request("/v1/cfdis/validacion-receptor?fecha_inicial=2024-01-01")
+			assert.Equal(t, int64(2), responseBody.TotalResults)
+
+			_, responseBody = request("/v1/cfdis/validacion-receptor?rfc=CACX7605101P8&limit=1")
+			assert.Equal(t, int64(2), responseBody.TotalResults)
+			assert.Len(t, responseBody.Results, 1)
+		})
+
+		t.Run("should return 400 for invalid parameters", func(t *testing.T) {
+			apiResponse, _ := request("/v1/cfdis/validacion-receptor?fecha_inicial=2024-02-01&fecha_final=2024-01-01")
+			assert.Equal(t, http.StatusBadRequest, apiResponse.StatusCode)
+
+			apiResponse, _ = request("/v1/cfdis/validacion-receptor?rfc=INVALIDO")
+			assert.Equal(t, http.StatusBadRequest, apiResponse.StatusCode)
+		})
+	})
+
+	t.Run("POST /v1/cfdis/validacion-receptor", func(t *testing.T) {
+		ingreso, err := fixture.CFDI(fixture.CFDIIngreso40)
+		assert.Nil(t, err)
+
+		// validar posts the ingreso_40 fixture with its Receptor replaced.
+		validar := func(receptor, accessToken string) (*http.Response, *response.ValidacionReceptor) {
+			xml := string(ingreso)
+			if receptor != "" {
+				inicio := strings.Index(xml, "<cfdi:Receptor ")
+				fin := inicio + strings.Index(xml[inicio:], "/>") + 2
+				xml = xml[:inicio] + receptor + xml[fin:]
+			}
+
+			request := httptest.NewRequest(http.MethodPost, "/v1/cfdis/validacion-receptor", strings.NewReader(xml))
+			request.Header.Set("Content-Type", "application/xml")
+			request.Header.Set("Authorization", "Bearer "+accessToken)
+
+			apiResponse, err := test.App.Test(request, -1)
+			assert.Nil(t, err)
+
+			responseBody := &struct {
+				Data response.ValidacionReceptor `json:"data"`
+			}{}
+			if apiResponse.StatusCode == http.StatusOK {
+				assert.Nil(t, json.NewDecoder(apiResponse.Body).Decode(responseBody))
+			}
+
+			return apiResponse, &responseBody.Data
+		}
+
+		t.Run("should accept a valid receptor", func(t *testing.T) {
+			apiResponse, validacion := validar("", accessToken)
+
+			assert.Equal(t, http.StatusOK, apiResponse.StatusCode)
+			assert.Equal(t, uuidIngreso40, strings.ToUpper(validacion.UUID.String()))
+			assert.Equal(t, "XIA190128J61", validacion.RFCReceptor)
+			assert.True(t, validacion.Valido)
+			assert.Empty(t, validacion.Incidencias)
+		})
+
+		t.Run("should check the UsoCFDI against the persona and the régimen", func(t *testing.T) {
+			_, validacion := validar(`<cfdi:Receptor Rfc="XIA190128J61" Nombre="XENON INDUSTRIAL ARTICLES" `+
+				`DomicilioFiscalReceptor="76343" RegimenFiscalReceptor="601" UsoCFDI="D01"/>`, accessToken)
+
+			assert.False(t, validacion.Valido)
+			assert.Len(t, validacion.Incidencias, 2)
+			assertIncidencia(t, validacion.Incidencias, cfdi.IncidenciaTipoPersona, "Receptor@UsoCFDI")
+			assertIncidencia(t, validacion.Incidencias, cfdi.IncidenciaUsoCFDIRegimen, "Receptor@UsoCFDI")
+		})
+
+		t.Run("should check the régimen of personas morales", func(t *testing.T) {
+			_, validacion := validar(`<cfdi:Receptor Rfc="XIA190128J61" Nombre="XENON INDUSTRIAL ARTICLES" `+
+				`DomicilioFiscalReceptor="76343" RegimenFiscalReceptor="605" UsoCFDI="G03"/>`, accessToken)
+
+			assert.Len(t, validacion.Incidencias, 2)
+			assertIncidencia(t, validacion.Incidencias, cfdi.IncidenciaTipoPersona, "Receptor@RegimenFiscalReceptor")
+			assertIncidencia(t, validacion.Incidencias, cfdi.IncidenciaUsoCFDIRegimen, "Receptor@UsoCFDI")
+		})
+
+		t.Run("should check the claves exist and were in effect", func(t *testing.T) {
+			_, validacion := validar(`<cfdi:Receptor Rfc="XIA190128J61" Nombre="XENON INDUSTRIAL ARTICLES" `+
+				`DomicilioFiscalReceptor="99999" RegimenFiscalReceptor="609" UsoCFDI="P01"/>`, accessToken)
+
+			assert.Len(t, validacion.Incidencias, 3)
+			assertIncidencia(t, validacion.Incidencias, cfdi.IncidenciaClaveInvalida, "Receptor@DomicilioFiscalReceptor")
+			assertIncidencia(t, validacion.Incidencias, cfdi.IncidenciaClaveNoVigente, "Receptor@RegimenFiscalReceptor")
+			assertIncidencia(t, validacion.Incidencias, cfdi.IncidenciaClaveNoVigente, "Receptor@UsoCFDI")
+			assert.Contains(t, validacion.Incidencias[0].Mensaje, catalogo.CodigoPostal)
+		})
+
+		t.Run("should check the generic RFCs", func(t *testing.T) {
+			_, validacion := validar(`<cfdi:Receptor Rfc="XAXX010101000" Nombre="PUBLICO EN GENERAL" `+
+				`DomicilioFiscalReceptor="26015" RegimenFiscalReceptor="616" UsoCFDI="S01"/>`, accessToken)
+			assert.True(t, validacion.Valido)
+
+			_, validacion = validar(`<cfdi:Receptor Rfc="XAXX010101000" Nombre="PUBLICO EN GENERAL" `+
+				`DomicilioFiscalReceptor="76343" RegimenFiscalReceptor="616" UsoCFDI="G03"/>`, accessToken)
+			assert.Len(t, validacion.Incidencias, 2)
+			assertIncidencia(t, validacion.Incidencias, cfdi.IncidenciaRFCGenerico, "Receptor@UsoCFDI")
+			assertIncidencia(t, validacion.Incidencias, cfdi.IncidenciaRFCGenerico, "Receptor@DomicilioFiscalReceptor")
+		})
+
+		t.Run("should return 400 for invalid XML and 422 for CFDI 3.3", func(t *testing.T) {
+			apiResponse, _ := validar("<cfdi:Receptor", accessToken)
+			assert.Equal(t, http.StatusBadRequest, apiResponse.StatusCode)
+
+			ingreso33, err := fixture.CFDI(fixture.CFDIIngreso33)
+			assert.Nil(t, err)
+
+			request := httptest.NewRequest(http.MethodPost, "/v1/cfdis/validacion-receptor", strings.NewReader(string(ingreso33)))
+			request.Header.Set("Authorization", "Bearer "+accessToken)
+
+			apiResponse, err = test.App.Test(request, -1)
+			assert.Nil(t, err)
+			assert.Equal(t, http.StatusUnprocessableEntity, apiResponse.StatusCode)
+		})
+
+		t.Run("should return 401 without a token", func(t *testing.T) {
+			apiResponse, _ := validar("", "")
+			assert.Equal(t, http.StatusUnauthorized, apiResponse.StatusCode)
+		})
+	})
+}
+
+// assertIncidencia checks the incidencias include one of tipo at nodo.
+func assertIncidencia(t *testing.T, incidencias []cfdi.Incidencia, tipo, nodo string) {
+	t.Helper()
+
+	for _, incidencia := range incidencias {
+		if incidencia.Tipo == tipo && incidencia.Nodo == nodo {
+			return
+		}
+	}
+
+	t.Errorf("no %s incidencia at %s in %+v", tipo, nodo, incidencias)
+}
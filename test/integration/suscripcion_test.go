@@ -0,0 +1,224 @@
+package integration
+
+import (
+	"app/src/model"
+	"app/src/response"
+	"app/test"
+	"app/test/fixture"
+	"app/test/helper"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type suscripcionResponse struct {
+	Code    int                  `json:"code"`
+	Status  string               `json:"status"`
+	Message string               `json:"message"`
+	Data    response.Suscripcion `json:"data"`
+}
+
+func suscripcionRequest(t *testing.T, method, target, accessToken, body string) *http.Response {
+	request := httptest.NewRequest(method, target, strings.NewReader(body))
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Authorization", "Bearer "+accessToken)
+
+	apiResponse, err := test.App.Test(request)
+	assert.Nil(t, err)
+
+	return apiResponse
+}
+
+func TestSuscripcionRoutes(t *testing.T) {
+	t.Run("GET /v1/suscripciones/me", func(t *testing.T) {
+		t.Run("should return 200 and the remaining quota", func(t *testing.T) {
+			helper.ClearAll(test.DB)
+			helper.InsertUser(test.DB, fixture.UserOne)
+			helper.InsertPlan(test.DB, fixture.PlanBasico)
+
+			_, err := helper.InsertSuscripcion(test.DB, fixture.UserOne.ID, fixture.PlanBasico, 3)
+			assert.Nil(t, err)
+
+			userOneAccessToken, err := fixture.AccessToken(fixture.UserOne)
+			assert.Nil(t, err)
+
+			request := httptest.NewRequest(http.MethodGet, "/v1/suscripciones/me", nil)
+			request.Header.Set("Authorization", "Bearer "+userOneAccessToken)
+
+			apiResponse, err := test.App.Test(request)
+			assert.Nil(t, err)
+
+			responseBody := new(suscripcionResponse)
+			err = json.NewDecoder(apiResponse.Body).Decode(responseBody)
+			assert.Nil(t, err)
+
+			assert.Equal(t, http.StatusOK, apiResponse.StatusCode)
+			assert.Equal(t, 7, responseBody.Data.DescargasRestantes)
+			assert.Equal(t, fixture.PlanBasico.Nombre, responseBody.Data.Plan.Nombre)
+			assert.Equal(t, "10", apiResponse.Header.Get("X-Quota-Limit"))
+			assert.Equal(t, "7", apiResponse.Header.Get("X-Quota-Remaining"))
+			assert.Equal(t, responseBody.Data.ProximoReinicio, apiResponse.Header.Get("X-Quota-Reset"))
+		})
+
+		t.Run("should return 404 without an active subscription", func(t *testing.T) {
+			helper.ClearAll(test.DB)
+			helper.InsertUser(test.DB, fixture.UserOne)
+
+			userOneAccessToken, err := fixture.AccessToken(fixture.UserOne)
+			assert.Nil(t, err)
+
+			request := httptest.NewRequest(http.MethodGet, "/v1/suscripciones/me", nil)
+			request.Header.Set("Authorization", "Bearer "+userOneAccessToken)
+
+			apiResponse, err := test.App.Test(request)
+			assert.Nil(t, err)
+			assert.Equal(t, http.StatusNotFound, apiResponse.StatusCode)
+		})
+	})
+
+	t.Run("POST /v1/suscripciones", func(t *testing.T) {
+		t.Run("should return 201 and subscribe the user to an active plan", func(t *testing.T) {
+			helper.ClearAll(test.DB)
+			helper.InsertUser(test.DB, fixture.UserOne)
+			helper.InsertPlan(test.DB, fixture.PlanBasico)
+
+			userOneAccessToken, err := fixture.AccessToken(fixture.UserOne)
+			assert.Nil(t, err)
+
+			body := `{"plan_id":"` + fixture.PlanBasico.ID.String() + `","meses":3}`
+
+			apiResponse := suscripcionRequest(t, http.MethodPost, "/v1/suscripciones", userOneAccessToken, body)
+
+			responseBody := new(suscripcionResponse)
+			err = json.NewDecoder(apiResponse.Body).Decode(responseBody)
+			assert.Nil(t, err)
+
+			assert.Equal(t, http.StatusCreated, apiResponse.StatusCode)
+			assert.Equal(t, model.EstatusSuscripcionActivo, responseBody.Data.Estatus)
+			assert.Equal(t, fixture.PlanBasico.LimiteDescargasMensuales, responseBody.Data.DescargasRestantes)
+			assert.Equal(t, model.FinVigencia(responseBody.Data.FechaInicio, 3), responseBody.Data.FechaFin)
+
+			apiResponse = suscripcionRequest(t, http.MethodPost, "/v1/suscripciones", userOneAccessToken, body)
+			assert.Equal(t, http.StatusConflict, apiResponse.StatusCode)
+		})
+
+		t.Run("should return 422 for an inactive plan", func(t *testing.T) {
+			helper.ClearAll(test.DB)
+			helper.InsertUser(test.DB, fixture.UserOne)
+			helper.InsertPlan(test.DB, fixture.PlanBasico)
+
+			err := test.DB.Model(fixture.PlanBasico).Update("activo", false).Error
+			assert.Nil(t, err)
+
+			userOneAccessToken, err := fixture.AccessToken(fixture.UserOne)
+			assert.Nil(t, err)
+
+			apiResponse := suscripcionRequest(t, http.MethodPost, "/v1/suscripciones", userOneAccessToken,
+				`{"plan_id":"`+fixture.PlanBasico.ID.String()+`"}`)
+			assert.Equal(t, http.StatusUnprocessableEntity, apiResponse.StatusCode)
+		})
+	})
+
+	t.Run("POST /v1/suscripciones/me/renovar", func(t *testing.T) {
+		t.Run("should extend the active subscription", func(t *testing.T) {
+			helper.ClearAll(test.DB)
+			helper.InsertUser(test.DB, fixture.UserOne)
+			helper.InsertPlan(test.DB, fixture.PlanBasico)
+
+			suscripcion, err := helper.InsertSuscripcion(test.DB, fixture.UserOne.ID, fixture.PlanBasico, 2)
+			assert.Nil(t, err)
+
+			userOneAccessToken, err := fixture.AccessToken(fixture.UserOne)
+			assert.Nil(t, err)
+
+			apiResponse := suscripcionRequest(t, http.MethodPost, "/v1/suscripciones/me/renovar", userOneAccessToken, `{"meses":2}`)
+			assert.Equal(t, http.StatusOK, apiResponse.StatusCode)
+
+			stored, err := helper.GetSuscripcionByID(test.DB, suscripcion.ID)
+			assert.Nil(t, err)
+
+			suscripcion.Extender(2)
+			assert.True(t, suscripcion.FechaFin.Equal(stored.FechaFin))
+			assert.Equal(t, 2, stored.DescargasUsadas)
+		})
+	})
+
+	t.Run("POST /v1/suscripciones/me/cancelar", func(t *testing.T) {
+		t.Run("should cancel the subscription and block new downloads", func(t *testing.T) {
+			helper.ClearAll(test.DB)
+			helper.InsertUser(test.DB, fixture.UserOne)
+			helper.InsertPlan(test.DB, fixture.PlanBasico)
+
+			suscripcion, err := helper.InsertSuscripcion(test.DB, fixture.UserOne.ID, fixture.PlanBasico, 0)
+			assert.Nil(t, err)
+
+			userOneAccessToken, err := fixture.AccessToken(fixture.UserOne)
+			assert.Nil(t, err)
+
+			apiResponse := suscripcionRequest(t, http.MethodPost, "/v1/suscripciones/me/cancelar", userOneAccessToken, "")
+			assert.Equal(t, http.StatusOK, apiResponse.StatusCode)
+
+			stored, err := helper.GetSuscripcionByID(test.DB, suscripcion.ID)
+			assert.Nil(t, err)
+			assert.Equal(t, model.EstatusSuscripcionCancelado, stored.Estatus)
+			assert.NotNil(t, stored.CanceladaAt)
+
+			apiResponse = suscripcionRequest(t, http.MethodPost, "/v1/descargas", userOneAccessToken, `{}`)
+			assert.Equal(t, http.StatusPaymentRequired, apiResponse.StatusCode)
+		})
+	})
+
+	t.Run("POST /v1/descargas quota", func(t *testing.T) {
+		t.Run("should return 402 without an active subscription", func(t *testing.T) {
+			helper.ClearAll(test.DB)
+			helper.InsertUser(test.DB, fixture.UserOne)
+
+			userOneAccessToken, err := fixture.AccessToken(fixture.UserOne)
+			assert.Nil(t, err)
+
+			apiResponse := suscripcionRequest(t, http.MethodPost, "/v1/descargas", userOneAccessToken, `{}`)
+			assert.Equal(t, http.StatusPaymentRequired, apiResponse.StatusCode)
+		})
+
+		t.Run("should return 429 when the monthly quota is exhausted", func(t *testing.T) {
+			helper.ClearAll(test.DB)
+			helper.InsertUser(test.DB, fixture.UserOne)
+			helper.InsertPlan(test.DB, fixture.PlanBasico)
+
+			_, err := helper.InsertSuscripcion(test.DB, fixture.UserOne.ID, fixture.PlanBasico, 10)
+			assert.Nil(t, err)
+
+			userOneAccessToken, err := fixture.AccessToken(fixture.UserOne)
+			assert.Nil(t, err)
+
+			apiResponse := suscripcionRequest(t, http.MethodPost, "/v1/descargas", userOneAccessToken, `{}`)
+			assert.Equal(t, http.StatusTooManyRequests, apiResponse.StatusCode)
+			assert.Equal(t, "0", apiResponse.Header.Get("X-Quota-Remaining"))
+		})
+
+		t.Run("should give the download back when the request fails", func(t *testing.T) {
+			helper.ClearAll(test.DB)
+			helper.InsertUser(test.DB, fixture.UserOne)
+			helper.InsertPlan(test.DB, fixture.PlanBasico)
+
+			suscripcion, err := helper.InsertSuscripcion(test.DB, fixture.UserOne.ID, fixture.PlanBasico, 9)
+			assert.Nil(t, err)
+
+			userOneAccessToken, err := fixture.AccessToken(fixture.UserOne)
+			assert.Nil(t, err)
+
+			apiResponse := suscripcionRequest(t, http.MethodPost, "/v1/descargas", userOneAccessToken, `{"tipo_descarga":"invalid"}`)
+			assert.Equal(t, http.StatusBadRequest, apiResponse.StatusCode)
+			assert.Equal(t, "1", apiResponse.Header.Get("X-Quota-Remaining"))
+
+			stored, err := helper.GetSuscripcionByID(test.DB, suscripcion.ID)
+			assert.Nil(t, err)
+			assert.Equal(t, 9, stored.DescargasUsadas)
+			assert.Equal(t, model.EstatusSuscripcionActivo, stored.Estatus)
+		})
+	})
+}
@@ -0,0 +1,101 @@
+package integration
+
+import (
+	"app/src/model"
+	"app/src/service"
+	"app/test"
+	"app/test/fixture"
+	"app/test/helper"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// emailRecorder is an EmailService that keeps the expiry notices instead of
+// sending them.
+type emailRecorder struct {
+	service.EmailService
+	vencimientos []string
+}
+
+func (e *emailRecorder) SendVencimientoSuscripcionEmail(to, _ string, _ time.Time) error {
+	e.vencimientos = append(e.vencimientos, to)
+	return nil
+}
+
+func TestVencimientoService(t *testing.T) {
+	ctx := context.Background()
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+
+	t.Run("should expire subscriptions past fecha_fin", func(t *testing.T) {
+		helper.ClearAll(test.DB)
+		helper.InsertUser(test.DB, fixture.UserOne)
+		helper.InsertPlan(test.DB, fixture.PlanBasico)
+
+		suscripcion, err := helper.InsertSuscripcion(test.DB, fixture.UserOne.ID, fixture.PlanBasico, 0)
+		assert.Nil(t, err)
+
+		err = test.DB.Model(suscripcion).Update("fecha_fin", today.AddDate(0, 0, -1)).Error
+		assert.Nil(t, err)
+
+		expiradas, err := service.NewVencimientoService(test.DB, &emailRecorder{}, 7).ExpirarSuscripciones(ctx)
+		assert.Nil(t, err)
+		assert.Equal(t, 1, expiradas)
+
+		stored, err := helper.GetSuscripcionByID(test.DB, suscripcion.ID)
+		assert.Nil(t, err)
+		assert.Equal(t, model.EstatusSuscripcionExpirado, stored.Estatus)
+	})
+
+	t.Run("should reset the counter when a new period starts", func(t *testing.T) {
+		helper.ClearAll(test.DB)
+		helper.InsertUser(test.DB, fixture.UserOne)
+		helper.InsertPlan(test.DB, fixture.PlanBasico)
+
+		suscripcion, err := helper.InsertSuscripcion(test.DB, fixture.UserOne.ID, fixture.PlanBasico, 8)
+		assert.Nil(t, err)
+
+		inicio := today.AddDate(0, -1, -3)
+		err = test.DB.Model(suscripcion).Updates(map[string]interface{}{
+			"fecha_inicio":   inicio,
+			"periodo_inicio": inicio,
+		}).Error
+		assert.Nil(t, err)
+
+		reiniciadas, err := service.NewVencimientoService(test.DB, &emailRecorder{}, 7).ReiniciarPeriodos(ctx)
+		assert.Nil(t, err)
+		assert.Equal(t, 1, reiniciadas)
+
+		stored, err := helper.GetSuscripcionByID(test.DB, suscripcion.ID)
+		assert.Nil(t, err)
+		assert.Equal(t, 0, stored.DescargasUsadas)
+		assert.True(t, stored.PeriodoActual(today).Equal(stored.PeriodoInicio))
+	})
+
+	t.Run("should email users once before the subscription expires", func(t *testing.T) {
+		helper.ClearAll(test.DB)
+		helper.InsertUser(test.DB, fixture.UserOne)
+		helper.InsertPlan(test.DB, fixture.PlanBasico)
+
+		suscripcion, err := helper.InsertSuscripcion(test.DB, fixture.UserOne.ID, fixture.PlanBasico, 0)
+		assert.Nil(t, err)
+
+		err = test.DB.Model(suscripcion).Update("fecha_fin", today.AddDate(0, 0, 3)).Error
+		assert.Nil(t, err)
+
+		emails := &emailRecorder{}
+		vencimientoService := service.NewVencimientoService(test.DB, emails, 7)
+
+		avisadas, err := vencimientoService.AvisarVencimientos(ctx)
+		assert.Nil(t, err)
+		assert.Equal(t, 1, avisadas)
+
+		avisadas, err = vencimientoService.AvisarVencimientos(ctx)
+		assert.Nil(t, err)
+		assert.Equal(t, 0, avisadas)
+
+		assert.Equal(t, []string{fixture.UserOne.Email}, emails.vencimientos)
+	})
+}
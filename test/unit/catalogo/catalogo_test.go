@@ -0,0 +1,139 @@
+package catalogo_test
+
+import (
+	"app/src/catalogo"
+	"app/test/fixture"
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func leerFixture(t *testing.T, name string) (catalogo.Catalogo, []catalogo.Registro) {
+	t.Helper()
+
+	data, err := fixture.Catalogo(name)
+	if err != nil {
+		t.Fatalf("read fixture %s: %v", name, err)
+	}
+
+	definicion, registros, err := catalogo.LeerCSV(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("read catalog %s: %v", name, err)
+	}
+
+	return definicion, registros
+}
+
+func TestLeerCSV(t *testing.T) {
+	t.Run("skips the title rows and keeps the extra columns", func(t *testing.T) {
+		definicion, registros := leerFixture(t, fixture.CatalogoUsoCFDI)
+
+		assert.Equal(t, catalogo.UsoCFDI, definicion.Nombre)
+		assert.Equal(t, "uso-cfdi", definicion.Slug)
+		assert.Len(t, registros, 9)
+
+		d01 := registros[4]
+		assert.Equal(t, "D01", d01.Clave)
+		assert.Equal(t, "Honorarios médicos, dentales y gastos hospitalarios.", d01.Descripcion)
+		assert.Equal(t, time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC), *d01.FechaInicioVigencia)
+		assert.Nil(t, d01.FechaFinVigencia)
+		assert.Equal(t, map[string]string{
+			"aplica_para_tipo_persona_fisica": "Sí",
+			"aplica_para_tipo_persona_moral":  "No",
+			"regimen_fiscal_receptor":         "605, 606, 608, 611, 612, 614, 607, 615, 625",
+		}, d01.Atributos)
+
+		p01 := registros[8]
+		assert.Equal(t, time.Date(2022, 6, 30, 0, 0, 0, 0, time.UTC), *p01.FechaFinVigencia)
+		assert.NotContains(t, p01.Atributos, "regimen_fiscal_receptor")
+	})
+
+	t.Run("restores the leading zeros of numeric claves", func(t *testing.T) {
+		_, registros := leerFixture(t, fixture.CatalogoClaveProdServ)
+
+		assert.Equal(t, "01010101", registros[0].Clave)
+		assert.Equal(t, "Laptop, computadora portátil", registros[2].Atributos["palabras_similares"])
+
+		_, registros = leerFixture(t, fixture.CatalogoCodigoPostal)
+
+		assert.Equal(t, "01000", registros[0].Clave)
+		assert.Empty(t, registros[0].Descripcion)
+		assert.Equal(t, "CMX", registros[0].Atributos["c_estado"])
+	})
+
+	t.Run("reads Windows-1252 delimited by semicolons", func(t *testing.T) {
+		definicion, registros := leerFixture(t, fixture.CatalogoFormaPago)
+
+		assert.Equal(t, catalogo.FormaPago, definicion.Nombre)
+		assert.Len(t, registros, 4)
+		assert.Equal(t, "03", registros[2].Clave)
+		assert.Equal(t, "Transferencia electrónica de fondos", registros[2].Descripcion)
+		assert.Equal(t, "Sí", registros[2].Atributos["bancarizado"])
+		assert.Equal(t, "Opcional", registros[2].Atributos["numero_de_operacion"])
+	})
+
+	t.Run("reports invalid rows along with the valid ones", func(t *testing.T) {
+		data := "c_Moneda,Descripción,Decimales,Fecha inicio de vigencia,Fecha fin de vigencia\n" +
+			"MXN,Peso Mexicano,2,01/01/2022,\n" +
+			"USD,Dolar americano,2,2022-13-01,\n" +
+			"MXN,Peso Mexicano,2,01/01/2022,\n" +
+			",,,,\n" +
+			"EUR,Euro,2,2022-01-01,\n"
+
+		definicion, registros, err := catalogo.LeerCSV(strings.NewReader(data))
+		assert.ErrorIs(t, err, catalogo.ErrCatalogoInvalido)
+		assert.Contains(t, err.Error(), "line 3")
+		assert.Contains(t, err.Error(), "duplicate clave MXN")
+		assert.Equal(t, "c_Moneda", definicion.Nombre)
+		assert.Len(t, registros, 2)
+		assert.Equal(t, "EUR", registros[1].Clave)
+	})
+
+	t.Run("rejects unsupported catalogs and files without header", func(t *testing.T) {
+		_, _, err := catalogo.LeerCSV(strings.NewReader("c_TasaOCuota,Rango o Fijo,Valor mínimo\nFijo,0.000000,\n"))
+		assert.ErrorIs(t, err, catalogo.ErrCatalogoDesconocido)
+
+		_, _, err = catalogo.LeerCSV(strings.NewReader("Clave,Descripción\n01,Efectivo\n"))
+		assert.ErrorIs(t, err, catalogo.ErrCatalogoInvalido)
+	})
+}
+
+func TestCatalogos(t *testing.T) {
+	definicion, ok := catalogo.PorSlug("clave-prod-serv")
+	assert.True(t, ok)
+	assert.Equal(t, catalogo.ClaveProdServ, definicion.Nombre)
+
+	definicion, ok = catalogo.PorNombre("C_REGIMENFISCAL")
+	assert.True(t, ok)
+	assert.Equal(t, "601", definicion.NormalizarClave("601"))
+	assert.Equal(t, "099", definicion.NormalizarClave(" 99 "))
+	assert.Equal(t, "", definicion.NormalizarClave(""))
+	assert.Equal(t, "X1", definicion.NormalizarClave("X1"))
+
+	_, ok = catalogo.PorSlug("c_UsoCFDI")
+	assert.False(t, ok)
+
+	assert.Equal(t, "fecha_de_inicio_de_vigencia", catalogo.Normalizar(" Fecha de inicio  de vigencia"))
+	assert.Equal(t, "estimulo_franja_fronteriza", catalogo.Normalizar("Estímulo Franja Fronteriza"))
+}
+
+func TestCache(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	cache := catalogo.NewCache[string](time.Hour)
+	cache.Now = func() time.Time { return now }
+
+	cache.Set("c_UsoCFDI|G03", "Gastos en general.")
+
+	valor, ok := cache.Get("c_UsoCFDI|G03")
+	assert.True(t, ok)
+	assert.Equal(t, "Gastos en general.", valor)
+
+	now = now.Add(time.Hour)
+
+	_, ok = cache.Get("c_UsoCFDI|G03")
+	assert.False(t, ok)
+}
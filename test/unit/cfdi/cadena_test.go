@@ -0,0 +1,157 @@
+package cfdi_test
+
+import (
+	"app/src/cfdi"
+	"app/test/fixture"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func cadenasFixture(t *testing.T, name string) (string, string) {
+	t.Helper()
+
+	data, err := fixture.CFDI(name)
+	if err != nil {
+		t.Fatalf("read fixture %s: %v", name, err)
+	}
+
+	comprobante, timbre, err := cfdi.CadenasOriginales(data)
+	if err != nil {
+		t.Fatalf("cadenas of fixture %s: %v", name, err)
+	}
+
+	return comprobante, timbre
+}
+
+func TestCadenasOriginales(t *testing.T) {
+	t.Run("CFDI 4.0", func(t *testing.T) {
+		comprobante, timbre := cadenasFixture(t, fixture.CFDIIngreso40)
+
+		assert.Equal(t, "||4.0|A|1024|2024-01-15T10:30:00|03|30001000000500003416|Contado|1451.50|51.50|MXN|1624.00|I|01|PUE|26015"+
+			"|EKU9003173C9|ESCUELA KEMPER URGATE|601|XIA190128J61|XENON INDUSTRIAL ARTICLES|76343|601|G03"+
+			"|43211503|LAP-001|2|H87|Pieza|Computadora portátil|500.00|1000.00|02|1000.00|002|Tasa|0.160000|160.00"+
+			"|81112101|3|E48|Servicio|Soporte técnico remoto|150.50|451.50|51.50|02|400.00|002|Tasa|0.160000|64.00"+
+			"|1400.00|002|Tasa|0.160000|224.00|224.00||", comprobante)
+		assert.Equal(t, "||1.1|5b4b4d8e-2c6f-4a0b-9f4e-0e5c1a2b3c4d|2024-01-15T10:31:12|SPR190613I52"+
+			"|c2VsbG8gZGUgcHJ1ZWJhIGluZ3Jlc28gNC4w|30001000000500003456||", timbre)
+	})
+
+	t.Run("CFDI 3.3 with retenciones", func(t *testing.T) {
+		comprobante, _ := cadenasFixture(t, fixture.CFDIIngreso33)
+
+		assert.Equal(t, "||3.3|H|87|2022-03-10T09:15:00|99|30001000000400002330|10000.00|MXN|9533.34|I|PPD|44100"+
+			"|CACX7605101P8|XOCHILT CASAS CHAVEZ|612|EKU9003173C9|ESCUELA KEMPER URGATE|G03"+
+			"|80101500|1|E48|Servicio|Honorarios por consultoría administrativa|10000.00|10000.00"+
+			"|10000.00|002|Tasa|0.160000|1600.00|10000.00|001|Tasa|0.100000|1000.00|10000.00|002|Tasa|0.106666|1066.66"+
+			"|001|1000.00|002|1066.66|2066.66|002|Tasa|0.160000|1600.00|1600.00||", comprobante)
+	})
+
+	t.Run("Carta Porte 3.1", func(t *testing.T) {
+		comprobante, timbre := cadenasFixture(t, fixture.CFDICartaPorte40)
+
+		assert.Equal(t, "||4.0|CP|118|2024-03-04T06:30:00|30001000000500003416|0|XXX|0|T|01|26015"+
+			"|EKU9003173C9|ESCUELA KEMPER URGATE|601|EKU9003173C9|ESCUELA KEMPER URGATE|26015|601|S01"+
+			"|31181701|40|H87|Pieza|Empaques de cartón corrugado|0|0|01"+
+			"|44121618|10|XBX|Caja|Tijeras escolares|0|0|01"+
+			"|3.1|CCC1B2C3-D4E5-4F60-8A7B-9C0D1E2F3A4B|No|350"+
+			"|Origen|OR000001|EKU9003173C9|ESCUELA KEMPER URGATE|2024-03-04T07:00:00|Av. Juárez|100|039|JAL|MEX|44100"+
+			"|Destino|DE000002|EKU9003173C9|ESCUELA KEMPER URGATE|2024-03-04T12:30:00|350"+
+			"|Blvd. Díaz Ordaz|2500|039|NLE|MEX|64000"+
+			"|1250.500|KGM|2|31181701|Empaques de cartón corrugado|40|H87|No|1000.000"+
+			"|44121618|Tijeras escolares|10|XBX|250.500"+
+			"|TPAF01|0X2XTXZ0X5X0X3X2X1X0|C2R2|12|501AAA|2020|SEGUROS DEL NORTE|154647|CTR004|VL45K98"+
+			"|01|CACX7605101P8|a234567890|XOCHILT CASAS CHAVEZ||", comprobante)
+		assert.Contains(t, timbre, "|2E3F4A5B-6C7D-4E8F-9A0B-1C2D3E4F5A6B|")
+	})
+
+	t.Run("Nómina 1.2", func(t *testing.T) {
+		comprobante, _ := cadenasFixture(t, fixture.CFDINomina40)
+
+		assert.Equal(t, "||4.0|NOM|2024-02|2024-01-15T18:00:00|30001000000500003416|15000.00|2500.00|MXN|12500.00|N|01|PUE|26015"+
+			"|EKU9003173C9|ESCUELA KEMPER URGATE|601|CACX7605101P8|XOCHILT CASAS CHAVEZ|36257|605|CN01"+
+			"|84111505|1|ACT|Pago de nómina|15000.00|15000.00|2500.00|01"+
+			"|1.2|O|2024-01-15|2024-01-01|2024-01-15|15.000|14500.00|2500.00|500.00|Y5412345108"+
+			"|CACX760510MGTSHC04|04078873454|2015-03-01|P462W|01|No|01|02|0001|Contabilidad|Contadora|1|04|1000.00|1050.00|JAL"+
+			"|14500.00|13000.00|1500.00|001|001|Sueldos, Salarios Rayas y Jornales|13000.00|0.00"+
+			"|029|029|Vales de despensa|0.00|1500.00"+
+			"|500.00|2000.00|001|001|Seguridad social|500.00|002|002|ISR|2000.00"+
+			"|002|002|Subsidio para el empleo|500.00|500.00||", comprobante)
+	})
+
+	t.Run("Pagos 1.0", func(t *testing.T) {
+		comprobante, _ := cadenasFixture(t, fixture.CFDIPago33)
+
+		assert.Equal(t, "||3.3|P|15|2022-04-05T12:00:00|30001000000400002330|0|XXX|0|P|44100"+
+			"|CACX7605101P8|XOCHILT CASAS CHAVEZ|612|EKU9003173C9|ESCUELA KEMPER URGATE|P01"+
+			"|84111506|1|ACT|Pago|0|0"+
+			"|1.0|2022-04-04T12:00:00|03|MXN|5000.00|000123"+
+			"|3f2a1b0c-9d8e-4f7a-8b6c-5d4e3f2a1b0c|H|87|MXN|PPD|1|9533.34|5000.00|4533.34||", comprobante)
+	})
+
+	t.Run("whitespace is normalized and unstamped CFDIs have no timbre cadena", func(t *testing.T) {
+		data := []byte(`<cfdi:Comprobante xmlns:cfdi="http://www.sat.gob.mx/cfd/4" Version="4.0" Fecha="2024-01-01T00:00:00" SubTotal="10" Total="10" TipoDeComprobante="I">
+  <cfdi:Emisor Rfc="EKU9003173C9" Nombre="  ESCUELA   KEMPER
+    URGATE "/>
+</cfdi:Comprobante>`)
+
+		comprobante, timbre, err := cfdi.CadenasOriginales(data)
+		assert.NoError(t, err)
+		assert.Equal(t, "||4.0|2024-01-01T00:00:00|10|10|I|EKU9003173C9|ESCUELA KEMPER URGATE||", comprobante)
+		assert.Empty(t, timbre)
+	})
+
+	t.Run("only XML whitespace is normalized", func(t *testing.T) {
+		data := []byte("<cfdi:Comprobante xmlns:cfdi=\"http://www.sat.gob.mx/cfd/4\" Version=\"4.0\"><cfdi:Conceptos>" +
+			"<cfdi:Concepto ClaveProdServ=\"01010101\" Descripcion=\" Servicio\u00a0de\u00a0 soporte\u0085 \"/>" +
+			"</cfdi:Conceptos></cfdi:Comprobante>")
+
+		comprobante, _, err := cfdi.CadenasOriginales(data)
+		assert.NoError(t, err)
+		assert.Equal(t, "||4.0|01010101|Servicio\u00a0de\u00a0 soporte\u0085||", comprobante)
+	})
+
+	t.Run("not a Comprobante", func(t *testing.T) {
+		for _, data := range []string{"", "not xml", `<?xml version="1.0"?><Factura Version="4.0"/>`} {
+			_, _, err := cfdi.CadenasOriginales([]byte(data))
+			assert.ErrorIs(t, err, cfdi.ErrNotComprobante, data)
+		}
+	})
+
+	t.Run("Pagos 2.0", func(t *testing.T) {
+		comprobante, timbre := cadenasFixture(t, fixture.CFDIPago40)
+
+		assert.Equal(t, "||4.0|P|41|2023-02-10T11:20:00|30001000000500003416|0|XXX|0|P|01|44100"+
+			"|CACX7605101P8|XOCHILT CASAS CHAVEZ|612|EKU9003173C9|ESCUELA KEMPER URGATE|26015|601|CP01"+
+			"|84111506|1|ACT|Pago|0|0|01"+
+			"|2.0|2000.00|2023-02-09T12:00:00|03|MXN|1|2000.00|000456"+
+			"|3F2A1B0C-9D8E-4F7A-8B6C-5D4E3F2A1B0C|H|87|MXN|1|2|4533.34|2000.00|2533.34|01||", comprobante)
+		assert.Equal(t, "||1.1|4D5E6F7A-8B9C-4D0E-8F1A-2B3C4D5E6F7A|2023-02-10T11:21:03|SPR190613I52"+
+			"|c2VsbG8gZGUgcHJ1ZWJhIHBhZ28gNC4w|30001000000500003456||", timbre)
+	})
+
+	t.Run("complements follow the SAT XSLT rather than the document", func(t *testing.T) {
+		data := []byte(`<cfdi:Comprobante xmlns:cfdi="http://www.sat.gob.mx/cfd/4" Version="4.0">
+  <cfdi:Complemento>
+    <pago20:Pagos xmlns:pago20="http://www.sat.gob.mx/Pagos20" Version="2.0">
+      <pago20:Pago Monto="116.00" MonedaP="MXN" FormaDePagoP="03" FechaPago="2024-01-10T12:00:00">
+        <pago20:ImpuestosP>
+          <pago20:TrasladosP>
+            <pago20:TrasladoP ImporteP="16.00" TasaOCuotaP="0.160000" TipoFactorP="Tasa" ImpuestoP="002" BaseP="100.00"/>
+          </pago20:TrasladosP>
+        </pago20:ImpuestosP>
+        <pago20:DoctoRelacionado ObjetoImpDR="01" ImpSaldoInsoluto="0.00" ImpPagado="116.00" ImpSaldoAnt="116.00" NumParcialidad="1" MonedaDR="MXN" IdDocumento="5B4B4D8E-2C6F-4A0B-9F4E-0E5C1A2B3C4D"/>
+      </pago20:Pago>
+      <pago20:Totales MontoTotalPagos="116.00" TotalTrasladosImpuestoIVA16="16.00" TotalTrasladosBaseIVA16="100.00"/>
+    </pago20:Pagos>
+    <otro:Complemento xmlns:otro="urn:otro" B="2" A="1"/>
+  </cfdi:Complemento>
+</cfdi:Comprobante>`)
+
+		comprobante, _, err := cfdi.CadenasOriginales(data)
+		assert.NoError(t, err)
+		assert.Equal(t, "||4.0|2.0|100.00|16.00|116.00|2024-01-10T12:00:00|03|MXN|116.00"+
+			"|5B4B4D8E-2C6F-4A0B-9F4E-0E5C1A2B3C4D|MXN|1|116.00|116.00|0.00|01"+
+			"|100.00|002|Tasa|0.160000|16.00|2|1||", comprobante)
+	})
+}
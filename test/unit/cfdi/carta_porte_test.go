@@ -0,0 +1,135 @@
+package cfdi_test
+
+import (
+	"app/src/cfdi"
+	"app/test/fixture"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// assertIncidencia checks the incidencias include one of tipo at nodo.
+func assertIncidencia(t *testing.T, incidencias []cfdi.Incidencia, tipo, nodo string) {
+	t.Helper()
+
+	for _, incidencia := range incidencias {
+		if incidencia.Tipo == tipo && incidencia.Nodo == nodo {
+			return
+		}
+	}
+
+	t.Errorf("no %s incidencia at %s in %+v", tipo, nodo, incidencias)
+}
+
+func TestParseCartaPorte(t *testing.T) {
+	c := parseFixture(t, fixture.CFDICartaPorte40)
+
+	assert.Equal(t, cfdi.TipoTraslado, c.TipoDeComprobante)
+	assertCuadra(t, c)
+
+	cartaPorte := c.Complemento.CartaPorte
+	assert.NotNil(t, cartaPorte)
+	assert.Equal(t, cfdi.VersionCartaPorte31, cartaPorte.Version)
+	assert.Equal(t, "No", cartaPorte.TranspInternac)
+	assert.Equal(t, "350", cartaPorte.TotalDistRec.String())
+	assert.Equal(t, cfdi.MedioAutotransporte, cartaPorte.MedioTransporte())
+
+	assert.Len(t, cartaPorte.Ubicaciones.Ubicacion, 2)
+	origen := cartaPorte.Ubicaciones.Ubicacion[0]
+	assert.Equal(t, cfdi.TipoUbicacionOrigen, origen.TipoUbicacion)
+	assert.Equal(t, time.Date(2024, 3, 4, 7, 0, 0, 0, time.UTC), origen.FechaHoraSalidaLlegada.Time)
+	assert.Equal(t, "44100", origen.Domicilio.CodigoPostal)
+
+	mercancias := cartaPorte.Mercancias
+	assert.Equal(t, "1250.500", mercancias.PesoBrutoTotal.String())
+	assert.Equal(t, 2, mercancias.NumTotalMercancias)
+	assert.Len(t, mercancias.Mercancia, 2)
+	assert.Equal(t, "31181701", mercancias.Mercancia[0].BienesTransp)
+
+	autotransporte := mercancias.Autotransporte
+	assert.Equal(t, "TPAF01", autotransporte.PermSCT)
+	assert.Equal(t, "C2R2", autotransporte.IdentificacionVehicular.ConfigVehicular)
+	assert.Equal(t, "154647", autotransporte.Seguros.PolizaRespCivil)
+	assert.Len(t, autotransporte.Remolques, 1)
+
+	assert.Len(t, cartaPorte.FiguraTransporte.TiposFigura, 1)
+	assert.Equal(t, cfdi.TipoFiguraOperador, cartaPorte.FiguraTransporte.TiposFigura[0].TipoFigura)
+
+	t.Run("no complement on other CFDIs", func(t *testing.T) {
+		assert.Nil(t, parseFixture(t, fixture.CFDITraslado40).Complemento.CartaPorte)
+	})
+}
+
+func TestValidarCartaPorte(t *testing.T) {
+	t.Run("should find nothing in a valid complement", func(t *testing.T) {
+		cartaPorte := parseFixture(t, fixture.CFDICartaPorte40).Complemento.CartaPorte
+
+		assert.Empty(t, cartaPorte.Validar())
+	})
+
+	t.Run("should report missing nodes", func(t *testing.T) {
+		cartaPorte := parseFixture(t, fixture.CFDICartaPorte40).Complemento.CartaPorte
+		cartaPorte.Ubicaciones.Ubicacion = cartaPorte.Ubicaciones.Ubicacion[:1]
+		cartaPorte.Mercancias.Autotransporte.Seguros = nil
+		cartaPorte.Mercancias.Autotransporte.Remolques = nil
+		cartaPorte.FiguraTransporte = nil
+		cartaPorte.IdCCP = ""
+
+		incidencias := cartaPorte.Validar()
+
+		assertIncidencia(t, incidencias, cfdi.IncidenciaNodoFaltante, "CartaPorte@IdCCP")
+		assertIncidencia(t, incidencias, cfdi.IncidenciaNodoFaltante, "CartaPorte/Ubicaciones/Ubicacion")
+		assertIncidencia(t, incidencias, cfdi.IncidenciaNodoFaltante, "CartaPorte/Mercancias/Autotransporte/Seguros")
+		assertIncidencia(t, incidencias, cfdi.IncidenciaNodoFaltante, "CartaPorte/Mercancias/Autotransporte/Remolques")
+		assertIncidencia(t, incidencias, cfdi.IncidenciaNodoFaltante, "CartaPorte/FiguraTransporte")
+		assertIncidencia(t, incidencias, cfdi.IncidenciaTotalInconsistente, "CartaPorte@TotalDistRec")
+	})
+
+	t.Run("should report inconsistent weights and totals", func(t *testing.T) {
+		cartaPorte := parseFixture(t, fixture.CFDICartaPorte40).Complemento.CartaPorte
+		cartaPorte.Mercancias.PesoBrutoTotal = cfdi.MustParseDecimal("1250")
+		cartaPorte.Mercancias.PesoNetoTotal = cfdi.MustParseDecimal("1300")
+		cartaPorte.Mercancias.NumTotalMercancias = 3
+		cartaPorte.Mercancias.Mercancia[1].PesoEnKg = cfdi.Decimal{}
+		cartaPorte.Mercancias.Autotransporte.IdentificacionVehicular.PesoBrutoVehicular = cfdi.MustParseDecimal("1.2")
+
+		incidencias := cartaPorte.Validar()
+
+		assertIncidencia(t, incidencias, cfdi.IncidenciaPesoInconsistente, "CartaPorte/Mercancias@PesoBrutoTotal")
+		assertIncidencia(t, incidencias, cfdi.IncidenciaPesoInconsistente, "CartaPorte/Mercancias@PesoNetoTotal")
+		assertIncidencia(t, incidencias, cfdi.IncidenciaPesoInconsistente, "CartaPorte/Mercancias/Mercancia[2]@PesoEnKg")
+		assertIncidencia(t, incidencias, cfdi.IncidenciaPesoInconsistente,
+			"CartaPorte/Mercancias/Autotransporte/IdentificacionVehicular@PesoBrutoVehicular")
+		assertIncidencia(t, incidencias, cfdi.IncidenciaTotalInconsistente, "CartaPorte/Mercancias@NumTotalMercancias")
+	})
+
+	t.Run("should report invalid catalog keys", func(t *testing.T) {
+		cartaPorte := parseFixture(t, fixture.CFDICartaPorte40).Complemento.CartaPorte
+		cartaPorte.Version = "2.0"
+		cartaPorte.TranspInternac = "Si"
+		cartaPorte.Ubicaciones.Ubicacion[0].IDUbicacion = "DE000001"
+		cartaPorte.Ubicaciones.Ubicacion[1].Domicilio.CodigoPostal = "640"
+		cartaPorte.Mercancias.Mercancia[0].BienesTransp = "3118170"
+		cartaPorte.Mercancias.Autotransporte.PermSCT = "TPAF1"
+		cartaPorte.Mercancias.Autotransporte.IdentificacionVehicular.ConfigVehicular = "C9"
+		cartaPorte.Mercancias.Autotransporte.Remolques[0].SubTipoRem = "CTR999"
+		cartaPorte.FiguraTransporte.TiposFigura[0].TipoFigura = "09"
+
+		incidencias := cartaPorte.Validar()
+
+		assertIncidencia(t, incidencias, cfdi.IncidenciaClaveInvalida, "CartaPorte@Version")
+		assertIncidencia(t, incidencias, cfdi.IncidenciaClaveInvalida, "CartaPorte@TranspInternac")
+		assertIncidencia(t, incidencias, cfdi.IncidenciaClaveInvalida, "CartaPorte/Ubicaciones/Ubicacion[1]@IDUbicacion")
+		assertIncidencia(t, incidencias, cfdi.IncidenciaClaveInvalida,
+			"CartaPorte/Ubicaciones/Ubicacion[2]/Domicilio@CodigoPostal")
+		assertIncidencia(t, incidencias, cfdi.IncidenciaClaveInvalida, "CartaPorte/Mercancias/Mercancia[1]@BienesTransp")
+		assertIncidencia(t, incidencias, cfdi.IncidenciaClaveInvalida, "CartaPorte/Mercancias/Autotransporte@PermSCT")
+		assertIncidencia(t, incidencias, cfdi.IncidenciaClaveInvalida,
+			"CartaPorte/Mercancias/Autotransporte/IdentificacionVehicular@ConfigVehicular")
+		assertIncidencia(t, incidencias, cfdi.IncidenciaClaveInvalida,
+			"CartaPorte/Mercancias/Autotransporte/Remolques/Remolque[1]@SubTipoRem")
+		assertIncidencia(t, incidencias, cfdi.IncidenciaClaveInvalida, "CartaPorte/FiguraTransporte/TiposFigura[1]@TipoFigura")
+		assertIncidencia(t, incidencias, cfdi.IncidenciaNodoFaltante, "CartaPorte/FiguraTransporte/TiposFigura")
+	})
+}
@@ -0,0 +1,117 @@
+package cfdi_test
+
+import (
+	"app/src/cfdi"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseDecimal(t *testing.T) {
+	t.Run("keeps the decimals as written", func(t *testing.T) {
+		for _, text := range []string{"0", "16", "1160.00", "0.160000", "-0.50", "0.05"} {
+			d, err := cfdi.ParseDecimal(text)
+			assert.NoError(t, err)
+			assert.Equal(t, text, d.String())
+		}
+	})
+
+	t.Run("accepts leading sign and missing integer part", func(t *testing.T) {
+		d, err := cfdi.ParseDecimal("+.5")
+		assert.NoError(t, err)
+		assert.Equal(t, "0.5", d.String())
+	})
+
+	t.Run("rejects invalid amounts", func(t *testing.T) {
+		for _, text := range []string{"", " ", "abc", "1.2.3", "1,000.00", "1e3", "-", "."} {
+			_, err := cfdi.ParseDecimal(text)
+			assert.ErrorIs(t, err, cfdi.ErrInvalidDecimal, text)
+		}
+	})
+}
+
+func TestDecimalArithmetic(t *testing.T) {
+	t.Run("sums exactly", func(t *testing.T) {
+		sum := cfdi.Decimal{}
+		for i := 0; i < 10; i++ {
+			sum = sum.Add(cfdi.MustParseDecimal("0.1"))
+		}
+
+		assert.True(t, sum.Equal(cfdi.MustParseDecimal("1")))
+		assert.Equal(t, "1.0", sum.String())
+	})
+
+	t.Run("mixes scales", func(t *testing.T) {
+		a := cfdi.MustParseDecimal("1400.00")
+		b := cfdi.MustParseDecimal("0.160000")
+
+		assert.Equal(t, "224.00000000", a.Mul(b).String())
+		assert.Equal(t, "1399.840000", a.Sub(b).String())
+		assert.Equal(t, 1, a.Cmp(b))
+		assert.Equal(t, -1, b.Cmp(a))
+		assert.Equal(t, "-1400.00", a.Neg().String())
+	})
+
+	t.Run("zero value is zero", func(t *testing.T) {
+		var zero cfdi.Decimal
+
+		assert.True(t, zero.IsZero())
+		assert.Equal(t, "0", zero.String())
+		assert.True(t, zero.Equal(cfdi.MustParseDecimal("0.00")))
+		assert.Equal(t, "2.5", zero.Add(cfdi.NewDecimal(25, 1)).String())
+	})
+}
+
+func TestDecimalRound(t *testing.T) {
+	cases := map[string]string{
+		"1066.665":   "1066.67",
+		"1066.664":   "1066.66",
+		"-1066.665":  "-1066.67",
+		"0.005":      "0.01",
+		"0.004999":   "0.00",
+		"12":         "12.00",
+		"160.000000": "160.00",
+	}
+
+	for text, expected := range cases {
+		assert.Equal(t, expected, cfdi.MustParseDecimal(text).Round(2).String(), text)
+	}
+}
+
+func TestDecimalJSON(t *testing.T) {
+	data, err := json.Marshal(map[string]cfdi.Decimal{"total": cfdi.MustParseDecimal("1624.00")})
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"total": 1624.00}`, string(data))
+	assert.Contains(t, string(data), "1624.00")
+
+	var decoded struct {
+		Total cfdi.Decimal `json:"total"`
+	}
+
+	assert.NoError(t, json.Unmarshal([]byte(`{"total": "9533.34"}`), &decoded))
+	assert.Equal(t, "9533.34", decoded.Total.String())
+}
+
+func TestDecimalSQL(t *testing.T) {
+	value, err := cfdi.MustParseDecimal("9533.34").Value()
+	assert.NoError(t, err)
+	assert.Equal(t, "9533.34", value)
+
+	for src, expected := range map[interface{}]string{
+		"1624.00":    "1624.00",
+		int64(16):    "16",
+		float64(0.5): "0.5",
+		nil:          "0",
+		"-2.5000":    "-2.5000",
+	} {
+		var d cfdi.Decimal
+		assert.NoError(t, d.Scan(src), src)
+		assert.Equal(t, expected, d.String(), src)
+	}
+
+	var d cfdi.Decimal
+	assert.NoError(t, d.Scan([]byte("0.160000")))
+	assert.Equal(t, "0.160000", d.String())
+	assert.ErrorIs(t, d.Scan(true), cfdi.ErrInvalidDecimal)
+}
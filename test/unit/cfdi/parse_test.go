@@ -0,0 +1,316 @@
+package cfdi_test
+
+import (
+	"app/src/cfdi"
+	"app/test/fixture"
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func parseFixture(t *testing.T, name string) *cfdi.Comprobante {
+	t.Helper()
+
+	data, err := fixture.CFDI(name)
+	if err != nil {
+		t.Fatalf("read fixture %s: %v", name, err)
+	}
+
+	comprobante, err := cfdi.Parse(data)
+	if err != nil {
+		t.Fatalf("parse fixture %s: %v", name, err)
+	}
+
+	return comprobante
+}
+
+// assertCuadra checks the amounts of the fixture add up the way the SAT
+// validates them.
+func assertCuadra(t *testing.T, c *cfdi.Comprobante) {
+	t.Helper()
+
+	subTotal := cfdi.Decimal{}
+	for _, concepto := range c.Conceptos {
+		subTotal = subTotal.Add(concepto.Importe)
+	}
+	assert.True(t, subTotal.Equal(c.SubTotal), "SubTotal %s, conceptos %s", c.SubTotal, subTotal)
+
+	total := c.SubTotal.Sub(c.Descuento)
+	if c.Impuestos != nil {
+		total = total.Add(c.Impuestos.TotalImpuestosTrasladados).Sub(c.Impuestos.TotalImpuestosRetenidos)
+	}
+	assert.True(t, total.Round(2).Equal(c.Total), "Total %s, computed %s", c.Total, total)
+}
+
+func TestParseIngreso40(t *testing.T) {
+	c := parseFixture(t, fixture.CFDIIngreso40)
+
+	assert.Equal(t, cfdi.Version40, c.Version)
+	assert.Equal(t, cfdi.TipoIngreso, c.TipoDeComprobante)
+	assert.Equal(t, "A", c.Serie)
+	assert.Equal(t, "1024", c.Folio)
+	assert.Equal(t, time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC), c.Fecha.Time)
+	assert.Equal(t, "PUE", c.MetodoPago)
+	assert.Equal(t, "03", c.FormaPago)
+	assert.Equal(t, "MXN", c.Moneda)
+	assert.Equal(t, "01", c.Exportacion)
+	assert.Equal(t, "1451.50", c.SubTotal.String())
+	assert.Equal(t, "51.50", c.Descuento.String())
+	assert.Equal(t, "1624.00", c.Total.String())
+	assert.True(t, c.TipoCambio.IsZero())
+	assert.NotEmpty(t, c.Sello)
+
+	assert.Equal(t, cfdi.Emisor{RFC: "EKU9003173C9", Nombre: "ESCUELA KEMPER URGATE", RegimenFiscal: "601"}, c.Emisor)
+	assert.Equal(t, "XIA190128J61", c.Receptor.RFC)
+	assert.Equal(t, "76343", c.Receptor.DomicilioFiscalReceptor)
+	assert.Equal(t, "601", c.Receptor.RegimenFiscalReceptor)
+	assert.Equal(t, "G03", c.Receptor.UsoCFDI)
+
+	assert.Len(t, c.Conceptos, 2)
+	assert.Equal(t, "Computadora portátil", c.Conceptos[0].Descripcion)
+	assert.Equal(t, "43211503", c.Conceptos[0].ClaveProdServ)
+	assert.Equal(t, "2", c.Conceptos[0].Cantidad.String())
+	assert.Equal(t, "51.50", c.Conceptos[1].Descuento.String())
+	assert.Len(t, c.Conceptos[1].Impuestos.Traslados, 1)
+	assert.Equal(t, "64.00", c.Conceptos[1].Impuestos.Traslados[0].Importe.String())
+
+	assert.Equal(t, "224.00", c.Impuestos.TotalImpuestosTrasladados.String())
+	assert.True(t, c.Impuestos.TotalImpuestosRetenidos.IsZero())
+	assert.Len(t, c.Impuestos.Traslados, 1)
+	assert.Equal(t, cfdi.Traslado{
+		Base:       cfdi.MustParseDecimal("1400.00"),
+		Impuesto:   "002",
+		TipoFactor: "Tasa",
+		TasaOCuota: cfdi.MustParseDecimal("0.160000"),
+		Importe:    cfdi.MustParseDecimal("224.00"),
+	}, c.Impuestos.Traslados[0])
+
+	assert.True(t, c.Timbrado())
+	assert.Equal(t, "5B4B4D8E-2C6F-4A0B-9F4E-0E5C1A2B3C4D", c.UUID())
+	assert.Equal(t, time.Date(2024, 1, 15, 10, 31, 12, 0, time.UTC), c.Complemento.TimbreFiscalDigital.FechaTimbrado.Time)
+	assert.Equal(t, c.Sello, c.Complemento.TimbreFiscalDigital.SelloCFD)
+	assert.NotEmpty(t, c.Complemento.TimbreFiscalDigital.SelloSAT)
+
+	assertCuadra(t, c)
+}
+
+func TestParseIngreso33(t *testing.T) {
+	c := parseFixture(t, fixture.CFDIIngreso33)
+
+	assert.Equal(t, cfdi.Version33, c.Version)
+	assert.Equal(t, "PPD", c.MetodoPago)
+	assert.Empty(t, c.Exportacion)
+	assert.Empty(t, c.Receptor.RegimenFiscalReceptor)
+	assert.Equal(t, "G03", c.Receptor.UsoCFDI)
+
+	retenciones := c.Conceptos[0].Impuestos.Retenciones
+	assert.Len(t, retenciones, 2)
+	assert.Equal(t, "001", retenciones[0].Impuesto)
+	assert.Equal(t, "0.106666", retenciones[1].TasaOCuota.String())
+
+	assert.Equal(t, "2066.66", c.Impuestos.TotalImpuestosRetenidos.String())
+	assert.Len(t, c.Impuestos.Retenciones, 2)
+	assert.True(t, c.Impuestos.Traslados[0].Base.IsZero())
+	assert.Equal(t, "3F2A1B0C-9D8E-4F7A-8B6C-5D4E3F2A1B0C", c.UUID())
+
+	assertCuadra(t, c)
+}
+
+func TestParseEgreso(t *testing.T) {
+	c := parseFixture(t, fixture.CFDIEgreso40)
+
+	assert.Equal(t, cfdi.TipoEgreso, c.TipoDeComprobante)
+	assert.Equal(t, []cfdi.CfdiRelacionados{{
+		TipoRelacion:    "01",
+		CfdiRelacionado: []cfdi.CfdiRelacionado{{UUID: "5B4B4D8E-2C6F-4A0B-9F4E-0E5C1A2B3C4D"}},
+	}}, c.CfdiRelacionados)
+
+	assertCuadra(t, c)
+}
+
+func TestParseTraslado(t *testing.T) {
+	c := parseFixture(t, fixture.CFDITraslado40)
+
+	assert.Equal(t, cfdi.TipoTraslado, c.TipoDeComprobante)
+	assert.Equal(t, "XXX", c.Moneda)
+	assert.True(t, c.Total.IsZero())
+	assert.Nil(t, c.Impuestos)
+	assert.Nil(t, c.Conceptos[0].Impuestos)
+	assert.Equal(t, c.Emisor.RFC, c.Receptor.RFC)
+
+	assertCuadra(t, c)
+}
+
+func TestParseNomina(t *testing.T) {
+	c := parseFixture(t, fixture.CFDINomina40)
+
+	assert.Equal(t, cfdi.TipoNomina, c.TipoDeComprobante)
+	assert.Equal(t, "CN01", c.Receptor.UsoCFDI)
+	assert.Equal(t, "Pago de nómina", c.Conceptos[0].Descripcion)
+	assert.Equal(t, "9E8D7C6B-5A4F-4E3D-8C2B-1A0F9E8D7C6B", c.UUID())
+
+	assertCuadra(t, c)
+
+	t.Run("Nómina 1.2", func(t *testing.T) {
+		assert.Len(t, c.Complemento.Nomina, 1)
+
+		nomina := c.Complemento.Nomina[0]
+		assert.Equal(t, cfdi.VersionNomina12, nomina.Version)
+		assert.Equal(t, "O", nomina.TipoNomina)
+		assert.Equal(t, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), nomina.FechaInicialPago.Time)
+		assert.Equal(t, time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC), nomina.FechaFinalPago.Time)
+		assert.Equal(t, "14500.00", nomina.TotalPercepciones.String())
+		assert.Equal(t, "2500.00", nomina.TotalDeducciones.String())
+		assert.Equal(t, "500.00", nomina.TotalOtrosPagos.String())
+
+		assert.Equal(t, "CACX760510MGTSHC04", nomina.Receptor.Curp)
+		assert.Equal(t, "P462W", nomina.Receptor.Antiguedad)
+		assert.Equal(t, "0001", nomina.Receptor.NumEmpleado)
+		assert.Equal(t, "Y5412345108", nomina.Emisor.RegistroPatronal)
+
+		assert.Len(t, nomina.Percepciones.Percepcion, 2)
+		assert.Equal(t, "13000.00", nomina.Percepciones.TotalGravado.String())
+		assert.Equal(t, "1500.00", nomina.Percepciones.TotalExento.String())
+		assert.Len(t, nomina.Deducciones.Deduccion, 2)
+		assert.Len(t, nomina.OtrosPagos, 1)
+
+		assert.Equal(t, "2000.00", nomina.ISRRetenido().String())
+		assert.Equal(t, "500.00", nomina.SubsidioCausado().String())
+
+		neto := nomina.TotalPercepciones.Add(nomina.TotalOtrosPagos).Sub(nomina.TotalDeducciones)
+		assert.True(t, neto.Equal(c.Total), "neto %s, Total %s", neto, c.Total)
+	})
+
+	t.Run("no complement on other tipos", func(t *testing.T) {
+		assert.Empty(t, parseFixture(t, fixture.CFDIIngreso40).Complemento.Nomina)
+	})
+}
+
+func TestParsePagos(t *testing.T) {
+	for _, name := range []string{fixture.CFDIPago33, fixture.CFDIPago40} {
+		c := parseFixture(t, name)
+
+		assert.Equal(t, cfdi.TipoPago, c.TipoDeComprobante, name)
+		assert.Equal(t, "XXX", c.Moneda, name)
+		assert.True(t, c.Total.IsZero(), name)
+		assert.True(t, c.Timbrado(), name)
+
+		assertCuadra(t, c)
+	}
+
+	t.Run("Pagos 1.0", func(t *testing.T) {
+		c := parseFixture(t, fixture.CFDIPago33)
+
+		assert.NotNil(t, c.Complemento.Pagos)
+		assert.Equal(t, cfdi.VersionPagos10, c.Complemento.Pagos.Version)
+		assert.Len(t, c.Complemento.Pagos.Pago, 1)
+
+		pago := c.Complemento.Pagos.Pago[0]
+		assert.Equal(t, time.Date(2022, 4, 4, 12, 0, 0, 0, time.UTC), pago.FechaPago.Time)
+		assert.Equal(t, "03", pago.FormaDePagoP)
+		assert.Equal(t, "5000.00", pago.Monto.String())
+		assert.Len(t, pago.Doctos, 1)
+
+		docto := pago.Doctos[0]
+		assert.Equal(t, "3F2A1B0C-9D8E-4F7A-8B6C-5D4E3F2A1B0C", docto.UUID())
+		assert.Equal(t, cfdi.MetodoPagoPPD, docto.MetodoDePagoDR)
+		assert.Equal(t, 1, docto.NumParcialidad)
+		assert.Equal(t, "9533.34", docto.ImpSaldoAnt.String())
+		assert.Equal(t, "5000.00", docto.ImpPagado.String())
+		assert.Equal(t, "4533.34", docto.ImpSaldoInsoluto.String())
+		assert.True(t, docto.ImpSaldoAnt.Sub(docto.ImpPagado).Equal(docto.ImpSaldoInsoluto))
+	})
+
+	t.Run("Pagos 2.0", func(t *testing.T) {
+		c := parseFixture(t, fixture.CFDIPago40)
+
+		assert.NotNil(t, c.Complemento.Pagos)
+		assert.Equal(t, cfdi.VersionPagos20, c.Complemento.Pagos.Version)
+
+		pago := c.Complemento.Pagos.Pago[0]
+		assert.Equal(t, "1", pago.TipoCambioP.String())
+		assert.Equal(t, "2000.00", pago.Monto.String())
+
+		docto := pago.Doctos[0]
+		assert.Equal(t, "3F2A1B0C-9D8E-4F7A-8B6C-5D4E3F2A1B0C", docto.UUID())
+		assert.Equal(t, "1", docto.EquivalenciaDR.String())
+		assert.Equal(t, 2, docto.NumParcialidad)
+		assert.Equal(t, "2533.34", docto.ImpSaldoInsoluto.String())
+		assert.Equal(t, "01", docto.ObjetoImpDR)
+	})
+
+	t.Run("no complement on other tipos", func(t *testing.T) {
+		assert.Nil(t, parseFixture(t, fixture.CFDIIngreso40).Complemento.Pagos)
+	})
+}
+
+func TestParseNamespaces(t *testing.T) {
+	t.Run("default namespace and ISO-8859-1", func(t *testing.T) {
+		c := parseFixture(t, fixture.CFDIIngreso40Latin1)
+
+		assert.Equal(t, cfdi.Version40, c.Version)
+		assert.Equal(t, "PÚBLICO EN GENERAL", c.Receptor.Nombre)
+		assert.Equal(t, "Piñata de cumpleaños", c.Conceptos[0].Descripcion)
+		assert.Equal(t, "250.000000", c.SubTotal.String())
+		assert.Equal(t, "6C5C5E9F-3D7A-4B1C-8A5F-1F6D2B3C4D5E", c.UUID())
+		assert.Equal(t, time.Date(2024, 3, 2, 8, 5, 41, 0, time.UTC), c.Complemento.TimbreFiscalDigital.FechaTimbrado.Time)
+
+		assertCuadra(t, c)
+	})
+
+	t.Run("other prefixes, BOM and version from the namespace", func(t *testing.T) {
+		data := []byte("\xEF\xBB\xBF" + `<?xml version="1.0" encoding="utf-8"?>
+<c:Comprobante xmlns:c="http://www.sat.gob.mx/cfd/4" Fecha="2024-01-01T00:00:00" SubTotal="10" Total="10" TipoDeComprobante="I">
+  <c:Emisor Rfc="EKU9003173C9"/>
+  <c:Receptor Rfc="XAXX010101000"/>
+  <c:Conceptos><c:Concepto Descripcion="Uno" Importe="10"/></c:Conceptos>
+</c:Comprobante>`)
+
+		c, err := cfdi.Parse(data)
+		assert.NoError(t, err)
+		assert.Equal(t, cfdi.Version40, c.Version)
+		assert.Equal(t, "EKU9003173C9", c.Emisor.RFC)
+		assert.False(t, c.Timbrado())
+		assert.Empty(t, c.UUID())
+	})
+
+	t.Run("reader", func(t *testing.T) {
+		data, err := fixture.CFDI(fixture.CFDIEgreso40)
+		assert.NoError(t, err)
+
+		c, err := cfdi.ParseReader(bytes.NewReader(data))
+		assert.NoError(t, err)
+		assert.Equal(t, "7A8B9C0D-1E2F-4A3B-9C4D-5E6F7A8B9C0D", c.UUID())
+	})
+}
+
+func TestParseInvalid(t *testing.T) {
+	t.Run("not a Comprobante", func(t *testing.T) {
+		for _, data := range []string{"", "not xml", `<?xml version="1.0"?><Factura Version="4.0"/>`} {
+			_, err := cfdi.Parse([]byte(data))
+			assert.ErrorIs(t, err, cfdi.ErrNotComprobante, data)
+		}
+	})
+
+	t.Run("unsupported version", func(t *testing.T) {
+		for _, data := range []string{
+			`<cfdi:Comprobante xmlns:cfdi="http://www.sat.gob.mx/cfd/3" Version="3.2"/>`,
+			`<Comprobante/>`,
+		} {
+			_, err := cfdi.Parse([]byte(data))
+			assert.ErrorIs(t, err, cfdi.ErrUnsupportedVersion, data)
+		}
+	})
+
+	t.Run("invalid amount", func(t *testing.T) {
+		_, err := cfdi.Parse([]byte(`<Comprobante Version="4.0" Total="1,000.00"/>`))
+		assert.ErrorIs(t, err, cfdi.ErrInvalidDecimal)
+	})
+
+	t.Run("unknown charset", func(t *testing.T) {
+		_, err := cfdi.Parse([]byte(`<?xml version="1.0" encoding="EBCDIC"?><Comprobante Version="4.0"/>`))
+		assert.ErrorIs(t, err, cfdi.ErrNotComprobante)
+	})
+}
@@ -0,0 +1,99 @@
+package cfdi_test
+
+import (
+	"app/src/cfdi"
+	"app/test/fixture"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidarReceptor(t *testing.T) {
+	t.Run("accepts the receptors of the fixtures", func(t *testing.T) {
+		for _, name := range []string{fixture.CFDIIngreso40, fixture.CFDINomina40, fixture.CFDIPago40, fixture.CFDICartaPorte40} {
+			c := parseFixture(t, name)
+			assert.Empty(t, c.ValidarReceptor(), name)
+		}
+	})
+
+	t.Run("skips CFDI 3.3", func(t *testing.T) {
+		c := parseFixture(t, fixture.CFDIIngreso33)
+		assert.Nil(t, c.ValidarReceptor())
+	})
+
+	t.Run("requires the attributes of CFDI 4.0", func(t *testing.T) {
+		c := parseFixture(t, fixture.CFDIIngreso40)
+		c.Receptor = cfdi.Receptor{RFC: "XIA190128J61"}
+
+		incidencias := c.ValidarReceptor()
+		assert.Len(t, incidencias, 4)
+		assertIncidencia(t, incidencias, cfdi.IncidenciaNodoFaltante, "Receptor@Nombre")
+		assertIncidencia(t, incidencias, cfdi.IncidenciaNodoFaltante, "Receptor@DomicilioFiscalReceptor")
+		assertIncidencia(t, incidencias, cfdi.IncidenciaNodoFaltante, "Receptor@RegimenFiscalReceptor")
+		assertIncidencia(t, incidencias, cfdi.IncidenciaNodoFaltante, "Receptor@UsoCFDI")
+	})
+
+	t.Run("checks the RFC and the Código Postal", func(t *testing.T) {
+		c := parseFixture(t, fixture.CFDIIngreso40)
+		c.Receptor.RFC = "XIA190128J62"
+		c.Receptor.DomicilioFiscalReceptor = "7634"
+
+		incidencias := c.ValidarReceptor()
+		assert.Len(t, incidencias, 2)
+		assertIncidencia(t, incidencias, cfdi.IncidenciaRFCInvalido, "Receptor@Rfc")
+		assertIncidencia(t, incidencias, cfdi.IncidenciaClaveInvalida, "Receptor@DomicilioFiscalReceptor")
+		assert.Contains(t, incidencias[0].Mensaje, "check digit")
+	})
+
+	t.Run("checks the UsoCFDI of pagos and nóminas", func(t *testing.T) {
+		c := parseFixture(t, fixture.CFDIPago40)
+		c.Receptor.UsoCFDI = "G03"
+		assertIncidencia(t, c.ValidarReceptor(), cfdi.IncidenciaUsoCFDITipo, "Receptor@UsoCFDI")
+
+		c = parseFixture(t, fixture.CFDINomina40)
+		c.Receptor.UsoCFDI = "S01"
+		assertIncidencia(t, c.ValidarReceptor(), cfdi.IncidenciaUsoCFDITipo, "Receptor@UsoCFDI")
+	})
+
+	t.Run("checks the generic RFCs", func(t *testing.T) {
+		c := parseFixture(t, fixture.CFDIIngreso40)
+		c.Receptor = cfdi.Receptor{
+			RFC:                     "XAXX010101000",
+			Nombre:                  "Público en general",
+			DomicilioFiscalReceptor: c.LugarExpedicion,
+			RegimenFiscalReceptor:   cfdi.RegimenSinObligaciones,
+			UsoCFDI:                 cfdi.UsoCFDISinEfectosFiscales,
+		}
+		assert.Empty(t, c.ValidarReceptor())
+
+		// Only a global CFDI must be issued to PUBLICO EN GENERAL.
+		c.Receptor.Nombre = "JUAN PEREZ"
+		assert.Empty(t, c.ValidarReceptor())
+
+		c.InformacionGlobal = &cfdi.InformacionGlobal{Periodicidad: "01", Meses: "01", Anio: "2024"}
+		c.Receptor = cfdi.Receptor{
+			RFC:                     "XAXX010101000",
+			Nombre:                  "JUAN PEREZ",
+			DomicilioFiscalReceptor: "76343",
+			RegimenFiscalReceptor:   "601",
+			UsoCFDI:                 "G03",
+		}
+
+		incidencias := c.ValidarReceptor()
+		assert.Len(t, incidencias, 4)
+		assertIncidencia(t, incidencias, cfdi.IncidenciaRFCGenerico, "Receptor@RegimenFiscalReceptor")
+		assertIncidencia(t, incidencias, cfdi.IncidenciaRFCGenerico, "Receptor@UsoCFDI")
+		assertIncidencia(t, incidencias, cfdi.IncidenciaRFCGenerico, "Receptor@DomicilioFiscalReceptor")
+		assertIncidencia(t, incidencias, cfdi.IncidenciaRFCGenerico, "Receptor@Nombre")
+
+		// Foreign residents keep their own name.
+		c.Receptor = cfdi.Receptor{
+			RFC:                     "XEXX010101000",
+			Nombre:                  "ACME INC",
+			DomicilioFiscalReceptor: c.LugarExpedicion,
+			RegimenFiscalReceptor:   cfdi.RegimenSinObligaciones,
+			UsoCFDI:                 cfdi.UsoCFDISinEfectosFiscales,
+		}
+		assert.Empty(t, c.ValidarReceptor())
+	})
+}
@@ -0,0 +1,149 @@
+package fiel_test
+
+import (
+	"app/src/fiel"
+	"app/test/fixture"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCertificate(t *testing.T) {
+	now := time.Now()
+
+	autoridades, err := fixture.SATAutoridades()
+	assert.NoError(t, err)
+
+	t.Run("should extract the certificate attributes", func(t *testing.T) {
+		efirma, err := fixture.ValidEfirma("EKU9003173C9")
+		assert.NoError(t, err)
+
+		cert, err := fiel.ParseCertificate(efirma.Certificate)
+		assert.NoError(t, err)
+
+		assert.Equal(t, "EKU9003173C9", cert.RFC)
+		assert.Equal(t, "30001000000500003416", cert.NoCertificado)
+		assert.Equal(t, fixture.SATIssuer, cert.Issuer)
+		assert.True(t, cert.MatchesRFC("eku9003173c9"))
+		assert.NoError(t, cert.Validate(now, autoridades))
+	})
+
+	t.Run("should reject data that is not a certificate", func(t *testing.T) {
+		_, err := fiel.ParseCertificate([]byte("not a certificate"))
+		assert.ErrorIs(t, err, fiel.ErrInvalidCertificate)
+	})
+
+	t.Run("should reject an expired certificate", func(t *testing.T) {
+		efirma, err := fixture.NewEfirma(
+			"EKU9003173C9", "30001000000500003416", fixture.SATIssuer,
+			now.AddDate(-4, 0, 0), now.AddDate(0, 0, -1),
+		)
+		assert.NoError(t, err)
+
+		cert, err := fiel.ParseCertificate(efirma.Certificate)
+		assert.NoError(t, err)
+		assert.ErrorIs(t, cert.Validate(now, autoridades), fiel.ErrExpiredCertificate)
+	})
+
+	t.Run("should reject a certificate that is not valid yet", func(t *testing.T) {
+		efirma, err := fixture.NewEfirma(
+			"EKU9003173C9", "30001000000500003416", fixture.SATIssuer,
+			now.AddDate(0, 0, 1), now.AddDate(4, 0, 0),
+		)
+		assert.NoError(t, err)
+
+		cert, err := fiel.ParseCertificate(efirma.Certificate)
+		assert.NoError(t, err)
+		assert.ErrorIs(t, cert.Validate(now, autoridades), fiel.ErrExpiredCertificate)
+	})
+
+	t.Run("should reject a certificate not issued by the SAT", func(t *testing.T) {
+		efirma, err := fixture.NewEfirma(
+			"EKU9003173C9", "30001000000500003416", "Some Other CA",
+			now.AddDate(0, -1, 0), now.AddDate(1, 0, 0),
+		)
+		assert.NoError(t, err)
+
+		cert, err := fiel.ParseCertificate(efirma.Certificate)
+		assert.NoError(t, err)
+		assert.ErrorIs(t, cert.Validate(now, autoridades), fiel.ErrUntrustedIssuer)
+	})
+
+	t.Run("should reject a self-signed certificate with the SAT issuer name", func(t *testing.T) {
+		efirma, err := fixture.ForgedEfirma("EKU9003173C9", now.AddDate(0, -1, 0), now.AddDate(1, 0, 0))
+		assert.NoError(t, err)
+
+		cert, err := fiel.ParseCertificate(efirma.Certificate)
+		assert.NoError(t, err)
+		assert.Equal(t, fixture.SATIssuer, cert.Issuer)
+		assert.ErrorIs(t, cert.Validate(now, autoridades), fiel.ErrUntrustedIssuer)
+	})
+
+	t.Run("should reject a certificate of another authority named like the SAT", func(t *testing.T) {
+		forged, err := fixture.NewAutoridad(fixture.SATIssuer)
+		assert.NoError(t, err)
+
+		efirma, err := fixture.NewEfirmaDe(forged, "EKU9003173C9", "30001000000500003416",
+			now.AddDate(0, -1, 0), now.AddDate(1, 0, 0))
+		assert.NoError(t, err)
+
+		cert, err := fiel.ParseCertificate(efirma.Certificate)
+		assert.NoError(t, err)
+		assert.Equal(t, fixture.SATIssuer, cert.Issuer)
+		assert.ErrorIs(t, cert.Validate(now, autoridades), fiel.ErrUntrustedIssuer)
+	})
+
+	t.Run("should reject every certificate without authorities", func(t *testing.T) {
+		efirma, err := fixture.ValidEfirma("EKU9003173C9")
+		assert.NoError(t, err)
+
+		cert, err := fiel.ParseCertificate(efirma.Certificate)
+		assert.NoError(t, err)
+		assert.ErrorIs(t, cert.Validate(now, nil), fiel.ErrUntrustedIssuer)
+		assert.ErrorIs(t, cert.Validate(now, x509.NewCertPool()), fiel.ErrUntrustedIssuer)
+	})
+}
+
+func TestLoadAuthorities(t *testing.T) {
+	autoridad, err := fixture.SATAutoridad()
+	assert.NoError(t, err)
+
+	efirma, err := fixture.ValidEfirma("EKU9003173C9")
+	assert.NoError(t, err)
+
+	cert, err := fiel.ParseCertificate(efirma.Certificate)
+	assert.NoError(t, err)
+
+	t.Run("should load DER and PEM authorities", func(t *testing.T) {
+		for name, data := range map[string][]byte{
+			"ac_sat.cer": autoridad.Certificate.Raw,
+			"ac_sat.pem": pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: autoridad.Certificate.Raw}),
+		} {
+			dir := t.TempDir()
+			assert.NoError(t, os.WriteFile(filepath.Join(dir, name), data, 0o600))
+			assert.NoError(t, os.WriteFile(filepath.Join(dir, "README.txt"), []byte("ignored"), 0o600))
+
+			autoridades, err := fiel.LoadAuthorities(dir)
+			assert.NoError(t, err)
+			assert.NoError(t, cert.Validate(time.Now(), autoridades), name)
+		}
+	})
+
+	t.Run("should reject certificates that are not authorities", func(t *testing.T) {
+		dir := t.TempDir()
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, "efirma.cer"), efirma.Certificate, 0o600))
+
+		_, err := fiel.LoadAuthorities(dir)
+		assert.ErrorIs(t, err, fiel.ErrInvalidCertificate)
+	})
+
+	t.Run("should fail for a missing directory", func(t *testing.T) {
+		_, err := fiel.LoadAuthorities(filepath.Join(t.TempDir(), "missing"))
+		assert.Error(t, err)
+	})
+}
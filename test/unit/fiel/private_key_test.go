@@ -0,0 +1,59 @@
+package fiel_test
+
+import (
+	"app/src/fiel"
+	"app/test/fixture"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrivateKey(t *testing.T) {
+	efirma, err := fixture.ValidEfirma("EKU9003173C9")
+	assert.NoError(t, err)
+
+	cert, err := fiel.ParseCertificate(efirma.Certificate)
+	assert.NoError(t, err)
+
+	keyDER, err := fixture.EncryptPrivateKey(efirma.PrivateKey, "12345678a")
+	assert.NoError(t, err)
+
+	t.Run("should decrypt the key with the right password", func(t *testing.T) {
+		key, err := fiel.DecryptPrivateKey(keyDER, []byte("12345678a"))
+		assert.NoError(t, err)
+		assert.True(t, key.Equal(efirma.PrivateKey))
+		assert.NoError(t, fiel.VerifyKeyPair(key, cert))
+	})
+
+	t.Run("should fail with a wrong password", func(t *testing.T) {
+		_, err := fiel.DecryptPrivateKey(keyDER, []byte("wrong_password"))
+		assert.ErrorIs(t, err, fiel.ErrInvalidPassword)
+	})
+
+	t.Run("should fail with data that is not an encrypted key", func(t *testing.T) {
+		_, err := fiel.DecryptPrivateKey([]byte("not a key"), []byte("12345678a"))
+		assert.ErrorIs(t, err, fiel.ErrInvalidPrivateKey)
+	})
+
+	t.Run("should reject iteration counts out of bounds before deriving the key", func(t *testing.T) {
+		for _, iterations := range []int{0, -1, 1000001, 1 << 31} {
+			keyDER, err := fixture.EncryptPrivateKeyDeclaring(efirma.PrivateKey, "12345678a", iterations)
+			assert.NoError(t, err)
+
+			_, err = fiel.DecryptPrivateKey(keyDER, []byte("12345678a"))
+			assert.ErrorIs(t, err, fiel.ErrInvalidPrivateKey, iterations)
+		}
+	})
+
+	t.Run("should detect a key that belongs to another certificate", func(t *testing.T) {
+		other, err := fixture.ValidEfirma("EKU9003173C9")
+		assert.NoError(t, err)
+
+		otherDER, err := fixture.EncryptPrivateKey(other.PrivateKey, "12345678a")
+		assert.NoError(t, err)
+
+		key, err := fiel.DecryptPrivateKey(otherDER, []byte("12345678a"))
+		assert.NoError(t, err)
+		assert.ErrorIs(t, fiel.VerifyKeyPair(key, cert), fiel.ErrKeyDoesNotMatchCer)
+	})
+}
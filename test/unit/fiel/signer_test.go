@@ -0,0 +1,71 @@
+package fiel_test
+
+import (
+	"app/src/fiel"
+	"app/test/fixture"
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/sha256"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSigner(t *testing.T) {
+	efirma, err := fixture.ValidEfirma("EKU9003173C9")
+	assert.NoError(t, err)
+
+	keyDER, err := fixture.EncryptPrivateKey(efirma.PrivateKey, "12345678a")
+	assert.NoError(t, err)
+
+	data := []byte("||4.0|A|1|2024-01-01T00:00:00||")
+
+	t.Run("should sign with RSA-SHA256 and RSA-SHA1", func(t *testing.T) {
+		signer, err := fiel.NewSigner(efirma.Certificate, keyDER, []byte("12345678a"))
+		assert.NoError(t, err)
+		defer signer.Close()
+
+		publicKey, ok := signer.Certificate().X509.PublicKey.(*rsa.PublicKey)
+		assert.True(t, ok)
+
+		signature, err := signer.Sign(data, crypto.SHA256)
+		assert.NoError(t, err)
+		digest256 := sha256.Sum256(data)
+		assert.NoError(t, rsa.VerifyPKCS1v15(publicKey, crypto.SHA256, digest256[:], signature))
+
+		signature, err = signer.Sign(data, crypto.SHA1)
+		assert.NoError(t, err)
+		digest1 := sha1.Sum(data)
+		assert.NoError(t, rsa.VerifyPKCS1v15(publicKey, crypto.SHA1, digest1[:], signature))
+	})
+
+	t.Run("should not sign after being closed", func(t *testing.T) {
+		signer, err := fiel.NewSigner(efirma.Certificate, keyDER, []byte("12345678a"))
+		assert.NoError(t, err)
+
+		signer.Close()
+		_, err = signer.Sign(data, crypto.SHA256)
+		assert.ErrorIs(t, err, fiel.ErrSignerClosed)
+	})
+
+	t.Run("should wipe the password once the key is decrypted", func(t *testing.T) {
+		password := []byte("12345678a")
+
+		signer, err := fiel.NewSigner(efirma.Certificate, keyDER, password)
+		assert.NoError(t, err)
+		defer signer.Close()
+
+		assert.Equal(t, make([]byte, len(password)), password)
+
+		wrong := []byte("wrong_password")
+		_, err = fiel.NewSigner(efirma.Certificate, keyDER, wrong)
+		assert.Error(t, err)
+		assert.Equal(t, make([]byte, len(wrong)), wrong)
+	})
+
+	t.Run("should fail with a wrong password", func(t *testing.T) {
+		_, err := fiel.NewSigner(efirma.Certificate, keyDER, []byte("wrong_password"))
+		assert.ErrorIs(t, err, fiel.ErrInvalidPassword)
+	})
+}
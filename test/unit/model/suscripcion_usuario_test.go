@@ -0,0 +1,76 @@
+package model_test
+
+import (
+	"app/src/model"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestSuscripcionUsuario(t *testing.T) {
+	suscripcion := &model.SuscripcionUsuario{
+		LimiteDescargasMensuales: 10,
+		FechaInicio:              date(2024, time.January, 31),
+		FechaFin:                 date(2024, time.December, 31),
+		Estatus:                  model.EstatusSuscripcionActivo,
+	}
+
+	t.Run("should anchor monthly periods to the start date", func(t *testing.T) {
+		assert.Equal(t, date(2024, time.January, 31), suscripcion.PeriodoActual(date(2024, time.January, 31)))
+		assert.Equal(t, date(2024, time.January, 31), suscripcion.PeriodoActual(date(2024, time.February, 28)))
+		assert.Equal(t, date(2024, time.February, 29), suscripcion.PeriodoActual(date(2024, time.February, 29)))
+		assert.Equal(t, date(2024, time.March, 31), suscripcion.PeriodoActual(date(2024, time.April, 29)))
+		assert.Equal(t, date(2024, time.April, 30), suscripcion.PeriodoActual(date(2024, time.April, 30)))
+	})
+
+	t.Run("should reset on the next period start", func(t *testing.T) {
+		assert.Equal(t, date(2024, time.February, 29), suscripcion.ProximoReinicio(date(2024, time.February, 10)))
+		assert.Equal(t, date(2024, time.March, 31), suscripcion.ProximoReinicio(date(2024, time.February, 29)))
+	})
+
+	t.Run("should never report negative remaining downloads", func(t *testing.T) {
+		usada := *suscripcion
+
+		usada.DescargasUsadas = 4
+		assert.Equal(t, 6, usada.DescargasRestantes())
+
+		usada.DescargasUsadas = 12
+		assert.Equal(t, 0, usada.DescargasRestantes())
+	})
+
+	t.Run("should only be in force between its dates while active", func(t *testing.T) {
+		assert.True(t, suscripcion.Vigente(time.Date(2024, time.December, 31, 23, 0, 0, 0, time.UTC)))
+		assert.False(t, suscripcion.Vigente(date(2025, time.January, 1)))
+		assert.False(t, suscripcion.Vigente(date(2024, time.January, 30)))
+
+		cancelada := *suscripcion
+		cancelada.Estatus = model.EstatusSuscripcionCancelado
+		assert.False(t, cancelada.Vigente(date(2024, time.June, 1)))
+	})
+}
+
+func TestSuscripcionUsuarioVigencia(t *testing.T) {
+	t.Run("should end the day before the same day of the last month", func(t *testing.T) {
+		assert.Equal(t, date(2024, time.February, 14), model.FinVigencia(date(2024, time.January, 15), 1))
+		assert.Equal(t, date(2024, time.February, 28), model.FinVigencia(date(2024, time.January, 31), 1))
+		assert.Equal(t, date(2025, time.January, 14), model.FinVigencia(date(2024, time.January, 15), 12))
+	})
+
+	t.Run("should extend without drifting from the start date", func(t *testing.T) {
+		suscripcion := &model.SuscripcionUsuario{
+			FechaInicio: date(2024, time.January, 31),
+			FechaFin:    model.FinVigencia(date(2024, time.January, 31), 1),
+		}
+
+		suscripcion.Extender(1)
+		assert.Equal(t, date(2024, time.March, 30), suscripcion.FechaFin)
+
+		suscripcion.Extender(2)
+		assert.Equal(t, date(2024, time.May, 30), suscripcion.FechaFin)
+	})
+}
@@ -0,0 +1,39 @@
+package response_test
+
+import (
+	"app/src/response"
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCSV(t *testing.T) {
+	app := fiber.New()
+	app.Get("/", func(c *fiber.Ctx) error {
+		return response.CSV(c, "reporte.csv", []string{"Nombre", "Importe"}, [][]string{
+			{"=HYPERLINK(\"http://example.com\")", "1000.00"},
+			{"+52 55 1234", "-5.00"},
+			{"@SUM(A1)", "\tTAB"},
+			{"\rCR", ""},
+			{"JUAN PÉREZ", "a=b"},
+		})
+	})
+
+	apiResponse, err := app.Test(httptest.NewRequest("GET", "/", nil))
+	assert.NoError(t, err)
+	assert.Equal(t, "text/csv; charset=utf-8", apiResponse.Header.Get(fiber.HeaderContentType))
+	assert.Equal(t, `attachment; filename="reporte.csv"`, apiResponse.Header.Get(fiber.HeaderContentDisposition))
+
+	body, err := io.ReadAll(apiResponse.Body)
+	assert.NoError(t, err)
+	assert.Equal(t, "\uFEFF"+
+		"Nombre,Importe\n"+
+		"\"'=HYPERLINK(\"\"http://example.com\"\")\",1000.00\n"+
+		"'+52 55 1234,'-5.00\n"+
+		"'@SUM(A1),'\tTAB\n"+
+		"\"'\rCR\",\n"+
+		"JUAN PÉREZ,a=b\n", string(body))
+}
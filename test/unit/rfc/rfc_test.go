@@ -0,0 +1,54 @@
+package rfc_test
+
+import (
+	"app/src/rfc"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheck(t *testing.T) {
+	t.Run("should accept valid RFCs", func(t *testing.T) {
+		for _, valor := range []string{
+			"EKU9003173C9",  // persona moral
+			"XIA190128J61",  // persona moral
+			"CACX7605101P8", // persona física
+			"FUNK671228PH6", // persona física
+			"eku9003173c9",
+			"XAXX010101000",
+			"XEXX010101000",
+		} {
+			assert.NoError(t, rfc.Check(valor), valor)
+		}
+	})
+
+	t.Run("should tell personas morales from personas físicas", func(t *testing.T) {
+		assert.True(t, rfc.IsPersonaMoral("EKU9003173C9"))
+		assert.False(t, rfc.IsPersonaMoral("CACX7605101P8"))
+		assert.True(t, rfc.IsGeneric("xaxx010101000"))
+	})
+
+	t.Run("should reject RFCs with the wrong length", func(t *testing.T) {
+		assert.ErrorIs(t, rfc.Check("EKU9003173C"), rfc.ErrLength)
+		assert.ErrorIs(t, rfc.Check("CACX7605101P81"), rfc.ErrLength)
+	})
+
+	t.Run("should reject an invalid name segment", func(t *testing.T) {
+		assert.ErrorIs(t, rfc.Check("EK19003173C9"), rfc.ErrName)
+	})
+
+	t.Run("should reject an invalid date segment", func(t *testing.T) {
+		assert.ErrorIs(t, rfc.Check("EKU9013173C9"), rfc.ErrDate)
+		assert.ErrorIs(t, rfc.Check("EKU9002303C9"), rfc.ErrDate)
+	})
+
+	t.Run("should reject an invalid homoclave", func(t *testing.T) {
+		assert.ErrorIs(t, rfc.Check("EKU9003170C9"), rfc.ErrHomoclave)
+		assert.ErrorIs(t, rfc.Check("EKU900317-C9"), rfc.ErrHomoclave)
+	})
+
+	t.Run("should reject a wrong check digit", func(t *testing.T) {
+		assert.ErrorIs(t, rfc.Check("EKU9003173C8"), rfc.ErrCheckDigit)
+		assert.ErrorIs(t, rfc.Check("CACX7605101P9"), rfc.ErrCheckDigit)
+	})
+}
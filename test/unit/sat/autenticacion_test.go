@@ -0,0 +1,97 @@
+package sat_test
+
+import (
+	"app/src/fiel"
+	"app/src/sat"
+	"app/test/fakesat"
+	"app/test/fixture"
+	"context"
+	"crypto"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// tamperedSigner signs something other than what it is asked to sign.
+type tamperedSigner struct {
+	*fiel.Signer
+}
+
+func (s tamperedSigner) Sign(data []byte, hash crypto.Hash) ([]byte, error) {
+	return s.Signer.Sign(append(data, '!'), hash)
+}
+
+func TestAutentica(t *testing.T) {
+	fake := fakesat.New()
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	client := sat.NewClient(sat.Endpoints{Autenticacion: server.URL})
+
+	signer, err := fixture.ValidSigner("EKU9003173C9")
+	assert.NoError(t, err)
+	defer signer.Close()
+
+	t.Run("should return a token with its expiry", func(t *testing.T) {
+		token, err := client.Autentica(context.Background(), signer)
+		assert.NoError(t, err)
+
+		assert.NotEmpty(t, token.Value)
+		assert.WithinDuration(t, time.Now().Add(5*time.Minute), token.Expires, 5*time.Second)
+		assert.True(t, token.Valid(time.Now()))
+		assert.False(t, token.Valid(token.Expires))
+
+		rfc, ok := fake.RFCForToken(token.Value)
+		assert.True(t, ok)
+		assert.Equal(t, "EKU9003173C9", rfc)
+	})
+
+	t.Run("should return the SOAP fault when the signature is invalid", func(t *testing.T) {
+		_, err := client.Autentica(context.Background(), tamperedSigner{signer})
+
+		var fault *sat.FaultError
+		assert.True(t, errors.As(err, &fault))
+		assert.Equal(t, "a:InvalidSecurity", fault.Code)
+	})
+}
+
+func TestTokenCache(t *testing.T) {
+	now := time.Now()
+	cache := sat.NewTokenCache()
+	cache.Now = func() time.Time { return now }
+
+	// Two users registered the same RFC, each one has their own e.firma.
+	propio, ajeno := uuid.New(), uuid.New()
+	cache.Set(propio, &sat.Token{Value: "token", Expires: now.Add(5 * time.Minute)})
+
+	t.Run("should return the token of the fiscal data while it is valid", func(t *testing.T) {
+		token, ok := cache.Get(propio)
+		assert.True(t, ok)
+		assert.Equal(t, "token", token.Value)
+
+		_, ok = cache.Get(ajeno)
+		assert.False(t, ok)
+	})
+
+	t.Run("should drop an invalidated token", func(t *testing.T) {
+		cache.Set(ajeno, &sat.Token{Value: "otro", Expires: now.Add(5 * time.Minute)})
+		cache.Delete(ajeno)
+
+		_, ok := cache.Get(ajeno)
+		assert.False(t, ok)
+
+		_, ok = cache.Get(propio)
+		assert.True(t, ok)
+	})
+
+	t.Run("should drop the token when it is about to expire", func(t *testing.T) {
+		now = now.Add(5*time.Minute - 10*time.Second)
+
+		_, ok := cache.Get(propio)
+		assert.False(t, ok)
+	})
+}
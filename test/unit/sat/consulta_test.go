@@ -0,0 +1,114 @@
+package sat_test
+
+import (
+	"app/src/cfdi"
+	"app/src/sat"
+	"app/test/fakesat"
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExpresionImpresa(t *testing.T) {
+	t.Run("should pad the total to 10 integer digits and 6 decimals", func(t *testing.T) {
+		expresion := sat.ExpresionImpresa("eku9003173c9", "XIA190128J61", cfdi.MustParseDecimal("1624.5"),
+			"5b4b4d8e-2c6f-4a0b-9f4e-0e5c1a2b3c4d")
+
+		assert.Equal(t,
+			"?re=EKU9003173C9&rr=XIA190128J61&tt=0000001624.500000&id=5B4B4D8E-2C6F-4A0B-9F4E-0E5C1A2B3C4D",
+			expresion)
+	})
+
+	t.Run("should keep an & in the RFC", func(t *testing.T) {
+		expresion := sat.ExpresionImpresa("A&B010101AAA", "XAXX010101000", cfdi.MustParseDecimal("0.1234567"),
+			"5B4B4D8E-2C6F-4A0B-9F4E-0E5C1A2B3C4D")
+
+		assert.Equal(t,
+			"?re=A&B010101AAA&rr=XAXX010101000&tt=0000000000.123457&id=5B4B4D8E-2C6F-4A0B-9F4E-0E5C1A2B3C4D",
+			expresion)
+	})
+}
+
+func TestConsultaCFDI(t *testing.T) {
+	fake := fakesat.New()
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	client := sat.NewClient(sat.Endpoints{Consulta: server.URL})
+
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	client.Now = func() time.Time { return now }
+
+	const id = "5B4B4D8E-2C6F-4A0B-9F4E-0E5C1A2B3C4D"
+	expresion := sat.ExpresionImpresa("A&B010101AAA", "XIA190128J61", cfdi.MustParseDecimal("1624.00"), id)
+
+	t.Run("should return the estado of a vigente CFDI", func(t *testing.T) {
+		fake.SetCFDI(id, fakesat.CFDI{RFCEmisor: "A&B010101AAA", RFCReceptor: "XIA190128J61", Total: "1624"})
+
+		result, err := client.ConsultaCFDI(context.Background(), expresion)
+		assert.NoError(t, err)
+		assert.Equal(t, sat.EstadoCFDIVigente, result.Estado)
+		assert.Equal(t, "S - Comprobante obtenido satisfactoriamente.", result.CodigoEstatus)
+		assert.Equal(t, "Cancelable sin aceptación", result.EsCancelable)
+		assert.Equal(t, "200", result.ValidacionEFOS)
+		assert.Equal(t, now, result.ConsultadoAt)
+	})
+
+	t.Run("should report a cancellation in progress", func(t *testing.T) {
+		fake.SetCFDI(id, fakesat.CFDI{
+			RFCEmisor: "A&B010101AAA", RFCReceptor: "XIA190128J61", Total: "1624.00",
+			EstatusCancelacion: fakesat.CancelacionEnCurso,
+		})
+
+		result, err := client.ConsultaCFDI(context.Background(), expresion)
+		assert.NoError(t, err)
+		assert.Equal(t, sat.EstadoCFDIVigente, result.Estado)
+		assert.Equal(t, sat.EstatusCancelacionCurso, result.EstatusCancelacion)
+	})
+
+	t.Run("should return the estado of a cancelled CFDI", func(t *testing.T) {
+		fake.SetCFDI(id, fakesat.CFDI{
+			RFCEmisor: "A&B010101AAA", RFCReceptor: "XIA190128J61", Total: "1624.00", Estado: fakesat.EstadoCancelado,
+		})
+
+		result, err := client.ConsultaCFDI(context.Background(), expresion)
+		assert.NoError(t, err)
+		assert.Equal(t, sat.EstadoCFDICancelado, result.Estado)
+	})
+
+	t.Run("should report No Encontrado if the total doesn't match", func(t *testing.T) {
+		result, err := client.ConsultaCFDI(context.Background(),
+			sat.ExpresionImpresa("A&B010101AAA", "XIA190128J61", cfdi.MustParseDecimal("1624.01"), id))
+		assert.NoError(t, err)
+		assert.Equal(t, sat.EstadoCFDINoEncontrado, result.Estado)
+		assert.Equal(t, "N - 602: Comprobante no encontrado.", result.CodigoEstatus)
+	})
+
+	t.Run("should report an invalid expresión impresa", func(t *testing.T) {
+		result, err := client.ConsultaCFDI(context.Background(), "?re=EKU9003173C9")
+		assert.NoError(t, err)
+		assert.Equal(t, sat.EstadoCFDINoEncontrado, result.Estado)
+		assert.Contains(t, result.CodigoEstatus, "N - 601")
+	})
+}
+
+func TestConsultaCache(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	cache := sat.NewConsultaCache(time.Hour)
+	cache.Now = func() time.Time { return now }
+
+	cache.Set("5b4b4d8e-2c6f-4a0b-9f4e-0e5c1a2b3c4d", &sat.ConsultaResult{Estado: sat.EstadoCFDIVigente, ConsultadoAt: now})
+
+	result, ok := cache.Get("5B4B4D8E-2C6F-4A0B-9F4E-0E5C1A2B3C4D")
+	assert.True(t, ok)
+	assert.Equal(t, sat.EstadoCFDIVigente, result.Estado)
+
+	now = now.Add(time.Hour)
+
+	_, ok = cache.Get("5B4B4D8E-2C6F-4A0B-9F4E-0E5C1A2B3C4D")
+	assert.False(t, ok)
+}
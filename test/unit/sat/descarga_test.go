@@ -0,0 +1,81 @@
+package sat_test
+
+import (
+	"app/src/sat"
+	"app/test/fakesat"
+	"app/test/fixture"
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDescargar(t *testing.T) {
+	fake := fakesat.New()
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	client := sat.NewClient(sat.Endpoints{Autenticacion: server.URL, Descarga: server.URL})
+
+	signer, err := fixture.ValidSigner("EKU9003173C9")
+	assert.NoError(t, err)
+	defer signer.Close()
+
+	token, err := client.Autentica(context.Background(), signer)
+	assert.NoError(t, err)
+
+	paquete, err := fixture.Paquete(map[string][]byte{
+		"5B4B4D8E-2C6F-4A0B-9F4E-0E5C1A2B3C4D.xml": []byte("<cfdi:Comprobante/>"),
+		"6C5C5E9F-3D7A-4B1C-8A5F-1F6D2B3C4D5E.xml": []byte("<cfdi:Comprobante/>"),
+	})
+	assert.NoError(t, err)
+	fake.SetPaquete("SOLICITUD_01", paquete)
+
+	t.Run("should decode the package and count its files", func(t *testing.T) {
+		result, err := client.Descargar(context.Background(), token.Value, signer, "EKU9003173C9", "SOLICITUD_01")
+		assert.NoError(t, err)
+		assert.Equal(t, sat.CodSolicitudAceptada, result.CodEstatus)
+		assert.Equal(t, paquete, result.Paquete)
+
+		archivos, err := sat.ValidatePaquete(result.Paquete)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, archivos)
+	})
+
+	t.Run("should stop serving a package after the SAT limit", func(t *testing.T) {
+		_, err := client.Descargar(context.Background(), token.Value, signer, "EKU9003173C9", "SOLICITUD_01")
+		assert.NoError(t, err)
+
+		result, err := client.Descargar(context.Background(), token.Value, signer, "EKU9003173C9", "SOLICITUD_01")
+		assert.NoError(t, err)
+		assert.Equal(t, sat.CodMaximoDescargas, result.CodEstatus)
+		assert.Nil(t, result.Paquete)
+	})
+
+	t.Run("should report unknown packages", func(t *testing.T) {
+		result, err := client.Descargar(context.Background(), token.Value, signer, "EKU9003173C9", "UNKNOWN_01")
+		assert.NoError(t, err)
+		assert.Equal(t, sat.CodSinInformacion, result.CodEstatus)
+	})
+}
+
+func TestValidatePaquete(t *testing.T) {
+	t.Run("should reject an empty package", func(t *testing.T) {
+		_, err := sat.ValidatePaquete(nil)
+		assert.ErrorIs(t, err, sat.ErrPaqueteVacio)
+	})
+
+	t.Run("should reject data that is not a ZIP archive", func(t *testing.T) {
+		_, err := sat.ValidatePaquete([]byte("not a zip"))
+		assert.ErrorIs(t, err, sat.ErrPaqueteInvalido)
+	})
+
+	t.Run("should reject a truncated archive", func(t *testing.T) {
+		paquete, err := fixture.Paquete(map[string][]byte{"a.xml": []byte("<cfdi:Comprobante/>")})
+		assert.NoError(t, err)
+
+		_, err = sat.ValidatePaquete(paquete[:len(paquete)-10])
+		assert.ErrorIs(t, err, sat.ErrPaqueteInvalido)
+	})
+}
@@ -0,0 +1,76 @@
+package sat_test
+
+import (
+	"app/src/sat"
+	"app/test/fixture"
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseMetadata(t *testing.T) {
+	t.Run("parses the rows of the fixture", func(t *testing.T) {
+		data, err := fixture.CFDI(fixture.CFDIMetadata)
+		assert.NoError(t, err)
+
+		filas, err := sat.ParseMetadata(bytes.NewReader(data))
+		assert.NoError(t, err)
+		assert.Len(t, filas, 4)
+
+		vigente := filas[0]
+		assert.Equal(t, "5B4B4D8E-2C6F-4A0B-9F4E-0E5C1A2B3C4D", vigente.UUID)
+		assert.Equal(t, "EKU9003173C9", vigente.RFCEmisor)
+		assert.Equal(t, "XENON INDUSTRIAL ARTICLES", vigente.NombreReceptor)
+		assert.Equal(t, "SPR190613I52", vigente.RFCPac)
+		assert.Equal(t, time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC), vigente.FechaEmision)
+		assert.Equal(t, time.Date(2024, 1, 15, 10, 31, 12, 0, time.UTC), vigente.FechaCertificacion)
+		assert.Equal(t, "1624.00", vigente.Monto.String())
+		assert.Equal(t, "I", vigente.EfectoComprobante)
+		assert.False(t, vigente.Cancelado)
+		assert.Nil(t, vigente.FechaCancelacion)
+
+		cancelado := filas[1]
+		assert.True(t, cancelado.Cancelado)
+		assert.Equal(t, time.Date(2024, 2, 5, 9, 0, 0, 0, time.UTC), *cancelado.FechaCancelacion)
+	})
+
+	t.Run("locates the columns by name", func(t *testing.T) {
+		data := "\xEF\xBB\xBFestatus~UUID~RfcEmisor~NombreEmisor~RfcReceptor~NombreReceptor~RfcPac~FechaEmision~FechaCertificacionSat~Monto~EfectoComprobante~FechaCancelacion\n" +
+			"Vigente~8d1f2e3a-4b5c-4d6e-9f70-81a2b3c4d5e6~eku9003173c9~E~XIA190128J61~X~SPR190613I52~2024-01-25T12:00:00~~580~i~\n\n"
+
+		filas, err := sat.ParseMetadata(strings.NewReader(data))
+		assert.NoError(t, err)
+		assert.Len(t, filas, 1)
+		assert.Equal(t, "8D1F2E3A-4B5C-4D6E-9F70-81A2B3C4D5E6", filas[0].UUID)
+		assert.Equal(t, "EKU9003173C9", filas[0].RFCEmisor)
+		assert.Equal(t, "I", filas[0].EfectoComprobante)
+		assert.True(t, filas[0].FechaCertificacion.IsZero())
+		assert.False(t, filas[0].Cancelado)
+	})
+
+	t.Run("skips and reports invalid rows", func(t *testing.T) {
+		header := "Uuid~RfcEmisor~NombreEmisor~RfcReceptor~NombreReceptor~RfcPac~FechaEmision~FechaCertificacionSat~Monto~EfectoComprobante~Estatus~FechaCancelacion\n"
+		data := header +
+			"5B4B4D8E-2C6F-4A0B-9F4E-0E5C1A2B3C4D~EKU9003173C9~E~XIA190128J61~X~SPR190613I52~2024-01-15 10:30:00~2024-01-15 10:31:12~1624.00~I~1~\n" +
+			"7A8B9C0D-1E2F-4A3B-9C4D-5E6F7A8B9C0D~EKU9003173C9~E~XIA190128J61~X~SPR190613I52~2024-01-20 16:00:00~~1,160.00~E~1~\n" +
+			"1C2D3E4F-5A6B-4C7D-8E9F-0A1B2C3D4E5F~EKU9003173C9~A~B~EKU9003173C9~X~SPR190613I52~2024-02-01 07:45:00~~0~T~1~\n" +
+			"8D1F2E3A-4B5C-4D6E-9F70-81A2B3C4D5E6~EKU9003173C9~E~XIA190128J61~X~SPR190613I52~2024-01-25 12:00:00~~580~I~2~\n"
+
+		filas, err := sat.ParseMetadata(strings.NewReader(data))
+		assert.ErrorIs(t, err, sat.ErrMetadataInvalida)
+		assert.ErrorContains(t, err, "line 3: Monto")
+		assert.ErrorContains(t, err, "line 4: expected 12 fields, got 13")
+		assert.ErrorContains(t, err, `line 5: unknown Estatus "2"`)
+		assert.Len(t, filas, 1)
+	})
+
+	t.Run("rejects files without the expected header", func(t *testing.T) {
+		for _, data := range []string{"", "\r\n", "Uuid~RfcEmisor~Monto\n"} {
+			_, err := sat.ParseMetadata(strings.NewReader(data))
+			assert.ErrorIs(t, err, sat.ErrMetadataInvalida, data)
+		}
+	})
+}
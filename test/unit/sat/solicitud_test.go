@@ -0,0 +1,97 @@
+package sat_test
+
+import (
+	"app/src/sat"
+	"app/test/fakesat"
+	"app/test/fixture"
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSolicitaDescarga(t *testing.T) {
+	fake := fakesat.New()
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	client := sat.NewClient(sat.Endpoints{Autenticacion: server.URL, Solicitud: server.URL})
+
+	signer, err := fixture.ValidSigner("EKU9003173C9")
+	assert.NoError(t, err)
+	defer signer.Close()
+
+	token, err := client.Autentica(context.Background(), signer)
+	assert.NoError(t, err)
+
+	params := sat.SolicitudParams{
+		RFCSolicitante: "EKU9003173C9",
+		TipoDescarga:   sat.TipoDescargaEmitidos,
+		TipoSolicitud:  sat.TipoSolicitudCFDI,
+		FechaInicial:   time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		FechaFinal:     time.Date(2024, 1, 31, 23, 59, 59, 0, time.UTC),
+		RFCContraparte: "XIA190128J61",
+	}
+
+	t.Run("should send a signed request and return the IdSolicitud", func(t *testing.T) {
+		result, err := client.SolicitaDescarga(context.Background(), token.Value, signer, params)
+		assert.NoError(t, err)
+
+		assert.True(t, result.Aceptada())
+		assert.NotEmpty(t, result.IDSolicitud)
+		assert.Equal(t, "EKU9003173C9", result.RFCSolicitante)
+
+		solicitudes := fake.Solicitudes()
+		assert.Len(t, solicitudes, 1)
+		assert.Equal(t, "SolicitaDescargaEmitidos", solicitudes[0].Operation)
+		assert.Equal(t, "2024-01-01T00:00:00", solicitudes[0].Attrs["FechaInicial"])
+		assert.Equal(t, "EKU9003173C9", solicitudes[0].Attrs["RfcEmisor"])
+	})
+
+	t.Run("should report a duplicated request", func(t *testing.T) {
+		result, err := client.SolicitaDescarga(context.Background(), token.Value, signer, params)
+		assert.NoError(t, err)
+		assert.Equal(t, sat.CodSolicitudDuplicada, result.CodEstatus)
+		assert.False(t, result.Aceptada())
+	})
+
+	t.Run("should filter received CFDIs by emisor", func(t *testing.T) {
+		recibidos := params
+		recibidos.TipoDescarga = sat.TipoDescargaRecibidos
+		recibidos.EstadoComprobante = "Vigente"
+
+		result, err := client.SolicitaDescarga(context.Background(), token.Value, signer, recibidos)
+		assert.NoError(t, err)
+		assert.True(t, result.Aceptada())
+
+		for _, solicitud := range fake.Solicitudes() {
+			if solicitud.ID == result.IDSolicitud {
+				assert.Equal(t, "SolicitaDescargaRecibidos", solicitud.Operation)
+				assert.Equal(t, "EKU9003173C9", solicitud.Attrs["RfcReceptor"])
+				assert.Equal(t, "XIA190128J61", solicitud.Attrs["RfcEmisor"])
+			}
+		}
+	})
+
+	t.Run("should return the CodEstatus of rejected requests", func(t *testing.T) {
+		fake.SolicitudCodEstatus = sat.CodSolicitudesAgotadas
+		defer func() { fake.SolicitudCodEstatus = "" }()
+
+		params.FechaFinal = params.FechaFinal.AddDate(0, 1, 0)
+
+		result, err := client.SolicitaDescarga(context.Background(), token.Value, signer, params)
+		assert.NoError(t, err)
+		assert.Equal(t, sat.CodSolicitudesAgotadas, result.CodEstatus)
+		assert.NotEmpty(t, result.Mensaje)
+	})
+
+	t.Run("should be rejected with an unknown token", func(t *testing.T) {
+		params.FechaFinal = params.FechaFinal.AddDate(0, 1, 0)
+
+		result, err := client.SolicitaDescarga(context.Background(), "invalid", signer, params)
+		assert.NoError(t, err)
+		assert.Equal(t, sat.CodUsuarioNoValido, result.CodEstatus)
+	})
+}
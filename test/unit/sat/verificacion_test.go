@@ -0,0 +1,69 @@
+package sat_test
+
+import (
+	"app/src/sat"
+	"app/test/fakesat"
+	"app/test/fixture"
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerificaSolicitudDescarga(t *testing.T) {
+	fake := fakesat.New()
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	client := sat.NewClient(sat.Endpoints{Autenticacion: server.URL, Solicitud: server.URL, Verificacion: server.URL})
+
+	signer, err := fixture.ValidSigner("EKU9003173C9")
+	assert.NoError(t, err)
+	defer signer.Close()
+
+	token, err := client.Autentica(context.Background(), signer)
+	assert.NoError(t, err)
+
+	solicitud, err := client.SolicitaDescarga(context.Background(), token.Value, signer, sat.SolicitudParams{
+		RFCSolicitante: "EKU9003173C9",
+		TipoDescarga:   sat.TipoDescargaRecibidos,
+		TipoSolicitud:  sat.TipoSolicitudMetadata,
+		FechaInicial:   time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		FechaFinal:     time.Date(2024, 1, 31, 23, 59, 59, 0, time.UTC),
+	})
+	assert.NoError(t, err)
+
+	t.Run("should report a request in progress", func(t *testing.T) {
+		fake.SetEstado(solicitud.IDSolicitud, fakesat.EstadoEnProceso)
+
+		result, err := client.VerificaSolicitudDescarga(
+			context.Background(), token.Value, signer, "EKU9003173C9", solicitud.IDSolicitud,
+		)
+		assert.NoError(t, err)
+		assert.Equal(t, sat.CodSolicitudAceptada, result.CodEstatus)
+		assert.Equal(t, sat.EstadoSolicitudEnProceso, result.EstadoSolicitud)
+		assert.Empty(t, result.IDsPaquetes)
+	})
+
+	t.Run("should return the packages of a finished request", func(t *testing.T) {
+		fake.Complete(solicitud.IDSolicitud, 1500, solicitud.IDSolicitud+"_01", solicitud.IDSolicitud+"_02")
+
+		result, err := client.VerificaSolicitudDescarga(
+			context.Background(), token.Value, signer, "EKU9003173C9", solicitud.IDSolicitud,
+		)
+		assert.NoError(t, err)
+		assert.Equal(t, sat.EstadoSolicitudTerminada, result.EstadoSolicitud)
+		assert.Equal(t, 1500, result.NumeroCFDIs)
+		assert.Equal(t, []string{solicitud.IDSolicitud + "_01", solicitud.IDSolicitud + "_02"}, result.IDsPaquetes)
+	})
+
+	t.Run("should not find requests of another RFC", func(t *testing.T) {
+		result, err := client.VerificaSolicitudDescarga(
+			context.Background(), token.Value, signer, "EKU9003173C9", "00000000-0000-0000-0000-000000000000",
+		)
+		assert.NoError(t, err)
+		assert.Equal(t, sat.CodSinInformacion, result.CodEstatus)
+	})
+}
@@ -0,0 +1,119 @@
+package secrets_test
+
+import (
+	"app/src/secrets"
+	"app/src/utils"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeyring(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("should use the legacy key as version 1", func(t *testing.T) {
+		keyring, err := secrets.ParseKeyring("", "legacy")
+		assert.NoError(t, err)
+		version, err := keyring.CurrentVersion(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, version)
+
+		legacy, err := utils.EncryptAESGCM("legacy", []byte("secret"))
+		assert.NoError(t, err)
+
+		plaintext, err := keyring.DecryptLegacy(legacy, 1)
+		assert.NoError(t, err)
+		assert.Equal(t, "secret", string(plaintext))
+	})
+
+	t.Run("should wrap with the newest version and unwrap older ones", func(t *testing.T) {
+		oldKeyring, err := secrets.ParseKeyring("1:first", "")
+		assert.NoError(t, err)
+
+		keyring, err := secrets.ParseKeyring("1:first, 2:second", "")
+		assert.NoError(t, err)
+		current, err := keyring.CurrentVersion(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, current)
+
+		dataKey, err := secrets.NewDataKey()
+		assert.NoError(t, err)
+
+		wrapped, version, err := oldKeyring.Wrap(ctx, dataKey)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, version)
+
+		unwrapped, err := keyring.Unwrap(ctx, wrapped, version)
+		assert.NoError(t, err)
+		assert.Equal(t, dataKey, unwrapped)
+
+		rewrapped, version, err := keyring.Wrap(ctx, unwrapped)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, version)
+
+		_, err = keyring.Unwrap(ctx, rewrapped, 1)
+		assert.Error(t, err)
+	})
+
+	t.Run("should fail on an unknown version", func(t *testing.T) {
+		keyring, err := secrets.ParseKeyring("2:second", "")
+		assert.NoError(t, err)
+
+		_, err = keyring.Unwrap(ctx, "irrelevant", 1)
+		assert.ErrorIs(t, err, secrets.ErrUnknownKeyVersion)
+	})
+
+	t.Run("should load the master keys from a file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "master.keys")
+		err := os.WriteFile(path, []byte("# rotated 2024-10\n1:first\n3:third\n"), 0o600)
+		assert.NoError(t, err)
+
+		keyring, err := secrets.LoadKeyringFile(path)
+		assert.NoError(t, err)
+
+		version, err := keyring.CurrentVersion(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, 3, version)
+	})
+
+	t.Run("should reject malformed configuration", func(t *testing.T) {
+		for _, spec := range []string{"first", "a:first", "1:", "1: ", "0:zero", "1:a,1:b"} {
+			_, err := secrets.ParseKeyring(spec, "")
+			assert.Error(t, err, spec)
+		}
+	})
+
+	t.Run("should fail without any master key", func(t *testing.T) {
+		for _, legacyKey := range []string{"", "  "} {
+			_, err := secrets.ParseKeyring("", legacyKey)
+			assert.ErrorIs(t, err, secrets.ErrNoMasterKey)
+		}
+
+		_, err := secrets.NewKeyring(map[int]string{})
+		assert.ErrorIs(t, err, secrets.ErrNoMasterKey)
+
+		_, err = secrets.NewKeyring(map[int]string{1: ""})
+		assert.ErrorIs(t, err, secrets.ErrNoMasterKey)
+	})
+}
+
+func TestEnvelope(t *testing.T) {
+	dataKey, err := secrets.NewDataKey()
+	assert.NoError(t, err)
+
+	sealed, err := secrets.Seal(dataKey, []byte("efirma"))
+	assert.NoError(t, err)
+
+	plaintext, err := secrets.Open(dataKey, sealed)
+	assert.NoError(t, err)
+	assert.Equal(t, "efirma", string(plaintext))
+
+	otherKey, err := secrets.NewDataKey()
+	assert.NoError(t, err)
+
+	_, err = secrets.Open(otherKey, sealed)
+	assert.Error(t, err)
+}
@@ -0,0 +1,74 @@
+package secrets_test
+
+import (
+	"app/src/secrets"
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVaultTransit(t *testing.T) {
+	ctx := context.Background()
+
+	standIn := secrets.NewTransitStandIn("root-token", "transit")
+	_, err := standIn.Rotate("datos-fiscales")
+	assert.NoError(t, err)
+
+	server := httptest.NewServer(standIn)
+	defer server.Close()
+
+	provider, err := secrets.NewVaultTransit(server.URL, "root-token", "transit", "datos-fiscales")
+	assert.NoError(t, err)
+
+	dataKey, err := secrets.NewDataKey()
+	assert.NoError(t, err)
+
+	t.Run("should wrap and unwrap a data key", func(t *testing.T) {
+		wrapped, version, err := provider.Wrap(ctx, dataKey)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, version)
+		assert.Contains(t, wrapped, "vault:v1:")
+
+		unwrapped, err := provider.Unwrap(ctx, wrapped, version)
+		assert.NoError(t, err)
+		assert.Equal(t, dataKey, unwrapped)
+	})
+
+	t.Run("should keep old versions readable after rotating", func(t *testing.T) {
+		wrapped, version, err := provider.Wrap(ctx, dataKey)
+		assert.NoError(t, err)
+
+		_, err = standIn.Rotate("datos-fiscales")
+		assert.NoError(t, err)
+
+		current, err := provider.CurrentVersion(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, version+1, current)
+
+		unwrapped, err := provider.Unwrap(ctx, wrapped, version)
+		assert.NoError(t, err)
+		assert.Equal(t, dataKey, unwrapped)
+
+		_, newVersion, err := provider.Wrap(ctx, dataKey)
+		assert.NoError(t, err)
+		assert.Equal(t, current, newVersion)
+	})
+
+	t.Run("should reject a version that does not match the ciphertext", func(t *testing.T) {
+		wrapped, version, err := provider.Wrap(ctx, dataKey)
+		assert.NoError(t, err)
+
+		_, err = provider.Unwrap(ctx, wrapped, version-1)
+		assert.ErrorIs(t, err, secrets.ErrUnknownKeyVersion)
+	})
+
+	t.Run("should fail with a wrong token", func(t *testing.T) {
+		unauthorized, err := secrets.NewVaultTransit(server.URL, "wrong", "transit", "datos-fiscales")
+		assert.NoError(t, err)
+
+		_, _, err = unauthorized.Wrap(ctx, dataKey)
+		assert.ErrorContains(t, err, "permission denied")
+	})
+}
@@ -0,0 +1,207 @@
+package sello_test
+
+import (
+	"app/src/cfdi"
+	"app/src/sello"
+	"app/test/fixture"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Placeholder values of the ingreso_40 fixture that sellar replaces.
+const (
+	selloFixture       = "c2VsbG8gZGUgcHJ1ZWJhIGluZ3Jlc28gNC4w"
+	certificadoFixture = "Y2VydGlmaWNhZG8gZGUgcHJ1ZWJh"
+	selloSATFixture    = "c2VsbG8gU0FUIGRlIHBydWViYQ=="
+)
+
+func firmar(t *testing.T, efirma *fixture.Efirma, cadena string) string {
+	t.Helper()
+
+	digest := sha256.Sum256([]byte(cadena))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, efirma.PrivateKey, crypto.SHA256, digest[:])
+	if err != nil {
+		t.Fatalf("sign cadena: %v", err)
+	}
+
+	return base64.StdEncoding.EncodeToString(signature)
+}
+
+// Cadenas originales of the ingreso_40 fixture, written out from
+// cadenaoriginal_4_0.xslt and cadenaoriginal_TFD_1_1.xslt rather than taken
+// from cfdi.CadenasOriginales, so a change in the cadenas Verificar builds
+// makes the seals below fail.
+const (
+	cadenaIngreso40 = "||4.0|A|1024|2024-01-15T10:30:00|03|30001000000500003416|Contado|1451.50|51.50|MXN|1624.00|I|01|PUE|26015" +
+		"|EKU9003173C9|ESCUELA KEMPER URGATE|601|XIA190128J61|XENON INDUSTRIAL ARTICLES|76343|601|G03" +
+		"|43211503|LAP-001|2|H87|Pieza|Computadora portátil|500.00|1000.00|02|1000.00|002|Tasa|0.160000|160.00" +
+		"|81112101|3|E48|Servicio|Soporte técnico remoto|150.50|451.50|51.50|02|400.00|002|Tasa|0.160000|64.00" +
+		"|1400.00|002|Tasa|0.160000|224.00|224.00||"
+	timbreIngreso40 = "||1.1|5b4b4d8e-2c6f-4a0b-9f4e-0e5c1a2b3c4d|2024-01-15T10:31:12|SPR190613I52|%s|30001000000500003456||"
+)
+
+// sellar seals the ingreso_40 fixture with the emisor e.firma and stamps it
+// with the SAT one, the way a PAC would.
+func sellar(t *testing.T, emisor, sat *fixture.Efirma) []byte {
+	t.Helper()
+
+	data, err := fixture.CFDI(fixture.CFDIIngreso40)
+	if err != nil {
+		t.Fatalf("read fixture: %v", err)
+	}
+
+	documento := strings.Replace(string(data), certificadoFixture, base64.StdEncoding.EncodeToString(emisor.Certificate), 1)
+
+	selloCFD := firmar(t, emisor, cadenaIngreso40)
+	documento = strings.ReplaceAll(documento, selloFixture, selloCFD)
+
+	return []byte(strings.Replace(documento, selloSATFixture, firmar(t, sat, fmt.Sprintf(timbreIngreso40, selloCFD)), 1))
+}
+
+func verificar(data []byte, almacen *sello.Almacen) error {
+	comprobante, err := cfdi.Parse(data)
+	if err != nil {
+		return err
+	}
+
+	return sello.Verificar(data, comprobante, almacen)
+}
+
+func TestVerificar(t *testing.T) {
+	desde := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	hasta := time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	emisor, err := fixture.NewEfirma("EKU9003173C9", "30001000000500003416", fixture.SATIssuer, desde, hasta)
+	assert.NoError(t, err)
+	sat, err := fixture.NewEfirma("SAT970701NN3", "30001000000500003456", fixture.SATIssuer, desde, hasta)
+	assert.NoError(t, err)
+
+	autoridades, err := fixture.SATAutoridades()
+	assert.NoError(t, err)
+
+	almacen := sello.NewAlmacen()
+	assert.NoError(t, almacen.AgregarDER(sat.Certificate))
+	almacen.ConfiarEn(autoridades)
+
+	t.Run("should accept a CFDI sealed and stamped with known certificates", func(t *testing.T) {
+		assert.NoError(t, verificar(sellar(t, emisor, sat), almacen))
+	})
+
+	t.Run("should reject a CFDI changed after it was sealed", func(t *testing.T) {
+		data := strings.Replace(string(sellar(t, emisor, sat)), ` Total="1624.00"`, ` Total="1642.00"`, 1)
+
+		assert.ErrorIs(t, verificar([]byte(data), almacen), sello.ErrSelloInvalido)
+	})
+
+	t.Run("should reject a timbre changed after it was stamped", func(t *testing.T) {
+		data := strings.Replace(string(sellar(t, emisor, sat)), "2024-01-15T10:31:12", "2024-01-15T10:31:13", 1)
+
+		assert.ErrorIs(t, verificar([]byte(data), almacen), sello.ErrSelloSATInvalido)
+	})
+
+	t.Run("should reject a SelloCFD that is not the Sello", func(t *testing.T) {
+		data := []byte(strings.Replace(string(sellar(t, emisor, sat)), `SelloCFD="`, `SelloCFD="AAAA`, 1))
+
+		assert.ErrorIs(t, verificar(data, almacen), sello.ErrSelloCFDNoCorresponde)
+	})
+
+	t.Run("should reject a timbre of an unknown certificate", func(t *testing.T) {
+		desconocido := sello.NewAlmacen()
+		desconocido.ConfiarEn(autoridades)
+		err := verificar(sellar(t, emisor, sat), desconocido)
+
+		assert.ErrorIs(t, err, sello.ErrCertificadoSATDesconocido)
+		assert.Contains(t, err.Error(), "30001000000500003456")
+	})
+
+	t.Run("should reject a certificate of another RFC or number", func(t *testing.T) {
+		otro, err := fixture.NewEfirma("XIA190128J61", "30001000000500003416", fixture.SATIssuer, desde, hasta)
+		assert.NoError(t, err)
+
+		assert.ErrorIs(t, verificar(sellar(t, otro, sat), almacen), sello.ErrCertificadoNoCorresponde)
+
+		data := []byte(strings.Replace(string(sellar(t, emisor, sat)),
+			`NoCertificado="30001000000500003416"`, `NoCertificado="30001000000500003417"`, 1))
+		assert.ErrorIs(t, verificar(data, almacen), sello.ErrCertificadoNoCorresponde)
+	})
+
+	t.Run("should reject a certificate that was not valid at the fecha", func(t *testing.T) {
+		vencido, err := fixture.NewEfirma("EKU9003173C9", "30001000000500003416", fixture.SATIssuer,
+			desde, time.Date(2023, 12, 31, 0, 0, 0, 0, time.UTC))
+		assert.NoError(t, err)
+
+		assert.ErrorIs(t, verificar(sellar(t, vencido, sat), almacen), sello.ErrCertificadoNoVigente)
+	})
+
+	t.Run("should reject a certificate not issued by the SAT", func(t *testing.T) {
+		forged, err := fixture.ForgedEfirma("EKU9003173C9", desde, hasta)
+		assert.NoError(t, err)
+
+		assert.ErrorIs(t, verificar(sellar(t, forged, sat), almacen), sello.ErrCertificadoNoConfiable)
+		assert.ErrorIs(t, verificar(sellar(t, emisor, sat), sello.NewAlmacen()), sello.ErrCertificadoNoConfiable)
+	})
+
+	t.Run("should reject placeholder certificates and unstamped CFDIs", func(t *testing.T) {
+		data, err := fixture.CFDI(fixture.CFDIIngreso40)
+		assert.NoError(t, err)
+		assert.ErrorIs(t, verificar(data, almacen), sello.ErrCertificadoInvalido)
+
+		comprobante, err := cfdi.Parse(data)
+		assert.NoError(t, err)
+		comprobante.Complemento.TimbreFiscalDigital = nil
+		assert.ErrorIs(t, sello.Verificar(data, comprobante, almacen), sello.ErrSinTimbre)
+	})
+}
+
+func TestCargarAlmacen(t *testing.T) {
+	desde := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	hasta := time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	uno, err := fixture.NewEfirma("SAT970701NN3", "30001000000500003456", fixture.SATIssuer, desde, hasta)
+	assert.NoError(t, err)
+	dos, err := fixture.NewEfirma("SAT970701NN3", "00001000000504465028", fixture.SATIssuer, desde, hasta)
+	assert.NoError(t, err)
+
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "30001000000500003456.cer"), uno.Certificate, 0o600))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "00001000000504465028.pem"),
+		pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: dos.Certificate}), 0o600))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "LEEME.txt"), []byte("not a certificate"), 0o600))
+
+	t.Run("should load the DER and PEM certificates", func(t *testing.T) {
+		almacen, err := sello.CargarAlmacen(dir)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, almacen.Len())
+
+		_, ok := almacen.Certificado("30001000000500003456")
+		assert.True(t, ok)
+		_, ok = almacen.Certificado("00001000000504465028")
+		assert.True(t, ok)
+		_, ok = almacen.Certificado("30001000000500003416")
+		assert.False(t, ok)
+	})
+
+	t.Run("should fail on invalid certificates", func(t *testing.T) {
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, "roto.cer"), []byte("not DER"), 0o600))
+
+		_, err := sello.CargarAlmacen(dir)
+		assert.Error(t, err)
+	})
+
+	t.Run("should fail on a missing dir", func(t *testing.T) {
+		_, err := sello.CargarAlmacen(filepath.Join(dir, "missing"))
+		assert.Error(t, err)
+	})
+}
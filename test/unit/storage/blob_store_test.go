@@ -0,0 +1,82 @@
+package storage_test
+
+import (
+	"app/src/storage"
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testBlobStore(t *testing.T, store storage.BlobStore) {
+	ctx := context.Background()
+
+	t.Run("should store, read and delete objects", func(t *testing.T) {
+		err := store.Put(ctx, "paquetes/EKU9003173C9/SOLICITUD_01.zip", []byte("zip content"))
+		assert.NoError(t, err)
+
+		data, err := store.Get(ctx, "paquetes/EKU9003173C9/SOLICITUD_01.zip")
+		assert.NoError(t, err)
+		assert.Equal(t, []byte("zip content"), data)
+
+		assert.NoError(t, store.Delete(ctx, "paquetes/EKU9003173C9/SOLICITUD_01.zip"))
+
+		_, err = store.Get(ctx, "paquetes/EKU9003173C9/SOLICITUD_01.zip")
+		assert.ErrorIs(t, err, storage.ErrNotFound)
+	})
+
+	t.Run("should overwrite an existing object", func(t *testing.T) {
+		assert.NoError(t, store.Put(ctx, "paquetes/a.zip", []byte("first")))
+		assert.NoError(t, store.Put(ctx, "paquetes/a.zip", []byte("second")))
+
+		data, err := store.Get(ctx, "paquetes/a.zip")
+		assert.NoError(t, err)
+		assert.Equal(t, []byte("second"), data)
+	})
+
+	t.Run("should reject keys escaping the store", func(t *testing.T) {
+		for _, key := range []string{"", "/etc/passwd", "../outside.zip", "paquetes//a.zip", `paquetes\a.zip`} {
+			assert.ErrorIs(t, store.Put(ctx, key, []byte("x")), storage.ErrInvalidKey, key)
+		}
+	})
+
+	t.Run("should ignore deleting a missing object", func(t *testing.T) {
+		assert.NoError(t, store.Delete(ctx, "paquetes/missing.zip"))
+	})
+}
+
+func TestLocalStore(t *testing.T) {
+	store, err := storage.NewLocalStore(t.TempDir())
+	assert.NoError(t, err)
+
+	testBlobStore(t, store)
+}
+
+func TestS3Store(t *testing.T) {
+	standIn := storage.NewS3StandIn("access", "secret", "us-east-1", "paquetes-sat")
+	server := httptest.NewServer(standIn)
+	defer server.Close()
+
+	store, err := storage.NewS3Store(server.URL, "us-east-1", "paquetes-sat", "access", "secret")
+	assert.NoError(t, err)
+
+	testBlobStore(t, store)
+
+	t.Run("should reject requests signed with other credentials", func(t *testing.T) {
+		other, err := storage.NewS3Store(server.URL, "us-east-1", "paquetes-sat", "access", "wrong")
+		assert.NoError(t, err)
+
+		err = other.Put(context.Background(), "paquetes/b.zip", []byte("x"))
+		assert.ErrorContains(t, err, "HTTP 403")
+		assert.NotContains(t, standIn.Objects("paquetes-sat"), "paquetes/b.zip")
+	})
+
+	t.Run("should report a missing bucket", func(t *testing.T) {
+		other, err := storage.NewS3Store(server.URL, "us-east-1", "missing", "access", "secret")
+		assert.NoError(t, err)
+
+		_, err = other.Get(context.Background(), "paquetes/a.zip")
+		assert.ErrorIs(t, err, storage.ErrNotFound)
+	})
+}
@@ -0,0 +1,64 @@
+package validation_test
+
+import (
+	"app/src/cfdi"
+	"app/src/validation"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPlanValidation(t *testing.T) {
+	validate := validation.Validator()
+
+	precio := func(value string) *cfdi.Decimal {
+		decimal := cfdi.MustParseDecimal(value)
+		return &decimal
+	}
+
+	t.Run("should accept a valid plan", func(t *testing.T) {
+		for _, value := range []string{"0", "199", "199.9", "1499.99", "0.10", validation.MaxPrecio} {
+			err := validate.Struct(validation.CreatePlan{
+				Nombre:                   "Básico",
+				Descripcion:              "10 descargas masivas al mes",
+				LimiteDescargasMensuales: 10,
+				Precio:                   precio(value),
+			})
+			assert.NoError(t, err, value)
+		}
+	})
+
+	t.Run("should reject invalid prices", func(t *testing.T) {
+		for _, value := range []string{"-1", "19.999", "100000000.00"} {
+			err := validate.Struct(validation.CreatePlan{
+				Nombre:                   "Básico",
+				Descripcion:              "10 descargas masivas al mes",
+				LimiteDescargasMensuales: 10,
+				Precio:                   precio(value),
+			})
+			assert.Error(t, err, value)
+		}
+	})
+
+	t.Run("should require a price and a positive download limit", func(t *testing.T) {
+		err := validate.Struct(validation.CreatePlan{
+			Nombre:                   "Básico",
+			Descripcion:              "10 descargas masivas al mes",
+			LimiteDescargasMensuales: 10,
+		})
+		assert.Error(t, err)
+
+		limite := -5
+		err = validate.Struct(validation.UpdatePlan{LimiteDescargasMensuales: &limite})
+		assert.Error(t, err)
+	})
+
+	t.Run("should report the bounds in the error message", func(t *testing.T) {
+		limite := 0
+		err := validate.Struct(validation.UpdatePlan{LimiteDescargasMensuales: &limite})
+
+		messages := validation.CustomErrorMessages(err)
+		assert.Equal(t, "Field LimiteDescargasMensuales must be greater than or equal to 1",
+			messages["UpdatePlan.LimiteDescargasMensuales"])
+	})
+}
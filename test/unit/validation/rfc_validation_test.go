@@ -0,0 +1,20 @@
+package validation_test
+
+import (
+	"app/src/validation"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var validate = validation.Validator()
+
+func TestRFCValidation(t *testing.T) {
+	t.Run("should report the rfc tag through the custom messages", func(t *testing.T) {
+		err := validate.Struct(validation.DatosFiscalesRequest{RFC: "EKU9003173C8", Password: "password1"})
+		assert.Error(t, err)
+
+		messages := validation.CustomErrorMessages(err)
+		assert.Contains(t, messages["DatosFiscalesRequest.RFC"], "must be a valid RFC")
+	})
+}
@@ -0,0 +1,64 @@
+package worker_test
+
+import (
+	"app/src/worker"
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWorker(t *testing.T) {
+	t.Run("should run the job periodically until stopped", func(t *testing.T) {
+		var runs atomic.Int32
+		w := worker.New("test", 10*time.Millisecond, func(ctx context.Context) error {
+			runs.Add(1)
+			return errors.New("failures do not stop the worker")
+		})
+
+		w.Start(context.Background())
+		assert.Eventually(t, func() bool { return runs.Load() >= 3 }, time.Second, 5*time.Millisecond)
+
+		w.Stop()
+		stopped := runs.Load()
+		time.Sleep(50 * time.Millisecond)
+		assert.Equal(t, stopped, runs.Load())
+	})
+
+	t.Run("should cancel the running job on stop", func(t *testing.T) {
+		started := make(chan struct{})
+		cancelled := make(chan struct{})
+
+		w := worker.New("test", time.Hour, func(ctx context.Context) error {
+			close(started)
+			<-ctx.Done()
+			close(cancelled)
+			return ctx.Err()
+		})
+
+		w.Start(context.Background())
+		<-started
+		w.Stop()
+
+		select {
+		case <-cancelled:
+		default:
+			t.Fatal("Stop returned before the job was cancelled")
+		}
+	})
+
+	t.Run("should recover from a panicking job", func(t *testing.T) {
+		var runs atomic.Int32
+		w := worker.New("test", 10*time.Millisecond, func(ctx context.Context) error {
+			runs.Add(1)
+			panic("boom")
+		})
+
+		w.Start(context.Background())
+		assert.Eventually(t, func() bool { return runs.Load() >= 2 }, time.Second, 5*time.Millisecond)
+		w.Stop()
+	})
+}